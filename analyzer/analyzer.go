@@ -12,6 +12,11 @@ import (
 	"sort"
 )
 
+// MaxLocations caps the positions kept per node type in
+// AnalysisResult.Locations, so analyzing a large corpus does not hold a
+// position for every node. NodeCounts always has the full counts.
+const MaxLocations = 100
+
 // NodeCount tracks the count of each AST node type.
 type NodeCount struct {
 	Type  string
@@ -20,10 +25,26 @@ type NodeCount struct {
 
 // AnalysisResult contains the results of AST analysis.
 type AnalysisResult struct {
-	FileName   string
-	NodeCounts map[string]int
-	TotalNodes int
+	FileName    string
+	NodeCounts  map[string]int
+	TotalNodes  int
 	UniqueTypes int
+
+	// Locations records where each node type occurs, in source order,
+	// keeping at most MaxLocations positions per type.
+	Locations map[string][]token.Position
+
+	// TokenCounts records operator and keyword usage by token group, as
+	// returned by CountTokens.
+	TokenCounts map[string]map[string]int
+
+	// VariantCounts records structural variant usage, as returned by
+	// CountVariants.
+	VariantCounts map[string]int
+
+	// TokenKindCounts records lexical token usage, as returned by
+	// ScanTokenKinds. It is only set when the source text is available.
+	TokenKindCounts map[string]int
 }
 
 // AnalyzeFile parses a Go source file and returns analysis results.
@@ -41,11 +62,53 @@ func AnalyzeFile(filePath string) (*AnalysisResult, error) {
 		return nil, fmt.Errorf("failed to parse file: %w", err)
 	}
 
-	// Count nodes
+	result := AnalyzeAST(filePath, fset, file)
+	result.TokenKindCounts = ScanTokenKinds(src)
+	return result, nil
+}
+
+// AnalyzeAST returns analysis results for an already parsed file, labeled
+// with fileName.
+func AnalyzeAST(fileName string, fset *token.FileSet, file *ast.File) *AnalysisResult {
+	nodeCounts, totalNodes := CountNodes(file)
+
+	return &AnalysisResult{
+		FileName:      fileName,
+		NodeCounts:    nodeCounts,
+		TotalNodes:    totalNodes,
+		UniqueTypes:   len(nodeCounts),
+		Locations:     NodeLocations(fset, file),
+		TokenCounts:   CountTokens(file),
+		VariantCounts: CountVariants(file),
+	}
+}
+
+// NodeLocations walks the tree rooted at root and returns the start
+// positions of its nodes, grouped by type name: the first MaxLocations of
+// each type.
+func NodeLocations(fset *token.FileSet, root ast.Node) map[string][]token.Position {
+	locations := make(map[string][]token.Position)
+
+	ast.Inspect(root, func(n ast.Node) bool {
+		if n != nil {
+			nodeType := fmt.Sprintf("%T", n)
+			if len(locations[nodeType]) < MaxLocations {
+				locations[nodeType] = append(locations[nodeType], fset.Position(n.Pos()))
+			}
+		}
+		return true
+	})
+
+	return locations
+}
+
+// CountNodes walks the tree rooted at root and returns the number of nodes
+// of each type, keyed by type name (e.g. "*ast.Ident"), along with the total.
+func CountNodes(root ast.Node) (map[string]int, int) {
 	nodeCounts := make(map[string]int)
 	totalNodes := 0
 
-	ast.Inspect(file, func(n ast.Node) bool {
+	ast.Inspect(root, func(n ast.Node) bool {
 		if n != nil {
 			nodeType := fmt.Sprintf("%T", n)
 			nodeCounts[nodeType]++
@@ -54,12 +117,7 @@ func AnalyzeFile(filePath string) (*AnalysisResult, error) {
 		return true
 	})
 
-	return &AnalysisResult{
-		FileName:    filePath,
-		NodeCounts:  nodeCounts,
-		TotalNodes:  totalNodes,
-		UniqueTypes: len(nodeCounts),
-	}, nil
+	return nodeCounts, totalNodes
 }
 
 // PrintAnalysis prints the analysis results in a human-readable format.
@@ -84,78 +142,17 @@ func PrintAnalysis(result *AnalysisResult) {
 	fmt.Println("========================================")
 }
 
-// GetAllNodeTypes returns a list of all AST node types defined in go/ast package.
+// GetAllNodeTypes returns every AST node type defined in the go/ast
+// package, sorted, except those listed in excludedNodeTypes. The list is
+// generated from the go/ast sources by go generate.
 func GetAllNodeTypes() []string {
-	// This is a comprehensive list of all AST node types
-	return []string{
-		// Expression nodes
-		"*ast.BadExpr",
-		"*ast.Ident",
-		"*ast.Ellipsis",
-		"*ast.BasicLit",
-		"*ast.FuncLit",
-		"*ast.CompositeLit",
-		"*ast.ParenExpr",
-		"*ast.SelectorExpr",
-		"*ast.IndexExpr",
-		"*ast.IndexListExpr", // Go 1.18+ generics
-		"*ast.SliceExpr",
-		"*ast.TypeAssertExpr",
-		"*ast.CallExpr",
-		"*ast.StarExpr",
-		"*ast.UnaryExpr",
-		"*ast.BinaryExpr",
-		"*ast.KeyValueExpr",
-
-		// Statement nodes
-		"*ast.BadStmt",
-		"*ast.DeclStmt",
-		"*ast.EmptyStmt",
-		"*ast.LabeledStmt",
-		"*ast.ExprStmt",
-		"*ast.SendStmt",
-		"*ast.IncDecStmt",
-		"*ast.AssignStmt",
-		"*ast.GoStmt",
-		"*ast.DeferStmt",
-		"*ast.ReturnStmt",
-		"*ast.BranchStmt",
-		"*ast.BlockStmt",
-		"*ast.IfStmt",
-		"*ast.CaseClause",
-		"*ast.SwitchStmt",
-		"*ast.TypeSwitchStmt",
-		"*ast.CommClause",
-		"*ast.SelectStmt",
-		"*ast.ForStmt",
-		"*ast.RangeStmt",
-
-		// Declaration nodes
-		"*ast.BadDecl",
-		"*ast.GenDecl",
-		"*ast.FuncDecl",
-
-		// Spec nodes
-		"*ast.ImportSpec",
-		"*ast.ValueSpec",
-		"*ast.TypeSpec",
-
-		// Other important nodes
-		"*ast.File",
-		"*ast.Package",
-		"*ast.Comment",
-		"*ast.CommentGroup",
-		"*ast.Field",
-		"*ast.FieldList",
-
-		// Type nodes
-		"*ast.ArrayType",
-		"*ast.StructType",
-		"*ast.FuncType",
-		"*ast.InterfaceType",
-		"*ast.MapType",
-		"*ast.ChanType",
+	all := make([]string, 0, len(nodeTypes))
+	for _, nodeType := range nodeTypes {
+		if _, excluded := excludedNodeTypes[nodeType]; !excluded {
+			all = append(all, nodeType)
+		}
 	}
+	return all
 }
 
 // CompareWithExpected compares analysis results with expected node types.
@@ -185,27 +182,65 @@ func CompareWithExpected(result *AnalysisResult, expectedTypes []string) {
 	fmt.Println("========================================")
 }
 
-// AnalyzeDirectory analyzes all Go files in a directory.
+// FileError records a file that could not be analyzed.
+type FileError struct {
+	FileName string
+	Err      error
+}
+
+// AnalyzeDirectory analyzes all Go files in a directory, leaving out any
+// file that cannot be analyzed. Callers that need to know which files were
+// left out use AnalyzeDirectoryWithSkips.
 func AnalyzeDirectory(dirPath string) ([]*AnalysisResult, error) {
+	results, _, err := AnalyzeDirectoryWithSkips(dirPath)
+	return results, err
+}
+
+// AnalyzeDirectoryWithSkips analyzes all Go files in a directory and
+// returns the files that could not be analyzed alongside the results. It
+// only fails if the directory cannot be read.
+func AnalyzeDirectoryWithSkips(dirPath string) ([]*AnalysisResult, []FileError, error) {
+	files, err := ListGoFiles(dirPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	results, skipped := AnalyzeFiles(files)
+	return results, skipped, nil
+}
+
+// AnalyzeFiles analyzes the given Go files, returning the files that could
+// not be analyzed alongside the results.
+func AnalyzeFiles(files []string) ([]*AnalysisResult, []FileError) {
+	var results []*AnalysisResult
+	var skipped []FileError
+	for _, filePath := range files {
+		result, err := AnalyzeFile(filePath)
+		if err != nil {
+			skipped = append(skipped, FileError{FileName: filePath, Err: err})
+			continue
+		}
+		results = append(results, result)
+	}
+
+	return results, skipped
+}
+
+// ListGoFiles returns the paths of the Go files directly in a directory,
+// in directory order.
+func ListGoFiles(dirPath string) ([]string, error) {
 	entries, err := os.ReadDir(dirPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read directory: %w", err)
 	}
 
-	var results []*AnalysisResult
+	var files []string
 	for _, entry := range entries {
 		if !entry.IsDir() && len(entry.Name()) > 3 && entry.Name()[len(entry.Name())-3:] == ".go" {
-			filePath := dirPath + "/" + entry.Name()
-			result, err := AnalyzeFile(filePath)
-			if err != nil {
-				fmt.Printf("Warning: failed to analyze %s: %v\n", filePath, err)
-				continue
-			}
-			results = append(results, result)
+			files = append(files, dirPath+"/"+entry.Name())
 		}
 	}
-
-	return results, nil
+	return files, nil
 }
 
 // AnalyzePackage parses a directory as a package to exercise ast.Package nodes.
@@ -229,11 +264,17 @@ func AnalyzePackage(dirPath string) error {
 	return nil
 }
 
-// AggregateResults combines multiple analysis results into one.
+// AggregateResults combines multiple analysis results into one. Locations
+// are ordered by file name and then position, so the result does not
+// depend on the order of results, and capped at MaxLocations per type.
 func AggregateResults(results []*AnalysisResult) *AnalysisResult {
 	aggregated := &AnalysisResult{
-		FileName:   "Aggregated",
-		NodeCounts: make(map[string]int),
+		FileName:        "Aggregated",
+		NodeCounts:      make(map[string]int),
+		Locations:       make(map[string][]token.Position),
+		TokenCounts:     make(map[string]map[string]int),
+		VariantCounts:   make(map[string]int),
+		TokenKindCounts: make(map[string]int),
 	}
 
 	for _, result := range results {
@@ -241,6 +282,35 @@ func AggregateResults(results []*AnalysisResult) *AnalysisResult {
 			aggregated.NodeCounts[nodeType] += count
 			aggregated.TotalNodes += count
 		}
+		for nodeType, positions := range result.Locations {
+			aggregated.Locations[nodeType] = append(aggregated.Locations[nodeType], positions...)
+		}
+		for group, counts := range result.TokenCounts {
+			if aggregated.TokenCounts[group] == nil {
+				aggregated.TokenCounts[group] = make(map[string]int)
+			}
+			for tok, count := range counts {
+				aggregated.TokenCounts[group][tok] += count
+			}
+		}
+		for variant, count := range result.VariantCounts {
+			aggregated.VariantCounts[variant] += count
+		}
+		for kind, count := range result.TokenKindCounts {
+			aggregated.TokenKindCounts[kind] += count
+		}
+	}
+
+	for nodeType, positions := range aggregated.Locations {
+		sort.SliceStable(positions, func(i, j int) bool {
+			if positions[i].Filename != positions[j].Filename {
+				return positions[i].Filename < positions[j].Filename
+			}
+			return positions[i].Offset < positions[j].Offset
+		})
+		if len(positions) > MaxLocations {
+			aggregated.Locations[nodeType] = positions[:MaxLocations:MaxLocations]
+		}
 	}
 
 	aggregated.UniqueTypes = len(aggregated.NodeCounts)
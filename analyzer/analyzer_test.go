@@ -0,0 +1,38 @@
+package analyzer
+
+import (
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+)
+
+// TestNodeLocationsCap tests that locations are capped per node type while
+// counts stay complete, for single files and aggregated results
+func TestNodeLocationsCap(t *testing.T) {
+	src := "package p\n\nvar _ = []int{" + strings.Repeat("1, ", MaxLocations+50) + "}\n"
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "p.go", src, 0)
+	if err != nil {
+		t.Fatalf("failed to parse source: %v", err)
+	}
+
+	result := AnalyzeAST("p.go", fset, file)
+	if got := len(result.Locations["*ast.BasicLit"]); got != MaxLocations {
+		t.Errorf("expected %d BasicLit locations, got %d", MaxLocations, got)
+	}
+	if got := result.NodeCounts["*ast.BasicLit"]; got != MaxLocations+50 {
+		t.Errorf("expected %d BasicLits counted, got %d", MaxLocations+50, got)
+	}
+	if got := result.Locations["*ast.ArrayType"]; len(got) != 1 || got[0].Line != 3 {
+		t.Errorf("expected one ArrayType on line 3, got %v", got)
+	}
+
+	aggregated := AggregateResults([]*AnalysisResult{result, result})
+	if got := len(aggregated.Locations["*ast.BasicLit"]); got != MaxLocations {
+		t.Errorf("expected aggregated BasicLit locations capped at %d, got %d", MaxLocations, got)
+	}
+	if got := aggregated.NodeCounts["*ast.BasicLit"]; got != 2*(MaxLocations+50) {
+		t.Errorf("expected %d aggregated BasicLits counted, got %d", 2*(MaxLocations+50), got)
+	}
+}
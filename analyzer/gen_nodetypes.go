@@ -0,0 +1,42 @@
+//go:build ignore
+
+// gen_nodetypes writes nodetypes_gen.go, the table of go/ast node types
+// behind GetAllNodeTypes. Run it with go generate after a toolchain
+// upgrade.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"runtime"
+
+	"zylisp/go-ast-coverage/analyzer"
+)
+
+func main() {
+	nodeTypes, err := analyzer.EnumerateNodeTypes()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by gen_nodetypes.go from %s; DO NOT EDIT.\n\n", runtime.Version())
+	buf.WriteString("package analyzer\n\n")
+	buf.WriteString("// nodeTypes lists every go/ast struct type implementing ast.Node.\n")
+	buf.WriteString("var nodeTypes = []string{\n")
+	for _, nodeType := range nodeTypes {
+		fmt.Fprintf(&buf, "\t%q,\n", nodeType)
+	}
+	buf.WriteString("}\n")
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile("nodetypes_gen.go", src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,81 @@
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// maxCloseMatches limits how many close matches LookupNodeType suggests.
+const maxCloseMatches = 3
+
+// LookupNodeType resolves a node type name given as "*ast.SelectStmt",
+// "ast.SelectStmt", or "SelectStmt" to its full name. An unknown name is
+// an error listing the closest known names.
+func LookupNodeType(name string) (string, error) {
+	bare := strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(name), "*"), "ast.")
+	for _, nodeType := range nodeTypes {
+		if nodeType == "*ast."+bare {
+			return nodeType, nil
+		}
+	}
+
+	matches := closeNodeTypes(bare)
+	if len(matches) == 0 {
+		return "", fmt.Errorf("unknown node type %q", name)
+	}
+	return "", fmt.Errorf("unknown node type %q (did you mean %s?)", name, strings.Join(matches, ", "))
+}
+
+// closeNodeTypes returns the node types whose bare names are nearest to
+// bare, ignoring case: those it is a prefix or part of first, then those
+// within a few edits.
+func closeNodeTypes(bare string) []string {
+	type candidate struct {
+		name     string
+		distance int
+	}
+
+	want := strings.ToLower(bare)
+	var candidates []candidate
+	for _, nodeType := range nodeTypes {
+		have := strings.ToLower(strings.TrimPrefix(nodeType, "*ast."))
+		distance := editDistance(want, have)
+		if want != "" && strings.Contains(have, want) {
+			distance = 0
+		}
+		if distance <= max(2, len(want)/3) {
+			candidates = append(candidates, candidate{nodeType, distance})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].distance < candidates[j].distance
+	})
+	var matches []string
+	for i := 0; i < len(candidates) && i < maxCloseMatches; i++ {
+		matches = append(matches, candidates[i].name)
+	}
+	return matches
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,54 @@
+package analyzer
+
+import (
+	"fmt"
+	"go/importer"
+	"go/token"
+	"go/types"
+	"sort"
+)
+
+//go:generate go run gen_nodetypes.go
+
+// excludedNodeTypes lists go/ast types that implement ast.Node but never
+// appear in a parsed tree, with the reason they are not expected.
+var excludedNodeTypes = map[string]string{
+	"*ast.Directive": "built by ast.ParseDirective from a comment, never by the parser",
+}
+
+// EnumerateNodeTypes type-checks the go/ast package from the toolchain's
+// source and returns, sorted, every exported struct type whose pointer
+// implements ast.Node, as "*ast.Name". It needs GOROOT sources, so it runs
+// when generating nodeTypes and in tests rather than in normal use.
+func EnumerateNodeTypes() ([]string, error) {
+	pkg, err := importer.ForCompiler(token.NewFileSet(), "source", nil).Import("go/ast")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load go/ast: %w", err)
+	}
+
+	nodeObj := pkg.Scope().Lookup("Node")
+	if nodeObj == nil {
+		return nil, fmt.Errorf("go/ast has no Node interface")
+	}
+	node, ok := nodeObj.Type().Underlying().(*types.Interface)
+	if !ok {
+		return nil, fmt.Errorf("ast.Node is not an interface")
+	}
+
+	var nodeTypes []string
+	for _, name := range pkg.Scope().Names() {
+		obj, ok := pkg.Scope().Lookup(name).(*types.TypeName)
+		if !ok || !obj.Exported() {
+			continue
+		}
+		if _, isStruct := obj.Type().Underlying().(*types.Struct); !isStruct {
+			continue
+		}
+		if types.Implements(types.NewPointer(obj.Type()), node) {
+			nodeTypes = append(nodeTypes, "*ast."+name)
+		}
+	}
+	sort.Strings(nodeTypes)
+
+	return nodeTypes, nil
+}
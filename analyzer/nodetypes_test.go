@@ -0,0 +1,162 @@
+package analyzer
+
+import (
+	"sort"
+	"strings"
+	"testing"
+)
+
+// legacyNodeTypes is the hand-maintained list GetAllNodeTypes returned
+// before it was generated. Differences from it must be listed in
+// intentionalNodeTypeChanges.
+var legacyNodeTypes = []string{
+	// Expression nodes
+	"*ast.BadExpr",
+	"*ast.Ident",
+	"*ast.Ellipsis",
+	"*ast.BasicLit",
+	"*ast.FuncLit",
+	"*ast.CompositeLit",
+	"*ast.ParenExpr",
+	"*ast.SelectorExpr",
+	"*ast.IndexExpr",
+	"*ast.IndexListExpr", // Go 1.18+ generics
+	"*ast.SliceExpr",
+	"*ast.TypeAssertExpr",
+	"*ast.CallExpr",
+	"*ast.StarExpr",
+	"*ast.UnaryExpr",
+	"*ast.BinaryExpr",
+	"*ast.KeyValueExpr",
+
+	// Statement nodes
+	"*ast.BadStmt",
+	"*ast.DeclStmt",
+	"*ast.EmptyStmt",
+	"*ast.LabeledStmt",
+	"*ast.ExprStmt",
+	"*ast.SendStmt",
+	"*ast.IncDecStmt",
+	"*ast.AssignStmt",
+	"*ast.GoStmt",
+	"*ast.DeferStmt",
+	"*ast.ReturnStmt",
+	"*ast.BranchStmt",
+	"*ast.BlockStmt",
+	"*ast.IfStmt",
+	"*ast.CaseClause",
+	"*ast.SwitchStmt",
+	"*ast.TypeSwitchStmt",
+	"*ast.CommClause",
+	"*ast.SelectStmt",
+	"*ast.ForStmt",
+	"*ast.RangeStmt",
+
+	// Declaration nodes
+	"*ast.BadDecl",
+	"*ast.GenDecl",
+	"*ast.FuncDecl",
+
+	// Spec nodes
+	"*ast.ImportSpec",
+	"*ast.ValueSpec",
+	"*ast.TypeSpec",
+
+	// Other important nodes
+	"*ast.File",
+	"*ast.Package",
+	"*ast.Comment",
+	"*ast.CommentGroup",
+	"*ast.Field",
+	"*ast.FieldList",
+
+	// Type nodes
+	"*ast.ArrayType",
+	"*ast.StructType",
+	"*ast.FuncType",
+	"*ast.InterfaceType",
+	"*ast.MapType",
+	"*ast.ChanType",
+}
+
+// intentionalNodeTypeChanges lists node types deliberately added to (true)
+// or dropped from (false) the legacy list.
+var intentionalNodeTypeChanges = map[string]bool{}
+
+// TestNodeTypesMatchLegacyList tests that the generated list only differs
+// from the legacy list where intended
+func TestNodeTypesMatchLegacyList(t *testing.T) {
+	current := make(map[string]bool)
+	for _, nodeType := range GetAllNodeTypes() {
+		current[nodeType] = true
+	}
+	legacy := make(map[string]bool)
+	for _, nodeType := range legacyNodeTypes {
+		legacy[nodeType] = true
+	}
+
+	for nodeType := range current {
+		if !legacy[nodeType] && !intentionalNodeTypeChanges[nodeType] {
+			t.Errorf("%s is new; categorize it and record it in intentionalNodeTypeChanges", nodeType)
+		}
+	}
+	for nodeType := range legacy {
+		if added, ok := intentionalNodeTypeChanges[nodeType]; !current[nodeType] && !(ok && !added) {
+			t.Errorf("%s was dropped; record it in intentionalNodeTypeChanges", nodeType)
+		}
+	}
+}
+
+// TestGeneratedNodeTypesUpToDate tests that the generated table covers
+// every node type of the current toolchain
+func TestGeneratedNodeTypesUpToDate(t *testing.T) {
+	live, err := EnumerateNodeTypes()
+	if err != nil {
+		t.Skipf("go/ast sources unavailable: %v", err)
+	}
+
+	generated := make(map[string]bool)
+	for _, nodeType := range nodeTypes {
+		generated[nodeType] = true
+	}
+	for _, nodeType := range live {
+		if !generated[nodeType] {
+			t.Errorf("%s is missing from nodetypes_gen.go; run go generate ./analyzer", nodeType)
+		}
+	}
+
+	if !sort.StringsAreSorted(nodeTypes) {
+		t.Error("expected nodetypes_gen.go to be sorted")
+	}
+	for nodeType := range excludedNodeTypes {
+		if !generated[nodeType] {
+			t.Errorf("excluded type %s is not in the generated table", nodeType)
+		}
+	}
+}
+
+// TestLookupNodeType tests resolving node type names and suggesting close
+// matches for unknown ones
+func TestLookupNodeType(t *testing.T) {
+	for _, name := range []string{"*ast.SelectStmt", "ast.SelectStmt", " SelectStmt"} {
+		if got, err := LookupNodeType(name); err != nil || got != "*ast.SelectStmt" {
+			t.Errorf("LookupNodeType(%q) = %q, %v", name, got, err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"SelectStmnt", `unknown node type "SelectStmnt" (did you mean *ast.SelectStmt`},
+		{"*ast.gostmt", `(did you mean *ast.GoStmt`},
+		{"Chan", `(did you mean *ast.ChanType`},
+		{"Zzzzzzzzzzzz", `unknown node type "Zzzzzzzzzzzz"`},
+	}
+	for _, tt := range tests {
+		_, err := LookupNodeType(tt.name)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("LookupNodeType(%q): got %v, want %q", tt.name, err, tt.want)
+		}
+	}
+}
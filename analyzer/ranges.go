@@ -0,0 +1,254 @@
+package analyzer
+
+import (
+	"go/ast"
+	"go/token"
+)
+
+// maxIterDepth bounds how many declarations isIterExpr and isIntExpr follow,
+// so that a chain such as x := y; y := x cannot loop.
+const maxIterDepth = 8
+
+// stdIterFuncs are the standard library functions that return an iterator
+// function, by package-qualified name.
+var stdIterFuncs = map[string]bool{
+	"bytes.FieldsSeq":   true,
+	"bytes.Lines":       true,
+	"bytes.SplitSeq":    true,
+	"maps.All":          true,
+	"maps.Keys":         true,
+	"maps.Values":       true,
+	"slices.All":        true,
+	"slices.Backward":   true,
+	"slices.Chunk":      true,
+	"slices.Values":     true,
+	"strings.FieldsSeq": true,
+	"strings.Lines":     true,
+	"strings.SplitSeq":  true,
+}
+
+// IsRangeOverFunc reports whether s ranges over an iterator function, as
+// Go 1.23 allows. It works from syntax and the parser's object resolution
+// alone, so it recognizes X when it is a function literal; a function
+// declared in the file; a variable or parameter of function or iter.Seq
+// type, or assigned one of these; or a call to a function declared in the
+// file, or to a standard library iterator such as maps.Keys, that returns
+// one. Anything it cannot trace, such as a method call, is assumed not to
+// be an iterator.
+func IsRangeOverFunc(s *ast.RangeStmt) bool {
+	return isIterExpr(s.X, 0)
+}
+
+// isIterExpr reports whether x evaluates to an iterator function.
+func isIterExpr(x ast.Expr, depth int) bool {
+	if depth > maxIterDepth {
+		return false
+	}
+	switch x := ast.Unparen(x).(type) {
+	case *ast.FuncLit:
+		return true
+	case *ast.Ident:
+		return isIterObject(x, depth+1)
+	case *ast.CallExpr:
+		return isIterCall(x, depth+1)
+	}
+	return false
+}
+
+// isIterObject reports whether the function or variable ident refers to
+// is an iterator function.
+func isIterObject(ident *ast.Ident, depth int) bool {
+	obj := ident.Obj
+	if obj == nil {
+		return false
+	}
+	if obj.Kind == ast.Fun {
+		// Ranging over a function at all means it is an iterator
+		return true
+	}
+	if obj.Kind != ast.Var {
+		return false
+	}
+
+	switch decl := obj.Decl.(type) {
+	case *ast.Field:
+		return isIterType(decl.Type, depth)
+	case *ast.ValueSpec:
+		if decl.Type != nil {
+			return isIterType(decl.Type, depth)
+		}
+		for i, name := range decl.Names {
+			if name.Obj == obj && i < len(decl.Values) {
+				return isIterExpr(decl.Values[i], depth)
+			}
+		}
+	case *ast.AssignStmt:
+		if len(decl.Lhs) != len(decl.Rhs) {
+			return false
+		}
+		for i, lhs := range decl.Lhs {
+			if id, ok := lhs.(*ast.Ident); ok && id.Obj == obj {
+				return isIterExpr(decl.Rhs[i], depth)
+			}
+		}
+	}
+	return false
+}
+
+// isIterCall reports whether call returns an iterator function.
+func isIterCall(call *ast.CallExpr, depth int) bool {
+	fun := ast.Unparen(call.Fun)
+	// An explicit instantiation, as in Count[int](3)
+	switch f := fun.(type) {
+	case *ast.IndexExpr:
+		fun = f.X
+	case *ast.IndexListExpr:
+		fun = f.X
+	}
+
+	switch f := fun.(type) {
+	case *ast.FuncLit:
+		return returnsIter(f.Type, depth)
+	case *ast.Ident:
+		if f.Obj == nil || f.Obj.Kind != ast.Fun {
+			return false
+		}
+		if decl, ok := f.Obj.Decl.(*ast.FuncDecl); ok {
+			return returnsIter(decl.Type, depth)
+		}
+	case *ast.SelectorExpr:
+		if pkg, ok := f.X.(*ast.Ident); ok && pkg.Obj == nil {
+			return stdIterFuncs[pkg.Name+"."+f.Sel.Name]
+		}
+	}
+	return false
+}
+
+// returnsIter reports whether a function of type fn returns a single
+// iterator function.
+func returnsIter(fn *ast.FuncType, depth int) bool {
+	results := fn.Results
+	if results == nil || len(results.List) != 1 || len(results.List[0].Names) > 1 {
+		return false
+	}
+	return isIterType(results.List[0].Type, depth)
+}
+
+// isIterType reports whether t is an iterator function type: a func whose
+// only parameter is a yield func and which returns nothing, iter.Seq or
+// iter.Seq2, or a type declared in the file as one of these.
+func isIterType(t ast.Expr, depth int) bool {
+	if depth > maxIterDepth {
+		return false
+	}
+	switch t := ast.Unparen(t).(type) {
+	case *ast.FuncType:
+		if t.Results != nil && len(t.Results.List) > 0 {
+			return false
+		}
+		if t.Params == nil || len(t.Params.List) != 1 || len(t.Params.List[0].Names) > 1 {
+			return false
+		}
+		_, isFunc := t.Params.List[0].Type.(*ast.FuncType)
+		return isFunc
+	case *ast.SelectorExpr:
+		pkg, ok := t.X.(*ast.Ident)
+		return ok && pkg.Name == "iter" && (t.Sel.Name == "Seq" || t.Sel.Name == "Seq2")
+	case *ast.IndexExpr:
+		return isIterType(t.X, depth+1)
+	case *ast.IndexListExpr:
+		return isIterType(t.X, depth+1)
+	case *ast.Ident:
+		if t.Obj == nil || t.Obj.Kind != ast.Typ {
+			return false
+		}
+		if spec, ok := t.Obj.Decl.(*ast.TypeSpec); ok {
+			return isIterType(spec.Type, depth+1)
+		}
+	}
+	return false
+}
+
+// intTypes are the predeclared integer type names.
+var intTypes = map[string]bool{
+	"int": true, "int8": true, "int16": true, "int32": true, "int64": true,
+	"uint": true, "uint8": true, "uint16": true, "uint32": true, "uint64": true,
+	"uintptr": true, "byte": true, "rune": true,
+}
+
+// IsRangeOverInt reports whether s ranges over an integer, as Go 1.22
+// allows. Like IsRangeOverFunc it works from syntax alone, recognizing X
+// when it is an integer literal; a constant, variable, or parameter of
+// predeclared integer type or initialized with an integer; a call to len,
+// cap, or an integer conversion; or arithmetic on any of these.
+func IsRangeOverInt(s *ast.RangeStmt) bool {
+	return isIntExpr(s.X, 0)
+}
+
+// isIntExpr reports whether x is an integer.
+func isIntExpr(x ast.Expr, depth int) bool {
+	if depth > maxIterDepth {
+		return false
+	}
+	switch x := ast.Unparen(x).(type) {
+	case *ast.BasicLit:
+		return x.Kind == token.INT
+	case *ast.Ident:
+		return isIntObject(x, depth+1)
+	case *ast.CallExpr:
+		fun, ok := ast.Unparen(x.Fun).(*ast.Ident)
+		if !ok || fun.Obj != nil {
+			return false
+		}
+		return fun.Name == "len" || fun.Name == "cap" || intTypes[fun.Name]
+	case *ast.UnaryExpr:
+		return (x.Op == token.SUB || x.Op == token.ADD || x.Op == token.XOR) && isIntExpr(x.X, depth+1)
+	case *ast.BinaryExpr:
+		switch x.Op {
+		case token.SHL, token.SHR:
+			return isIntExpr(x.X, depth+1)
+		case token.ADD, token.SUB, token.MUL, token.QUO, token.REM, token.AND, token.OR, token.XOR, token.AND_NOT:
+			return isIntExpr(x.X, depth+1) || isIntExpr(x.Y, depth+1)
+		}
+	}
+	return false
+}
+
+// isIntObject reports whether the constant or variable ident refers to is
+// an integer.
+func isIntObject(ident *ast.Ident, depth int) bool {
+	obj := ident.Obj
+	if obj == nil || (obj.Kind != ast.Con && obj.Kind != ast.Var) {
+		return false
+	}
+
+	switch decl := obj.Decl.(type) {
+	case *ast.Field:
+		return isIntType(decl.Type)
+	case *ast.ValueSpec:
+		if decl.Type != nil {
+			return isIntType(decl.Type)
+		}
+		for i, name := range decl.Names {
+			if name.Obj == obj && i < len(decl.Values) {
+				return isIntExpr(decl.Values[i], depth)
+			}
+		}
+	case *ast.AssignStmt:
+		if len(decl.Lhs) != len(decl.Rhs) {
+			return false
+		}
+		for i, lhs := range decl.Lhs {
+			if id, ok := lhs.(*ast.Ident); ok && id.Obj == obj {
+				return isIntExpr(decl.Rhs[i], depth)
+			}
+		}
+	}
+	return false
+}
+
+// isIntType reports whether t names a predeclared integer type.
+func isIntType(t ast.Expr) bool {
+	ident, ok := ast.Unparen(t).(*ast.Ident)
+	return ok && ident.Obj == nil && intTypes[ident.Name]
+}
@@ -0,0 +1,132 @@
+package analyzer
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"testing"
+)
+
+// TestIsRangeOverFunc tests which ranged expressions the heuristic traces
+// to an iterator function
+func TestIsRangeOverFunc(t *testing.T) {
+	src := `package p
+
+import (
+	"iter"
+	"maps"
+)
+
+type Seq iter.Seq[int]
+
+func count(yield func(int) bool) {}
+
+func counter(n int) func(yield func(int) bool) { return count }
+
+func pairs() iter.Seq2[int, string] { return nil }
+
+func named() Seq { return nil }
+
+func ints() []int { return nil }
+
+func f(seq iter.Seq[int], fn func(int) bool, m map[string]int, s []int) {
+	for range count {}
+	for range counter(3) {}
+	for range pairs() {}
+	for range named() {}
+	for range seq {}
+	for range maps.Keys(m) {}
+	for range func(yield func() bool) {} {}
+	v := counter(1)
+	for range v {}
+	w := v
+	for range (w) {}
+	var typed Seq
+	for range typed {}
+	for range s {}
+	for range m {}
+	for range ints() {}
+	for range "abc" {}
+	x := s
+	for range x {}
+	for range fn {}
+}
+`
+	file, err := parser.ParseFile(token.NewFileSet(), "p.go", src, 0)
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+
+	var got []bool
+	ast.Inspect(file, func(n ast.Node) bool {
+		if s, ok := n.(*ast.RangeStmt); ok {
+			got = append(got, IsRangeOverFunc(s))
+		}
+		return true
+	})
+	// fn is a func but not an iterator, so ranging over it would not
+	// compile; the heuristic still rejects its type
+	want := []bool{true, true, true, true, true, true, true, true, true, true, false, false, false, false, false, false}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d range statements, got %d", len(want), len(got))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("range statement %d: expected %v, got %v", i, want[i], got[i])
+		}
+	}
+}
+
+// TestIsRangeOverInt tests which ranged expressions the heuristic traces
+// to an integer
+func TestIsRangeOverInt(t *testing.T) {
+	src := `package p
+
+const limit = 3
+
+func f(n int, s []int, m map[string]int, str string) {
+	for range 10 {}
+	for range n {}
+	for range limit {}
+	for range len(s) {}
+	for range n * 2 {}
+	for range (1 << 3) {}
+	for range int(m["a"]) {}
+	var i int
+	for i = range 5 {}
+	k := 7
+	for range k {}
+	for range s {}
+	for range m {}
+	for range str {}
+	for range "abc" {}
+	x := str + "d"
+	for range x {}
+	for range s[1:] {}
+}
+`
+	file, err := parser.ParseFile(token.NewFileSet(), "p.go", src, 0)
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+
+	var got []bool
+	ast.Inspect(file, func(n ast.Node) bool {
+		if s, ok := n.(*ast.RangeStmt); ok {
+			got = append(got, IsRangeOverInt(s))
+			if IsRangeOverInt(s) && IsRangeOverFunc(s) {
+				t.Errorf("range statement %d classified as both int and func", len(got)-1)
+			}
+		}
+		return true
+	})
+	want := []bool{true, true, true, true, true, true, true, true, true, false, false, false, false, false, false}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d range statements, got %d", len(want), len(got))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("range statement %d: expected %v, got %v", i, want[i], got[i])
+		}
+	}
+}
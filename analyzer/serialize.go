@@ -0,0 +1,54 @@
+package analyzer
+
+// SerializedResult is the JSON form of an AnalysisResult. It leaves out
+// Locations, which can be rebuilt from the source and would dominate the
+// output.
+type SerializedResult struct {
+	FileName        string                    `json:"file_name"`
+	NodeCounts      map[string]int            `json:"node_counts"`
+	TotalNodes      int                       `json:"total_nodes"`
+	UniqueTypes     int                       `json:"unique_types"`
+	TokenCounts     map[string]map[string]int `json:"token_counts,omitempty"`
+	VariantCounts   map[string]int            `json:"variant_counts,omitempty"`
+	TokenKindCounts map[string]int            `json:"token_kind_counts,omitempty"`
+}
+
+// Serialize returns the JSON form of the result.
+func (r *AnalysisResult) Serialize() SerializedResult {
+	return SerializedResult{
+		FileName:        r.FileName,
+		NodeCounts:      r.NodeCounts,
+		TotalNodes:      r.TotalNodes,
+		UniqueTypes:     r.UniqueTypes,
+		TokenCounts:     r.TokenCounts,
+		VariantCounts:   r.VariantCounts,
+		TokenKindCounts: r.TokenKindCounts,
+	}
+}
+
+// Result converts the JSON form back to an AnalysisResult, without
+// Locations.
+func (s SerializedResult) Result() *AnalysisResult {
+	nodeCounts := s.NodeCounts
+	if nodeCounts == nil {
+		nodeCounts = make(map[string]int)
+	}
+	return &AnalysisResult{
+		FileName:        s.FileName,
+		NodeCounts:      nodeCounts,
+		TotalNodes:      s.TotalNodes,
+		UniqueTypes:     s.UniqueTypes,
+		TokenCounts:     s.TokenCounts,
+		VariantCounts:   s.VariantCounts,
+		TokenKindCounts: s.TokenKindCounts,
+	}
+}
+
+// SerializeResults returns the JSON form of each result.
+func SerializeResults(results []*AnalysisResult) []SerializedResult {
+	serialized := make([]SerializedResult, 0, len(results))
+	for _, r := range results {
+		serialized = append(serialized, r.Serialize())
+	}
+	return serialized
+}
@@ -0,0 +1,95 @@
+package analyzer
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+)
+
+// nodeSnippets holds, for each node type, a minimal top-level Go
+// declaration whose AST contains that node type.
+var nodeSnippets = map[string]string{
+	// Expression nodes
+	"*ast.Ident":          "var x = y",
+	"*ast.Ellipsis":       "func f(args ...int) {}",
+	"*ast.BasicLit":       "var x = 42",
+	"*ast.FuncLit":        "var f = func() {}",
+	"*ast.CompositeLit":   "var x = []int{1, 2}",
+	"*ast.ParenExpr":      "var x = (1 + 2) * 3",
+	"*ast.SelectorExpr":   "var x = s.Field",
+	"*ast.IndexExpr":      "var x = s[0]",
+	"*ast.IndexListExpr":  "var x Pair[int, string]",
+	"*ast.SliceExpr":      "var x = s[1:2]",
+	"*ast.TypeAssertExpr": "var x = v.(int)",
+	"*ast.CallExpr":       "var x = f()",
+	"*ast.StarExpr":       "var p *int",
+	"*ast.UnaryExpr":      "var x = -y",
+	"*ast.BinaryExpr":     "var x = 1 + 2",
+	"*ast.KeyValueExpr":   "var m = map[string]int{\"a\": 1}",
+
+	// Statement nodes
+	"*ast.DeclStmt":       "func f() { var x int; _ = x }",
+	"*ast.EmptyStmt":      "func f() { for { ; break } }",
+	"*ast.LabeledStmt":    "func f() { loop: for { break loop } }",
+	"*ast.ExprStmt":       "func f() { g() }",
+	"*ast.SendStmt":       "func f(ch chan int) { ch <- 1 }",
+	"*ast.IncDecStmt":     "func f(i int) { i++ }",
+	"*ast.AssignStmt":     "func f() { x := 1; _ = x }",
+	"*ast.GoStmt":         "func f() { go g() }",
+	"*ast.DeferStmt":      "func f() { defer g() }",
+	"*ast.ReturnStmt":     "func f() int { return 1 }",
+	"*ast.BranchStmt":     "func f() { for { break } }",
+	"*ast.BlockStmt":      "func f() {}",
+	"*ast.IfStmt":         "func f(x int) { if x > 0 {} }",
+	"*ast.CaseClause":     "func f(x int) { switch x { case 1: } }",
+	"*ast.SwitchStmt":     "func f(x int) { switch x {} }",
+	"*ast.TypeSwitchStmt": "func f(v any) { switch v.(type) {} }",
+	"*ast.CommClause":     "func f(ch chan int) { select { case <-ch: } }",
+	"*ast.SelectStmt":     "func f() { select {} }",
+	"*ast.ForStmt":        "func f() { for i := 0; i < 3; i++ {} }",
+	"*ast.RangeStmt":      "func f(s []int) { for range s {} }",
+
+	// Declaration and spec nodes
+	"*ast.GenDecl":    "var x int",
+	"*ast.FuncDecl":   "func f() {}",
+	"*ast.ImportSpec": "import \"fmt\"",
+	"*ast.ValueSpec":  "const c = 1",
+	"*ast.TypeSpec":   "type T int",
+
+	// Type nodes
+	"*ast.ArrayType":     "var a [3]int",
+	"*ast.StructType":    "type S struct{ X int }",
+	"*ast.FuncType":      "type F func(int) error",
+	"*ast.InterfaceType": "type I interface{ M() }",
+	"*ast.MapType":       "var m map[string]int",
+	"*ast.ChanType":      "var ch chan int",
+
+	// Structural and top-level nodes
+	"*ast.Comment":      "// comment\nvar x int",
+	"*ast.CommentGroup": "// comment\nvar x int",
+	"*ast.Field":        "type S struct{ X int }",
+	"*ast.FieldList":    "func f(x int) {}",
+	"*ast.File":         "var x int",
+}
+
+// SuggestSnippet returns a minimal top-level declaration that produces
+// nodeType when parsed after a package clause. Bad* nodes, which only
+// parse errors produce, and *ast.Package, which comes from parsing a
+// directory, have no snippet.
+func SuggestSnippet(nodeType string) (string, bool) {
+	snippet, ok := nodeSnippets[nodeType]
+	return snippet, ok
+}
+
+// ValidateSnippet parses snippet after a package clause and checks that the
+// resulting AST contains nodeType.
+func ValidateSnippet(nodeType, snippet string) error {
+	file, err := parser.ParseFile(token.NewFileSet(), "snippet.go", "package p\n\n"+snippet+"\n", parser.ParseComments)
+	if err != nil {
+		return fmt.Errorf("snippet for %s does not parse: %w", nodeType, err)
+	}
+	if counts, _ := CountNodes(file); counts[nodeType] == 0 {
+		return fmt.Errorf("snippet for %s does not produce it: %q", nodeType, snippet)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package analyzer
+
+import (
+	"go/scanner"
+	"go/token"
+	"strings"
+)
+
+// Token kind groups returned by GetAllTokenKinds.
+const (
+	TokenKindLiteral  = "literal"
+	TokenKindOperator = "operator"
+	TokenKindKeyword  = "keyword"
+)
+
+// GetAllTokenKinds returns every token.Token kind the scanner can produce
+// for valid source, enumerated from token.Token's String table and grouped
+// into literals (including comments), operators, and keywords. ILLEGAL and
+// EOF are left out.
+func GetAllTokenKinds() []TokenGroup {
+	groups := []TokenGroup{
+		{Name: TokenKindLiteral},
+		{Name: TokenKindOperator},
+		{Name: TokenKindKeyword},
+	}
+	for tok := token.Token(0); tok < 256; tok++ {
+		if strings.HasPrefix(tok.String(), "token(") {
+			continue
+		}
+		switch {
+		case tok.IsLiteral(), tok == token.COMMENT:
+			groups[0].Tokens = append(groups[0].Tokens, tok)
+		case tok.IsOperator():
+			groups[1].Tokens = append(groups[1].Tokens, tok)
+		case tok.IsKeyword():
+			groups[2].Tokens = append(groups[2].Tokens, tok)
+		}
+	}
+	return groups
+}
+
+// ScanTokenKinds scans src and returns how often each token kind occurs,
+// keyed by the token's String (e.g. "IDENT", "<-", "func"). Semicolons are
+// only counted where the source spells them out, not where the scanner
+// inserts them. Scan errors are ignored.
+func ScanTokenKinds(src []byte) map[string]int {
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(src))
+
+	var s scanner.Scanner
+	s.Init(file, src, nil, scanner.ScanComments)
+
+	counts := make(map[string]int)
+	for {
+		_, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		if tok == token.SEMICOLON && lit != ";" {
+			continue
+		}
+		counts[tok.String()]++
+	}
+	return counts
+}
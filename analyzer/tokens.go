@@ -0,0 +1,78 @@
+package analyzer
+
+import (
+	"go/ast"
+	"go/token"
+)
+
+// Token groups tracked by CountTokens.
+const (
+	TokenGroupBinary = "binary"
+	TokenGroupUnary  = "unary"
+	TokenGroupAssign = "assign"
+	TokenGroupBranch = "branch"
+)
+
+// TokenGroup lists the tokens that can appear in one syntactic position.
+type TokenGroup struct {
+	Name   string
+	Tokens []token.Token
+}
+
+// GetAllTokenGroups returns every operator and keyword token the analyzer
+// tracks, grouped by the node field that holds it.
+func GetAllTokenGroups() []TokenGroup {
+	return []TokenGroup{
+		// BinaryExpr.Op
+		{Name: TokenGroupBinary, Tokens: []token.Token{
+			token.ADD, token.SUB, token.MUL, token.QUO, token.REM,
+			token.AND, token.OR, token.XOR, token.SHL, token.SHR, token.AND_NOT,
+			token.LAND, token.LOR,
+			token.EQL, token.NEQ, token.LSS, token.LEQ, token.GTR, token.GEQ,
+		}},
+		// UnaryExpr.Op; pointer indirection is a StarExpr instead
+		{Name: TokenGroupUnary, Tokens: []token.Token{
+			token.ADD, token.SUB, token.NOT, token.XOR, token.AND, token.ARROW, token.TILDE,
+		}},
+		// AssignStmt.Tok
+		{Name: TokenGroupAssign, Tokens: []token.Token{
+			token.ASSIGN, token.DEFINE,
+			token.ADD_ASSIGN, token.SUB_ASSIGN, token.MUL_ASSIGN, token.QUO_ASSIGN, token.REM_ASSIGN,
+			token.AND_ASSIGN, token.OR_ASSIGN, token.XOR_ASSIGN,
+			token.SHL_ASSIGN, token.SHR_ASSIGN, token.AND_NOT_ASSIGN,
+		}},
+		// BranchStmt.Tok
+		{Name: TokenGroupBranch, Tokens: []token.Token{
+			token.BREAK, token.CONTINUE, token.GOTO, token.FALLTHROUGH,
+		}},
+	}
+}
+
+// CountTokens walks the tree rooted at root and returns how often each
+// operator or keyword token is used, keyed by token group and then by the
+// token's text (e.g. "+", ":=", "goto").
+func CountTokens(root ast.Node) map[string]map[string]int {
+	tokenCounts := make(map[string]map[string]int)
+	add := func(group string, tok token.Token) {
+		if tokenCounts[group] == nil {
+			tokenCounts[group] = make(map[string]int)
+		}
+		tokenCounts[group][tok.String()]++
+	}
+
+	ast.Inspect(root, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.BinaryExpr:
+			add(TokenGroupBinary, node.Op)
+		case *ast.UnaryExpr:
+			add(TokenGroupUnary, node.Op)
+		case *ast.AssignStmt:
+			add(TokenGroupAssign, node.Tok)
+		case *ast.BranchStmt:
+			add(TokenGroupBranch, node.Tok)
+		}
+		return true
+	})
+
+	return tokenCounts
+}
@@ -0,0 +1,110 @@
+package analyzer
+
+import (
+	"go/ast"
+)
+
+// Variant is a structural form of a node type that depends on which of its
+// optional fields are set, such as an IfStmt with an Init statement.
+type Variant struct {
+	Name        string // e.g. "IfStmt/init"
+	Description string
+	match       func(ast.Node) bool
+}
+
+// GetAllVariants returns the curated set of structural variants tracked by
+// CountVariants.
+func GetAllVariants() []Variant {
+	return []Variant{
+		{"IfStmt/plain", "if without Init or Else", func(n ast.Node) bool {
+			s, ok := n.(*ast.IfStmt)
+			return ok && s.Init == nil && s.Else == nil
+		}},
+		{"IfStmt/init", "if with an Init statement", func(n ast.Node) bool {
+			s, ok := n.(*ast.IfStmt)
+			return ok && s.Init != nil
+		}},
+		{"IfStmt/else", "if with an else block", func(n ast.Node) bool {
+			s, ok := n.(*ast.IfStmt)
+			if !ok {
+				return false
+			}
+			_, isBlock := s.Else.(*ast.BlockStmt)
+			return isBlock
+		}},
+		{"IfStmt/else-if", "if with an else if chain", func(n ast.Node) bool {
+			s, ok := n.(*ast.IfStmt)
+			if !ok {
+				return false
+			}
+			_, isIf := s.Else.(*ast.IfStmt)
+			return isIf
+		}},
+		{"ForStmt/infinite", "for without Init, Cond, or Post", func(n ast.Node) bool {
+			s, ok := n.(*ast.ForStmt)
+			return ok && s.Init == nil && s.Cond == nil && s.Post == nil
+		}},
+		{"ForStmt/cond", "for with only a condition", func(n ast.Node) bool {
+			s, ok := n.(*ast.ForStmt)
+			return ok && s.Init == nil && s.Cond != nil && s.Post == nil
+		}},
+		{"ForStmt/three-clause", "for with Init, Cond, and Post", func(n ast.Node) bool {
+			s, ok := n.(*ast.ForStmt)
+			return ok && s.Init != nil && s.Cond != nil && s.Post != nil
+		}},
+		{"RangeStmt/range-over-func", "range over an iterator function (Go 1.23+)", func(n ast.Node) bool {
+			s, ok := n.(*ast.RangeStmt)
+			return ok && IsRangeOverFunc(s)
+		}},
+		{"RangeStmt/range-over-int", "range over an integer (Go 1.22+)", func(n ast.Node) bool {
+			s, ok := n.(*ast.RangeStmt)
+			return ok && IsRangeOverInt(s)
+		}},
+		{"SliceExpr/2-index", "slice expression s[lo:hi]", func(n ast.Node) bool {
+			s, ok := n.(*ast.SliceExpr)
+			return ok && !s.Slice3
+		}},
+		{"SliceExpr/3-index", "full slice expression s[lo:hi:max]", func(n ast.Node) bool {
+			s, ok := n.(*ast.SliceExpr)
+			return ok && s.Slice3
+		}},
+		{"ReturnStmt/bare", "return without values", func(n ast.Node) bool {
+			s, ok := n.(*ast.ReturnStmt)
+			return ok && len(s.Results) == 0
+		}},
+		{"ReturnStmt/values", "return with values", func(n ast.Node) bool {
+			s, ok := n.(*ast.ReturnStmt)
+			return ok && len(s.Results) > 0
+		}},
+		{"FuncDecl/function", "function declaration without a receiver", func(n ast.Node) bool {
+			d, ok := n.(*ast.FuncDecl)
+			return ok && d.Recv == nil
+		}},
+		{"FuncDecl/method", "method declaration with a receiver", func(n ast.Node) bool {
+			d, ok := n.(*ast.FuncDecl)
+			return ok && d.Recv != nil
+		}},
+	}
+}
+
+// CountVariants walks the tree rooted at root and returns the number of
+// nodes matching each variant from GetAllVariants, keyed by variant name.
+// Variants with no matches are omitted.
+func CountVariants(root ast.Node) map[string]int {
+	variants := GetAllVariants()
+	variantCounts := make(map[string]int)
+
+	ast.Inspect(root, func(n ast.Node) bool {
+		if n == nil {
+			return true
+		}
+		for _, v := range variants {
+			if v.match(n) {
+				variantCounts[v.Name]++
+			}
+		}
+		return true
+	})
+
+	return variantCounts
+}
@@ -0,0 +1,73 @@
+package analyzer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// LatestGoVersion is the version profile that expects every node type.
+const LatestGoVersion = "latest"
+
+// baseGoVersion is the version that introduced every node type not listed
+// in nodeTypeVersions.
+const baseGoVersion = "go1"
+
+// nodeTypeVersions records the Go version that introduced each node type
+// added after Go 1.0.
+var nodeTypeVersions = map[string]string{
+	"*ast.IndexListExpr": "go1.18",
+}
+
+// NodeTypeVersion returns the Go version that introduced nodeType, such as
+// "go1.18", or "go1" for node types present since Go 1.0.
+func NodeTypeVersion(nodeType string) string {
+	if version, ok := nodeTypeVersions[nodeType]; ok {
+		return version
+	}
+	return baseGoVersion
+}
+
+// GetNodeTypesForVersion returns the node types expected for a corpus
+// targeting the Go version, such as "go1.17", leaving out node types
+// introduced later. LatestGoVersion or "" returns GetAllNodeTypes.
+func GetNodeTypesForVersion(version string) ([]string, error) {
+	all := GetAllNodeTypes()
+	if version == "" || version == LatestGoVersion {
+		return all, nil
+	}
+
+	target, err := parseGoVersion(version)
+	if err != nil {
+		return nil, err
+	}
+
+	var nodeTypes []string
+	for _, nodeType := range all {
+		// Versions in nodeTypeVersions are known to be valid
+		introduced, _ := parseGoVersion(NodeTypeVersion(nodeType))
+		if introduced <= target {
+			nodeTypes = append(nodeTypes, nodeType)
+		}
+	}
+	return nodeTypes, nil
+}
+
+// parseGoVersion returns the minor version of a "go1" or "go1.N" version
+// string, ignoring any patch release.
+func parseGoVersion(version string) (int, error) {
+	rest, ok := strings.CutPrefix(version, "go1")
+	if !ok {
+		return 0, fmt.Errorf("invalid Go version %q (expected e.g. go1.18 or %s)", version, LatestGoVersion)
+	}
+	if rest == "" {
+		return 0, nil
+	}
+
+	minor, _, _ := strings.Cut(strings.TrimPrefix(rest, "."), ".")
+	n, err := strconv.Atoi(minor)
+	if err != nil || !strings.HasPrefix(rest, ".") {
+		return 0, fmt.Errorf("invalid Go version %q (expected e.g. go1.18 or %s)", version, LatestGoVersion)
+	}
+	return n, nil
+}
@@ -1,16 +1,22 @@
 package archive
 
 import (
+	"bufio"
 	"bytes"
+	"compress/gzip"
 	"encoding/gob"
+	"errors"
 	"fmt"
 	"go/ast"
 	"go/format"
 	"go/parser"
 	"go/token"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
+
+	"zylisp/go-ast-coverage/analyzer"
 )
 
 // SimpleASTBundle stores AST with source for perfect reconstruction
@@ -25,10 +31,54 @@ type SimpleASTBundle struct {
 	// Optional: store the cleaned AST for quick access to structure
 	CleanedAST *ast.File `gob:"cleaned_ast,omitempty"`
 
+	// Store the layout of the FileSet that CleanedAST's positions refer to.
+	// token.FileSet has no exported fields, so it is captured explicitly.
+	Files []FileInfo `gob:"files,omitempty"`
+
 	// Store any additional metadata
 	Metadata map[string]interface{} `gob:"metadata,omitempty"`
 }
 
+// FileInfo captures one token.File of a FileSet so it can be serialized
+// and rebuilt with the same base, size, and line table.
+type FileInfo struct {
+	Name  string
+	Base  int
+	Size  int
+	Lines []int
+}
+
+// captureFileSet records the layout of every file in fset.
+func captureFileSet(fset *token.FileSet) []FileInfo {
+	var files []FileInfo
+	fset.Iterate(func(f *token.File) bool {
+		files = append(files, FileInfo{
+			Name:  f.Name(),
+			Base:  f.Base(),
+			Size:  f.Size(),
+			Lines: f.Lines(),
+		})
+		return true
+	})
+	return files
+}
+
+// restoreFileSet rebuilds a FileSet equivalent to the one captured in files,
+// so positions recorded against the original resolve identically.
+func restoreFileSet(files []FileInfo) (*token.FileSet, error) {
+	fset := token.NewFileSet()
+	for _, info := range files {
+		if info.Base < fset.Base() {
+			return nil, fmt.Errorf("file %s has base %d below FileSet base %d", info.Name, info.Base, fset.Base())
+		}
+		f := fset.AddFile(info.Name, info.Base, info.Size)
+		if !f.SetLines(info.Lines) {
+			return nil, fmt.Errorf("file %s has an invalid line table", info.Name)
+		}
+	}
+	return fset, nil
+}
+
 // ASTArchive provides a convenient API for working with archived AST data.
 // It wraps SimpleASTBundle with helper methods for common operations.
 type ASTArchive struct {
@@ -70,6 +120,17 @@ func (a *ASTArchive) GetCleanedAST() *ast.File {
 	return a.bundle.CleanedAST
 }
 
+// GetCleanedFileSet rebuilds the FileSet that positions in GetCleanedAST
+// refer to, so fset.Position reports the line and column of each node in
+// GetSourceCode. It returns nil for archives written before file sets were
+// stored, whose cleaned positions do not correspond to the stored source.
+func (a *ASTArchive) GetCleanedFileSet() (*token.FileSet, error) {
+	if len(a.bundle.Files) == 0 {
+		return nil, nil
+	}
+	return restoreFileSet(a.bundle.Files)
+}
+
 // GetMetadata retrieves a metadata value by key.
 func (a *ASTArchive) GetMetadata(key string) interface{} {
 	return a.bundle.Metadata[key]
@@ -90,6 +151,15 @@ func (a *ASTArchive) NodeCount() int {
 	return count
 }
 
+// NodeCounts returns the per-type node counts of the original source,
+// recorded when the archive was written, or nil for archives written
+// before they were stored. They can differ slightly from counts taken on
+// GetAST, since formatting drops constructs such as empty statements.
+func (a *ASTArchive) NodeCounts() map[string]int {
+	counts, _ := a.bundle.Metadata["node_counts"].(map[string]int)
+	return counts
+}
+
 // DeclarationCount returns the number of top-level declarations.
 func (a *ASTArchive) DeclarationCount() int {
 	if count, ok := a.bundle.Metadata["num_declarations"].(int); ok {
@@ -195,31 +265,35 @@ func RegisterAllASTTypes() {
 	gob.Register([]*ast.ImportSpec{})
 
 	// Token types
+	gob.Register(map[string]int{})
 	gob.Register(token.Token(0))
 	gob.Register(token.Pos(0))
 }
 
-// SaveASTWithSourcePreservation saves AST by preserving source code
-func SaveASTWithSourcePreservation(file *ast.File, fset *token.FileSet, filename, outputFile string) error {
-	// Register all AST types for gob encoding
-	RegisterAllASTTypes()
-
+// NewBundle builds the archive bundle for file without writing it anywhere.
+// The AST is formatted back to source through fset; it is not modified.
+func NewBundle(file *ast.File, fset *token.FileSet, filename string) (*SimpleASTBundle, error) {
 	// Convert AST back to source code
-	var buf bytes.Buffer
-	if err := format.Node(&buf, fset, file); err != nil {
-		return fmt.Errorf("failed to format AST to source: %w", err)
+	buf := getSourceBuffer()
+	defer putSourceBuffer(buf)
+	if err := format.Node(buf, fset, file); err != nil {
+		return nil, fmt.Errorf("failed to format AST to source: %w", err)
 	}
 
 	sourceCode := buf.String()
 
 	// Create a cleaned copy for structural analysis (optional)
-	cleanedFile := deepCopyAndClean(file)
+	cleanedFile, cleanedFset, err := parseCleaned(filename, sourceCode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cleaned AST: %w", err)
+	}
 
-	bundle := SimpleASTBundle{
+	bundle := &SimpleASTBundle{
 		SourceCode: sourceCode,
 		Filename:   filename,
 		ParseMode:  parser.ParseComments, // Preserve comments by default
 		CleanedAST: cleanedFile,
+		Files:      captureFileSet(cleanedFset),
 		Metadata:   make(map[string]interface{}),
 	}
 
@@ -227,31 +301,243 @@ func SaveASTWithSourcePreservation(file *ast.File, fset *token.FileSet, filename
 	bundle.Metadata["original_package"] = file.Name.Name
 	bundle.Metadata["num_declarations"] = len(file.Decls)
 	bundle.Metadata["num_imports"] = len(file.Imports)
+	bundle.Metadata["node_counts"], _ = analyzer.CountNodes(file)
+
+	return bundle, nil
+}
+
+// Archive payloads start with a header of the magic bytes, a version byte,
+// and a flags byte, followed by the gob-encoded SimpleASTBundle. The low
+// bits of the flags byte hold the CompressionMode applied to the payload.
+// Archives written before the header was introduced are plain gob and
+// still load.
+const (
+	bundleMagic     = "ASTA"
+	bundleVersion   = 1
+	headerSize      = len(bundleMagic) + 2
+	compressionMask = 0x0f
+)
+
+// DefaultMaxDecompressedSize bounds how many bytes a compressed archive may
+// expand to when DecodeOptions.MaxDecompressedSize is unset.
+const DefaultMaxDecompressedSize = 256 << 20
 
-	// Serialize with gob
-	var gobBuf bytes.Buffer
-	encoder := gob.NewEncoder(&gobBuf)
-	if err := encoder.Encode(&bundle); err != nil {
+// ErrNotArchive is returned when data is neither a headered archive nor a
+// legacy headerless bundle.
+var ErrNotArchive = errors.New("not an AST archive")
+
+// ErrBundleTooLarge is returned when a compressed archive expands beyond
+// the configured maximum decompressed size.
+var ErrBundleTooLarge = errors.New("decompressed archive exceeds size limit")
+
+// VersionError is returned when an archive was written by a newer format
+// version than this package understands.
+type VersionError struct {
+	Version int
+}
+
+func (e *VersionError) Error() string {
+	return fmt.Sprintf("archive format version %d is newer than supported version %d", e.Version, bundleVersion)
+}
+
+// CompressionMode selects how the archive payload is compressed.
+type CompressionMode uint8
+
+const (
+	// CompressionNone stores the gob payload as is.
+	CompressionNone CompressionMode = 0
+
+	// CompressionGzip gzip-compresses the gob payload.
+	CompressionGzip CompressionMode = 1
+)
+
+// EncodeOptions controls how bundles are written.
+type EncodeOptions struct {
+	Compression CompressionMode
+}
+
+// DecodeOptions controls how bundles are read.
+type DecodeOptions struct {
+	// MaxDecompressedSize limits the size of a compressed payload after
+	// decompression. Zero means DefaultMaxDecompressedSize.
+	MaxDecompressedSize int64
+}
+
+// EncodeBundle serializes b to w without compression.
+func EncodeBundle(w io.Writer, b *SimpleASTBundle) error {
+	return EncodeBundleWithOptions(w, b, EncodeOptions{})
+}
+
+// EncodeBundleWithOptions serializes b to w, preceded by the archive header.
+// The encoding is written as it is produced, so wrapping w in a
+// bufio.Writer avoids holding the whole payload in memory.
+func EncodeBundleWithOptions(w io.Writer, b *SimpleASTBundle, opts EncodeOptions) error {
+	// Register all AST types for gob encoding
+	RegisterAllASTTypes()
+
+	if opts.Compression != CompressionNone && opts.Compression != CompressionGzip {
+		return fmt.Errorf("unknown compression mode %d", opts.Compression)
+	}
+
+	header := append([]byte(bundleMagic), bundleVersion, byte(opts.Compression))
+	if _, err := w.Write(header); err != nil {
+		return fmt.Errorf("failed to write header: %w", err)
+	}
+
+	if opts.Compression == CompressionGzip {
+		gz := getGzipWriter(w)
+		defer putGzipWriter(gz)
+		if err := gob.NewEncoder(gz).Encode(b); err != nil {
+			return fmt.Errorf("failed to encode bundle: %w", err)
+		}
+		if err := gz.Close(); err != nil {
+			return fmt.Errorf("failed to compress bundle: %w", err)
+		}
+		return nil
+	}
+
+	if err := gob.NewEncoder(w).Encode(b); err != nil {
 		return fmt.Errorf("failed to encode bundle: %w", err)
 	}
+	return nil
+}
 
-	return os.WriteFile(outputFile, gobBuf.Bytes(), 0644)
+// DecodeBundle reads a bundle written by EncodeBundle from r using the
+// default decode options.
+func DecodeBundle(r io.Reader) (*SimpleASTBundle, error) {
+	return DecodeBundleWithOptions(r, DecodeOptions{})
 }
 
-// LoadASTWithSourceReconstruction loads AST and reconstructs all references
-func LoadASTWithSourceReconstruction(filename string) (*ast.File, *token.FileSet, string, error) {
+// DecodeBundleWithOptions reads a bundle from r, decompressing it as the
+// header indicates. Legacy headerless bundles are decoded as well; anything
+// else yields an error wrapping ErrNotArchive, or a *VersionError for newer
+// format versions.
+func DecodeBundleWithOptions(r io.Reader, opts DecodeOptions) (*SimpleASTBundle, error) {
 	// Register all AST types for gob decoding
 	RegisterAllASTTypes()
 
-	data, err := os.ReadFile(filename)
-	if err != nil {
-		return nil, nil, "", fmt.Errorf("failed to read file: %w", err)
+	br, ok := r.(*bufio.Reader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+
+	var payload io.Reader = br
+	header, err := br.Peek(headerSize)
+	hasHeader := err == nil && string(header[:len(bundleMagic)]) == bundleMagic
+	if hasHeader {
+		if version := int(header[len(bundleMagic)]); version > bundleVersion {
+			return nil, &VersionError{Version: version}
+		}
+		flags := header[len(bundleMagic)+1]
+		if flags&^compressionMask != 0 {
+			return nil, fmt.Errorf("%w: unknown header flags %#x", ErrNotArchive, flags)
+		}
+		br.Discard(headerSize)
+
+		switch CompressionMode(flags & compressionMask) {
+		case CompressionNone:
+		case CompressionGzip:
+			gz, err := gzip.NewReader(br)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decompress bundle: %w", err)
+			}
+			defer gz.Close()
+
+			limit := opts.MaxDecompressedSize
+			if limit <= 0 {
+				limit = DefaultMaxDecompressedSize
+			}
+			payload = &limitedReader{r: gz, remaining: limit}
+		default:
+			return nil, fmt.Errorf("%w: unknown compression mode %d", ErrNotArchive, flags&compressionMask)
+		}
 	}
 
 	var bundle SimpleASTBundle
-	decoder := gob.NewDecoder(bytes.NewReader(data))
-	if err := decoder.Decode(&bundle); err != nil {
-		return nil, nil, "", fmt.Errorf("failed to decode bundle: %w", err)
+	if err := gob.NewDecoder(payload).Decode(&bundle); err != nil {
+		if errors.Is(err, ErrBundleTooLarge) {
+			return nil, err
+		}
+		if !hasHeader {
+			return nil, fmt.Errorf("%w: no %s header and not a legacy bundle: %v", ErrNotArchive, bundleMagic, err)
+		}
+		return nil, fmt.Errorf("failed to decode bundle: %w", err)
+	}
+	return &bundle, nil
+}
+
+// limitedReader fails with ErrBundleTooLarge once more than remaining bytes
+// have been read, guarding against decompression bombs.
+type limitedReader struct {
+	r         io.Reader
+	remaining int64
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if int64(len(p)) > l.remaining+1 {
+		p = p[:l.remaining+1]
+	}
+	n, err := l.r.Read(p)
+	l.remaining -= int64(n)
+	if l.remaining < 0 {
+		return n, ErrBundleTooLarge
+	}
+	return n, err
+}
+
+// NewASTArchive wraps an in-memory bundle in the convenience API.
+func NewASTArchive(bundle *SimpleASTBundle) *ASTArchive {
+	return &ASTArchive{bundle: bundle}
+}
+
+// SaveASTWithSourcePreservation saves AST by preserving source code
+func SaveASTWithSourcePreservation(file *ast.File, fset *token.FileSet, filename, outputFile string) error {
+	return SaveASTWithOptions(file, fset, filename, outputFile, EncodeOptions{})
+}
+
+// SaveASTWithOptions saves AST by preserving source code, encoding the
+// archive as opts specifies.
+func SaveASTWithOptions(file *ast.File, fset *token.FileSet, filename, outputFile string, opts EncodeOptions) error {
+	bundle, err := NewBundle(file, fset, filename)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(outputFile)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer f.Close()
+
+	w := getWriter(f)
+	defer putWriter(w)
+	if err := EncodeBundleWithOptions(w, bundle, opts); err != nil {
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	return f.Close()
+}
+
+// readBundle opens filename and decodes the bundle it contains.
+func readBundle(filename string, opts DecodeOptions) (*SimpleASTBundle, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	defer f.Close()
+
+	r := getReader(f)
+	defer putReader(r)
+	return DecodeBundleWithOptions(r, opts)
+}
+
+// LoadASTWithSourceReconstruction loads AST and reconstructs all references
+func LoadASTWithSourceReconstruction(filename string) (*ast.File, *token.FileSet, string, error) {
+	bundle, err := readBundle(filename, DecodeOptions{})
+	if err != nil {
+		return nil, nil, "", err
 	}
 
 	// Re-parse the source code to get perfect AST with all references
@@ -266,21 +552,17 @@ func LoadASTWithSourceReconstruction(filename string) (*ast.File, *token.FileSet
 
 // Load loads a single AST archive and wraps it in the convenience API.
 func Load(filename string) (*ASTArchive, error) {
-	// Register all AST types for gob decoding
-	RegisterAllASTTypes()
+	return LoadWithOptions(filename, DecodeOptions{})
+}
 
-	data, err := os.ReadFile(filename)
+// LoadWithOptions loads a single AST archive using the given decode options.
+func LoadWithOptions(filename string, opts DecodeOptions) (*ASTArchive, error) {
+	bundle, err := readBundle(filename, opts)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read file: %w", err)
-	}
-
-	var bundle SimpleASTBundle
-	decoder := gob.NewDecoder(bytes.NewReader(data))
-	if err := decoder.Decode(&bundle); err != nil {
-		return nil, fmt.Errorf("failed to decode bundle: %w", err)
+		return nil, err
 	}
 
-	return &ASTArchive{bundle: &bundle}, nil
+	return NewASTArchive(bundle), nil
 }
 
 // LoadAll loads all .asta files from a directory.
@@ -336,16 +618,15 @@ func Walk(dir string, fn func(*ASTArchive) error) error {
 	return nil
 }
 
-// deepCopyAndClean creates a copy without circular references (for optional storage)
-func deepCopyAndClean(file *ast.File) *ast.File {
-	// Use go/format and re-parse to get a clean copy
+// parseCleaned parses the formatted source without object resolution, giving
+// a copy free of circular references whose positions match the stored source.
+func parseCleaned(filename, sourceCode string) (*ast.File, *token.FileSet, error) {
 	fset := token.NewFileSet()
-	var buf bytes.Buffer
-	format.Node(&buf, fset, file)
-
-	// Parse without object resolution to avoid circular references
-	cleanFile, _ := parser.ParseFile(fset, "", buf.String(), parser.SkipObjectResolution)
-	return cleanFile
+	cleanFile, err := parser.ParseFile(fset, filename, sourceCode, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cleanFile, fset, nil
 }
 
 // VerifyPerfectFidelity ensures the loaded AST is identical to original
@@ -362,7 +643,7 @@ func VerifyPerfectFidelity(original, restored *ast.File, originalFset, restoredF
 	}
 
 	if origBuf.String() != restBuf.String() {
-		return fmt.Errorf("source code does not match")
+		return newFidelityError(original, restored, originalFset, restoredFset, origBuf.String(), restBuf.String())
 	}
 
 	// Verify scope/object preservation
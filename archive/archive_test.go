@@ -1,13 +1,18 @@
 package archive
 
 import (
+	"bytes"
+	"encoding/gob"
+	"errors"
 	"fmt"
 	"go/ast"
 	"go/parser"
 	"go/token"
+	"io"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 // TestSimpleASTArchive tests basic AST archiving and restoration
@@ -509,3 +514,299 @@ func TestWalkError(t *testing.T) {
 		t.Errorf("expected walk to stop after 1 archive, processed %d", count)
 	}
 }
+
+// TestCleanedFileSetPositions tests that cleaned AST positions resolve to the
+// same lines and columns after a round trip
+func TestCleanedFileSetPositions(t *testing.T) {
+	source := `package main
+
+import "fmt"
+
+type Point struct {
+	X, Y int
+}
+
+func (p Point) String() string {
+	return fmt.Sprintf("(%d, %d)", p.X, p.Y)
+}
+
+func main() {
+	fmt.Println(Point{X: 1, Y: 2})
+}
+`
+
+	defer os.Remove("test_positions.asta")
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "positions.go", source, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("failed to parse source: %v", err)
+	}
+
+	if err := SaveASTWithSourcePreservation(file, fset, "positions.go", "test_positions.asta"); err != nil {
+		t.Fatalf("failed to save AST: %v", err)
+	}
+
+	archive, err := Load("test_positions.asta")
+	if err != nil {
+		t.Fatalf("failed to load AST: %v", err)
+	}
+
+	restoredFset, err := archive.GetCleanedFileSet()
+	if err != nil {
+		t.Fatalf("GetCleanedFileSet failed: %v", err)
+	}
+	if restoredFset == nil {
+		t.Fatal("expected a FileSet to be stored")
+	}
+
+	// Collect positions of a few node kinds in traversal order
+	positions := func(root ast.Node, fset *token.FileSet) []token.Position {
+		var result []token.Position
+		ast.Inspect(root, func(n ast.Node) bool {
+			switch n.(type) {
+			case *ast.FuncDecl, *ast.TypeSpec, *ast.BasicLit, *ast.CompositeLit, *ast.SelectorExpr:
+				result = append(result, fset.Position(n.Pos()), fset.Position(n.End()))
+			}
+			return true
+		})
+		return result
+	}
+
+	original := positions(file, fset)
+	restored := positions(archive.GetCleanedAST(), restoredFset)
+
+	if len(original) == 0 || len(original) != len(restored) {
+		t.Fatalf("position count mismatch: original=%d, restored=%d", len(original), len(restored))
+	}
+	for i := range original {
+		if original[i] != restored[i] {
+			t.Errorf("position %d mismatch: original=%v, restored=%v", i, original[i], restored[i])
+		}
+	}
+}
+
+// slowReader delivers data a few bytes at a time with a short pause, to
+// exercise decoding from a stream that is not fully available up front.
+type slowReader struct {
+	r io.Reader
+}
+
+func (s *slowReader) Read(p []byte) (int, error) {
+	time.Sleep(10 * time.Microsecond)
+	if len(p) > 7 {
+		p = p[:7]
+	}
+	return s.r.Read(p)
+}
+
+// newTestBundle parses source and builds an in-memory bundle for it.
+func newTestBundle(t *testing.T, filename, source string) *SimpleASTBundle {
+	t.Helper()
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, source, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("failed to parse source: %v", err)
+	}
+	bundle, err := NewBundle(file, fset, filename)
+	if err != nil {
+		t.Fatalf("NewBundle failed: %v", err)
+	}
+	return bundle
+}
+
+const streamSource = `package stream
+
+// Sum adds its arguments.
+func Sum(values ...int) int {
+	total := 0
+	for _, v := range values {
+		total += v
+	}
+	return total
+}
+`
+
+// TestEncodeDecodeBuffer tests an in-memory round trip through bytes.Buffer
+func TestEncodeDecodeBuffer(t *testing.T) {
+	bundle := newTestBundle(t, "stream.go", streamSource)
+
+	var buf bytes.Buffer
+	if err := EncodeBundle(&buf, bundle); err != nil {
+		t.Fatalf("EncodeBundle failed: %v", err)
+	}
+
+	decoded, err := DecodeBundle(&buf)
+	if err != nil {
+		t.Fatalf("DecodeBundle failed: %v", err)
+	}
+
+	archive := NewASTArchive(decoded)
+	if archive.GetSourceCode() != streamSource {
+		t.Error("source code mismatch")
+	}
+	if archive.GetPackageName() != "stream" {
+		t.Errorf("expected package 'stream', got '%s'", archive.GetPackageName())
+	}
+	names, err := GetFunctionNames(archive)
+	if err != nil || len(names) != 1 || names[0] != "Sum" {
+		t.Errorf("expected function 'Sum', got %v (%v)", names, err)
+	}
+}
+
+// TestEncodeDecodePipe tests streaming a bundle through a pipe to a slow reader
+func TestEncodeDecodePipe(t *testing.T) {
+	bundle := newTestBundle(t, "stream.go", streamSource)
+
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(EncodeBundle(pw, bundle))
+	}()
+
+	decoded, err := DecodeBundle(&slowReader{r: pr})
+	if err != nil {
+		t.Fatalf("DecodeBundle failed: %v", err)
+	}
+
+	if decoded.SourceCode != streamSource {
+		t.Error("source code mismatch")
+	}
+	if decoded.CleanedAST == nil || len(decoded.CleanedAST.Decls) != 1 {
+		t.Error("expected the cleaned AST to survive the stream")
+	}
+}
+
+// TestBundleHeader tests that encoded bundles carry the magic header
+func TestBundleHeader(t *testing.T) {
+	bundle := newTestBundle(t, "stream.go", streamSource)
+
+	var buf bytes.Buffer
+	if err := EncodeBundle(&buf, bundle); err != nil {
+		t.Fatalf("EncodeBundle failed: %v", err)
+	}
+
+	header := buf.Bytes()[:headerSize]
+	if string(header[:4]) != "ASTA" || header[4] != bundleVersion || header[5] != 0 {
+		t.Errorf("unexpected header %q", header)
+	}
+}
+
+// TestDecodeLegacyBundle tests that headerless bundles still load
+func TestDecodeLegacyBundle(t *testing.T) {
+	bundle := newTestBundle(t, "stream.go", streamSource)
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(bundle); err != nil {
+		t.Fatalf("failed to encode legacy bundle: %v", err)
+	}
+
+	decoded, err := DecodeBundle(&buf)
+	if err != nil {
+		t.Fatalf("DecodeBundle failed on legacy bundle: %v", err)
+	}
+	if decoded.SourceCode != streamSource {
+		t.Error("source code mismatch")
+	}
+}
+
+// TestDecodeRejectsForeignData tests errors for data that is not an archive
+func TestDecodeRejectsForeignData(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"text", []byte("package main\n\nfunc main() {}\n")},
+		{"other magic", append([]byte("ASTB\x01\x00"), make([]byte, 32)...)},
+		{"unknown flags", []byte("ASTA\x01\x80")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := DecodeBundle(bytes.NewReader(tt.data))
+			if !errors.Is(err, ErrNotArchive) {
+				t.Errorf("expected ErrNotArchive, got %v", err)
+			}
+		})
+	}
+}
+
+// TestDecodeNewerVersion tests that future format versions are rejected clearly
+func TestDecodeNewerVersion(t *testing.T) {
+	_, err := DecodeBundle(bytes.NewReader([]byte("ASTA\x09\x00")))
+
+	var versionErr *VersionError
+	if !errors.As(err, &versionErr) || versionErr.Version != 9 {
+		t.Errorf("expected VersionError for version 9, got %v", err)
+	}
+}
+
+// TestGzipCompression tests that gzip archives are smaller and load identically
+func TestGzipCompression(t *testing.T) {
+	source, err := os.ReadFile("../nodes/go/edge_cases.go")
+	if err != nil {
+		t.Skipf("Skipping test - corpus not available: %v", err)
+	}
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "edge_cases.go", source, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("failed to parse source: %v", err)
+	}
+
+	dir := t.TempDir()
+	plainPath := filepath.Join(dir, "plain.asta")
+	gzipPath := filepath.Join(dir, "gzip.asta")
+
+	if err := SaveASTWithOptions(file, fset, "edge_cases.go", plainPath, EncodeOptions{}); err != nil {
+		t.Fatalf("failed to save plain archive: %v", err)
+	}
+	if err := SaveASTWithOptions(file, fset, "edge_cases.go", gzipPath, EncodeOptions{Compression: CompressionGzip}); err != nil {
+		t.Fatalf("failed to save gzip archive: %v", err)
+	}
+
+	plainInfo, _ := os.Stat(plainPath)
+	gzipInfo, _ := os.Stat(gzipPath)
+	t.Logf("archive size: plain=%d bytes, gzip=%d bytes", plainInfo.Size(), gzipInfo.Size())
+	if gzipInfo.Size()*2 > plainInfo.Size() {
+		t.Errorf("expected gzip to at least halve the archive size: plain=%d, gzip=%d",
+			plainInfo.Size(), gzipInfo.Size())
+	}
+
+	restoredFile, restoredFset, _, err := LoadASTWithSourceReconstruction(gzipPath)
+	if err != nil {
+		t.Fatalf("failed to load gzip archive: %v", err)
+	}
+	if err := VerifyPerfectFidelity(file, restoredFile, fset, restoredFset); err != nil {
+		t.Errorf("fidelity check failed: %v", err)
+	}
+
+	plain, _ := Load(plainPath)
+	compressed, _ := Load(gzipPath)
+	if plain.NodeCount() != compressed.NodeCount() {
+		t.Errorf("node count mismatch: plain=%d, gzip=%d", plain.NodeCount(), compressed.NodeCount())
+	}
+}
+
+// TestDecompressionLimit tests the guard against oversized payloads
+func TestDecompressionLimit(t *testing.T) {
+	bundle := newTestBundle(t, "stream.go", streamSource)
+
+	var buf bytes.Buffer
+	if err := EncodeBundleWithOptions(&buf, bundle, EncodeOptions{Compression: CompressionGzip}); err != nil {
+		t.Fatalf("EncodeBundleWithOptions failed: %v", err)
+	}
+	data := buf.Bytes()
+
+	if _, err := DecodeBundleWithOptions(bytes.NewReader(data), DecodeOptions{MaxDecompressedSize: 64}); !errors.Is(err, ErrBundleTooLarge) {
+		t.Errorf("expected ErrBundleTooLarge, got %v", err)
+	}
+
+	decoded, err := DecodeBundleWithOptions(bytes.NewReader(data), DecodeOptions{})
+	if err != nil {
+		t.Fatalf("DecodeBundleWithOptions failed: %v", err)
+	}
+	if decoded.SourceCode != streamSource {
+		t.Error("source code mismatch")
+	}
+}
@@ -0,0 +1,125 @@
+package archive
+
+import (
+	"bytes"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// syntheticSource generates a file with n functions for benchmarks
+func syntheticSource(n int) string {
+	var b strings.Builder
+	b.WriteString("package bench\n\nimport \"fmt\"\n")
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&b, `
+// Func%[1]d is generated.
+func Func%[1]d(values []int) (int, error) {
+	total := 0
+	for i, v := range values {
+		if v%%2 == 0 {
+			total += v * i
+		} else {
+			total -= v
+		}
+	}
+	if total < 0 {
+		return 0, fmt.Errorf("negative total %%d", total)
+	}
+	return total, nil
+}
+`, i)
+	}
+	return b.String()
+}
+
+// benchmarkSizes are the synthetic file sizes, in functions
+var benchmarkSizes = []struct {
+	name  string
+	funcs int
+}{
+	{"small", 5},
+	{"medium", 100},
+	{"large", 2000},
+}
+
+// BenchmarkSave benchmarks saving archives to disk. Before/after numbers
+// for the pooled buffers are recorded in testdata/bench_baseline.txt.
+func BenchmarkSave(b *testing.B) {
+	for _, size := range benchmarkSizes {
+		b.Run(size.name, func(b *testing.B) {
+			source := syntheticSource(size.funcs)
+			fset := token.NewFileSet()
+			file, err := parser.ParseFile(fset, "bench.go", source, parser.ParseComments)
+			if err != nil {
+				b.Fatalf("failed to parse source: %v", err)
+			}
+			out := filepath.Join(b.TempDir(), "bench.asta")
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := SaveASTWithSourcePreservation(file, fset, "bench.go", out); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkLoad benchmarks loading archives from disk
+func BenchmarkLoad(b *testing.B) {
+	for _, size := range benchmarkSizes {
+		b.Run(size.name, func(b *testing.B) {
+			source := syntheticSource(size.funcs)
+			fset := token.NewFileSet()
+			file, err := parser.ParseFile(fset, "bench.go", source, parser.ParseComments)
+			if err != nil {
+				b.Fatalf("failed to parse source: %v", err)
+			}
+			out := filepath.Join(b.TempDir(), "bench.asta")
+			if err := SaveASTWithSourcePreservation(file, fset, "bench.go", out); err != nil {
+				b.Fatal(err)
+			}
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := Load(out); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkEncodeGzip benchmarks compressed in-memory encoding
+func BenchmarkEncodeGzip(b *testing.B) {
+	for _, size := range benchmarkSizes {
+		b.Run(size.name, func(b *testing.B) {
+			source := syntheticSource(size.funcs)
+			fset := token.NewFileSet()
+			file, err := parser.ParseFile(fset, "bench.go", source, parser.ParseComments)
+			if err != nil {
+				b.Fatalf("failed to parse source: %v", err)
+			}
+			bundle, err := NewBundle(file, fset, "bench.go")
+			if err != nil {
+				b.Fatal(err)
+			}
+			var buf bytes.Buffer
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				buf.Reset()
+				if err := EncodeBundleWithOptions(&buf, bundle, EncodeOptions{Compression: CompressionGzip}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
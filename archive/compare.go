@@ -0,0 +1,188 @@
+package archive
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"reflect"
+)
+
+// CompareOptions controls which parts of two ASTs DeepCompareAST considers.
+type CompareOptions struct {
+	// IgnorePositions skips every token.Pos field.
+	IgnorePositions bool
+
+	// IgnoreComments skips comment groups, both attached docs and the
+	// file-level comment list.
+	IgnoreComments bool
+}
+
+var (
+	posType          = reflect.TypeOf(token.Pos(0))
+	commentGroupType = reflect.TypeOf((*ast.CommentGroup)(nil))
+	commentListType  = reflect.TypeOf([]*ast.CommentGroup(nil))
+)
+
+// skippedCompareFields are never compared: Obj and Scope hold resolver
+// state with cycles, and Imports and Unresolved repeat nodes found
+// elsewhere in the file.
+var skippedCompareFields = map[string]bool{
+	"Obj":        true,
+	"Scope":      true,
+	"Imports":    true,
+	"Unresolved": true,
+}
+
+// DeepCompareAST reports whether a and b are structurally identical. When
+// they differ, path identifies the first mismatching field, for example
+// "File.Decls[2].(*ast.FuncDecl).Body.List[0]". A mismatch at the roots
+// themselves, such as a nil node or nodes of different types, has the
+// path ".".
+func DeepCompareAST(a, b ast.Node, opts CompareOptions) (bool, string) {
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	if !va.IsValid() || !vb.IsValid() {
+		if va.IsValid() != vb.IsValid() {
+			return false, "."
+		}
+		return true, ""
+	}
+	if va.Type() != vb.Type() {
+		return false, "."
+	}
+
+	c := comparer{opts: opts, nodeA: a, nodeB: b}
+	return c.compare(va, vb, rootName(va.Type()))
+}
+
+// firstDifference is like DeepCompareAST but also returns the innermost
+// nodes of each tree that enclose the first mismatch.
+func firstDifference(a, b ast.Node, opts CompareOptions) (path string, nodeA, nodeB ast.Node, equal bool) {
+	c := comparer{opts: opts, nodeA: a, nodeB: b}
+	equal, path = c.compare(reflect.ValueOf(a), reflect.ValueOf(b), rootName(reflect.TypeOf(a)))
+	return path, c.nodeA, c.nodeB, equal
+}
+
+// comparer walks two values in parallel using reflection. nodeA and nodeB
+// track the innermost nodes being compared; after a mismatch they are
+// left pointing at the nodes enclosing it.
+type comparer struct {
+	opts         CompareOptions
+	nodeA, nodeB ast.Node
+}
+
+// rootName names the top-level node in mismatch paths, e.g. "File".
+func rootName(t reflect.Type) string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Name()
+}
+
+func (c *comparer) compare(a, b reflect.Value, path string) (bool, string) {
+	switch a.Kind() {
+	case reflect.Interface:
+		if a.IsNil() || b.IsNil() {
+			if a.IsNil() != b.IsNil() {
+				return false, path
+			}
+			return true, ""
+		}
+		ea, eb := a.Elem(), b.Elem()
+		if ea.Type() != eb.Type() {
+			c.enter(ea, eb)
+			return false, path
+		}
+		return c.compare(ea, eb, path+".("+ea.Type().String()+")")
+
+	case reflect.Ptr:
+		if a.IsNil() || b.IsNil() {
+			if a.IsNil() != b.IsNil() {
+				return false, path
+			}
+			return true, ""
+		}
+		if a.Pointer() == b.Pointer() {
+			return true, ""
+		}
+		prevA, prevB := c.nodeA, c.nodeB
+		c.enter(a, b)
+		ok, p := c.compare(a.Elem(), b.Elem(), path)
+		if ok {
+			c.nodeA, c.nodeB = prevA, prevB
+		}
+		return ok, p
+
+	case reflect.Struct:
+		t := a.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() || c.skipField(field) {
+				continue
+			}
+			if ok, p := c.compare(a.Field(i), b.Field(i), path+"."+field.Name); !ok {
+				return false, p
+			}
+		}
+		return true, ""
+
+	case reflect.Slice:
+		if a.Len() != b.Len() {
+			return false, path
+		}
+		for i := 0; i < a.Len(); i++ {
+			if ok, p := c.compare(a.Index(i), b.Index(i), fmt.Sprintf("%s[%d]", path, i)); !ok {
+				return false, p
+			}
+		}
+		return true, ""
+
+	case reflect.Map:
+		// Maps only occur in resolver state, which is skipped above.
+		return true, ""
+
+	default:
+		if a.Interface() != b.Interface() {
+			return false, path
+		}
+		return true, ""
+	}
+}
+
+// enter records a and b as the current nodes if they are AST nodes.
+func (c *comparer) enter(a, b reflect.Value) {
+	if n, ok := a.Interface().(ast.Node); ok {
+		c.nodeA = n
+	}
+	if n, ok := b.Interface().(ast.Node); ok {
+		c.nodeB = n
+	}
+}
+
+// skipField reports whether a struct field is excluded from comparison.
+func (c *comparer) skipField(field reflect.StructField) bool {
+	if skippedCompareFields[field.Name] {
+		return true
+	}
+	if c.opts.IgnorePositions && field.Type == posType {
+		return true
+	}
+	if c.opts.IgnoreComments && (field.Type == commentGroupType || field.Type == commentListType) {
+		return true
+	}
+	return false
+}
+
+// EqualStructure reports whether a and b are the same program modulo
+// formatting: positions and resolver state are ignored, and nil and empty
+// slices compare equal. Comments must still match.
+func EqualStructure(a, b ast.Node) bool {
+	equal, _ := DeepCompareAST(a, b, CompareOptions{IgnorePositions: true})
+	return equal
+}
+
+// EqualStructureIgnoringComments is like EqualStructure but also ignores
+// comments.
+func EqualStructureIgnoringComments(a, b ast.Node) bool {
+	equal, _ := DeepCompareAST(a, b, CompareOptions{IgnorePositions: true, IgnoreComments: true})
+	return equal
+}
@@ -0,0 +1,243 @@
+package archive
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"testing"
+)
+
+// parseForCompare parses source with comments for comparator tests
+func parseForCompare(t *testing.T, source string) *ast.File {
+	t.Helper()
+	file, err := parser.ParseFile(token.NewFileSet(), "compare.go", source, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("failed to parse source: %v", err)
+	}
+	return file
+}
+
+// TestDeepCompareAST tests comparison options and mismatch path reporting
+func TestDeepCompareAST(t *testing.T) {
+	base := `package p
+
+// Add adds.
+func Add(a, b int) int {
+	return a + b
+}
+`
+
+	tests := []struct {
+		name  string
+		other string
+		opts  CompareOptions
+		equal bool
+		path  string
+	}{
+		{
+			name:  "identical",
+			other: base,
+			equal: true,
+		},
+		{
+			name: "shifted positions",
+			other: `package p
+
+
+// Add adds.
+func Add(a, b int) int {
+	return a + b
+}
+`,
+			equal: false,
+			path:  "File.Decls[0].(*ast.FuncDecl).Doc.List[0].Slash",
+		},
+		{
+			name: "shifted positions ignored",
+			other: `package p
+
+
+// Add adds.
+func Add(a, b int) int {
+	return a + b
+}
+`,
+			opts:  CompareOptions{IgnorePositions: true},
+			equal: true,
+		},
+		{
+			name: "different comment",
+			other: `package p
+
+// Add sums.
+func Add(a, b int) int {
+	return a + b
+}
+`,
+			opts:  CompareOptions{IgnorePositions: true},
+			equal: false,
+			path:  "File.Decls[0].(*ast.FuncDecl).Doc.List[0].Text",
+		},
+		{
+			name: "different comment ignored",
+			other: `package p
+
+// Add sums.
+func Add(a, b int) int {
+	return a + b
+}
+`,
+			opts:  CompareOptions{IgnorePositions: true, IgnoreComments: true},
+			equal: true,
+		},
+		{
+			name: "different operator",
+			other: `package p
+
+// Add adds.
+func Add(a, b int) int {
+	return a - b
+}
+`,
+			equal: false,
+			path:  "File.Decls[0].(*ast.FuncDecl).Body.List[0].(*ast.ReturnStmt).Results[0].(*ast.BinaryExpr).Op",
+		},
+		{
+			name: "different parameter name",
+			other: `package p
+
+// Add adds.
+func Add(a, c int) int {
+	return a + b
+}
+`,
+			equal: false,
+			path:  "File.Decls[0].(*ast.FuncDecl).Type.Params.List[0].Names[1].Name",
+		},
+		{
+			name: "different node type",
+			other: `package p
+
+// Add adds.
+func Add(a, b int) int {
+	panic(a + b)
+}
+`,
+			equal: false,
+			path:  "File.Decls[0].(*ast.FuncDecl).Body.List[0]",
+		},
+		{
+			name: "extra statement",
+			other: `package p
+
+// Add adds.
+func Add(a, b int) int {
+	_ = a
+	return a + b
+}
+`,
+			opts:  CompareOptions{IgnorePositions: true},
+			equal: false,
+			path:  "File.Decls[0].(*ast.FuncDecl).Body.List",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := parseForCompare(t, base)
+			b := parseForCompare(t, tt.other)
+
+			equal, path := DeepCompareAST(a, b, tt.opts)
+			if equal != tt.equal {
+				t.Fatalf("expected equal=%v, got %v (path %q)", tt.equal, equal, path)
+			}
+			if path != tt.path {
+				t.Errorf("expected path %q, got %q", tt.path, path)
+			}
+		})
+	}
+}
+
+// TestDeepCompareASTNil tests comparisons involving nil nodes
+func TestDeepCompareASTNil(t *testing.T) {
+	file := parseForCompare(t, "package p\n")
+
+	if equal, _ := DeepCompareAST(nil, nil, CompareOptions{}); !equal {
+		t.Error("expected nil nodes to compare equal")
+	}
+	if equal, path := DeepCompareAST(file, nil, CompareOptions{}); equal || path != "." {
+		t.Errorf("expected file and nil to differ at the root, got %v at %q", equal, path)
+	}
+	if equal, path := DeepCompareAST(file, file.Name, CompareOptions{}); equal || path != "." {
+		t.Errorf("expected nodes of different types to differ at the root, got %v at %q", equal, path)
+	}
+}
+
+// TestEqualStructure tests formatting-insensitive structural equality
+func TestEqualStructure(t *testing.T) {
+	formatted := `package p
+
+import "fmt"
+
+// Point is a point.
+type Point struct {
+	X, Y int
+}
+
+func (p Point) String() string {
+	if p.X > 0 {
+		return fmt.Sprintf("(%d, %d)", p.X, p.Y)
+	}
+	return ""
+}
+`
+	reformatted := `package p
+import "fmt"
+// Point is a point.
+type Point struct { X, Y int }
+func (p Point) String() string { if p.X > 0 { return fmt.Sprintf("(%d, %d)", p.X, p.Y) }
+	return "" }
+`
+	changed := `package p
+import "fmt"
+// Point is a point.
+type Point struct { X, Y int }
+func (p Point) String() string { if p.X >= 0 { return fmt.Sprintf("(%d, %d)", p.X, p.Y) }
+	return "" }
+`
+	recommented := `package p
+import "fmt"
+// Point is a 2D point.
+type Point struct { X, Y int }
+func (p Point) String() string { if p.X > 0 { return fmt.Sprintf("(%d, %d)", p.X, p.Y) }
+	return "" }
+`
+
+	a := parseForCompare(t, formatted)
+
+	if !EqualStructure(a, parseForCompare(t, reformatted)) {
+		t.Error("expected differently formatted sources to compare equal")
+	}
+	if EqualStructure(a, parseForCompare(t, changed)) {
+		t.Error("expected a changed operator to compare unequal")
+	}
+	if EqualStructureIgnoringComments(a, parseForCompare(t, changed)) {
+		t.Error("expected a changed operator to compare unequal when ignoring comments")
+	}
+	if EqualStructure(a, parseForCompare(t, recommented)) {
+		t.Error("expected a changed comment to compare unequal")
+	}
+	if !EqualStructureIgnoringComments(a, parseForCompare(t, recommented)) {
+		t.Error("expected a changed comment to compare equal when ignoring comments")
+	}
+}
+
+// TestEqualStructureNilAndEmpty tests that nil and empty slices compare equal
+func TestEqualStructureNilAndEmpty(t *testing.T) {
+	withNil := &ast.BlockStmt{}
+	withEmpty := &ast.BlockStmt{List: []ast.Stmt{}}
+
+	if !EqualStructure(withNil, withEmpty) {
+		t.Error("expected nil and empty statement lists to compare equal")
+	}
+}
@@ -0,0 +1,103 @@
+package archive
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strings"
+)
+
+// excerptContext is the number of lines shown on each side of a difference.
+const excerptContext = 3
+
+// FidelityError describes where a restored AST first diverges from the
+// original when their formatted sources differ.
+type FidelityError struct {
+	// Path locates the first mismatching field, as reported by
+	// DeepCompareAST. It is empty if the trees are structurally equal and
+	// only their formatting differs.
+	Path string
+
+	// NodeType is the type of the innermost node enclosing the mismatch,
+	// e.g. "*ast.BasicLit".
+	NodeType string
+
+	// OriginalPos and RestoredPos locate that node in each tree.
+	OriginalPos token.Position
+	RestoredPos token.Position
+
+	// Line is the first line at which the formatted sources differ, and
+	// the excerpts show that line with surrounding context from each.
+	Line            int
+	OriginalExcerpt string
+	RestoredExcerpt string
+}
+
+func (e *FidelityError) Error() string {
+	var b strings.Builder
+	b.WriteString("source code does not match")
+	if e.Path != "" {
+		fmt.Fprintf(&b, ": first difference at %s (%s, original %s, restored %s)",
+			e.Path, e.NodeType, e.OriginalPos, e.RestoredPos)
+	}
+	if e.Line > 0 {
+		fmt.Fprintf(&b, "\noriginal:\n%srestored:\n%s", e.OriginalExcerpt, e.RestoredExcerpt)
+	}
+	return b.String()
+}
+
+// newFidelityError locates the first difference between two trees whose
+// formatted sources, origSrc and restSrc, differ.
+func newFidelityError(original, restored *ast.File, originalFset, restoredFset *token.FileSet, origSrc, restSrc string) *FidelityError {
+	e := &FidelityError{}
+
+	path, nodeA, nodeB, equal := firstDifference(original, restored, CompareOptions{IgnorePositions: true})
+	if !equal {
+		e.Path = path
+		if nodeA != nil {
+			e.NodeType = fmt.Sprintf("%T", nodeA)
+			e.OriginalPos = originalFset.Position(nodeA.Pos())
+		}
+		if nodeB != nil {
+			if e.NodeType == "" {
+				e.NodeType = fmt.Sprintf("%T", nodeB)
+			}
+			e.RestoredPos = restoredFset.Position(nodeB.Pos())
+		}
+	}
+
+	origLines := strings.Split(origSrc, "\n")
+	restLines := strings.Split(restSrc, "\n")
+	line := 0
+	for line < len(origLines) && line < len(restLines) && origLines[line] == restLines[line] {
+		line++
+	}
+	e.Line = line + 1
+	e.OriginalExcerpt = excerpt(origLines, line)
+	e.RestoredExcerpt = excerpt(restLines, line)
+
+	return e
+}
+
+// excerpt renders lines around the zero-based index at with line numbers,
+// marking the line at index at.
+func excerpt(lines []string, at int) string {
+	start := at - excerptContext
+	if start < 0 {
+		start = 0
+	}
+	end := at + excerptContext + 1
+	if end > len(lines) {
+		end = len(lines)
+	}
+
+	var b strings.Builder
+	for i := start; i < end; i++ {
+		marker := " "
+		if i == at {
+			marker = ">"
+		}
+		fmt.Fprintf(&b, "%s %4d | %s\n", marker, i+1, lines[i])
+	}
+	return b.String()
+}
@@ -0,0 +1,75 @@
+package archive
+
+import (
+	"errors"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+)
+
+// TestFidelityErrorLocatesLiteral tests that a mutated literal is pinpointed
+func TestFidelityErrorLocatesLiteral(t *testing.T) {
+	source := `package main
+
+import "fmt"
+
+func main() {
+	fmt.Println("first")
+	fmt.Println("second")
+	fmt.Println("third")
+}
+`
+	originalFset := token.NewFileSet()
+	original, err := parser.ParseFile(originalFset, "main.go", source, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("failed to parse source: %v", err)
+	}
+	restoredFset := token.NewFileSet()
+	restored, err := parser.ParseFile(restoredFset, "main.go", source, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("failed to parse source: %v", err)
+	}
+
+	// Mutate the second literal in the restored tree
+	seen := 0
+	ast.Inspect(restored, func(n ast.Node) bool {
+		if lit, ok := n.(*ast.BasicLit); ok && lit.Kind == token.STRING && lit.Value == `"second"` {
+			lit.Value = `"changed"`
+			seen++
+		}
+		return true
+	})
+	if seen != 1 {
+		t.Fatalf("expected to mutate one literal, mutated %d", seen)
+	}
+
+	err = VerifyPerfectFidelity(original, restored, originalFset, restoredFset)
+	var fe *FidelityError
+	if !errors.As(err, &fe) {
+		t.Fatalf("expected *FidelityError, got %v", err)
+	}
+
+	if fe.NodeType != "*ast.BasicLit" {
+		t.Errorf("expected node type *ast.BasicLit, got %q", fe.NodeType)
+	}
+	if !strings.HasSuffix(fe.Path, ".Args[0].(*ast.BasicLit).Value") {
+		t.Errorf("unexpected path %q", fe.Path)
+	}
+	if fe.OriginalPos.Line != 7 || fe.RestoredPos.Line != 7 {
+		t.Errorf("expected line 7, got original %d, restored %d", fe.OriginalPos.Line, fe.RestoredPos.Line)
+	}
+	if fe.Line != 7 {
+		t.Errorf("expected first differing line 7, got %d", fe.Line)
+	}
+	if !strings.Contains(fe.OriginalExcerpt, `>    7 | 	fmt.Println("second")`) {
+		t.Errorf("original excerpt missing marked line:\n%s", fe.OriginalExcerpt)
+	}
+	if !strings.Contains(fe.RestoredExcerpt, `"changed"`) || strings.Contains(fe.RestoredExcerpt, "package main") {
+		t.Errorf("restored excerpt should cover only lines 4-9:\n%s", fe.RestoredExcerpt)
+	}
+	if !strings.Contains(err.Error(), "*ast.BasicLit") || !strings.Contains(err.Error(), "main.go:7:14") {
+		t.Errorf("error message should name the node and position: %v", err)
+	}
+}
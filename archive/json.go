@@ -0,0 +1,411 @@
+package archive
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"reflect"
+)
+
+// jsonBundleVersion is the version of the JSON bundle layout.
+const jsonBundleVersion = 1
+
+// jsonBundle is the interchange form of a SimpleASTBundle. The AST is
+// stored as tagged nodes rather than gob structs so that consumers in
+// other languages can read it.
+type jsonBundle struct {
+	Version    int                    `json:"version"`
+	Filename   string                 `json:"filename"`
+	ParseMode  parser.Mode            `json:"parse_mode"`
+	SourceCode string                 `json:"source"`
+	Files      []FileInfo             `json:"files,omitempty"`
+	AST        interface{}            `json:"ast,omitempty"`
+	Metadata   map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// jsonPosition is a token.Pos resolved through the bundle's FileSet.
+type jsonPosition struct {
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Column int    `json:"col"`
+}
+
+// kindField is the discriminator key present on every encoded node.
+const kindField = "kind"
+
+// jsonNodeTypes lists every concrete node type that can appear in a
+// parsed file, keyed by the kind written to JSON.
+var jsonNodeTypes = map[string]reflect.Type{}
+
+func init() {
+	for _, n := range []interface{}{
+		&ast.ArrayType{}, &ast.AssignStmt{}, &ast.BadDecl{}, &ast.BadExpr{},
+		&ast.BadStmt{}, &ast.BasicLit{}, &ast.BinaryExpr{}, &ast.BlockStmt{},
+		&ast.BranchStmt{}, &ast.CallExpr{}, &ast.CaseClause{}, &ast.ChanType{},
+		&ast.CommClause{}, &ast.Comment{}, &ast.CommentGroup{}, &ast.CompositeLit{},
+		&ast.DeclStmt{}, &ast.DeferStmt{}, &ast.Ellipsis{}, &ast.EmptyStmt{},
+		&ast.ExprStmt{}, &ast.Field{}, &ast.FieldList{}, &ast.File{},
+		&ast.ForStmt{}, &ast.FuncDecl{}, &ast.FuncLit{}, &ast.FuncType{},
+		&ast.GenDecl{}, &ast.GoStmt{}, &ast.Ident{}, &ast.IfStmt{},
+		&ast.ImportSpec{}, &ast.IncDecStmt{}, &ast.IndexExpr{}, &ast.IndexListExpr{},
+		&ast.InterfaceType{}, &ast.KeyValueExpr{}, &ast.LabeledStmt{}, &ast.MapType{},
+		&ast.ParenExpr{}, &ast.RangeStmt{}, &ast.ReturnStmt{}, &ast.SelectStmt{},
+		&ast.SelectorExpr{}, &ast.SendStmt{}, &ast.SliceExpr{}, &ast.StarExpr{},
+		&ast.StructType{}, &ast.SwitchStmt{}, &ast.TypeAssertExpr{}, &ast.TypeSpec{},
+		&ast.TypeSwitchStmt{}, &ast.UnaryExpr{}, &ast.ValueSpec{},
+	} {
+		t := reflect.TypeOf(n).Elem()
+		jsonNodeTypes[t.Name()] = t
+	}
+}
+
+// skippedJSONFields are not encoded: Obj, Scope, and Unresolved hold
+// resolver state, and Imports is rebuilt from the declarations on decode.
+var skippedJSONFields = map[string]bool{
+	"Obj":        true,
+	"Scope":      true,
+	"Unresolved": true,
+	"Imports":    true,
+}
+
+var tokenType = reflect.TypeOf(token.ILLEGAL)
+
+// tokensByName maps token spellings back to tokens for decoding.
+var tokensByName = func() map[string]token.Token {
+	m := make(map[string]token.Token)
+	for tok := token.ILLEGAL; tok <= token.TILDE; tok++ {
+		m[tok.String()] = tok
+	}
+	return m
+}()
+
+// EncodeBundleJSON writes b to w as JSON. The cleaned AST is written as a
+// tree of tagged nodes whose positions are file/line/column triples
+// resolved through the bundle's FileSet.
+func EncodeBundleJSON(w io.Writer, b *SimpleASTBundle) error {
+	out := jsonBundle{
+		Version:    jsonBundleVersion,
+		Filename:   b.Filename,
+		ParseMode:  b.ParseMode,
+		SourceCode: b.SourceCode,
+		Files:      b.Files,
+		Metadata:   b.Metadata,
+	}
+
+	if b.CleanedAST != nil {
+		fset, err := restoreFileSet(b.Files)
+		if err != nil {
+			return err
+		}
+		if fset == nil {
+			return fmt.Errorf("bundle has a cleaned AST but no file set")
+		}
+		enc := jsonEncoder{fset: fset}
+		out.AST = enc.value(reflect.ValueOf(b.CleanedAST))
+	}
+
+	e := json.NewEncoder(w)
+	e.SetIndent("", "  ")
+	if err := e.Encode(out); err != nil {
+		return fmt.Errorf("failed to encode bundle: %w", err)
+	}
+	return nil
+}
+
+// DecodeBundleJSON reads a bundle written by EncodeBundleJSON. The cleaned
+// AST is rebuilt from its tagged nodes; the source is not re-parsed.
+func DecodeBundleJSON(r io.Reader) (*SimpleASTBundle, error) {
+	var in struct {
+		jsonBundle
+		AST json.RawMessage `json:"ast,omitempty"`
+	}
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	if err := dec.Decode(&in); err != nil {
+		return nil, fmt.Errorf("failed to decode bundle: %w", err)
+	}
+	if in.Version > jsonBundleVersion {
+		return nil, &VersionError{Version: in.Version}
+	}
+
+	bundle := &SimpleASTBundle{
+		SourceCode: in.SourceCode,
+		Filename:   in.Filename,
+		ParseMode:  in.ParseMode,
+		Files:      in.Files,
+		Metadata:   decodeMetadata(in.Metadata),
+	}
+
+	if len(in.AST) > 0 && string(in.AST) != "null" {
+		fset, err := restoreFileSet(in.Files)
+		if err != nil {
+			return nil, err
+		}
+		if fset == nil {
+			return nil, fmt.Errorf("bundle has an AST but no file set")
+		}
+		dec := newJSONDecoder(fset)
+
+		root, err := dec.node(in.AST, "File")
+		if err != nil {
+			return nil, err
+		}
+		file, ok := root.Interface().(*ast.File)
+		if !ok {
+			return nil, fmt.Errorf("bundle AST root is %s, not a file", root.Type())
+		}
+		rebuildImports(file)
+		bundle.CleanedAST = file
+	}
+
+	return bundle, nil
+}
+
+// rebuildImports fills file.Imports from its import declarations, as the
+// parser does.
+func rebuildImports(file *ast.File) {
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			if imp, ok := spec.(*ast.ImportSpec); ok {
+				file.Imports = append(file.Imports, imp)
+			}
+		}
+	}
+}
+
+// jsonEncoder converts AST values to JSON-friendly values.
+type jsonEncoder struct {
+	fset *token.FileSet
+}
+
+func (e *jsonEncoder) value(v reflect.Value) interface{} {
+	switch v.Type() {
+	case posType:
+		return e.position(token.Pos(v.Int()))
+	case tokenType:
+		return token.Token(v.Int()).String()
+	}
+
+	switch v.Kind() {
+	case reflect.Interface, reflect.Ptr:
+		if v.IsNil() {
+			return nil
+		}
+		return e.value(v.Elem())
+
+	case reflect.Struct:
+		node := map[string]interface{}{kindField: v.Type().Name()}
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() || skippedJSONFields[field.Name] {
+				continue
+			}
+			if fv := e.value(v.Field(i)); fv != nil {
+				node[field.Name] = fv
+			}
+		}
+		return node
+
+	case reflect.Slice:
+		if v.Len() == 0 {
+			return nil
+		}
+		list := make([]interface{}, v.Len())
+		for i := range list {
+			list[i] = e.value(v.Index(i))
+		}
+		return list
+
+	default:
+		return v.Interface()
+	}
+}
+
+func (e *jsonEncoder) position(pos token.Pos) interface{} {
+	if !pos.IsValid() {
+		return nil
+	}
+	p := e.fset.PositionFor(pos, false)
+	return jsonPosition{File: p.Filename, Line: p.Line, Column: p.Column}
+}
+
+// jsonDecoder rebuilds AST values from decoded JSON.
+type jsonDecoder struct {
+	files map[string]*token.File
+}
+
+func newJSONDecoder(fset *token.FileSet) *jsonDecoder {
+	d := &jsonDecoder{files: make(map[string]*token.File)}
+	fset.Iterate(func(f *token.File) bool {
+		d.files[f.Name()] = f
+		return true
+	})
+	return d
+}
+
+// value decodes data into dst, which must be settable. path names dst in
+// error messages.
+func (d *jsonDecoder) value(data json.RawMessage, dst reflect.Value, path string) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	switch dst.Type() {
+	case posType:
+		pos, err := d.position(data)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		dst.SetInt(int64(pos))
+		return nil
+	case tokenType:
+		var name string
+		if err := json.Unmarshal(data, &name); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		tok, ok := tokensByName[name]
+		if !ok {
+			return fmt.Errorf("%s: unknown token %q", path, name)
+		}
+		dst.SetInt(int64(tok))
+		return nil
+	}
+
+	switch dst.Kind() {
+	case reflect.Interface, reflect.Ptr:
+		node, err := d.node(data, path)
+		if err != nil {
+			return err
+		}
+		if !node.Type().AssignableTo(dst.Type()) {
+			return fmt.Errorf("%s: %s cannot be used as %s", path, node.Type(), dst.Type())
+		}
+		dst.Set(node)
+		return nil
+
+	case reflect.Slice:
+		var items []json.RawMessage
+		if err := json.Unmarshal(data, &items); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		list := reflect.MakeSlice(dst.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := d.value(item, list.Index(i), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+		dst.Set(list)
+		return nil
+
+	default:
+		if err := json.Unmarshal(data, dst.Addr().Interface()); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		return nil
+	}
+}
+
+// node decodes a tagged node, returning a pointer to the new value.
+func (d *jsonDecoder) node(data json.RawMessage, path string) (reflect.Value, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return reflect.Value{}, fmt.Errorf("%s: %w", path, err)
+	}
+
+	var kind string
+	if err := json.Unmarshal(fields[kindField], &kind); err != nil {
+		return reflect.Value{}, fmt.Errorf("%s: missing node kind", path)
+	}
+	t, ok := jsonNodeTypes[kind]
+	if !ok {
+		return reflect.Value{}, fmt.Errorf("%s: unknown node kind %q", path, kind)
+	}
+
+	ptr := reflect.New(t)
+	v := ptr.Elem()
+	for name, raw := range fields {
+		if name == kindField {
+			continue
+		}
+		field, ok := t.FieldByName(name)
+		if !ok || !field.IsExported() || skippedJSONFields[name] {
+			return reflect.Value{}, fmt.Errorf("%s: unknown field %s.%s", path, kind, name)
+		}
+		if err := d.value(raw, v.FieldByIndex(field.Index), path+"."+name); err != nil {
+			return reflect.Value{}, err
+		}
+	}
+	return ptr, nil
+}
+
+func (d *jsonDecoder) position(data json.RawMessage) (token.Pos, error) {
+	var p jsonPosition
+	if err := json.Unmarshal(data, &p); err != nil {
+		return token.NoPos, err
+	}
+	f, ok := d.files[p.File]
+	if !ok {
+		return token.NoPos, fmt.Errorf("position refers to unknown file %q", p.File)
+	}
+	if p.Line < 1 || p.Line > f.LineCount() || p.Column < 1 {
+		return token.NoPos, fmt.Errorf("invalid position %s:%d:%d", p.File, p.Line, p.Column)
+	}
+	pos := f.LineStart(p.Line) + token.Pos(p.Column-1)
+	if int(pos) > f.Base()+f.Size() {
+		return token.NoPos, fmt.Errorf("invalid position %s:%d:%d", p.File, p.Line, p.Column)
+	}
+	return pos, nil
+}
+
+// decodeMetadata restores the types NewBundle gives metadata values, which
+// JSON loses: integers come back as int rather than numbers, and the
+// node_counts map as a map[string]int. Other numbers become float64, as
+// they would without UseNumber.
+func decodeMetadata(in map[string]interface{}) map[string]interface{} {
+	metadata := make(map[string]interface{}, len(in))
+	for key, value := range in {
+		metadata[key] = decodeMetadataValue(value)
+	}
+	if counts, ok := metadata["node_counts"].(map[string]interface{}); ok {
+		result := make(map[string]int, len(counts))
+		for nodeType, count := range counts {
+			if n, ok := count.(int); ok {
+				result[nodeType] = n
+			}
+		}
+		if len(result) == len(counts) {
+			metadata["node_counts"] = result
+		}
+	}
+	return metadata
+}
+
+// decodeMetadataValue converts the json.Numbers in a decoded metadata
+// value to int when they are integers and to float64 otherwise.
+func decodeMetadataValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return int(n)
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]interface{}:
+		for key, elem := range v {
+			v[key] = decodeMetadataValue(elem)
+		}
+	case []interface{}:
+		for i, elem := range v {
+			v[i] = decodeMetadataValue(elem)
+		}
+	}
+	return value
+}
@@ -0,0 +1,131 @@
+package archive
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestJSONBundleRoundTrip tests JSON encoding of every corpus file
+func TestJSONBundleRoundTrip(t *testing.T) {
+	var paths []string
+	for _, dir := range corpusDirs {
+		matches, err := filepath.Glob(filepath.Join(dir, "*.go"))
+		if err != nil {
+			t.Fatalf("failed to list %s: %v", dir, err)
+		}
+		paths = append(paths, matches...)
+	}
+	if len(paths) == 0 {
+		t.Skip("Skipping test - corpus not available")
+	}
+
+	for _, path := range paths {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			source, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("failed to read %s: %v", path, err)
+			}
+			bundle := newTestBundle(t, filepath.Base(path), string(source))
+
+			var buf bytes.Buffer
+			if err := EncodeBundleJSON(&buf, bundle); err != nil {
+				t.Fatalf("EncodeBundleJSON failed: %v", err)
+			}
+
+			decoded, err := DecodeBundleJSON(&buf)
+			if err != nil {
+				t.Fatalf("DecodeBundleJSON failed: %v", err)
+			}
+			if decoded.SourceCode != bundle.SourceCode || decoded.Filename != bundle.Filename {
+				t.Error("bundle fields do not match")
+			}
+
+			original, restored := NewASTArchive(bundle), NewASTArchive(decoded)
+			if restored.DeclarationCount() == 0 || restored.DeclarationCount() != original.DeclarationCount() {
+				t.Errorf("expected %d declarations, got %d", original.DeclarationCount(), restored.DeclarationCount())
+			}
+			if restored.ImportCount() != original.ImportCount() {
+				t.Errorf("expected %d imports, got %d", original.ImportCount(), restored.ImportCount())
+			}
+			if !reflect.DeepEqual(restored.NodeCounts(), original.NodeCounts()) {
+				t.Error("node counts do not match")
+			}
+
+			originalFset, err := NewASTArchive(bundle).GetCleanedFileSet()
+			if err != nil {
+				t.Fatalf("failed to restore original file set: %v", err)
+			}
+			decodedFset, err := NewASTArchive(decoded).GetCleanedFileSet()
+			if err != nil {
+				t.Fatalf("failed to restore decoded file set: %v", err)
+			}
+
+			if equal, path := DeepCompareAST(bundle.CleanedAST, decoded.CleanedAST, CompareOptions{}); !equal {
+				t.Errorf("decoded AST differs at %s", path)
+			}
+			if err := VerifyPerfectFidelity(bundle.CleanedAST, decoded.CleanedAST, originalFset, decodedFset); err != nil {
+				t.Errorf("fidelity check failed: %v", err)
+			}
+			if len(decoded.CleanedAST.Imports) != len(bundle.CleanedAST.Imports) {
+				t.Errorf("expected %d imports, got %d",
+					len(bundle.CleanedAST.Imports), len(decoded.CleanedAST.Imports))
+			}
+		})
+	}
+}
+
+// TestJSONBundleFormat tests the tagged node representation
+func TestJSONBundleFormat(t *testing.T) {
+	bundle := newTestBundle(t, "stream.go", streamSource)
+
+	var buf bytes.Buffer
+	if err := EncodeBundleJSON(&buf, bundle); err != nil {
+		t.Fatalf("EncodeBundleJSON failed: %v", err)
+	}
+
+	var doc struct {
+		AST struct {
+			Kind  string `json:"kind"`
+			Name  map[string]interface{}
+			Decls []map[string]interface{}
+		} `json:"ast"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("output is not valid JSON: %v", err)
+	}
+
+	if doc.AST.Kind != "File" {
+		t.Errorf("expected root kind File, got %q", doc.AST.Kind)
+	}
+	if doc.AST.Name["kind"] != "Ident" || doc.AST.Name["Name"] != "stream" {
+		t.Errorf("unexpected package name node: %v", doc.AST.Name)
+	}
+	pos, ok := doc.AST.Name["NamePos"].(map[string]interface{})
+	if !ok || pos["file"] != "stream.go" || pos["line"] != float64(1) || pos["col"] != float64(9) {
+		t.Errorf("unexpected package name position: %v", doc.AST.Name["NamePos"])
+	}
+	if len(doc.AST.Decls) == 0 {
+		t.Fatal("expected declarations")
+	}
+}
+
+// TestJSONBundleRejectsUnknownKind tests decoding of malformed node kinds
+func TestJSONBundleRejectsUnknownKind(t *testing.T) {
+	bundle := newTestBundle(t, "stream.go", streamSource)
+
+	var buf bytes.Buffer
+	if err := EncodeBundleJSON(&buf, bundle); err != nil {
+		t.Fatalf("EncodeBundleJSON failed: %v", err)
+	}
+	data := strings.Replace(buf.String(), `"kind": "FuncDecl"`, `"kind": "Bogus"`, 1)
+
+	_, err := DecodeBundleJSON(strings.NewReader(data))
+	if err == nil || !strings.Contains(err.Error(), `unknown node kind "Bogus"`) {
+		t.Errorf("expected unknown kind error, got %v", err)
+	}
+}
@@ -0,0 +1,67 @@
+package archive
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"sync"
+)
+
+// Archiving a corpus saves and loads many files in a row, so the buffers
+// and compressors used per file are pooled rather than reallocated.
+var (
+	sourceBufferPool = sync.Pool{New: func() interface{} { return new(bytes.Buffer) }}
+	writerPool       = sync.Pool{New: func() interface{} { return bufio.NewWriter(nil) }}
+	readerPool       = sync.Pool{New: func() interface{} { return bufio.NewReader(nil) }}
+	gzipWriterPool   = sync.Pool{New: func() interface{} { return gzip.NewWriter(nil) }}
+)
+
+// maxPooledBufferSize keeps unusually large buffers out of the pool so one
+// huge file does not pin its memory for the life of the process.
+const maxPooledBufferSize = 4 << 20
+
+func getSourceBuffer() *bytes.Buffer {
+	buf := sourceBufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	return buf
+}
+
+func putSourceBuffer(buf *bytes.Buffer) {
+	if buf.Cap() <= maxPooledBufferSize {
+		sourceBufferPool.Put(buf)
+	}
+}
+
+func getWriter(w io.Writer) *bufio.Writer {
+	bw := writerPool.Get().(*bufio.Writer)
+	bw.Reset(w)
+	return bw
+}
+
+func putWriter(bw *bufio.Writer) {
+	bw.Reset(nil)
+	writerPool.Put(bw)
+}
+
+func getReader(r io.Reader) *bufio.Reader {
+	br := readerPool.Get().(*bufio.Reader)
+	br.Reset(r)
+	return br
+}
+
+func putReader(br *bufio.Reader) {
+	br.Reset(nil)
+	readerPool.Put(br)
+}
+
+func getGzipWriter(w io.Writer) *gzip.Writer {
+	gz := gzipWriterPool.Get().(*gzip.Writer)
+	gz.Reset(w)
+	return gz
+}
+
+func putGzipWriter(gz *gzip.Writer) {
+	gz.Reset(nil)
+	gzipWriterPool.Put(gz)
+}
@@ -0,0 +1,102 @@
+package archive
+
+import (
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// corpusDirs lists the directories whose Go files are round-tripped
+var corpusDirs = []string{"../nodes/go"}
+
+// roundTripFile saves path as an archive, loads it back, and checks source,
+// formatting, and structural fidelity against the original parse
+func roundTripFile(t *testing.T, path string) {
+	t.Helper()
+
+	source, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", path, err)
+	}
+
+	filename := filepath.Base(path)
+	fset := token.NewFileSet()
+	original, err := parser.ParseFile(fset, filename, source, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("failed to parse %s: %v", path, err)
+	}
+
+	archivePath := filepath.Join(t.TempDir(), filename+".asta")
+	if err := SaveASTWithSourcePreservation(original, fset, filename, archivePath); err != nil {
+		t.Fatalf("failed to save archive: %v", err)
+	}
+
+	restored, restoredFset, restoredSource, err := LoadASTWithSourceReconstruction(archivePath)
+	if err != nil {
+		t.Fatalf("failed to load archive: %v", err)
+	}
+
+	// Source fidelity: the archive holds the gofmt'd original
+	formatted, err := format.Source(source)
+	if err != nil {
+		t.Fatalf("failed to format source: %v", err)
+	}
+	if restoredSource != string(formatted) && restoredSource != string(source) {
+		t.Errorf("restored source does not match the formatted original")
+	}
+
+	// Formatting fidelity
+	if err := VerifyPerfectFidelity(original, restored, fset, restoredFset); err != nil {
+		t.Errorf("fidelity check failed: %v", err)
+	}
+
+	// Structural fidelity: the restored AST must match a parse of the
+	// formatted original exactly, positions and comments included
+	formattedFset := token.NewFileSet()
+	formattedFile, err := parser.ParseFile(formattedFset, filename, formatted, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("failed to parse formatted source: %v", err)
+	}
+	if equal, path := DeepCompareAST(formattedFile, restored, CompareOptions{}); !equal {
+		t.Errorf("restored AST differs from formatted original at %s", path)
+	}
+
+	// Formatting may drop redundant nodes such as explicit empty
+	// statements, so differences from the raw parse are only logged
+	if equal, path := DeepCompareAST(original, restored, CompareOptions{IgnorePositions: true}); !equal {
+		t.Logf("formatting changed the AST structure at %s", path)
+	}
+
+	// The cleaned AST is parsed without comments but must otherwise match
+	archive, err := Load(archivePath)
+	if err != nil {
+		t.Fatalf("failed to load archive: %v", err)
+	}
+	if equal, path := DeepCompareAST(archive.GetCleanedAST(), restored, CompareOptions{IgnoreComments: true}); !equal {
+		t.Errorf("cleaned AST differs from restored AST at %s", path)
+	}
+}
+
+// TestCorpusRoundTrip tests archive round-trips for every corpus file
+func TestCorpusRoundTrip(t *testing.T) {
+	found := 0
+	for _, dir := range corpusDirs {
+		matches, err := filepath.Glob(filepath.Join(dir, "*.go"))
+		if err != nil {
+			t.Fatalf("failed to list %s: %v", dir, err)
+		}
+		for _, path := range matches {
+			found++
+			t.Run(filepath.Base(path), func(t *testing.T) {
+				roundTripFile(t, path)
+			})
+		}
+	}
+
+	if found == 0 {
+		t.Skip("Skipping test - corpus not available")
+	}
+}
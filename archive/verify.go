@@ -0,0 +1,174 @@
+package archive
+
+import (
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// RoundTripError reports which check a file failed when it was
+// round-tripped through an archive.
+type RoundTripError struct {
+	// Check is the failing step: "save", "load", "source",
+	// "fidelity", "structure", or "cleaned".
+	Check string
+	Err   error
+}
+
+func (e *RoundTripError) Error() string {
+	return fmt.Sprintf("%s check failed: %v", e.Check, e.Err)
+}
+
+func (e *RoundTripError) Unwrap() error {
+	return e.Err
+}
+
+// RoundTripResult is the outcome of round-tripping one Go file.
+type RoundTripResult struct {
+	Path         string
+	Nodes        int   // AST nodes in the original parse
+	ArchiveBytes int64 // size of the saved archive
+	Err          error // nil when every check passed; a *RoundTripError when one failed
+}
+
+// RoundTripSummary aggregates the results of VerifyArchives.
+type RoundTripSummary struct {
+	Results      []*RoundTripResult
+	Passed       int
+	Failed       int
+	Nodes        int
+	ArchiveBytes int64
+}
+
+// VerifyRoundTrip parses the Go file at path, saves it as an archive in
+// dir, loads it back, and checks that nothing was lost: the restored
+// source is the (formatted) original, VerifyPerfectFidelity passes, the
+// restored AST matches a parse of the formatted original exactly, and the
+// cleaned AST matches the restored one apart from comments. A file that
+// cannot be read or parsed is returned as an error; a failed check is
+// recorded in the result.
+func VerifyRoundTrip(path, dir string) (*RoundTripResult, error) {
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	filename := filepath.Base(path)
+	fset := token.NewFileSet()
+	original, err := parser.ParseFile(fset, filename, source, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	result := &RoundTripResult{Path: path}
+	ast.Inspect(original, func(n ast.Node) bool {
+		if n != nil {
+			result.Nodes++
+		}
+		return true
+	})
+	fail := func(check string, err error) (*RoundTripResult, error) {
+		result.Err = &RoundTripError{Check: check, Err: err}
+		return result, nil
+	}
+
+	archivePath := filepath.Join(dir, filename+".asta")
+	if err := SaveASTWithSourcePreservation(original, fset, filename, archivePath); err != nil {
+		return fail("save", err)
+	}
+	if info, err := os.Stat(archivePath); err == nil {
+		result.ArchiveBytes = info.Size()
+	}
+
+	restored, restoredFset, restoredSource, err := LoadASTWithSourceReconstruction(archivePath)
+	if err != nil {
+		return fail("load", err)
+	}
+
+	// The archive holds the gofmt'd original
+	formatted, err := format.Source(source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to format %s: %w", path, err)
+	}
+	if restoredSource != string(formatted) && restoredSource != string(source) {
+		return fail("source", sourceMismatch(string(formatted), restoredSource))
+	}
+
+	if err := VerifyPerfectFidelity(original, restored, fset, restoredFset); err != nil {
+		return fail("fidelity", err)
+	}
+
+	formattedFset := token.NewFileSet()
+	formattedFile, err := parser.ParseFile(formattedFset, filename, formatted, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse formatted %s: %w", path, err)
+	}
+	if equal, at := DeepCompareAST(formattedFile, restored, CompareOptions{}); !equal {
+		return fail("structure", fmt.Errorf("restored AST differs from the formatted original at %s", at))
+	}
+
+	loaded, err := Load(archivePath)
+	if err != nil {
+		return fail("load", err)
+	}
+	if equal, at := DeepCompareAST(loaded.GetCleanedAST(), restored, CompareOptions{IgnoreComments: true}); !equal {
+		return fail("cleaned", fmt.Errorf("cleaned AST differs from the restored AST at %s", at))
+	}
+
+	return result, nil
+}
+
+// VerifyArchives round-trips every Go file in dir through archives in a
+// temporary directory with VerifyRoundTrip, in file name order.
+func VerifyArchives(dir string) (*RoundTripSummary, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", dir, err)
+	}
+
+	tmpDir, err := os.MkdirTemp("", "go-ast-coverage-archives-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create archive directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	summary := &RoundTripSummary{}
+	for _, file := range files {
+		result, err := VerifyRoundTrip(file, tmpDir)
+		if err != nil {
+			return nil, err
+		}
+		summary.Add(result)
+	}
+	return summary, nil
+}
+
+// Add records result in the summary.
+func (s *RoundTripSummary) Add(result *RoundTripResult) {
+	s.Results = append(s.Results, result)
+	if result.Err != nil {
+		s.Failed++
+	} else {
+		s.Passed++
+	}
+	s.Nodes += result.Nodes
+	s.ArchiveBytes += result.ArchiveBytes
+}
+
+// sourceMismatch describes the first line at which the restored source
+// differs from the expected one.
+func sourceMismatch(want, got string) error {
+	wantLines := strings.Split(want, "\n")
+	gotLines := strings.Split(got, "\n")
+	line := 0
+	for line < len(wantLines) && line < len(gotLines) && wantLines[line] == gotLines[line] {
+		line++
+	}
+	return fmt.Errorf("restored source differs at line %d\noriginal:\n%srestored:\n%s",
+		line+1, excerpt(wantLines, line), excerpt(gotLines, line))
+}
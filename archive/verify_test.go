@@ -0,0 +1,60 @@
+package archive
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestVerifyArchives tests that every corpus file survives the round trip
+func TestVerifyArchives(t *testing.T) {
+	summary, err := VerifyArchives(corpusDirs[0])
+	if err != nil {
+		t.Fatalf("VerifyArchives failed: %v", err)
+	}
+	if len(summary.Results) == 0 {
+		t.Skip("Skipping test - corpus not available")
+	}
+
+	for _, result := range summary.Results {
+		if result.Err != nil {
+			t.Errorf("%s: %v", result.Path, result.Err)
+		}
+		if result.Nodes == 0 || result.ArchiveBytes == 0 {
+			t.Errorf("%s: expected nodes and archive bytes, got %+v", result.Path, result)
+		}
+	}
+	if summary.Passed != len(summary.Results) || summary.Failed != 0 {
+		t.Errorf("expected every file to pass, got %d passed, %d failed", summary.Passed, summary.Failed)
+	}
+}
+
+// TestVerifyRoundTripUnparsable tests that a file that does not parse is an
+// error rather than a failed check
+func TestVerifyRoundTripUnparsable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "broken.go")
+	if err := os.WriteFile(path, []byte("package main\n\nfunc main() {\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if _, err := VerifyRoundTrip(path, t.TempDir()); err == nil {
+		t.Errorf("expected a parse error")
+	}
+}
+
+// TestRoundTripError tests the check name and the source mismatch details
+func TestRoundTripError(t *testing.T) {
+	err := error(&RoundTripError{Check: "source", Err: sourceMismatch("a\nb\nc\n", "a\nB\nc\n")})
+
+	var rtErr *RoundTripError
+	if !errors.As(err, &rtErr) || rtErr.Check != "source" {
+		t.Fatalf("expected a source RoundTripError, got %v", err)
+	}
+	msg := err.Error()
+	for _, want := range []string{"source check failed: restored source differs at line 2", ">    2 | b", ">    2 | B"} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("error missing %q:\n%s", want, msg)
+		}
+	}
+}
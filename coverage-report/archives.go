@@ -0,0 +1,52 @@
+package report
+
+import (
+	"fmt"
+	"time"
+
+	"zylisp/go-ast-coverage/analyzer"
+	"zylisp/go-ast-coverage/archive"
+)
+
+// GenerateReportFromArchives creates a coverage report from the .asta
+// archives in dir. File names come from the archives, node counts from
+// the counts stored when each archive was written, and everything else
+// from the AST reconstructed from the archived source. Archives without
+// stored counts are counted on the reconstructed AST.
+func GenerateReportFromArchives(dir string) (*CoverageReport, error) {
+	start := time.Now()
+	var results []*analyzer.AnalysisResult
+	err := archive.Walk(dir, func(a *archive.ASTArchive) error {
+		file, fset, err := a.GetAST()
+		if err != nil {
+			return fmt.Errorf("failed to analyze %s: %w", a.GetFilename(), err)
+		}
+
+		result := analyzer.AnalyzeAST(a.GetFilename(), fset, file)
+		result.TokenKindCounts = analyzer.ScanTokenKinds([]byte(a.GetSourceCode()))
+		if counts := a.NodeCounts(); counts != nil {
+			result.NodeCounts = counts
+			result.TotalNodes = 0
+			for _, count := range counts {
+				result.TotalNodes += count
+			}
+			result.UniqueTypes = len(counts)
+		}
+		results = append(results, result)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archives: %w", err)
+	}
+	if len(results) == 0 {
+		return nil, fmt.Errorf("no archives found in %s", dir)
+	}
+
+	analysis := time.Since(start)
+
+	start = time.Now()
+	report := buildReport(results)
+	report.Meta = newReportMeta(len(results), 0, totalNodes(report.FileReports), analysis, time.Since(start))
+	report.Source = dir
+	return report, nil
+}
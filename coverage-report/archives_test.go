@@ -0,0 +1,62 @@
+package report
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"zylisp/go-ast-coverage/archive"
+)
+
+// TestGenerateReportFromArchives tests that archives of the corpus produce
+// the same report as the corpus sources
+func TestGenerateReportFromArchives(t *testing.T) {
+	const corpusDir = "../nodes/go"
+
+	archiveDir := t.TempDir()
+	entries, err := os.ReadDir(corpusDir)
+	if err != nil {
+		t.Fatalf("failed to read corpus: %v", err)
+	}
+	for _, entry := range entries {
+		if !strings.HasSuffix(entry.Name(), ".go") {
+			continue
+		}
+		fset := token.NewFileSet()
+		file, err := parser.ParseFile(fset, filepath.Join(corpusDir, entry.Name()), nil, parser.ParseComments)
+		if err != nil {
+			t.Fatalf("failed to parse %s: %v", entry.Name(), err)
+		}
+		out := filepath.Join(archiveDir, strings.TrimSuffix(entry.Name(), ".go")+".asta")
+		if err := archive.SaveASTWithSourcePreservation(file, fset, entry.Name(), out); err != nil {
+			t.Fatalf("failed to archive %s: %v", entry.Name(), err)
+		}
+	}
+
+	fromSource, err := GenerateReport(corpusDir)
+	if err != nil {
+		t.Fatalf("GenerateReport failed: %v", err)
+	}
+	fromArchives, err := GenerateReportFromArchives(archiveDir)
+	if err != nil {
+		t.Fatalf("GenerateReportFromArchives failed: %v", err)
+	}
+
+	// Archives record base names, a different source, and a different
+	// generation time and duration
+	fromArchives.GeneratedAt = fromSource.GeneratedAt
+	fromArchives.Source = fromSource.Source
+	fromArchives.Meta.AnalysisDuration = fromSource.Meta.AnalysisDuration
+	fromArchives.Meta.BuildDuration = fromSource.Meta.BuildDuration
+	for _, fr := range fromSource.FileReports {
+		fr.FileName = filepath.Base(fr.FileName)
+	}
+
+	if !reflect.DeepEqual(fromSource, fromArchives) {
+		t.Error("report from archives differs from report from sources")
+	}
+}
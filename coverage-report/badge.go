@@ -0,0 +1,52 @@
+package report
+
+import (
+	"fmt"
+	"os"
+)
+
+// Badge colors, matching the text report's thresholds.
+const (
+	badgeGreen  = "#4c1"
+	badgeYellow = "#dfb317"
+	badgeRed    = "#e05d44"
+)
+
+// RenderBadge renders a shields.io-style SVG badge showing the report's
+// coverage percentage.
+func RenderBadge(report *CoverageReport) []byte {
+	label := "ast coverage"
+	value := fmt.Sprintf("%.1f%%", report.CoveragePercent)
+
+	color := badgeRed
+	switch {
+	case report.CoveragePercent >= 90:
+		color = badgeGreen
+	case report.CoveragePercent >= 75:
+		color = badgeYellow
+	}
+
+	// Approximate Verdana 11px glyph widths
+	labelWidth := 6*len(label) + 10
+	valueWidth := 7*len(value) + 10
+	width := labelWidth + valueWidth
+
+	return []byte(fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%[1]d" height="20" role="img" aria-label="%[4]s: %[5]s">
+<title>%[4]s: %[5]s</title>
+<rect width="%[2]d" height="20" fill="#555"/>
+<rect x="%[2]d" width="%[3]d" height="20" fill="%[6]s"/>
+<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">
+<text x="%[7]d" y="14">%[4]s</text>
+<text x="%[8]d" y="14">%[5]s</text>
+</g>
+</svg>
+`, width, labelWidth, valueWidth, label, value, color, labelWidth/2, labelWidth+valueWidth/2))
+}
+
+// SaveReportBadge saves the report's coverage badge as SVG.
+func SaveReportBadge(report *CoverageReport, filePath string) error {
+	if err := os.WriteFile(filePath, RenderBadge(report), 0644); err != nil {
+		return fmt.Errorf("failed to write badge: %w", err)
+	}
+	return nil
+}
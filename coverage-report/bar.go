@@ -0,0 +1,29 @@
+package report
+
+import "strings"
+
+// barFill returns how many of width cells a bar at percent fills. It
+// rounds down, so a bar is only full at 100%, and clamps to [0, width].
+func barFill(percent float64, width int) int {
+	filled := int(percent / 100 * float64(width))
+	if filled < 0 {
+		return 0
+	}
+	if filled > width {
+		return width
+	}
+	return filled
+}
+
+// renderBar draws a width-cell progress bar for percent in unicode.
+func renderBar(percent float64, width int) string {
+	filled := barFill(percent, width)
+	return strings.Repeat(unicodeGlyphs.BarFilled, filled) + strings.Repeat(unicodeGlyphs.BarEmpty, width-filled)
+}
+
+// bar draws a width-cell progress bar for percent with the filled cells
+// painted in the percent's threshold color.
+func (rw *reportWriter) bar(percent float64, width int) string {
+	filled := barFill(percent, width)
+	return rw.paint(thresholdColor(percent), strings.Repeat(rw.glyphs.BarFilled, filled)) + strings.Repeat(rw.glyphs.BarEmpty, width-filled)
+}
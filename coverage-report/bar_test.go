@@ -0,0 +1,33 @@
+package report
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestRenderBar tests bar rounding at the edges
+func TestRenderBar(t *testing.T) {
+	tests := []struct {
+		percent float64
+		filled  int
+	}{
+		{0, 0},
+		{4.9, 0},
+		{5, 1},
+		{50, 10},
+		{99.9, 19},
+		{100, 20},
+		{120, 20},
+		{-5, 0},
+	}
+
+	for _, tt := range tests {
+		bar := renderBar(tt.percent, 20)
+		if got := strings.Count(bar, unicodeGlyphs.BarFilled); got != tt.filled {
+			t.Errorf("renderBar(%v, 20) filled %d cells, want %d", tt.percent, got, tt.filled)
+		}
+		if got := strings.Count(bar, unicodeGlyphs.BarFilled) + strings.Count(bar, unicodeGlyphs.BarEmpty); got != 20 {
+			t.Errorf("renderBar(%v, 20) is %d cells wide, want 20", tt.percent, got)
+		}
+	}
+}
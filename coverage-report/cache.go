@@ -0,0 +1,102 @@
+package report
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// generateCachedReport returns the report cached in opts.CacheDir for the
+// corpus's current contents, or generates and caches it. A cache hit gets
+// a fresh GeneratedAt, Source, and revision, since those describe the run
+// rather than the contents. A cache entry that cannot be loaded is
+// regenerated.
+func generateCachedReport(resultsDir string, opts ReportOptions) (*CoverageReport, error) {
+	entry, exists, err := CacheEntry(resultsDir, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if exists {
+		cached, err := LoadReportJSON(entry)
+		if err == nil {
+			cached.GeneratedAt = time.Now().UTC().Truncate(time.Second)
+			cached.Source = resultsDir
+			recordRevision(cached, opts)
+			return cached, nil
+		}
+		fmt.Fprintf(opts.warnings(), "Warning: ignoring unreadable cache entry %s: %v\n", entry, err)
+	}
+
+	report, err := generateReport(resultsDir, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(opts.CacheDir, 0755); err != nil {
+		fmt.Fprintf(opts.warnings(), "Warning: failed to create cache directory: %v\n", err)
+	} else if err := SaveReportJSON(report, entry); err != nil {
+		fmt.Fprintf(opts.warnings(), "Warning: failed to cache report: %v\n", err)
+	}
+	return report, nil
+}
+
+// CacheEntry returns the file in opts.CacheDir that caches the report for
+// the corpus's current contents, and whether it exists, without generating
+// anything.
+func CacheEntry(resultsDir string, opts ReportOptions) (string, bool, error) {
+	digest, err := reportDigest(resultsDir, opts)
+	if err != nil {
+		return "", false, err
+	}
+	entry := filepath.Join(opts.CacheDir, digest+".json")
+	_, err = os.Stat(entry)
+	return entry, err == nil, nil
+}
+
+// reportDigest hashes everything a generated report depends on: the
+// sorted names and contents of the corpus's Go files, the options that
+// shape the report, and the tool and schema versions.
+func reportDigest(resultsDir string, opts ReportOptions) (string, error) {
+	files, err := reportFiles(resultsDir, opts)
+	if err != nil {
+		return "", err
+	}
+	files = append([]string{}, files...)
+	sort.Strings(files)
+
+	h := sha256.New()
+	fmt.Fprintf(h, "tool %q\nschema %d\nversion %q\nraw %t\n", toolVersion(), CurrentSchemaVersion, opts.GoVersion, opts.IncludeRawAnalysis)
+	for _, ex := range opts.Exclusions {
+		fmt.Fprintf(h, "exclude %q %q\n", ex.NodeType, ex.Reason)
+	}
+	for _, file := range files {
+		contentHash, err := fileDigest(file)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "file %q %s\n", filepath.Base(file), contentHash)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// fileDigest returns the hex SHA-256 of a file's contents.
+func fileDigest(filePath string) (string, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %w", err)
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("failed to read file: %w", err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,103 @@
+package report
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestGenerateReportCache tests cache hits, misses after an edit, and
+// recovery from a corrupted cache entry
+func TestGenerateReportCache(t *testing.T) {
+	corpus := t.TempDir()
+	src, err := os.ReadFile("../nodes/go/statements.go")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	file := filepath.Join(corpus, "statements.go")
+	if err := os.WriteFile(file, src, 0644); err != nil {
+		t.Fatalf("failed to write fixture: %v", err)
+	}
+
+	opts := ReportOptions{CacheDir: filepath.Join(t.TempDir(), "cache")}
+	first, err := GenerateReportWithOptions(corpus, opts)
+	if err != nil {
+		t.Fatalf("GenerateReportWithOptions failed: %v", err)
+	}
+	entries, _ := filepath.Glob(filepath.Join(opts.CacheDir, "*.json"))
+	if len(entries) != 1 {
+		t.Fatalf("expected one cache entry, got %v", entries)
+	}
+	entry := entries[0]
+	if path, exists, err := CacheEntry(corpus, opts); err != nil || !exists || path != entry {
+		t.Errorf("CacheEntry = %q, %v, %v; want %q, true", path, exists, err, entry)
+	}
+
+	// Hit: a marked cache entry is returned with a fresh timestamp
+	cached, err := LoadReportJSON(entry)
+	if err != nil {
+		t.Fatalf("LoadReportJSON failed: %v", err)
+	}
+	cached.CoveragePercent = 12.34
+	cached.GeneratedAt = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	if err := SaveReportJSON(cached, entry); err != nil {
+		t.Fatalf("SaveReportJSON failed: %v", err)
+	}
+	hit, err := GenerateReportWithOptions(corpus, opts)
+	if err != nil {
+		t.Fatalf("GenerateReportWithOptions failed: %v", err)
+	}
+	if hit.CoveragePercent != 12.34 {
+		t.Errorf("expected the cached report, got coverage %.2f%%", hit.CoveragePercent)
+	}
+	if hit.GeneratedAt.Year() == 2000 {
+		t.Errorf("GeneratedAt was not refreshed")
+	}
+
+	// Different options miss
+	versioned, err := GenerateReportWithOptions(corpus, ReportOptions{CacheDir: opts.CacheDir, GoVersion: "go1.17"})
+	if err != nil {
+		t.Fatalf("GenerateReportWithOptions failed: %v", err)
+	}
+	if versioned.CoveragePercent == 12.34 {
+		t.Errorf("options did not change the cache key")
+	}
+
+	// Miss after an edit
+	if err := os.WriteFile(file, append(src, "\nfunc extra() {}\n"...), 0644); err != nil {
+		t.Fatalf("failed to edit fixture: %v", err)
+	}
+	edited, err := GenerateReportWithOptions(corpus, opts)
+	if err != nil {
+		t.Fatalf("GenerateReportWithOptions failed: %v", err)
+	}
+	if edited.CoveragePercent != first.CoveragePercent || edited.Meta.TotalNodes <= first.Meta.TotalNodes {
+		t.Errorf("expected a regenerated report after the edit, got %.2f%% and %d nodes", edited.CoveragePercent, edited.Meta.TotalNodes)
+	}
+
+	// A corrupted entry is regenerated and rewritten
+	if err := os.WriteFile(file, src, 0644); err != nil {
+		t.Fatalf("failed to restore fixture: %v", err)
+	}
+	if err := os.WriteFile(entry, []byte("{not json"), 0644); err != nil {
+		t.Fatalf("failed to corrupt cache entry: %v", err)
+	}
+	var warnings bytes.Buffer
+	opts.Warnings = &warnings
+	recovered, err := GenerateReportWithOptions(corpus, opts)
+	if err != nil {
+		t.Fatalf("GenerateReportWithOptions failed: %v", err)
+	}
+	if !strings.Contains(warnings.String(), "Warning: ignoring unreadable cache entry "+entry) {
+		t.Errorf("expected a warning about the corrupted entry, got %q", warnings.String())
+	}
+	if recovered.CoveragePercent != first.CoveragePercent {
+		t.Errorf("expected a regenerated report, got coverage %.2f%%", recovered.CoveragePercent)
+	}
+	if _, err := LoadReportJSON(entry); err != nil {
+		t.Errorf("corrupted cache entry was not rewritten: %v", err)
+	}
+}
@@ -0,0 +1,129 @@
+package report
+
+import "sort"
+
+// Node categories used to group node types in reports.
+const (
+	categoryExpression    = "Expression Nodes"
+	categoryStatement     = "Statement Nodes"
+	categoryDeclaration   = "Declaration Nodes"
+	categorySpec          = "Spec Nodes"
+	categoryType          = "Type Nodes"
+	categoryStructural    = "Structural Nodes"
+	categoryTopLevel      = "Top-Level Nodes"
+	categoryUncategorized = "Uncategorized"
+)
+
+// nodeCategories maps every node type known to the analyzer to its
+// category. Node types are placed by the go/ast interface they implement
+// rather than by name, so *ast.CaseClause is a statement and *ast.Ident an
+// expression.
+var nodeCategories = map[string]string{
+	// Expressions
+	"*ast.BadExpr":        categoryExpression,
+	"*ast.Ident":          categoryExpression,
+	"*ast.Ellipsis":       categoryExpression,
+	"*ast.BasicLit":       categoryExpression,
+	"*ast.FuncLit":        categoryExpression,
+	"*ast.CompositeLit":   categoryExpression,
+	"*ast.ParenExpr":      categoryExpression,
+	"*ast.SelectorExpr":   categoryExpression,
+	"*ast.IndexExpr":      categoryExpression,
+	"*ast.IndexListExpr":  categoryExpression,
+	"*ast.SliceExpr":      categoryExpression,
+	"*ast.TypeAssertExpr": categoryExpression,
+	"*ast.CallExpr":       categoryExpression,
+	"*ast.StarExpr":       categoryExpression,
+	"*ast.UnaryExpr":      categoryExpression,
+	"*ast.BinaryExpr":     categoryExpression,
+	"*ast.KeyValueExpr":   categoryExpression,
+
+	// Statements
+	"*ast.BadStmt":        categoryStatement,
+	"*ast.DeclStmt":       categoryStatement,
+	"*ast.EmptyStmt":      categoryStatement,
+	"*ast.LabeledStmt":    categoryStatement,
+	"*ast.ExprStmt":       categoryStatement,
+	"*ast.SendStmt":       categoryStatement,
+	"*ast.IncDecStmt":     categoryStatement,
+	"*ast.AssignStmt":     categoryStatement,
+	"*ast.GoStmt":         categoryStatement,
+	"*ast.DeferStmt":      categoryStatement,
+	"*ast.ReturnStmt":     categoryStatement,
+	"*ast.BranchStmt":     categoryStatement,
+	"*ast.BlockStmt":      categoryStatement,
+	"*ast.IfStmt":         categoryStatement,
+	"*ast.CaseClause":     categoryStatement,
+	"*ast.SwitchStmt":     categoryStatement,
+	"*ast.TypeSwitchStmt": categoryStatement,
+	"*ast.CommClause":     categoryStatement,
+	"*ast.SelectStmt":     categoryStatement,
+	"*ast.ForStmt":        categoryStatement,
+	"*ast.RangeStmt":      categoryStatement,
+
+	// Declarations
+	"*ast.BadDecl":  categoryDeclaration,
+	"*ast.GenDecl":  categoryDeclaration,
+	"*ast.FuncDecl": categoryDeclaration,
+
+	// Specs
+	"*ast.ImportSpec": categorySpec,
+	"*ast.ValueSpec":  categorySpec,
+	"*ast.TypeSpec":   categorySpec,
+
+	// Types
+	"*ast.ArrayType":     categoryType,
+	"*ast.StructType":    categoryType,
+	"*ast.FuncType":      categoryType,
+	"*ast.InterfaceType": categoryType,
+	"*ast.MapType":       categoryType,
+	"*ast.ChanType":      categoryType,
+
+	// Structural nodes
+	"*ast.Comment":      categoryStructural,
+	"*ast.CommentGroup": categoryStructural,
+	"*ast.Field":        categoryStructural,
+	"*ast.FieldList":    categoryStructural,
+
+	// Top-level nodes
+	"*ast.File":    categoryTopLevel,
+	"*ast.Package": categoryTopLevel,
+}
+
+// categorizeNode returns the category of a node type. Node types missing
+// from nodeCategories are reported as Uncategorized rather than guessed
+// from their names, so a new node type shows up until it is mapped.
+func categorizeNode(node string) string {
+	if category, ok := nodeCategories[node]; ok {
+		return category
+	}
+	return categoryUncategorized
+}
+
+// categorizeNodes groups nodes by their category.
+func categorizeNodes(nodes []string) map[string][]string {
+	categories := make(map[string][]string)
+
+	for _, node := range nodes {
+		category := categorizeNode(node)
+		categories[category] = append(categories[category], node)
+	}
+
+	// Sort within categories
+	for _, nodeList := range categories {
+		sort.Strings(nodeList)
+	}
+
+	return categories
+}
+
+// sortedCategories returns the category names of a categorizeNodes result
+// in sorted order.
+func sortedCategories(categories map[string][]string) []string {
+	names := make([]string, 0, len(categories))
+	for name := range categories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,113 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ColorMode controls ANSI coloring of the text report.
+type ColorMode string
+
+const (
+	// ColorAuto colors output written to a terminal unless NO_COLOR is set
+	// to a non-empty value.
+	ColorAuto ColorMode = "auto"
+	// ColorAlways always colors output.
+	ColorAlways ColorMode = "always"
+	// ColorNever never colors output.
+	ColorNever ColorMode = "never"
+)
+
+// ParseColorMode validates a color mode name. The empty string means
+// ColorAuto.
+func ParseColorMode(name string) (ColorMode, error) {
+	switch mode := ColorMode(name); mode {
+	case "":
+		return ColorAuto, nil
+	case ColorAuto, ColorAlways, ColorNever:
+		return mode, nil
+	}
+	return "", fmt.Errorf("unknown color mode %q (supported: auto, always, never)", name)
+}
+
+// PrintOptions controls how PrintReportWithOptions renders the report.
+type PrintOptions struct {
+	// Color selects ANSI coloring; the zero value means ColorAuto.
+	Color ColorMode
+
+	// ASCIIOnly draws bars and markers with plain ASCII instead of
+	// unicode symbols and emoji.
+	ASCIIOnly bool
+}
+
+// ANSI escape sequences used by the text report.
+const (
+	ansiReset  = "\x1b[0m"
+	ansiBold   = "\x1b[1m"
+	ansiRed    = "\x1b[31m"
+	ansiGreen  = "\x1b[32m"
+	ansiYellow = "\x1b[33m"
+)
+
+// useColor reports whether output to w should be colored under mode.
+func useColor(w io.Writer, mode ColorMode) bool {
+	switch mode {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	}
+	// An empty NO_COLOR does not count, per https://no-color.org
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	return isTerminal(w)
+}
+
+// isTerminal reports whether w is a character device such as a terminal.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// paint wraps s in the given ANSI code when coloring is enabled.
+func (rw *reportWriter) paint(code, s string) string {
+	if !rw.color || s == "" {
+		return s
+	}
+	return code + s + ansiReset
+}
+
+// heading writes a bold section heading.
+func (rw *reportWriter) heading(s string) {
+	rw.println(rw.paint(ansiBold, s))
+}
+
+// thresholdColor picks the color for a coverage percentage.
+func thresholdColor(percent float64) string {
+	switch {
+	case percent >= 90:
+		return ansiGreen
+	case percent >= 75:
+		return ansiYellow
+	default:
+		return ansiRed
+	}
+}
+
+// stripANSI removes the escape sequences written by paint.
+func stripANSI(s string) string {
+	for _, code := range []string{ansiReset, ansiBold, ansiRed, ansiGreen, ansiYellow} {
+		s = strings.ReplaceAll(s, code, "")
+	}
+	return s
+}
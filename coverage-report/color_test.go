@@ -0,0 +1,66 @@
+package report
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+// TestColorOutput tests that coloring only adds escape sequences
+func TestColorOutput(t *testing.T) {
+	var plain, colored bytes.Buffer
+	if err := PrintReportWithOptions(&plain, sampleReport(), PrintOptions{Color: ColorNever}); err != nil {
+		t.Fatalf("PrintReportWithOptions failed: %v", err)
+	}
+	if err := PrintReportWithOptions(&colored, sampleReport(), PrintOptions{Color: ColorAlways}); err != nil {
+		t.Fatalf("PrintReportWithOptions failed: %v", err)
+	}
+
+	if strings.Contains(plain.String(), "\x1b[") {
+		t.Error("expected no escape sequences with ColorNever")
+	}
+	if !strings.Contains(colored.String(), ansiGreen+"✓ *ast.File"+ansiReset) {
+		t.Error("expected covered nodes in green with ColorAlways")
+	}
+	if !strings.Contains(colored.String(), ansiRed+"✗ *ast.GoStmt"+ansiReset) {
+		t.Error("expected missing nodes in red with ColorAlways")
+	}
+	if stripANSI(colored.String()) != plain.String() {
+		t.Error("colored output differs from plain output after stripping escapes")
+	}
+}
+
+// TestColorAutoDetection tests automatic color detection
+func TestColorAutoDetection(t *testing.T) {
+	var buf bytes.Buffer
+	if useColor(&buf, ColorAuto) {
+		t.Error("expected no color for a non-terminal writer")
+	}
+	if !useColor(&buf, ColorAlways) {
+		t.Error("expected ColorAlways to force color")
+	}
+
+	t.Setenv("NO_COLOR", "1")
+	if useColor(&buf, ColorAuto) {
+		t.Error("expected NO_COLOR to disable color")
+	}
+
+	// A character device stands in for a terminal
+	dev, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatalf("failed to open %s: %v", os.DevNull, err)
+	}
+	defer dev.Close()
+	if useColor(dev, ColorAuto) {
+		t.Error("expected NO_COLOR=1 to disable color on a terminal")
+	}
+	t.Setenv("NO_COLOR", "")
+	if isTerminal(dev) && !useColor(dev, ColorAuto) {
+		t.Error("expected an empty NO_COLOR to leave color on")
+	}
+
+	if _, err := ParseColorMode("sometimes"); err == nil {
+		t.Error("expected error for unknown color mode")
+	}
+}
@@ -0,0 +1,89 @@
+package report
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SaveReportCSV saves the report as two CSV files derived from filePath:
+// "<name>-nodes.csv" with one row per node type and "<name>-files.csv"
+// with one row per file, where <name> is filePath without its extension.
+func SaveReportCSV(report *CoverageReport, filePath string) error {
+	base := strings.TrimSuffix(filePath, filepath.Ext(filePath))
+
+	if err := writeCSVFile(base+"-nodes.csv", report, writeNodesCSV); err != nil {
+		return err
+	}
+	return writeCSVFile(base+"-files.csv", report, writeFilesCSV)
+}
+
+// writeCSVFile creates filePath and fills it using write.
+func writeCSVFile(filePath string, report *CoverageReport, write func(io.Writer, *CoverageReport) error) error {
+	f, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer f.Close()
+
+	if err := write(f, report); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// writeNodesCSV writes one row per node type, sorted by type, with columns
+// type, category, covered, and count.
+func writeNodesCSV(w io.Writer, report *CoverageReport) error {
+	covered := stringSet(report.CoveredNodes)
+	nodes := make([]string, 0, len(report.CoveredNodes)+len(report.MissingNodes))
+	nodes = append(nodes, report.CoveredNodes...)
+	nodes = append(nodes, report.MissingNodes...)
+	sort.Strings(nodes)
+
+	rows := [][]string{{"type", "category", "covered", "count"}}
+	for _, node := range nodes {
+		rows = append(rows, []string{
+			node,
+			categorizeNode(node),
+			strconv.FormatBool(covered[node]),
+			strconv.Itoa(report.NodeCounts[node]),
+		})
+	}
+	return writeCSV(w, rows)
+}
+
+// writeFilesCSV writes one row per file, sorted by file name, with columns
+// file, node_count, unique_types, and coverage_percent.
+func writeFilesCSV(w io.Writer, report *CoverageReport) error {
+	files := make([]*FileReport, len(report.FileReports))
+	copy(files, report.FileReports)
+	sort.SliceStable(files, func(i, j int) bool {
+		return getBaseName(files[i].FileName) < getBaseName(files[j].FileName)
+	})
+
+	rows := [][]string{{"file", "node_count", "unique_types", "coverage_percent"}}
+	for _, fr := range files {
+		rows = append(rows, []string{
+			getBaseName(fr.FileName),
+			strconv.Itoa(fr.NodeCount),
+			strconv.Itoa(fr.UniqueTypes),
+			strconv.FormatFloat(fr.CoveragePercent, 'f', 2, 64),
+		})
+	}
+	return writeCSV(w, rows)
+}
+
+// writeCSV writes rows to w as CSV.
+func writeCSV(w io.Writer, rows [][]string) error {
+	cw := csv.NewWriter(w)
+	if err := cw.WriteAll(rows); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return nil
+}
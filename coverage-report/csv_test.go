@@ -0,0 +1,60 @@
+package report
+
+import (
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// readCSV parses the CSV file at path
+func readCSV(t *testing.T, path string) [][]string {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open %s: %v", path, err)
+	}
+	defer f.Close()
+
+	rows, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatalf("failed to parse %s: %v", path, err)
+	}
+	return rows
+}
+
+// TestSaveReportCSV tests that both CSV files parse back with the expected rows
+func TestSaveReportCSV(t *testing.T) {
+	rep := sampleReport()
+	rep.NodeCounts = map[string]int{"*ast.BinaryExpr": 3, "*ast.File": 2, "*ast.Ident": 17, "*ast.ReturnStmt": 4}
+	rep.FileReports[0].FileName = "nodes/go/odd, name.go"
+	rep.FileReports[0].CoveragePercent = 50
+
+	dir := t.TempDir()
+	if err := SaveReportCSV(rep, filepath.Join(dir, "coverage.csv")); err != nil {
+		t.Fatalf("SaveReportCSV failed: %v", err)
+	}
+
+	nodes := readCSV(t, filepath.Join(dir, "coverage-nodes.csv"))
+	if len(nodes) != 7 {
+		t.Fatalf("expected header and 6 node rows, got %d rows", len(nodes))
+	}
+	if got := nodes[0]; got[0] != "type" || got[3] != "count" {
+		t.Errorf("unexpected header %v", got)
+	}
+	// Rows are sorted by type: BinaryExpr, File, GoStmt, Ident, ...
+	if got := nodes[3]; got[0] != "*ast.GoStmt" || got[1] != "Statement Nodes" || got[2] != "false" || got[3] != "0" {
+		t.Errorf("unexpected GoStmt row %v", got)
+	}
+	if got := nodes[4]; got[0] != "*ast.Ident" || got[2] != "true" || got[3] != "17" {
+		t.Errorf("unexpected Ident row %v", got)
+	}
+
+	files := readCSV(t, filepath.Join(dir, "coverage-files.csv"))
+	if len(files) != 3 {
+		t.Fatalf("expected header and 2 file rows, got %d rows", len(files))
+	}
+	if got := files[2]; got[0] != "odd, name.go" || got[1] != "40" || got[3] != "50.00" {
+		t.Errorf("unexpected file row %v", got)
+	}
+}
@@ -0,0 +1,75 @@
+package report
+
+import (
+	"sort"
+	"time"
+)
+
+// deterministicReport returns a copy of report that serializes the same
+// way for the same inputs. GeneratedAt becomes timestamp, the run's
+// timings are zeroed, and every list whose order carries no meaning is
+// sorted. Lists in a fixed order, such as categories, token groups, and
+// merge sources, are kept as they are. Maps need no work: encoding/json
+// writes their keys sorted. The original report is not modified.
+func deterministicReport(report *CoverageReport, timestamp time.Time) *CoverageReport {
+	out := *report
+	out.GeneratedAt = timestamp.UTC()
+	out.Meta.AnalysisDuration = 0
+	out.Meta.BuildDuration = 0
+
+	out.CoveredNodes = sortedStrings(report.CoveredNodes)
+	out.MissingNodes = sortedStrings(report.MissingNodes)
+
+	out.FileReports = make([]*FileReport, len(report.FileReports))
+	for i, fr := range report.FileReports {
+		frCopy := *fr
+		frCopy.NodeTypes = sortedStrings(fr.NodeTypes)
+		out.FileReports[i] = &frCopy
+	}
+	sort.SliceStable(out.FileReports, func(i, j int) bool {
+		return out.FileReports[i].FileName < out.FileReports[j].FileName
+	})
+
+	if report.Attribution != nil {
+		out.Attribution = make(map[string][]string, len(report.Attribution))
+		for node, files := range report.Attribution {
+			out.Attribution[node] = sortedStrings(files)
+		}
+	}
+
+	out.SkippedFiles = append(report.SkippedFiles[:0:0], report.SkippedFiles...)
+	sort.SliceStable(out.SkippedFiles, func(i, j int) bool {
+		return out.SkippedFiles[i].FileName < out.SkippedFiles[j].FileName
+	})
+
+	out.Suggestions = append(report.Suggestions[:0:0], report.Suggestions...)
+	sort.SliceStable(out.Suggestions, func(i, j int) bool {
+		return out.Suggestions[i].NodeType < out.Suggestions[j].NodeType
+	})
+
+	out.Variants = append(report.Variants[:0:0], report.Variants...)
+	sort.SliceStable(out.Variants, func(i, j int) bool {
+		return out.Variants[i].Name < out.Variants[j].Name
+	})
+
+	if report.Analysis != nil {
+		analysis := *report.Analysis
+		analysis.Files = append(analysis.Files[:0:0], analysis.Files...)
+		sort.SliceStable(analysis.Files, func(i, j int) bool {
+			return analysis.Files[i].FileName < analysis.Files[j].FileName
+		})
+		out.Analysis = &analysis
+	}
+
+	return &out
+}
+
+// sortedStrings returns a sorted copy of list, keeping nil as nil.
+func sortedStrings(list []string) []string {
+	if list == nil {
+		return nil
+	}
+	sorted := append([]string{}, list...)
+	sort.Strings(sorted)
+	return sorted
+}
@@ -0,0 +1,89 @@
+package report
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestSaveReportJSONDeterministic tests that two runs over the same corpus
+// save byte-identical JSON
+func TestSaveReportJSONDeterministic(t *testing.T) {
+	dir := t.TempDir()
+	stamp := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	var saved [][]byte
+	for i := 0; i < 2; i++ {
+		rep, err := GenerateReport("../nodes/go")
+		if err != nil {
+			t.Fatalf("GenerateReport failed: %v", err)
+		}
+		// Reverse the unordered lists to show they are sorted on save
+		for l, r := 0, len(rep.FileReports)-1; l < r; l, r = l+1, r-1 {
+			rep.FileReports[l], rep.FileReports[r] = rep.FileReports[r], rep.FileReports[l]
+		}
+		if i == 1 {
+			for l, r := 0, len(rep.CoveredNodes)-1; l < r; l, r = l+1, r-1 {
+				rep.CoveredNodes[l], rep.CoveredNodes[r] = rep.CoveredNodes[r], rep.CoveredNodes[l]
+			}
+		}
+
+		path := filepath.Join(dir, "report.json")
+		if err := SaveReportJSONWithOptions(rep, path, JSONOptions{Deterministic: true, Timestamp: stamp}); err != nil {
+			t.Fatalf("SaveReportJSONWithOptions failed: %v", err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("failed to read report: %v", err)
+		}
+		saved = append(saved, data)
+
+		if rep.Meta.AnalysisDuration == 0 {
+			t.Errorf("saving modified the report's timings")
+		}
+	}
+
+	if !bytes.Equal(saved[0], saved[1]) {
+		t.Errorf("deterministic JSON differs between runs")
+	}
+
+	loaded, err := LoadReportJSON(filepath.Join(dir, "report.json"))
+	if err != nil {
+		t.Fatalf("LoadReportJSON failed: %v", err)
+	}
+	if !loaded.GeneratedAt.Equal(stamp) || loaded.Meta.AnalysisDuration != 0 {
+		t.Errorf("expected fixed timestamp and no timings, got %v and %v", loaded.GeneratedAt, loaded.Meta.AnalysisDuration)
+	}
+}
+
+// TestSaveReportJSONDeterministicZeroTimestamp tests that a zero Timestamp
+// writes the zero time and the report still loads
+func TestSaveReportJSONDeterministicZeroTimestamp(t *testing.T) {
+	rep := &CoverageReport{
+		SchemaVersion: CurrentSchemaVersion,
+		GeneratedAt:   time.Now().UTC(),
+		CoveredNodes:  []string{},
+		MissingNodes:  []string{},
+	}
+	path := filepath.Join(t.TempDir(), "report.json")
+	if err := SaveReportJSONWithOptions(rep, path, JSONOptions{Deterministic: true}); err != nil {
+		t.Fatalf("SaveReportJSONWithOptions failed: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read report: %v", err)
+	}
+	if !bytes.Contains(data, []byte(`"GeneratedAt": "0001-01-01T00:00:00Z"`)) {
+		t.Errorf("expected the zero GeneratedAt, got:\n%s", data)
+	}
+
+	loaded, err := LoadReportJSON(path)
+	if err != nil {
+		t.Fatalf("LoadReportJSON failed: %v", err)
+	}
+	if !loaded.GeneratedAt.IsZero() {
+		t.Errorf("expected a zero GeneratedAt, got %v", loaded.GeneratedAt)
+	}
+}
@@ -0,0 +1,184 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// ReportDiff describes how coverage changed between two reports.
+type ReportDiff struct {
+	NewlyCovered []string // node types covered in the new report but not the old
+	Regressed    []string // node types covered in the old report but not the new
+	OldPercent   float64
+	NewPercent   float64
+	PercentDelta float64
+	FileChanges  []*FileChange
+}
+
+// FileChange describes how one file's report changed. Files are matched by
+// base name; a file present in only one report has zero counts on the
+// other side.
+type FileChange struct {
+	FileName       string
+	Added          bool
+	Removed        bool
+	OldNodeCount   int
+	NewNodeCount   int
+	OldUniqueTypes int
+	NewUniqueTypes int
+}
+
+// HasChanges reports whether the diff records any change at all.
+func (d *ReportDiff) HasChanges() bool {
+	return len(d.NewlyCovered) > 0 || len(d.Regressed) > 0 || d.PercentDelta != 0 || len(d.FileChanges) > 0
+}
+
+// DiffReports compares an old report against a new one. Either report may
+// be nil or come from an older tool version with fields left unset: a
+// missing coverage percentage is recomputed from the node lists, and
+// missing lists are treated as empty.
+func DiffReports(old, new *CoverageReport) *ReportDiff {
+	if old == nil {
+		old = &CoverageReport{}
+	}
+	if new == nil {
+		new = &CoverageReport{}
+	}
+
+	oldCovered := stringSet(old.CoveredNodes)
+	newCovered := stringSet(new.CoveredNodes)
+
+	diff := &ReportDiff{
+		NewlyCovered: []string{},
+		Regressed:    []string{},
+		OldPercent:   reportPercent(old),
+		NewPercent:   reportPercent(new),
+		FileChanges:  []*FileChange{},
+	}
+	diff.PercentDelta = diff.NewPercent - diff.OldPercent
+
+	for node := range newCovered {
+		if !oldCovered[node] {
+			diff.NewlyCovered = append(diff.NewlyCovered, node)
+		}
+	}
+	for node := range oldCovered {
+		if !newCovered[node] {
+			diff.Regressed = append(diff.Regressed, node)
+		}
+	}
+	sort.Strings(diff.NewlyCovered)
+	sort.Strings(diff.Regressed)
+
+	oldFiles := fileReportsByName(old.FileReports)
+	newFiles := fileReportsByName(new.FileReports)
+	names := make([]string, 0, len(oldFiles)+len(newFiles))
+	for name := range oldFiles {
+		names = append(names, name)
+	}
+	for name := range newFiles {
+		if _, ok := oldFiles[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		oldFile, inOld := oldFiles[name]
+		newFile, inNew := newFiles[name]
+		change := &FileChange{FileName: name, Added: !inOld, Removed: !inNew}
+		if inOld {
+			change.OldNodeCount, change.OldUniqueTypes = oldFile.NodeCount, oldFile.UniqueTypes
+		}
+		if inNew {
+			change.NewNodeCount, change.NewUniqueTypes = newFile.NodeCount, newFile.UniqueTypes
+		}
+		if change.Added || change.Removed ||
+			change.OldNodeCount != change.NewNodeCount ||
+			change.OldUniqueTypes != change.NewUniqueTypes {
+			diff.FileChanges = append(diff.FileChanges, change)
+		}
+	}
+
+	return diff
+}
+
+// reportPercent returns the report's coverage percentage, recomputing it
+// when a report written by an older version left it unset.
+func reportPercent(report *CoverageReport) float64 {
+	if report.CoveragePercent != 0 {
+		return report.CoveragePercent
+	}
+	total := report.TotalNodeTypes
+	if total == 0 {
+		total = len(report.CoveredNodes) + len(report.MissingNodes)
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(len(report.CoveredNodes)) / float64(total) * 100
+}
+
+// stringSet returns the set of strings in list.
+func stringSet(list []string) map[string]bool {
+	set := make(map[string]bool, len(list))
+	for _, s := range list {
+		set[s] = true
+	}
+	return set
+}
+
+// fileReportsByName indexes file reports by base name, skipping nil entries.
+func fileReportsByName(files []*FileReport) map[string]*FileReport {
+	byName := make(map[string]*FileReport, len(files))
+	for _, fr := range files {
+		if fr != nil {
+			byName[getBaseName(fr.FileName)] = fr
+		}
+	}
+	return byName
+}
+
+// PrintReportDiff writes a diff to w, marking gains with "+" and losses
+// with "-".
+func PrintReportDiff(w io.Writer, diff *ReportDiff) {
+	fmt.Fprintln(w, strings.Repeat("=", 80))
+	fmt.Fprintln(w, "COVERAGE DIFF")
+	fmt.Fprintln(w, strings.Repeat("=", 80))
+	fmt.Fprintf(w, "Coverage: %.2f%% -> %.2f%% (%+.2f%%)\n", diff.OldPercent, diff.NewPercent, diff.PercentDelta)
+
+	if !diff.HasChanges() {
+		fmt.Fprintln(w, "\nNo changes.")
+		return
+	}
+
+	if len(diff.NewlyCovered) > 0 || len(diff.Regressed) > 0 {
+		fmt.Fprintln(w, "\nNODE TYPES")
+		fmt.Fprintln(w, strings.Repeat("-", 80))
+		for _, node := range diff.NewlyCovered {
+			fmt.Fprintf(w, "+ %s\n", node)
+		}
+		for _, node := range diff.Regressed {
+			fmt.Fprintf(w, "- %s\n", node)
+		}
+	}
+
+	if len(diff.FileChanges) > 0 {
+		fmt.Fprintln(w, "\nFILES")
+		fmt.Fprintln(w, strings.Repeat("-", 80))
+		for _, fc := range diff.FileChanges {
+			switch {
+			case fc.Added:
+				fmt.Fprintf(w, "+ %-30s  Nodes: %5d  Unique Types: %3d\n", fc.FileName, fc.NewNodeCount, fc.NewUniqueTypes)
+			case fc.Removed:
+				fmt.Fprintf(w, "- %-30s  Nodes: %5d  Unique Types: %3d\n", fc.FileName, fc.OldNodeCount, fc.OldUniqueTypes)
+			default:
+				fmt.Fprintf(w, "  %-30s  Nodes: %5d (%+d)  Unique Types: %3d (%+d)\n", fc.FileName,
+					fc.NewNodeCount, fc.NewNodeCount-fc.OldNodeCount,
+					fc.NewUniqueTypes, fc.NewUniqueTypes-fc.OldUniqueTypes)
+			}
+		}
+	}
+}
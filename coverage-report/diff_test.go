@@ -0,0 +1,85 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// TestDiffReportsImprovement tests a diff where coverage grows
+func TestDiffReportsImprovement(t *testing.T) {
+	old := sampleReport()
+	new := sampleReport()
+	new.CoveredNodes = append(new.CoveredNodes, "*ast.GoStmt")
+	new.MissingNodes = []string{"*ast.TypeSpec"}
+	new.CoveredNodeTypes = 5
+	new.CoveragePercent = 5.0 / 6 * 100
+	new.FileReports[0].NodeCount = 48
+	new.FileReports[0].UniqueTypes = 4
+
+	diff := DiffReports(old, new)
+	if len(diff.NewlyCovered) != 1 || diff.NewlyCovered[0] != "*ast.GoStmt" {
+		t.Errorf("expected *ast.GoStmt to be newly covered, got %v", diff.NewlyCovered)
+	}
+	if len(diff.Regressed) != 0 {
+		t.Errorf("expected no regressions, got %v", diff.Regressed)
+	}
+	if diff.PercentDelta <= 0 {
+		t.Errorf("expected a positive delta, got %.2f", diff.PercentDelta)
+	}
+	if len(diff.FileChanges) != 1 || diff.FileChanges[0].FileName != "statements.go" {
+		t.Fatalf("expected statements.go to change, got %v", diff.FileChanges)
+	}
+
+	var buf bytes.Buffer
+	PrintReportDiff(&buf, diff)
+	out := buf.String()
+	for _, want := range []string{"+ *ast.GoStmt", "66.67% -> 83.33% (+16.67%)", "Nodes:    48 (+8)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("diff output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+// TestDiffReportsRegression tests a diff where coverage shrinks and a file
+// disappears, against an old report missing fields from older versions
+func TestDiffReportsRegression(t *testing.T) {
+	old := sampleReport()
+	old.CoveragePercent = 0 // written before the field existed
+	new := sampleReport()
+	new.CoveredNodes = []string{"*ast.File", "*ast.Ident", "*ast.ReturnStmt"}
+	new.MissingNodes = []string{"*ast.BinaryExpr", "*ast.GoStmt", "*ast.TypeSpec"}
+	new.CoveragePercent = 50
+	new.FileReports = new.FileReports[:1]
+
+	diff := DiffReports(old, new)
+	if len(diff.Regressed) != 1 || diff.Regressed[0] != "*ast.BinaryExpr" {
+		t.Errorf("expected *ast.BinaryExpr to regress, got %v", diff.Regressed)
+	}
+	if diff.OldPercent < 66.6 || diff.OldPercent > 66.7 {
+		t.Errorf("expected old percent to be recomputed, got %.2f", diff.OldPercent)
+	}
+	if len(diff.FileChanges) != 1 || !diff.FileChanges[0].Removed {
+		t.Fatalf("expected one removed file, got %v", diff.FileChanges)
+	}
+
+	var buf bytes.Buffer
+	PrintReportDiff(&buf, diff)
+	if out := buf.String(); !strings.Contains(out, "- *ast.BinaryExpr") || !strings.Contains(out, "- expressions.go") {
+		t.Errorf("expected regression markers:\n%s", out)
+	}
+}
+
+// TestDiffReportsIdentical tests that identical reports produce an empty diff
+func TestDiffReportsIdentical(t *testing.T) {
+	diff := DiffReports(sampleReport(), sampleReport())
+	if diff.HasChanges() {
+		t.Errorf("expected no changes, got %+v", diff)
+	}
+
+	var buf bytes.Buffer
+	PrintReportDiff(&buf, diff)
+	if !strings.Contains(buf.String(), "No changes.") {
+		t.Errorf("expected no-change message:\n%s", buf.String())
+	}
+}
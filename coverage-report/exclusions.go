@@ -0,0 +1,122 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"zylisp/go-ast-coverage/analyzer"
+)
+
+// Exclusion removes a node type from the expected set on purpose, such as
+// a deprecated type the corpus will never cover.
+type Exclusion struct {
+	// NodeType is the excluded type, as "*ast.Package" or "Package".
+	NodeType string `json:"node_type"`
+	Reason   string `json:"reason"`
+}
+
+// ExcludedNode records an excluded node type in a report.
+type ExcludedNode struct {
+	NodeType string
+	Reason   string
+
+	// Covered is set when the corpus contains the type anyway, which
+	// usually means the exclusion is stale.
+	Covered bool
+}
+
+// ReportOptions controls how GenerateReportWithOptions builds a report.
+type ReportOptions struct {
+	// GoVersion is the version profile, such as "go1.17"; node types
+	// introduced after it are not expected. Empty means the latest.
+	GoVersion string
+
+	// Exclusions lists node types that are not expected and do not count
+	// against coverage.
+	Exclusions []Exclusion
+
+	// Revision, if set, records the corpus's version control revision in
+	// the report's Meta. A provider error is reported as a warning and
+	// does not fail the report.
+	Revision RevisionProvider
+
+	// IncludeRawAnalysis embeds the aggregated and per-file analysis
+	// results in the report, for consumers that need the raw counts.
+	IncludeRawAnalysis bool
+
+	// Files, if set, restricts the report to these Go files instead of
+	// every Go file in the directory. Coverage then describes only them.
+	Files []string
+
+	// CacheDir, if set, is where generated reports are cached, keyed by
+	// a digest of the corpus file contents and these options. Empty
+	// disables caching.
+	CacheDir string
+
+	// Warnings receives warnings about problems that do not fail the
+	// report. The zero value means os.Stderr.
+	Warnings io.Writer
+}
+
+// warnings returns the writer for the report's warnings.
+func (o ReportOptions) warnings() io.Writer {
+	if o.Warnings == nil {
+		return os.Stderr
+	}
+	return o.Warnings
+}
+
+// LoadExclusions reads a JSON list of exclusions, such as
+// [{"node_type": "Package", "reason": "deprecated"}].
+func LoadExclusions(filePath string) ([]Exclusion, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read exclusions: %w", err)
+	}
+
+	var exclusions []Exclusion
+	if err := json.Unmarshal(data, &exclusions); err != nil {
+		return nil, fmt.Errorf("failed to decode exclusions: %w", err)
+	}
+	return exclusions, nil
+}
+
+// applyExclusions removes the excluded node types from allNodeTypes. It
+// fails on a node type go/ast does not define; exclusions of types the
+// version profile does not expect anyway are dropped.
+func applyExclusions(allNodeTypes []string, exclusions []Exclusion) ([]string, []ExcludedNode, error) {
+	known := stringSet(analyzer.GetAllNodeTypes())
+	expected := stringSet(allNodeTypes)
+
+	excluded := []ExcludedNode{}
+	reasons := make(map[string]string)
+	for _, ex := range exclusions {
+		node := qualifyNodeType(ex.NodeType)
+		if !known[node] {
+			return nil, nil, fmt.Errorf("exclusion names unknown node type %q", ex.NodeType)
+		}
+		if _, dup := reasons[node]; dup || !expected[node] {
+			continue
+		}
+		reasons[node] = ex.Reason
+		excluded = append(excluded, ExcludedNode{NodeType: node, Reason: ex.Reason})
+	}
+
+	remaining := make([]string, 0, len(allNodeTypes))
+	for _, node := range allNodeTypes {
+		if _, ok := reasons[node]; !ok {
+			remaining = append(remaining, node)
+		}
+	}
+	return remaining, excluded, nil
+}
+
+// markCoveredExclusions sets Covered on the excluded node types the
+// report's files contain.
+func markCoveredExclusions(excluded []ExcludedNode, nodeCounts map[string]int) {
+	for i := range excluded {
+		excluded[i].Covered = nodeCounts[excluded[i].NodeType] > 0
+	}
+}
@@ -0,0 +1,60 @@
+package report
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestGenerateReportWithExclusions tests that excluded node types leave the
+// expected set and covered exclusions are flagged
+func TestGenerateReportWithExclusions(t *testing.T) {
+	full, err := GenerateReport("../nodes/go")
+	if err != nil {
+		t.Fatalf("GenerateReport failed: %v", err)
+	}
+
+	rep, err := GenerateReportWithOptions("../nodes/go", ReportOptions{
+		Exclusions: []Exclusion{
+			{NodeType: "Package", Reason: "deprecated"},
+			{NodeType: "*ast.GoStmt", Reason: "stale"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("GenerateReportWithOptions failed: %v", err)
+	}
+
+	if rep.TotalNodeTypes != full.TotalNodeTypes-2 {
+		t.Errorf("TotalNodeTypes = %d, want %d", rep.TotalNodeTypes, full.TotalNodeTypes-2)
+	}
+	for _, node := range append(append([]string{}, rep.CoveredNodes...), rep.MissingNodes...) {
+		if node == "*ast.Package" || node == "*ast.GoStmt" {
+			t.Errorf("excluded node type %s is still expected", node)
+		}
+	}
+
+	want := []ExcludedNode{
+		{NodeType: "*ast.Package", Reason: "deprecated"},
+		{NodeType: "*ast.GoStmt", Reason: "stale", Covered: true},
+	}
+	if !reflect.DeepEqual(rep.ExcludedNodes, want) {
+		t.Errorf("ExcludedNodes = %+v, want %+v", rep.ExcludedNodes, want)
+	}
+
+	var buf bytes.Buffer
+	if err := PrintReportTo(&buf, rep); err != nil {
+		t.Fatalf("PrintReportTo failed: %v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "EXCLUDED NODE TYPES") || strings.Count(out, "⚠ covered by the corpus") != 1 {
+		t.Errorf("report does not flag exactly one covered exclusion:\n%s", out)
+	}
+
+	_, err = GenerateReportWithOptions("../nodes/go", ReportOptions{
+		Exclusions: []Exclusion{{NodeType: "NoSuchExpr"}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "NoSuchExpr") {
+		t.Errorf("expected unknown node type error, got %v", err)
+	}
+}
@@ -0,0 +1,77 @@
+package report
+
+import "sort"
+
+// DefaultTopN is the number of node types in the MostCommon and
+// LeastCommon lists.
+const DefaultTopN = 10
+
+// NodeFrequency is how often a node type occurs across all files.
+type NodeFrequency struct {
+	Type  string
+	Count int
+
+	// Percent is Count as a percentage of all nodes.
+	Percent float64
+}
+
+// MostCommonNodes returns the n node types with the highest counts, most
+// common first, breaking ties by type name.
+func MostCommonNodes(counts map[string]int, n int) []NodeFrequency {
+	frequencies := nodeFrequencies(counts)
+	if len(frequencies) > n {
+		frequencies = frequencies[:n]
+	}
+	return frequencies
+}
+
+// LeastCommonNodes returns the n node types with the lowest non-zero
+// counts, least common first, breaking ties by type name. These are the
+// types most easily lost when sample files change; types that never occur
+// are already listed as missing.
+func LeastCommonNodes(counts map[string]int, n int) []NodeFrequency {
+	frequencies := nodeFrequencies(counts)
+	sort.SliceStable(frequencies, func(i, j int) bool {
+		return frequencies[i].Count < frequencies[j].Count
+	})
+	if len(frequencies) > n {
+		frequencies = frequencies[:n]
+	}
+	return frequencies
+}
+
+// nodeFrequencies lists the node types with a non-zero count, sorted by
+// descending count and then by type name.
+func nodeFrequencies(counts map[string]int) []NodeFrequency {
+	total := 0
+	for _, count := range counts {
+		total += count
+	}
+
+	frequencies := []NodeFrequency{}
+	for nodeType, count := range counts {
+		if count <= 0 {
+			continue
+		}
+		frequencies = append(frequencies, NodeFrequency{
+			Type:    nodeType,
+			Count:   count,
+			Percent: float64(count) / float64(total) * 100,
+		})
+	}
+	sort.Slice(frequencies, func(i, j int) bool {
+		if frequencies[i].Count != frequencies[j].Count {
+			return frequencies[i].Count > frequencies[j].Count
+		}
+		return frequencies[i].Type < frequencies[j].Type
+	})
+	return frequencies
+}
+
+// printFrequencies writes a numbered list of node frequencies.
+func printFrequencies(rw *reportWriter, frequencies []NodeFrequency) {
+	for i, nf := range frequencies {
+		rw.printf("  %2d. %-30s %6d  %6.2f%%\n", i+1, nf.Type, nf.Count, nf.Percent)
+	}
+	rw.println("")
+}
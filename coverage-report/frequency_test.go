@@ -0,0 +1,47 @@
+package report
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestNodeFrequencies tests the most and least common node type lists
+func TestNodeFrequencies(t *testing.T) {
+	counts := map[string]int{
+		"*ast.Ident":     6,
+		"*ast.CallExpr":  2,
+		"*ast.BlockStmt": 1,
+		"*ast.GoStmt":    1,
+		"*ast.BadExpr":   0,
+	}
+
+	most := MostCommonNodes(counts, 2)
+	wantMost := []NodeFrequency{
+		{Type: "*ast.Ident", Count: 6, Percent: 60},
+		{Type: "*ast.CallExpr", Count: 2, Percent: 20},
+	}
+	if !reflect.DeepEqual(most, wantMost) {
+		t.Errorf("MostCommonNodes = %v, want %v", most, wantMost)
+	}
+
+	least := LeastCommonNodes(counts, 10)
+	var types []string
+	for _, nf := range least {
+		types = append(types, nf.Type)
+	}
+	wantLeast := []string{"*ast.BlockStmt", "*ast.GoStmt", "*ast.CallExpr", "*ast.Ident"}
+	if !reflect.DeepEqual(types, wantLeast) {
+		t.Errorf("LeastCommonNodes = %v, want %v", types, wantLeast)
+	}
+
+	rep, err := GenerateReport("../nodes/go")
+	if err != nil {
+		t.Fatalf("GenerateReport failed: %v", err)
+	}
+	if len(rep.MostCommon) != DefaultTopN || rep.MostCommon[0].Type != "*ast.Ident" {
+		t.Errorf("unexpected MostCommon %v", rep.MostCommon)
+	}
+	if len(rep.LeastCommon) != DefaultTopN || rep.LeastCommon[0].Count == 0 {
+		t.Errorf("unexpected LeastCommon %v", rep.LeastCommon)
+	}
+}
@@ -0,0 +1,191 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"zylisp/go-ast-coverage/analyzer"
+)
+
+// Gate kinds of violation.
+const (
+	ViolationMinPercent = "min-percent"
+	ViolationRequired   = "required"
+	ViolationForbidden  = "forbidden"
+)
+
+// Gate is a set of coverage requirements a report must meet.
+type Gate struct {
+	// Required lists node types that must be covered, as "*ast.SelectStmt"
+	// or "SelectStmt".
+	Required []string `json:"required"`
+
+	// MinPercent is the minimum overall coverage percentage.
+	MinPercent float64 `json:"min_percent"`
+
+	// Forbidden lists node types that must not appear, such as BadExpr.
+	Forbidden []string `json:"forbidden"`
+
+	// Baseline is an optional path to a JSON report used to name the files
+	// that covered a required node type before it regressed. LoadGate
+	// resolves it relative to the gate file.
+	Baseline string `json:"baseline,omitempty"`
+}
+
+// GateViolation is one requirement a report fails.
+type GateViolation struct {
+	Kind     string
+	NodeType string
+	Category string
+
+	// PreviousFiles lists the baseline files that covered a missing
+	// required node type, if a baseline was given.
+	PreviousFiles []string
+
+	Message string
+}
+
+// GateResult is the outcome of EvaluateGate.
+type GateResult struct {
+	Passed     bool
+	Violations []GateViolation
+}
+
+// LoadGate reads a gate from a JSON file.
+func LoadGate(filePath string) (*Gate, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read gate: %w", err)
+	}
+
+	var gate Gate
+	if err := json.Unmarshal(data, &gate); err != nil {
+		return nil, fmt.Errorf("failed to decode gate: %w", err)
+	}
+	if gate.Baseline != "" && !filepath.IsAbs(gate.Baseline) {
+		gate.Baseline = filepath.Join(filepath.Dir(filePath), gate.Baseline)
+	}
+
+	return &gate, nil
+}
+
+// EvaluateGate checks the report against the gate. It returns an error
+// only if the gate itself is invalid, such as naming an unknown node type
+// or a baseline that cannot be loaded.
+func EvaluateGate(report *CoverageReport, gate *Gate) (*GateResult, error) {
+	known := stringSet(analyzer.GetAllNodeTypes())
+	required, err := qualifyGateNodes(gate.Required, known)
+	if err != nil {
+		return nil, err
+	}
+	forbidden, err := qualifyGateNodes(gate.Forbidden, known)
+	if err != nil {
+		return nil, err
+	}
+
+	var baseline *CoverageReport
+	if gate.Baseline != "" {
+		if baseline, err = LoadReportJSON(gate.Baseline); err != nil {
+			return nil, fmt.Errorf("failed to load gate baseline: %w", err)
+		}
+	}
+
+	result := &GateResult{Violations: []GateViolation{}}
+
+	if report.CoveragePercent < gate.MinPercent {
+		result.Violations = append(result.Violations, GateViolation{
+			Kind: ViolationMinPercent,
+			Message: fmt.Sprintf("coverage %.2f%% is below the minimum of %.2f%%",
+				report.CoveragePercent, gate.MinPercent),
+		})
+	}
+
+	covered := stringSet(report.CoveredNodes)
+	for _, node := range required {
+		if covered[node] {
+			continue
+		}
+		violation := GateViolation{
+			Kind:     ViolationRequired,
+			NodeType: node,
+			Category: categorizeNode(node),
+			Message:  fmt.Sprintf("required node type %s is not covered", node),
+		}
+		if baseline != nil {
+			violation.PreviousFiles = filesCovering(baseline, node)
+		}
+		result.Violations = append(result.Violations, violation)
+	}
+
+	for _, node := range forbidden {
+		if !covered[node] {
+			continue
+		}
+		result.Violations = append(result.Violations, GateViolation{
+			Kind:     ViolationForbidden,
+			NodeType: node,
+			Category: categorizeNode(node),
+			Message:  fmt.Sprintf("forbidden node type %s appears in %s", node, strings.Join(filesCovering(report, node), ", ")),
+		})
+	}
+
+	result.Passed = len(result.Violations) == 0
+	return result, nil
+}
+
+// qualifyGateNodes qualifies node type names and rejects unknown ones.
+func qualifyGateNodes(names []string, known map[string]bool) ([]string, error) {
+	var nodes []string
+	for _, name := range names {
+		node := qualifyNodeType(name)
+		if node == "" {
+			continue
+		}
+		if !known[node] {
+			return nil, fmt.Errorf("gate names unknown node type %q", name)
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
+
+// filesCovering returns the sorted base names of the report's files that
+// contain node.
+func filesCovering(report *CoverageReport, node string) []string {
+	var files []string
+	for _, fr := range report.FileReports {
+		for _, nodeType := range fr.NodeTypes {
+			if nodeType == node {
+				files = append(files, getBaseName(fr.FileName))
+				break
+			}
+		}
+	}
+	sort.Strings(files)
+	return files
+}
+
+// PrintGateResult writes the gate outcome to w, with one line per
+// violation and where to look for a fix.
+func PrintGateResult(w io.Writer, result *GateResult) {
+	if result.Passed {
+		fmt.Fprintln(w, "✓ Coverage gate passed")
+		return
+	}
+
+	fmt.Fprintf(w, "✗ Coverage gate failed with %d violation(s):\n", len(result.Violations))
+	for _, v := range result.Violations {
+		fmt.Fprintf(w, "  - %s\n", v.Message)
+		if v.Category != "" {
+			fmt.Fprintf(w, "      category: %s\n", v.Category)
+		}
+		if len(v.PreviousFiles) > 0 {
+			fmt.Fprintf(w, "      previously covered by: %s\n", strings.Join(v.PreviousFiles, ", "))
+		}
+	}
+}
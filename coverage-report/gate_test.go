@@ -0,0 +1,62 @@
+package report
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestEvaluateGate tests gate violations with a baseline report
+func TestEvaluateGate(t *testing.T) {
+	dir := t.TempDir()
+
+	baseline := sampleReport()
+	baseline.FileReports[0].NodeTypes = append(baseline.FileReports[0].NodeTypes, "*ast.GoStmt")
+	if err := SaveReportJSON(baseline, filepath.Join(dir, "baseline.json")); err != nil {
+		t.Fatalf("SaveReportJSON failed: %v", err)
+	}
+
+	gatePath := filepath.Join(dir, "gate.json")
+	gateJSON := `{"required": ["GoStmt", "*ast.Ident"], "min_percent": 70, "forbidden": ["BinaryExpr"], "baseline": "baseline.json"}`
+	if err := os.WriteFile(gatePath, []byte(gateJSON), 0644); err != nil {
+		t.Fatalf("failed to write gate: %v", err)
+	}
+
+	gate, err := LoadGate(gatePath)
+	if err != nil {
+		t.Fatalf("LoadGate failed: %v", err)
+	}
+	result, err := EvaluateGate(sampleReport(), gate)
+	if err != nil {
+		t.Fatalf("EvaluateGate failed: %v", err)
+	}
+
+	if result.Passed || len(result.Violations) != 3 {
+		t.Fatalf("expected 3 violations, got %+v", result.Violations)
+	}
+	required := result.Violations[1]
+	if required.Kind != ViolationRequired || required.NodeType != "*ast.GoStmt" || required.Category != "Statement Nodes" {
+		t.Errorf("unexpected required violation %+v", required)
+	}
+	if len(required.PreviousFiles) != 1 || required.PreviousFiles[0] != "statements.go" {
+		t.Errorf("expected statements.go to have covered GoStmt, got %v", required.PreviousFiles)
+	}
+	if forbidden := result.Violations[2]; forbidden.Kind != ViolationForbidden || !strings.Contains(forbidden.Message, "expressions.go") {
+		t.Errorf("unexpected forbidden violation %+v", forbidden)
+	}
+
+	var buf bytes.Buffer
+	PrintGateResult(&buf, result)
+	if out := buf.String(); !strings.Contains(out, "3 violation(s)") || !strings.Contains(out, "previously covered by: statements.go") {
+		t.Errorf("unexpected gate output:\n%s", out)
+	}
+
+	if _, err := EvaluateGate(sampleReport(), &Gate{Required: []string{"NoSuchStmt"}}); err == nil {
+		t.Error("expected error for unknown node type")
+	}
+	if result, _ := EvaluateGate(sampleReport(), &Gate{Required: []string{"Ident"}, MinPercent: 50}); !result.Passed {
+		t.Errorf("expected gate to pass, got %+v", result.Violations)
+	}
+}
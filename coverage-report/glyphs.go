@@ -0,0 +1,72 @@
+package report
+
+import (
+	"os"
+	"runtime"
+	"strings"
+)
+
+// glyphSet is the symbols the text report draws with. Every mode must set
+// every field, which TestGlyphSets checks.
+type glyphSet struct {
+	BarFilled string
+	BarEmpty  string
+	Covered   string
+	Missing   string
+	Warning   string
+	Arrow     string
+
+	// Celebrate prefixes the closing line at full coverage.
+	Celebrate string
+}
+
+// unicodeGlyphs is the default glyph set.
+var unicodeGlyphs = glyphSet{
+	BarFilled: "█",
+	BarEmpty:  "░",
+	Covered:   "✓",
+	Missing:   "✗",
+	Warning:   "⚠",
+	Arrow:     "→",
+	Celebrate: "🎉",
+}
+
+// asciiGlyphs replaces every glyph with plain ASCII for terminals and log
+// viewers that cannot display unicode.
+var asciiGlyphs = glyphSet{
+	BarFilled: "#",
+	BarEmpty:  "-",
+	Covered:   "+",
+	Missing:   "-",
+	Warning:   "!",
+	Arrow:     "->",
+	Celebrate: "**",
+}
+
+// glyphsFor returns the glyph set for the ASCIIOnly option.
+func glyphsFor(asciiOnly bool) glyphSet {
+	if asciiOnly {
+		return asciiGlyphs
+	}
+	return unicodeGlyphs
+}
+
+// LocaleSupportsUTF8 reports whether the environment's locale suggests
+// the terminal can display UTF-8. The first of LC_ALL, LC_CTYPE, and LANG
+// that is set decides; with none set it assumes UTF-8, except in a legacy
+// Windows console.
+func LocaleSupportsUTF8() bool {
+	for _, name := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		locale := os.Getenv(name)
+		if locale == "" {
+			continue
+		}
+		locale = strings.ToLower(locale)
+		return strings.Contains(locale, "utf-8") || strings.Contains(locale, "utf8")
+	}
+	if runtime.GOOS == "windows" {
+		// Windows Terminal sets WT_SESSION; the legacy console does not
+		return os.Getenv("WT_SESSION") != ""
+	}
+	return true
+}
@@ -0,0 +1,84 @@
+package report
+
+import (
+	"bytes"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+// TestGlyphSets tests that the unicode and ASCII glyph sets define every
+// glyph and that the ASCII set is plain ASCII
+func TestGlyphSets(t *testing.T) {
+	uni := reflect.ValueOf(unicodeGlyphs)
+	ascii := reflect.ValueOf(asciiGlyphs)
+	for i := 0; i < uni.NumField(); i++ {
+		name := uni.Type().Field(i).Name
+		if uni.Field(i).String() == "" || ascii.Field(i).String() == "" {
+			t.Errorf("glyph %s is not set in both modes", name)
+		}
+		for _, r := range ascii.Field(i).String() {
+			if r > 127 {
+				t.Errorf("ASCII glyph %s contains %q", name, r)
+			}
+		}
+	}
+	if asciiGlyphs.Covered == asciiGlyphs.Missing {
+		t.Errorf("ASCII covered and missing markers are indistinguishable")
+	}
+}
+
+// TestPrintReportASCIIOnly tests that the ASCII-only report contains no
+// non-ASCII bytes
+func TestPrintReportASCIIOnly(t *testing.T) {
+	rep := sampleReport()
+	for _, percent := range []float64{rep.CoveragePercent, 100} {
+		rep.CoveragePercent = percent
+
+		var buf bytes.Buffer
+		if err := PrintReportWithOptions(&buf, rep, PrintOptions{Color: ColorNever, ASCIIOnly: true}); err != nil {
+			t.Fatalf("PrintReportWithOptions failed: %v", err)
+		}
+		for i, b := range buf.Bytes() {
+			if b > 127 {
+				t.Fatalf("non-ASCII byte at %d: %q", i, buf.Bytes()[i:min(i+8, buf.Len())])
+			}
+		}
+		if !bytes.Contains(buf.Bytes(), []byte("[#")) {
+			t.Errorf("expected an ASCII progress bar")
+		}
+	}
+}
+
+// TestLocaleSupportsUTF8 tests locale detection from the environment
+func TestLocaleSupportsUTF8(t *testing.T) {
+	tests := []struct {
+		lcAll, lcCtype, lang string
+		want                 bool
+	}{
+		{"", "", "en_US.UTF-8", true},
+		{"", "", "de_DE.utf8", true},
+		{"", "", "C", false},
+		{"POSIX", "", "en_US.UTF-8", false},
+		{"", "en_US.ISO-8859-1", "en_US.UTF-8", false},
+		{"C.UTF-8", "", "C", true},
+	}
+
+	for _, tt := range tests {
+		t.Setenv("LC_ALL", tt.lcAll)
+		t.Setenv("LC_CTYPE", tt.lcCtype)
+		t.Setenv("LANG", tt.lang)
+		if got := LocaleSupportsUTF8(); got != tt.want {
+			t.Errorf("LC_ALL=%q LC_CTYPE=%q LANG=%q: got %v, want %v", tt.lcAll, tt.lcCtype, tt.lang, got, tt.want)
+		}
+	}
+
+	if runtime.GOOS != "windows" {
+		t.Setenv("LC_ALL", "")
+		t.Setenv("LC_CTYPE", "")
+		t.Setenv("LANG", "")
+		if !LocaleSupportsUTF8() {
+			t.Errorf("expected UTF-8 with no locale set")
+		}
+	}
+}
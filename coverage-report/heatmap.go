@@ -0,0 +1,126 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"zylisp/go-ast-coverage/analyzer"
+)
+
+// HeatmapFormat selects the encoding of a heatmap export.
+type HeatmapFormat string
+
+const (
+	// HeatmapCSV writes a header row of column names, then one row per
+	// file starting with the file name.
+	HeatmapCSV HeatmapFormat = "csv"
+	// HeatmapJSON writes a Heatmap object.
+	HeatmapJSON HeatmapFormat = "json"
+)
+
+// HeatmapOptions controls ExportHeatmapWithOptions.
+type HeatmapOptions struct {
+	Format HeatmapFormat
+
+	// ByCategory rolls node types up into one column per category, which
+	// keeps the matrix small enough to eyeball.
+	ByCategory bool
+}
+
+// Heatmap is a file-by-node-type matrix of node counts.
+type Heatmap struct {
+	// Columns are node types in canonical order, or category names.
+	Columns []string     `json:"columns"`
+	Rows    []HeatmapRow `json:"rows"`
+}
+
+// HeatmapRow holds one file's counts, aligned with Heatmap.Columns.
+type HeatmapRow struct {
+	File   string `json:"file"`
+	Counts []int  `json:"counts"`
+}
+
+// ExportHeatmap writes a file-by-node-type matrix of node counts in the
+// given format.
+func ExportHeatmap(results []*analyzer.AnalysisResult, w io.Writer, format HeatmapFormat) error {
+	return ExportHeatmapWithOptions(results, w, HeatmapOptions{Format: format})
+}
+
+// ExportHeatmapWithOptions writes a file-by-node-type or file-by-category
+// matrix of node counts as configured by opts.
+func ExportHeatmapWithOptions(results []*analyzer.AnalysisResult, w io.Writer, opts HeatmapOptions) error {
+	heatmap := BuildHeatmap(results, opts.ByCategory)
+
+	switch opts.Format {
+	case HeatmapCSV:
+		rows := [][]string{append([]string{"file"}, heatmap.Columns...)}
+		for _, row := range heatmap.Rows {
+			record := []string{row.File}
+			for _, count := range row.Counts {
+				record = append(record, strconv.Itoa(count))
+			}
+			rows = append(rows, record)
+		}
+		return writeCSV(w, rows)
+	case HeatmapJSON:
+		data, err := json.MarshalIndent(heatmap, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal heatmap: %w", err)
+		}
+		if _, err := w.Write(append(data, '\n')); err != nil {
+			return fmt.Errorf("failed to write heatmap: %w", err)
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown heatmap format %q (supported: csv, json)", opts.Format)
+}
+
+// BuildHeatmap builds the matrix ExportHeatmap writes. Rows are sorted by
+// file name. Columns follow the canonical node type list, followed by any
+// other node types the results contain, so every node is counted; with
+// byCategory they are the sorted category names.
+func BuildHeatmap(results []*analyzer.AnalysisResult, byCategory bool) *Heatmap {
+	nodeTypes := analyzer.GetAllNodeTypes()
+	known := stringSet(nodeTypes)
+	var extra []string
+	for _, result := range results {
+		for nodeType := range result.NodeCounts {
+			if !known[nodeType] {
+				known[nodeType] = true
+				extra = append(extra, nodeType)
+			}
+		}
+	}
+	sort.Strings(extra)
+	nodeTypes = append(nodeTypes, extra...)
+
+	columns := nodeTypes
+	if byCategory {
+		columns = sortedCategories(categorizeNodes(nodeTypes))
+	}
+	index := make(map[string]int, len(columns))
+	for i, column := range columns {
+		index[column] = i
+	}
+
+	heatmap := &Heatmap{Columns: columns, Rows: []HeatmapRow{}}
+	for _, result := range results {
+		row := HeatmapRow{File: getBaseName(result.FileName), Counts: make([]int, len(columns))}
+		for nodeType, count := range result.NodeCounts {
+			column := nodeType
+			if byCategory {
+				column = categorizeNode(nodeType)
+			}
+			row.Counts[index[column]] += count
+		}
+		heatmap.Rows = append(heatmap.Rows, row)
+	}
+	sort.SliceStable(heatmap.Rows, func(i, j int) bool {
+		return heatmap.Rows[i].File < heatmap.Rows[j].File
+	})
+
+	return heatmap
+}
@@ -0,0 +1,76 @@
+package report
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strconv"
+	"testing"
+
+	"zylisp/go-ast-coverage/analyzer"
+)
+
+// TestExportHeatmap tests that every heatmap row sums to its file's node
+// count, in both formats and with category rollup
+func TestExportHeatmap(t *testing.T) {
+	results, err := analyzer.AnalyzeDirectory("../nodes/go")
+	if err != nil {
+		t.Fatalf("AnalyzeDirectory failed: %v", err)
+	}
+	totals := make(map[string]int)
+	for _, result := range results {
+		totals[getBaseName(result.FileName)] = result.TotalNodes
+	}
+
+	for _, byCategory := range []bool{false, true} {
+		var buf bytes.Buffer
+		if err := ExportHeatmapWithOptions(results, &buf, HeatmapOptions{Format: HeatmapJSON, ByCategory: byCategory}); err != nil {
+			t.Fatalf("ExportHeatmapWithOptions failed: %v", err)
+		}
+		var heatmap Heatmap
+		if err := json.Unmarshal(buf.Bytes(), &heatmap); err != nil {
+			t.Fatalf("failed to decode heatmap: %v", err)
+		}
+		if len(heatmap.Rows) != len(results) {
+			t.Fatalf("got %d rows, want %d", len(heatmap.Rows), len(results))
+		}
+		for i, row := range heatmap.Rows {
+			sum := 0
+			for _, count := range row.Counts {
+				sum += count
+			}
+			if sum != totals[row.File] {
+				t.Errorf("byCategory=%v: row %s sums to %d, want %d", byCategory, row.File, sum, totals[row.File])
+			}
+			if i > 0 && heatmap.Rows[i-1].File > row.File {
+				t.Errorf("rows not sorted: %s before %s", heatmap.Rows[i-1].File, row.File)
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := ExportHeatmap(results, &buf, HeatmapCSV); err != nil {
+		t.Fatalf("ExportHeatmap failed: %v", err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("failed to read CSV: %v", err)
+	}
+	if records[0][0] != "file" || records[0][1] != analyzer.GetAllNodeTypes()[0] {
+		t.Errorf("unexpected header %v", records[0][:2])
+	}
+	for _, record := range records[1:] {
+		sum := 0
+		for _, cell := range record[1:] {
+			count, _ := strconv.Atoi(cell)
+			sum += count
+		}
+		if sum != totals[record[0]] {
+			t.Errorf("CSV row %s sums to %d, want %d", record[0], sum, totals[record[0]])
+		}
+	}
+
+	if err := ExportHeatmap(results, &buf, "xml"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,129 @@
+package report
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// sparkLevels are the ASCII characters used by RenderTrend, from lowest
+// to highest.
+const sparkLevels = "_.,-=+*#"
+
+// maxTrendWidth is the number of most recent entries RenderTrend plots.
+const maxTrendWidth = 60
+
+// HistoryEntry is one line of a coverage history file.
+type HistoryEntry struct {
+	Timestamp time.Time `json:"timestamp"`
+	Percent   float64   `json:"percent"`
+	Covered   int       `json:"covered"`
+	Missing   int       `json:"missing"`
+	Total     int       `json:"total"`
+}
+
+// AppendHistory appends a summary of the report to the history file at
+// path as a single JSON line, creating the file if needed.
+func AppendHistory(report *CoverageReport, path string) error {
+	entry := HistoryEntry{
+		Timestamp: report.GeneratedAt.UTC(),
+		Percent:   report.CoveragePercent,
+		Covered:   report.CoveredNodeTypes,
+		Missing:   len(report.MissingNodes),
+		Total:     report.TotalNodeTypes,
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal history entry: %w", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open history file: %w", err)
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write history entry: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write history entry: %w", err)
+	}
+
+	return nil
+}
+
+// LoadHistory reads the history file at path. Blank lines are ignored and
+// lines that fail to decode are skipped with a warning on stderr.
+func LoadHistory(path string) ([]HistoryEntry, error) {
+	return LoadHistoryTo(path, os.Stderr)
+}
+
+// LoadHistoryTo is like LoadHistory but writes its warnings to warnings.
+func LoadHistoryTo(path string, warnings io.Writer) ([]HistoryEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history file: %w", err)
+	}
+	defer f.Close()
+
+	history := []HistoryEntry{}
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+		var entry HistoryEntry
+		if err := json.Unmarshal(text, &entry); err != nil {
+			fmt.Fprintf(warnings, "Warning: skipping %s:%d: %v\n", path, line, err)
+			continue
+		}
+		history = append(history, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history file: %w", err)
+	}
+
+	return history, nil
+}
+
+// RenderTrend writes an ASCII sparkline of the most recent history entries
+// followed by the minimum, maximum, and latest coverage.
+func RenderTrend(w io.Writer, history []HistoryEntry) {
+	if len(history) == 0 {
+		fmt.Fprintln(w, "Coverage trend: no history")
+		return
+	}
+
+	minPct, maxPct := history[0].Percent, history[0].Percent
+	for _, entry := range history {
+		if entry.Percent < minPct {
+			minPct = entry.Percent
+		}
+		if entry.Percent > maxPct {
+			maxPct = entry.Percent
+		}
+	}
+
+	recent := history
+	if len(recent) > maxTrendWidth {
+		recent = recent[len(recent)-maxTrendWidth:]
+	}
+	spark := make([]byte, len(recent))
+	for i, entry := range recent {
+		level := (len(sparkLevels) - 1) / 2
+		if maxPct > minPct {
+			level = int((entry.Percent - minPct) / (maxPct - minPct) * float64(len(sparkLevels)-1))
+		}
+		spark[i] = sparkLevels[level]
+	}
+
+	latest := history[len(history)-1]
+	fmt.Fprintf(w, "Coverage trend (%d runs): [%s]\n", len(history), spark)
+	fmt.Fprintf(w, "  min: %.2f%%  max: %.2f%%  latest: %.2f%% (%s)\n",
+		minPct, maxPct, latest.Percent, latest.Timestamp.Format(time.RFC3339))
+}
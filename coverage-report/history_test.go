@@ -0,0 +1,51 @@
+package report
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestHistory tests appending, loading, and rendering coverage history
+func TestHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+
+	for _, percent := range []float64{50, 75, 62.5} {
+		rep := sampleReport()
+		rep.CoveragePercent = percent
+		if err := AppendHistory(rep, path); err != nil {
+			t.Fatalf("AppendHistory failed: %v", err)
+		}
+	}
+
+	// A corrupted line must be skipped with a warning, not fatal
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	f.WriteString("{not json\n")
+	f.Close()
+
+	var warnings bytes.Buffer
+	history, err := LoadHistoryTo(path, &warnings)
+	if err != nil {
+		t.Fatalf("LoadHistoryTo failed: %v", err)
+	}
+	if !strings.Contains(warnings.String(), "Warning: skipping "+path+":4: ") {
+		t.Errorf("expected a warning about line 4, got %q", warnings.String())
+	}
+	if len(history) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(history))
+	}
+	if history[1].Percent != 75 || history[1].Covered != 4 || history[1].Missing != 2 || history[1].Total != 6 {
+		t.Errorf("unexpected entry: %+v", history[1])
+	}
+
+	var buf bytes.Buffer
+	RenderTrend(&buf, history)
+	out := buf.String()
+	for _, want := range []string{"(3 runs): [_#-]", "min: 50.00%", "max: 75.00%", "latest: 62.50%"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("trend output missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"os"
+	"strings"
+)
+
+// htmlTemplate lays out the HTML report. It is self-contained, with inline
+// styles, so a saved copy can be opened or attached anywhere.
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"percent": func(p float64) string { return fmt.Sprintf("%.2f%%", p) },
+	"base":    getBaseName,
+	"width":   func(p float64) string { return fmt.Sprintf("%.1f%%", p) },
+	"add":     func(a, b int) int { return a + b },
+	"join":    strings.Join,
+}).Parse(`{{define "tokens"}}
+<table>
+<tr><th>Group</th><th>Covered</th><th>Missing</th></tr>
+{{- range .Groups}}
+<tr><td>{{.Name}}</td><td>{{len .Covered}}/{{add (len .Covered) (len .Missing)}}</td><td class="missing">{{range $i, $tok := .Missing}}{{if $i}} {{end}}<code>{{$tok}}</code>{{else}}—{{end}}</td></tr>
+{{- end}}
+</table>
+{{- end}}<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Go AST Coverage Report</title>
+<style>
+body { font-family: sans-serif; margin: 2em auto; max-width: 60em; color: #222; }
+table { border-collapse: collapse; margin-bottom: 1.5em; }
+th, td { text-align: left; padding: 0.2em 0.8em; border-bottom: 1px solid #ddd; }
+.bar { background: #eee; width: 20em; height: 1em; display: inline-block; vertical-align: middle; }
+.bar span { background: #4c1; height: 100%; display: block; }
+.covered { color: #2a7d2a; }
+.missing { color: #c0392b; }
+details { margin-bottom: 1.5em; }
+pre { background: #f6f8fa; padding: 0.5em; margin: 0.3em 0 0.8em 1.5em; }
+</style>
+</head>
+<body>
+<h1>Go AST Coverage Report</h1>
+<p>Generated {{.Summary.GeneratedAt.Format "2006-01-02T15:04:05Z07:00"}}</p>
+
+<h2>Summary</h2>
+<table>
+<tr><th>Total node types</th><td>{{.Summary.Total}}</td></tr>
+<tr><th>Covered node types</th><td>{{.Summary.Covered}}</td></tr>
+<tr><th>Missing node types</th><td>{{.Summary.Missing}}</td></tr>
+<tr><th>Coverage</th><td><span class="bar"><span style="width: {{width .Summary.Percent}}"></span></span> {{percent .Summary.Percent}}</td></tr>
+</table>
+
+<h2>Categories</h2>
+<table>
+<tr><th>Category</th><th>Covered</th><th>Coverage</th></tr>
+{{- range .Categories}}
+<tr><td>{{.Name}}</td><td>{{.Covered}}/{{.Total}}</td><td><span class="bar"><span style="width: {{width .Percent}}"></span></span> {{percent .Percent}}</td></tr>
+{{- end}}
+</table>
+{{if .Missing}}
+<h2>Missing Node Types</h2>
+{{- range .MissingByCategory}}
+<h3>{{.Name}}</h3>
+<ul>
+{{- range .Nodes}}
+<li class="missing"><code>{{.}}</code>{{with index $.Report.MissingExamples .}}<pre>{{.}}</pre>{{end}}</li>
+{{- end}}
+</ul>
+{{- end}}
+{{end}}
+<h2>Covered Node Types</h2>
+{{- range .CoveredByCategory}}
+<h3>{{.Name}}</h3>
+<ul>
+{{- range .Nodes}}
+<li class="covered"><code>{{.}}</code></li>
+{{- end}}
+</ul>
+{{- end}}
+{{if .Tokens.Groups}}
+<h2>Tokens</h2>
+{{- template "tokens" .Tokens}}
+{{end}}
+{{- if .TokenKinds.Groups}}
+<h2>Token Kinds</h2>
+{{- template "tokens" .TokenKinds}}
+{{end}}
+{{- with .Report.Variants}}
+<h2>Structural Variants</h2>
+<ul>
+{{- range .}}
+<li class="{{if .Covered}}covered{{else}}missing{{end}}"><code>{{.Name}}</code> — {{.Description}} ({{.Count}})</li>
+{{- end}}
+</ul>
+{{end}}
+{{- with .Report.Attribution}}
+<h2>Attribution</h2>
+<details>
+<summary>{{len .}} node types</summary>
+<table>
+<tr><th>Node type</th><th>Files</th></tr>
+{{- range $node, $files := .}}
+<tr><td><code>{{$node}}</code></td><td>{{join $files ", "}}</td></tr>
+{{- end}}
+</table>
+</details>
+{{end}}
+<h2>Files</h2>
+<table>
+<tr><th>File</th><th>Nodes</th><th>Unique types</th></tr>
+{{- range .Files}}
+<tr><td>{{base .FileName}}</td><td>{{.NodeCount}}</td><td>{{.UniqueTypes}}</td></tr>
+{{- end}}
+</table>
+</body>
+</html>
+`))
+
+// RenderHTML renders the report as a standalone HTML page.
+func RenderHTML(report *CoverageReport) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := htmlTemplate.Execute(&buf, NewTemplateData(report)); err != nil {
+		return nil, fmt.Errorf("failed to render HTML: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// SaveReportHTML saves the report as a standalone HTML page.
+func SaveReportHTML(report *CoverageReport, filePath string) error {
+	data, err := RenderHTML(report)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(filePath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package report
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+// TestRenderHTML tests the HTML report's sections and escaping
+func TestRenderHTML(t *testing.T) {
+	rep := sampleReport()
+	rep.Categories = computeCategoryCoverage(append(append([]string{}, rep.CoveredNodes...), rep.MissingNodes...), rep.CoveredNodes)
+	rep.MissingExamples["*ast.TypeSpec"] = "type T <int>"
+	rep.Tokens = TokenCoverage{Groups: []TokenGroupCoverage{{Name: "Binary operators", Covered: []string{"+", "-"}, Missing: []string{"&^", "<<"}}}}
+	rep.TokenKinds = TokenCoverage{Groups: []TokenGroupCoverage{{Name: "Literals", Covered: []string{"INT", "STRING"}}}}
+	rep.Variants = []VariantCoverage{
+		{Name: "IfStmt/init", Description: "if with an Init statement", Covered: true, Count: 2},
+		{Name: "IfStmt/else-if", Description: "if with an else if chain"},
+	}
+	rep.Attribution = map[string][]string{"*ast.File": {"expressions.go", "statements.go"}, "*ast.BinaryExpr": {"expressions.go"}}
+
+	data, err := RenderHTML(rep)
+	if err != nil {
+		t.Fatalf("RenderHTML failed: %v", err)
+	}
+	html := string(data)
+
+	for _, want := range []string{
+		"<tr><th>Coverage</th><td>",
+		"66.67%",
+		"<tr><td>Statement Nodes</td><td>1/2</td>",
+		`<li class="missing"><code>*ast.GoStmt</code><pre>func f() { go g() }</pre></li>`,
+		`<li class="covered"><code>*ast.File</code></li>`,
+		"<tr><td>statements.go</td><td>40</td><td>3</td></tr>",
+		"type T &lt;int&gt;",
+		"<h2>Tokens</h2>",
+		"<tr><td>Binary operators</td><td>2/4</td><td class=\"missing\"><code>&amp;^</code> <code>&lt;&lt;</code></td></tr>",
+		"<h2>Token Kinds</h2>",
+		"<tr><td>Literals</td><td>2/2</td><td class=\"missing\">—</td></tr>",
+		"<h2>Structural Variants</h2>",
+		`<li class="covered"><code>IfStmt/init</code> — if with an Init statement (2)</li>`,
+		`<li class="missing"><code>IfStmt/else-if</code> — if with an else if chain (0)</li>`,
+		"<h2>Attribution</h2>",
+		"<summary>2 node types</summary>",
+		"<tr><td><code>*ast.BinaryExpr</code></td><td>expressions.go</td></tr>\n<tr><td><code>*ast.File</code></td><td>expressions.go, statements.go</td></tr>",
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("HTML report missing %q", want)
+		}
+	}
+}
+
+// TestRenderBadge tests that the badge is well-formed SVG colored by
+// coverage
+func TestRenderBadge(t *testing.T) {
+	tests := []struct {
+		percent float64
+		color   string
+	}{
+		{95, badgeGreen},
+		{80, badgeYellow},
+		{50, badgeRed},
+	}
+	for _, tt := range tests {
+		badge := RenderBadge(&CoverageReport{CoveragePercent: tt.percent})
+
+		var svg struct {
+			XMLName xml.Name `xml:"svg"`
+			Title   string   `xml:"title"`
+		}
+		if err := xml.Unmarshal(badge, &svg); err != nil {
+			t.Fatalf("badge is not well-formed SVG: %v", err)
+		}
+		if !strings.HasSuffix(svg.Title, "%") || !strings.Contains(string(badge), `fill="`+tt.color+`"`) {
+			t.Errorf("%.0f%%: unexpected badge %s", tt.percent, badge)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+)
+
+// junitTestSuites is the root element of a JUnit XML file.
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+// junitTestSuite holds the test cases of one node category.
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr,omitempty"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+// junitTestCase is one node type; missing node types carry a failure.
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+// junitFailure explains why a node type is missing.
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// SaveReportJUnit saves the report as JUnit XML, with one test suite per
+// node category and one test case per node type. Covered node types pass
+// and missing ones fail with their category and a placement suggestion.
+func SaveReportJUnit(report *CoverageReport, filePath string) error {
+	data, err := renderJUnit(report)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(filePath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	return nil
+}
+
+// renderJUnit renders the report as JUnit XML.
+func renderJUnit(report *CoverageReport) ([]byte, error) {
+	covered := stringSet(report.CoveredNodes)
+	suggestions := make(map[string]MissingNodeSuggestion, len(report.Suggestions))
+	for _, sg := range report.Suggestions {
+		suggestions[sg.NodeType] = sg
+	}
+
+	all := make([]string, 0, len(report.CoveredNodes)+len(report.MissingNodes))
+	all = append(all, report.CoveredNodes...)
+	all = append(all, report.MissingNodes...)
+	categories := categorizeNodes(all)
+
+	root := junitTestSuites{Name: "go-ast-coverage", Time: "0"}
+	for _, category := range sortedCategories(categories) {
+		suite := junitTestSuite{Name: category, Time: "0"}
+		if !report.GeneratedAt.IsZero() {
+			suite.Timestamp = report.GeneratedAt.UTC().Format("2006-01-02T15:04:05")
+		}
+		for _, node := range categories[category] {
+			tc := junitTestCase{Name: node, ClassName: "coverage." + category, Time: "0"}
+			if !covered[node] {
+				tc.Failure = &junitFailure{
+					Message: fmt.Sprintf("%s is not covered (%s)", node, category),
+					Type:    "missing",
+					Text:    junitFailureText(node, suggestions[node]),
+				}
+				suite.Failures++
+			}
+			suite.Cases = append(suite.Cases, tc)
+			suite.Tests++
+		}
+		root.Tests += suite.Tests
+		root.Failures += suite.Failures
+		root.Suites = append(root.Suites, suite)
+	}
+
+	data, err := xml.MarshalIndent(root, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal report: %w", err)
+	}
+	return append([]byte(xml.Header), append(data, '\n')...), nil
+}
+
+// junitFailureText describes where to add a missing node type.
+func junitFailureText(node string, sg MissingNodeSuggestion) string {
+	if sg.File == "" {
+		return fmt.Sprintf("Add an example of %s to the samples.", node)
+	}
+	if sg.Snippet == "" {
+		return fmt.Sprintf("Add an example of %s to %s.", node, sg.File)
+	}
+	return fmt.Sprintf("Add an example of %s to %s, e.g.\n%s", node, sg.File, sg.Snippet)
+}
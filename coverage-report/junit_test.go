@@ -0,0 +1,65 @@
+package report
+
+import (
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestSaveReportJUnit tests that the JUnit output is well-formed with
+// failure counts matching the missing node types
+func TestSaveReportJUnit(t *testing.T) {
+	rep := sampleReport()
+	rep.Suggestions = computeSuggestions(rep)
+
+	path := filepath.Join(t.TempDir(), "coverage.xml")
+	if err := SaveReportJUnit(rep, path); err != nil {
+		t.Fatalf("SaveReportJUnit failed: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read report: %v", err)
+	}
+
+	var suites junitTestSuites
+	if err := xml.Unmarshal(data, &suites); err != nil {
+		t.Fatalf("output is not well-formed XML: %v", err)
+	}
+
+	if suites.Tests != 6 || suites.Failures != len(rep.MissingNodes) {
+		t.Errorf("expected 6 tests and %d failures, got %d and %d", len(rep.MissingNodes), suites.Tests, suites.Failures)
+	}
+
+	failures := 0
+	for _, suite := range suites.Suites {
+		if suite.Tests != len(suite.Cases) {
+			t.Errorf("suite %s: tests=%d but %d cases", suite.Name, suite.Tests, len(suite.Cases))
+		}
+		for _, tc := range suite.Cases {
+			if tc.Failure != nil {
+				failures++
+				if !strings.Contains(tc.Failure.Message, suite.Name) {
+					t.Errorf("failure message %q does not name category %s", tc.Failure.Message, suite.Name)
+				}
+			}
+		}
+	}
+	if failures != len(rep.MissingNodes) {
+		t.Errorf("expected %d failing cases, got %d", len(rep.MissingNodes), failures)
+	}
+
+	if !strings.Contains(string(data), "Add an example of *ast.GoStmt to statements.go, e.g.") {
+		t.Errorf("expected a suggestion in the failure text:\n%s", data)
+	}
+
+	// Names must be escaped
+	data, err = renderJUnit(&CoverageReport{CoveredNodes: []string{"*ast.Odd<&>"}})
+	if err != nil {
+		t.Fatalf("renderJUnit failed: %v", err)
+	}
+	if !strings.Contains(string(data), `name="*ast.Odd&lt;&amp;&gt;"`) {
+		t.Errorf("expected escaped name:\n%s", data)
+	}
+}
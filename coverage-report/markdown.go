@@ -0,0 +1,159 @@
+package report
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// RenderMarkdown renders the report as GitHub-flavored markdown: a summary
+// table, a per-category checklist of every node type, an example for each
+// missing node type, and a collapsed file breakdown. The output is
+// deterministic and omits the generation time, so a committed copy only
+// changes when coverage does.
+func RenderMarkdown(report *CoverageReport) (string, error) {
+	if report == nil {
+		return "", fmt.Errorf("report is nil")
+	}
+
+	var b strings.Builder
+
+	b.WriteString("# Go AST Coverage Report\n\n")
+	b.WriteString("| Metric | Value |\n")
+	b.WriteString("|--------|-------|\n")
+	fmt.Fprintf(&b, "| Total AST node types | %d |\n", report.TotalNodeTypes)
+	fmt.Fprintf(&b, "| Covered node types | %d |\n", report.CoveredNodeTypes)
+	fmt.Fprintf(&b, "| Missing node types | %d |\n", len(report.MissingNodes))
+	fmt.Fprintf(&b, "| Coverage | %.2f%% |\n\n", report.CoveragePercent)
+
+	if len(report.SkippedFiles) > 0 {
+		fmt.Fprintf(&b, "> ⚠️ %d files could not be analyzed and do not count toward coverage:\n>\n", len(report.SkippedFiles))
+		for _, skip := range report.SkippedFiles {
+			fmt.Fprintf(&b, "> - `%s`: %s\n", getBaseName(skip.FileName), skip.Error)
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("## Node Types by Category\n")
+	covered := make(map[string]bool, len(report.CoveredNodes))
+	all := make([]string, 0, len(report.CoveredNodes)+len(report.MissingNodes))
+	for _, node := range report.CoveredNodes {
+		covered[node] = true
+		all = append(all, node)
+	}
+	all = append(all, report.MissingNodes...)
+
+	categories := categorizeNodes(all)
+	for _, name := range sortedCategories(categories) {
+		nodes := categories[name]
+		count := 0
+		for _, node := range nodes {
+			if covered[node] {
+				count++
+			}
+		}
+		fmt.Fprintf(&b, "\n### %s (%d/%d)\n\n", name, count, len(nodes))
+		for _, node := range nodes {
+			mark := "❌"
+			if covered[node] {
+				mark = "✅"
+			}
+			fmt.Fprintf(&b, "- %s `%s`\n", mark, node)
+		}
+	}
+
+	if len(report.MissingNodes) > 0 {
+		b.WriteString("\n## Missing Node Types\n\n")
+		for _, node := range report.MissingNodes {
+			fmt.Fprintf(&b, "- `%s`\n", node)
+			if example := report.MissingExamples[node]; example != "" {
+				fmt.Fprintf(&b, "\n  ```go\n  %s\n  ```\n", strings.ReplaceAll(example, "\n", "\n  "))
+			}
+		}
+	}
+
+	if len(report.Tokens.Groups) > 0 {
+		b.WriteString("\n## Tokens\n\n")
+		writeMarkdownTokenTable(&b, report.Tokens)
+	}
+
+	if len(report.TokenKinds.Groups) > 0 {
+		b.WriteString("\n## Token Kinds\n\n")
+		writeMarkdownTokenTable(&b, report.TokenKinds)
+	}
+
+	if len(report.Variants) > 0 {
+		b.WriteString("\n## Structural Variants\n\n")
+		for _, v := range report.Variants {
+			mark := "❌"
+			if v.Covered {
+				mark = "✅"
+			}
+			fmt.Fprintf(&b, "- %s `%s` — %s (%d)\n", mark, v.Name, v.Description, v.Count)
+		}
+	}
+
+	if len(report.Attribution) > 0 {
+		nodes := make([]string, 0, len(report.Attribution))
+		for node := range report.Attribution {
+			nodes = append(nodes, node)
+		}
+		sort.Strings(nodes)
+
+		b.WriteString("\n## Attribution\n\n")
+		fmt.Fprintf(&b, "<details>\n<summary>%d node types</summary>\n\n", len(nodes))
+		b.WriteString("| Node Type | Files |\n")
+		b.WriteString("|-----------|-------|\n")
+		for _, node := range nodes {
+			fmt.Fprintf(&b, "| `%s` | %s |\n", node, strings.Join(report.Attribution[node], ", "))
+		}
+		b.WriteString("\n</details>\n")
+	}
+
+	files := make([]*FileReport, len(report.FileReports))
+	copy(files, report.FileReports)
+	sort.SliceStable(files, func(i, j int) bool {
+		return getBaseName(files[i].FileName) < getBaseName(files[j].FileName)
+	})
+
+	b.WriteString("\n## Files\n\n")
+	fmt.Fprintf(&b, "<details>\n<summary>%d files</summary>\n\n", len(files))
+	b.WriteString("| File | Nodes | Unique Types |\n")
+	b.WriteString("|------|-------|--------------|\n")
+	for _, fr := range files {
+		fmt.Fprintf(&b, "| %s | %d | %d |\n", getBaseName(fr.FileName), fr.NodeCount, fr.UniqueTypes)
+	}
+	b.WriteString("\n</details>\n")
+
+	return b.String(), nil
+}
+
+// writeMarkdownTokenTable writes a table of covered counts and missing
+// tokens per group.
+func writeMarkdownTokenTable(b *strings.Builder, coverage TokenCoverage) {
+	b.WriteString("| Group | Covered | Missing |\n")
+	b.WriteString("|-------|---------|---------|\n")
+	for _, group := range coverage.Groups {
+		missing := "—"
+		if len(group.Missing) > 0 {
+			missing = "`" + strings.Join(group.Missing, "` `") + "`"
+		}
+		total := len(group.Covered) + len(group.Missing)
+		fmt.Fprintf(b, "| %s | %d/%d | %s |\n", group.Name, len(group.Covered), total, missing)
+	}
+}
+
+// SaveReportMarkdown saves the report as markdown.
+func SaveReportMarkdown(report *CoverageReport, filePath string) error {
+	md, err := RenderMarkdown(report)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(filePath, []byte(md), 0644); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+
+	return nil
+}
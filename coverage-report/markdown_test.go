@@ -0,0 +1,81 @@
+package report
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var updateGolden = flag.Bool("update", false, "update golden files in testdata")
+
+// sampleReport builds a small synthetic report for tests
+func sampleReport() *CoverageReport {
+	return &CoverageReport{
+		GeneratedAt:      time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		TotalNodeTypes:   6,
+		CoveredNodeTypes: 4,
+		CoveragePercent:  66.66666666666667,
+		CoveredNodes:     []string{"*ast.BinaryExpr", "*ast.File", "*ast.Ident", "*ast.ReturnStmt"},
+		MissingNodes:     []string{"*ast.GoStmt", "*ast.TypeSpec"},
+		FileReports: []*FileReport{
+			{FileName: "nodes/go/statements.go", NodeTypes: []string{"*ast.File", "*ast.Ident", "*ast.ReturnStmt"}, NodeCount: 40, UniqueTypes: 3},
+			{FileName: "nodes/go/expressions.go", NodeTypes: []string{"*ast.BinaryExpr", "*ast.File", "*ast.Ident"}, NodeCount: 25, UniqueTypes: 3},
+		},
+		MissingExamples: map[string]string{
+			"*ast.GoStmt":   "func f() { go g() }",
+			"*ast.TypeSpec": "type T int",
+		},
+	}
+}
+
+// checkGolden compares got against testdata/name, rewriting it with -update
+func checkGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *updateGolden {
+		if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatalf("failed to update golden file: %v", err)
+		}
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read golden file: %v", err)
+	}
+	if got != string(want) {
+		t.Errorf("output differs from %s; rerun with -update\n%s", path, got)
+	}
+}
+
+// TestRenderMarkdown tests the markdown report against a golden file
+func TestRenderMarkdown(t *testing.T) {
+	md, err := RenderMarkdown(sampleReport())
+	if err != nil {
+		t.Fatalf("RenderMarkdown failed: %v", err)
+	}
+	checkGolden(t, "report.md", md)
+
+	again, _ := RenderMarkdown(sampleReport())
+	if again != md {
+		t.Error("markdown output is not deterministic")
+	}
+}
+
+// TestSaveReportMarkdown tests writing the markdown report to disk
+func TestSaveReportMarkdown(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.md")
+	if err := SaveReportMarkdown(sampleReport(), path); err != nil {
+		t.Fatalf("SaveReportMarkdown failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read report: %v", err)
+	}
+	md, _ := RenderMarkdown(sampleReport())
+	if string(data) != md {
+		t.Error("saved markdown differs from RenderMarkdown output")
+	}
+}
@@ -0,0 +1,244 @@
+package report
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrIncompatibleReports is returned when merging reports that expect
+// different node type sets, usually because different tool versions wrote
+// them.
+var ErrIncompatibleReports = errors.New("incompatible reports")
+
+// SourceCoverage summarizes what one constituent report contributed to a
+// merged report.
+type SourceCoverage struct {
+	Name    string
+	Files   int
+	Covered int
+	Percent float64
+
+	// FirstCovered counts the node types this source covered that no
+	// earlier source did, with sources taken in merge order.
+	FirstCovered int
+
+	// OnlyCovered counts the node types no other source covered.
+	OnlyCovered int
+}
+
+// MergeReports combines reports from separately analyzed corpora. Covered
+// node types are unioned, missing types intersected, and file reports
+// concatenated with their Source set to the constituent report's label:
+// its Source, or "report N" when unset. FirstCoveredBy records which source
+// first covered each node type. All reports must expect the same number of
+// node types, or ErrIncompatibleReports is returned.
+func MergeReports(reports ...*CoverageReport) (*CoverageReport, error) {
+	if len(reports) == 0 {
+		return nil, fmt.Errorf("no reports to merge")
+	}
+
+	labels := sourceLabels(reports)
+	for i, rep := range reports[1:] {
+		if rep.TotalNodeTypes != reports[0].TotalNodeTypes {
+			return nil, fmt.Errorf("%w: %s expects %d node types but %s expects %d",
+				ErrIncompatibleReports, labels[0], reports[0].TotalNodeTypes, labels[i+1], rep.TotalNodeTypes)
+		}
+	}
+
+	merged := &CoverageReport{
+		SchemaVersion:  CurrentSchemaVersion,
+		GeneratedAt:    time.Now().UTC().Truncate(time.Second),
+		TotalNodeTypes: reports[0].TotalNodeTypes,
+		CoveredNodes:   []string{},
+		MissingNodes:   []string{},
+		FileReports:    []*FileReport{},
+		Attribution:    map[string][]string{},
+		NodeCounts:     map[string]int{},
+		GoVersion:      reports[0].GoVersion,
+		FirstCoveredBy: map[string]string{},
+		Sources:        []SourceCoverage{},
+		SkippedFiles:   []SkippedFile{},
+		Meta: ReportMeta{
+			ToolVersion: reports[0].Meta.ToolVersion,
+			GoVersion:   reports[0].Meta.GoVersion,
+			Revision:    reports[0].Meta.Revision,
+			Branch:      reports[0].Meta.Branch,
+			Dirty:       reports[0].Meta.Dirty,
+		},
+	}
+
+	coveredBy := make(map[string][]string)
+	missingIn := make(map[string]int)
+	tokenCounts := make(map[string]map[string]int)
+	tokenKindCounts := make(map[string]int)
+	variantCounts := make(map[string]int)
+	attribution := make(map[string]map[string]bool)
+	fileCounts := make([]int, len(reports))
+
+	for i, rep := range reports {
+		label := labels[i]
+		for _, nodeType := range rep.CoveredNodes {
+			if _, ok := merged.FirstCoveredBy[nodeType]; !ok {
+				merged.FirstCoveredBy[nodeType] = label
+			}
+			coveredBy[nodeType] = append(coveredBy[nodeType], label)
+		}
+		for _, nodeType := range rep.MissingNodes {
+			missingIn[nodeType]++
+		}
+
+		merged.SkippedFiles = append(merged.SkippedFiles, rep.SkippedFiles...)
+		// Reports built by hand can hold nil entries, which LoadReportJSON
+		// would have dropped
+		for _, fr := range rep.FileReports {
+			if fr == nil {
+				continue
+			}
+			fileCounts[i]++
+			copied := *fr
+			copied.Source = label
+			merged.FileReports = append(merged.FileReports, &copied)
+		}
+
+		for nodeType, files := range rep.Attribution {
+			if attribution[nodeType] == nil {
+				attribution[nodeType] = make(map[string]bool)
+			}
+			for _, file := range files {
+				attribution[nodeType][file] = true
+			}
+		}
+		for nodeType, count := range rep.NodeCounts {
+			merged.NodeCounts[nodeType] += count
+		}
+
+		for _, group := range rep.Tokens.Groups {
+			if tokenCounts[group.Name] == nil {
+				tokenCounts[group.Name] = make(map[string]int)
+			}
+			for _, tok := range group.Covered {
+				tokenCounts[group.Name][tok]++
+			}
+		}
+		for _, group := range rep.TokenKinds.Groups {
+			for _, tok := range group.Covered {
+				tokenKindCounts[tok]++
+			}
+		}
+		for _, v := range rep.Variants {
+			variantCounts[v.Name] += v.Count
+		}
+
+		merged.Meta.AnalysisDuration += rep.Meta.AnalysisDuration
+		merged.Meta.BuildDuration += rep.Meta.BuildDuration
+		merged.Meta.FilesAnalyzed += rep.Meta.FilesAnalyzed
+		merged.Meta.FilesSkipped += rep.Meta.FilesSkipped
+		merged.Meta.TotalNodes += rep.Meta.TotalNodes
+	}
+
+	for nodeType := range coveredBy {
+		merged.CoveredNodes = append(merged.CoveredNodes, nodeType)
+	}
+	for nodeType, count := range missingIn {
+		if count == len(reports) && coveredBy[nodeType] == nil {
+			merged.MissingNodes = append(merged.MissingNodes, nodeType)
+		}
+	}
+	sort.Strings(merged.CoveredNodes)
+	sort.Strings(merged.MissingNodes)
+
+	merged.CoveredNodeTypes = len(merged.CoveredNodes)
+	merged.CoveragePercent = reportPercent(merged)
+	computeFileContributions(merged.FileReports, merged.TotalNodeTypes)
+
+	all := append(append([]string{}, merged.CoveredNodes...), merged.MissingNodes...)
+	merged.Categories = computeCategoryCoverage(all, merged.CoveredNodes)
+	merged.Tokens = computeTokenCoverage(tokenCounts)
+	merged.TokenKinds = computeTokenKindCoverage(tokenKindCounts)
+	merged.Variants = computeVariantCoverage(variantCounts)
+	merged.ExcludedNodes = append([]ExcludedNode{}, reports[0].ExcludedNodes...)
+	markCoveredExclusions(merged.ExcludedNodes, merged.NodeCounts)
+	merged.MostCommon = MostCommonNodes(merged.NodeCounts, DefaultTopN)
+	merged.LeastCommon = LeastCommonNodes(merged.NodeCounts, DefaultTopN)
+	for nodeType, files := range attribution {
+		for file := range files {
+			merged.Attribution[nodeType] = append(merged.Attribution[nodeType], file)
+		}
+		sort.Strings(merged.Attribution[nodeType])
+	}
+	merged.MissingSince = missingSince(merged.MissingNodes)
+	merged.MissingExamples = computeMissingExamples(merged.MissingNodes, os.Stderr)
+	merged.Suggestions = computeSuggestions(merged)
+
+	for i, rep := range reports {
+		sc := SourceCoverage{
+			Name:    labels[i],
+			Files:   fileCounts[i],
+			Covered: len(rep.CoveredNodes),
+			Percent: reportPercent(rep),
+		}
+		for _, nodeType := range rep.CoveredNodes {
+			if merged.FirstCoveredBy[nodeType] == labels[i] {
+				sc.FirstCovered++
+			}
+			if len(coveredBy[nodeType]) == 1 {
+				sc.OnlyCovered++
+			}
+		}
+		merged.Sources = append(merged.Sources, sc)
+	}
+
+	return merged, nil
+}
+
+// sourceLabels names each report for a merge: its Source, or "report N"
+// when unset. Repeated labels get a "#N" suffix so sources stay distinct.
+func sourceLabels(reports []*CoverageReport) []string {
+	labels := make([]string, len(reports))
+	seen := make(map[string]int)
+	for i, rep := range reports {
+		label := rep.Source
+		if label == "" {
+			label = fmt.Sprintf("report %d", i+1)
+		}
+		seen[label]++
+		if seen[label] > 1 {
+			label = fmt.Sprintf("%s#%d", label, seen[label])
+		}
+		labels[i] = label
+	}
+	return labels
+}
+
+// PrintSourceContributions prints what each source contributed to a merged
+// report.
+func PrintSourceContributions(w io.Writer, report *CoverageReport) error {
+	rw := &reportWriter{w: w, glyphs: unicodeGlyphs}
+	printSources(rw, report)
+	return rw.err
+}
+
+// printSources writes the SOURCES section of a merged report.
+func printSources(rw *reportWriter, report *CoverageReport) {
+	rw.heading("SOURCES")
+	rw.println(strings.Repeat("-", 80))
+	for _, sc := range report.Sources {
+		rw.printf("%-30s  Files: %3d  Covered: %3d (%6.2f%%)  First: %3d  Only: %3d\n",
+			truncateSource(sc.Name, 30), sc.Files, sc.Covered, sc.Percent, sc.FirstCovered, sc.OnlyCovered)
+	}
+	rw.println("")
+}
+
+// truncateSource shortens a source label to width, keeping its end, which
+// is usually the most specific part of a path.
+func truncateSource(name string, width int) string {
+	if len(name) <= width {
+		return name
+	}
+	return "…" + name[len(name)-width+1:]
+}
@@ -0,0 +1,88 @@
+package report
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestMergeReports tests unioning coverage across corpora, skipping nil
+// file reports
+func TestMergeReports(t *testing.T) {
+	a := sampleReport()
+	a.Source = "nodes/go"
+	a.NodeCounts = map[string]int{"*ast.File": 2}
+	b := sampleReport()
+	b.Source = "go-nodes"
+	b.CoveredNodes = []string{"*ast.File", "*ast.GoStmt"}
+	b.MissingNodes = []string{"*ast.BinaryExpr", "*ast.Ident", "*ast.ReturnStmt", "*ast.TypeSpec"}
+	b.CoveredNodeTypes, b.CoveragePercent = 2, float64(2)/6*100
+	b.NodeCounts = map[string]int{"*ast.File": 1, "*ast.GoStmt": 2}
+	b.FileReports = []*FileReport{b.FileReports[0], nil}
+
+	merged, err := MergeReports(a, b)
+	if err != nil {
+		t.Fatalf("MergeReports failed: %v", err)
+	}
+
+	wantCovered := []string{"*ast.BinaryExpr", "*ast.File", "*ast.GoStmt", "*ast.Ident", "*ast.ReturnStmt"}
+	if !reflect.DeepEqual(merged.CoveredNodes, wantCovered) {
+		t.Errorf("got covered %v, want %v", merged.CoveredNodes, wantCovered)
+	}
+	if !reflect.DeepEqual(merged.MissingNodes, []string{"*ast.TypeSpec"}) {
+		t.Errorf("got missing %v, want only TypeSpec", merged.MissingNodes)
+	}
+	if merged.CoveredNodeTypes != 5 || merged.CoveragePercent != float64(5)/6*100 {
+		t.Errorf("unexpected totals %d, %.2f", merged.CoveredNodeTypes, merged.CoveragePercent)
+	}
+	if merged.FirstCoveredBy["*ast.File"] != "nodes/go" || merged.FirstCoveredBy["*ast.GoStmt"] != "go-nodes" {
+		t.Errorf("unexpected first coverage %v", merged.FirstCoveredBy)
+	}
+	if merged.NodeCounts["*ast.File"] != 3 {
+		t.Errorf("expected node counts to be summed, got %d", merged.NodeCounts["*ast.File"])
+	}
+
+	if len(merged.FileReports) != 3 || merged.FileReports[2].Source != "go-nodes" || a.FileReports[0].Source != "" {
+		t.Errorf("expected namespaced copies of the file reports, got %+v", merged.FileReports)
+	}
+
+	want := []SourceCoverage{
+		{Name: "nodes/go", Files: 2, Covered: 4, Percent: a.CoveragePercent, FirstCovered: 4, OnlyCovered: 3},
+		{Name: "go-nodes", Files: 1, Covered: 2, Percent: float64(2) / 6 * 100, FirstCovered: 1, OnlyCovered: 1},
+	}
+	if !reflect.DeepEqual(merged.Sources, want) {
+		t.Errorf("got sources %+v, want %+v", merged.Sources, want)
+	}
+
+	var buf bytes.Buffer
+	if err := PrintReportTo(&buf, merged); err != nil {
+		t.Fatalf("PrintReportTo failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "go-nodes                        Files:   1  Covered:   2 ( 33.33%)  First:   1  Only:   1\n") {
+		t.Errorf("expected a SOURCES section, got:\n%s", buf.String())
+	}
+}
+
+// TestMergeReportsIncompatible tests that differing node type totals are
+// rejected
+func TestMergeReportsIncompatible(t *testing.T) {
+	a, b := sampleReport(), sampleReport()
+	b.TotalNodeTypes = 57
+	if _, err := MergeReports(a, b); !errors.Is(err, ErrIncompatibleReports) {
+		t.Errorf("expected ErrIncompatibleReports, got %v", err)
+	}
+	if _, err := MergeReports(); err == nil {
+		t.Error("expected error when merging no reports")
+	}
+}
+
+// TestSourceLabels tests labels for unnamed and repeated sources
+func TestSourceLabels(t *testing.T) {
+	got := sourceLabels([]*CoverageReport{{}, {Source: "x"}, {Source: "x"}})
+	want := []string{"report 1", "x", "x#2"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,92 @@
+package report
+
+import (
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
+// ReportMeta records how a report was made, so two reports can be compared
+// meaningfully.
+type ReportMeta struct {
+	// AnalysisDuration is the time spent parsing and analyzing files, and
+	// BuildDuration the time spent assembling the report from the results.
+	AnalysisDuration time.Duration
+	BuildDuration    time.Duration
+
+	FilesAnalyzed int
+	FilesSkipped  int // files that failed to parse or analyze
+
+	// ToolVersion is the module version of this tool, or "(devel)" for a
+	// build from a source checkout.
+	ToolVersion string
+
+	// GoVersion is the Go toolchain the tool was built with, as opposed to
+	// the report's GoVersion profile.
+	GoVersion string
+
+	// TotalNodes is the number of AST nodes across all analyzed files.
+	TotalNodes int
+
+	// Revision, Branch, and Dirty describe the version control state of
+	// the corpus, when a RevisionProvider was given.
+	Revision string
+	Branch   string
+	Dirty    bool
+}
+
+// newReportMeta records the tool and toolchain versions along with the
+// given counts and timings.
+func newReportMeta(analyzed, skipped, totalNodes int, analysis, build time.Duration) ReportMeta {
+	return ReportMeta{
+		AnalysisDuration: analysis,
+		BuildDuration:    build,
+		FilesAnalyzed:    analyzed,
+		FilesSkipped:     skipped,
+		ToolVersion:      toolVersion(),
+		GoVersion:        runtime.Version(),
+		TotalNodes:       totalNodes,
+	}
+}
+
+// toolVersion returns the main module's version from the build info.
+func toolVersion() string {
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
+		return info.Main.Version
+	}
+	return "(devel)"
+}
+
+// revisionLabel describes the revision as "abc123def456 (main, dirty)",
+// abbreviating the commit. It is empty when no revision was recorded.
+func (m ReportMeta) revisionLabel() string {
+	if m.Revision == "" {
+		return ""
+	}
+
+	label := m.Revision
+	if len(label) > 12 {
+		label = label[:12]
+	}
+	var details []string
+	if m.Branch != "" {
+		details = append(details, m.Branch)
+	}
+	if m.Dirty {
+		details = append(details, "dirty")
+	}
+	if len(details) > 0 {
+		label += " (" + strings.Join(details, ", ") + ")"
+	}
+	return label
+}
+
+// totalNodes sums the node counts of files.
+func totalNodes(files []*FileReport) int {
+	total := 0
+	for _, fr := range files {
+		total += fr.NodeCount
+	}
+	return total
+}
@@ -0,0 +1,56 @@
+package report
+
+import (
+	"bytes"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// TestReportMeta tests that generating a report records timings, file
+// counts, and versions
+func TestReportMeta(t *testing.T) {
+	const corpusDir = "../nodes/go"
+
+	entries, err := os.ReadDir(corpusDir)
+	if err != nil {
+		t.Fatalf("failed to read corpus: %v", err)
+	}
+	goFiles := 0
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".go") {
+			goFiles++
+		}
+	}
+
+	rep, err := GenerateReport(corpusDir)
+	if err != nil {
+		t.Fatalf("GenerateReport failed: %v", err)
+	}
+
+	meta := rep.Meta
+	if meta.AnalysisDuration <= 0 || meta.BuildDuration <= 0 {
+		t.Errorf("expected non-zero durations, got analysis %v, build %v", meta.AnalysisDuration, meta.BuildDuration)
+	}
+	if meta.FilesAnalyzed != goFiles || meta.FilesSkipped != 0 {
+		t.Errorf("expected %d files analyzed and 0 skipped, got %d and %d", goFiles, meta.FilesAnalyzed, meta.FilesSkipped)
+	}
+	if meta.FilesAnalyzed != len(rep.FileReports) {
+		t.Errorf("FilesAnalyzed = %d, but report has %d files", meta.FilesAnalyzed, len(rep.FileReports))
+	}
+	if meta.TotalNodes != totalNodes(rep.FileReports) || meta.TotalNodes == 0 {
+		t.Errorf("unexpected TotalNodes %d", meta.TotalNodes)
+	}
+	if meta.GoVersion != runtime.Version() || meta.ToolVersion == "" {
+		t.Errorf("unexpected versions: tool %q, go %q", meta.ToolVersion, meta.GoVersion)
+	}
+
+	var buf bytes.Buffer
+	if err := PrintReportTo(&buf, rep); err != nil {
+		t.Fatalf("PrintReportTo failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "REPORT METADATA") {
+		t.Errorf("report missing metadata block")
+	}
+}
@@ -0,0 +1,158 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"zylisp/go-ast-coverage/analyzer"
+)
+
+// DefaultOverCoveredPercent is the share of files a node type must appear
+// in for RedundancyAnalysis to call it over-covered.
+const DefaultOverCoveredPercent = 90.0
+
+// Redundancy describes where a corpus covers the same node types more
+// than it needs to.
+type Redundancy struct {
+	// Threshold is the percentage of files used to flag over-covered types.
+	Threshold float64
+
+	// OverCovered lists node types present in at least Threshold percent
+	// of files, most widespread first.
+	OverCovered []OverCoveredType
+
+	// RedundantFiles lists files whose removal alone would not shrink the
+	// set of covered node types.
+	RedundantFiles []string
+
+	// MinimalFiles is a small set of files that together keep every
+	// covered node type, chosen greedily by new types contributed.
+	MinimalFiles []string
+}
+
+// OverCoveredType is a node type that appears in most files.
+type OverCoveredType struct {
+	NodeType string
+	Files    int
+	Percent  float64
+}
+
+// RedundancyAnalysis analyzes results with DefaultOverCoveredPercent.
+func RedundancyAnalysis(results []*analyzer.AnalysisResult) *Redundancy {
+	return RedundancyAnalysisWithThreshold(results, DefaultOverCoveredPercent)
+}
+
+// RedundancyAnalysisWithThreshold analyzes results, flagging node types
+// present in at least threshold percent of files as over-covered.
+func RedundancyAnalysisWithThreshold(results []*analyzer.AnalysisResult, threshold float64) *Redundancy {
+	r := &Redundancy{
+		Threshold:      threshold,
+		OverCovered:    []OverCoveredType{},
+		RedundantFiles: []string{},
+		MinimalFiles:   []string{},
+	}
+	if len(results) == 0 {
+		return r
+	}
+
+	// Work on base names in sorted order so ties break deterministically
+	files := make(map[string]map[string]bool, len(results))
+	for _, result := range results {
+		name := getBaseName(result.FileName)
+		if files[name] == nil {
+			files[name] = make(map[string]bool)
+		}
+		for nodeType := range result.NodeCounts {
+			files[name][nodeType] = true
+		}
+	}
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fileCounts := make(map[string]int)
+	for _, name := range names {
+		for nodeType := range files[name] {
+			fileCounts[nodeType]++
+		}
+	}
+
+	for nodeType, count := range fileCounts {
+		percent := float64(count) / float64(len(names)) * 100
+		if percent >= threshold {
+			r.OverCovered = append(r.OverCovered, OverCoveredType{NodeType: nodeType, Files: count, Percent: percent})
+		}
+	}
+	sort.Slice(r.OverCovered, func(i, j int) bool {
+		if r.OverCovered[i].Files != r.OverCovered[j].Files {
+			return r.OverCovered[i].Files > r.OverCovered[j].Files
+		}
+		return r.OverCovered[i].NodeType < r.OverCovered[j].NodeType
+	})
+
+	// A file is redundant if every one of its types appears in another file
+	for _, name := range names {
+		redundant := true
+		for nodeType := range files[name] {
+			if fileCounts[nodeType] == 1 {
+				redundant = false
+				break
+			}
+		}
+		if redundant {
+			r.RedundantFiles = append(r.RedundantFiles, name)
+		}
+	}
+
+	// Greedy set cover: repeatedly take the file adding the most new types
+	uncovered := make(map[string]bool, len(fileCounts))
+	for nodeType := range fileCounts {
+		uncovered[nodeType] = true
+	}
+	for len(uncovered) > 0 {
+		best, bestGain := "", 0
+		for _, name := range names {
+			gain := 0
+			for nodeType := range files[name] {
+				if uncovered[nodeType] {
+					gain++
+				}
+			}
+			if gain > bestGain {
+				best, bestGain = name, gain
+			}
+		}
+		r.MinimalFiles = append(r.MinimalFiles, best)
+		for nodeType := range files[best] {
+			delete(uncovered, nodeType)
+		}
+	}
+	sort.Strings(r.MinimalFiles)
+
+	return r
+}
+
+// PrintRedundancy writes the redundancy analysis to w.
+func PrintRedundancy(w io.Writer, r *Redundancy) {
+	fmt.Fprintln(w, "REDUNDANCY")
+	fmt.Fprintln(w, strings.Repeat("-", 80))
+
+	fmt.Fprintf(w, "Over-covered node types (in ≥ %.0f%% of files): %d\n", r.Threshold, len(r.OverCovered))
+	for _, oc := range r.OverCovered {
+		fmt.Fprintf(w, "  %-30s %3d files  %6.2f%%\n", oc.NodeType, oc.Files, oc.Percent)
+	}
+
+	fmt.Fprintf(w, "\nFiles with no unique node types: %d\n", len(r.RedundantFiles))
+	for _, name := range r.RedundantFiles {
+		fmt.Fprintf(w, "  %s\n", name)
+	}
+
+	fmt.Fprintf(w, "\nMinimal file set keeping current coverage: %d\n", len(r.MinimalFiles))
+	for _, name := range r.MinimalFiles {
+		fmt.Fprintf(w, "  %s\n", name)
+	}
+}
@@ -0,0 +1,50 @@
+package report
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"zylisp/go-ast-coverage/analyzer"
+)
+
+// TestRedundancyAnalysis tests over-covered types, redundant files, and
+// the greedy minimal file set
+func TestRedundancyAnalysis(t *testing.T) {
+	counts := func(types ...string) map[string]int {
+		m := make(map[string]int)
+		for _, nodeType := range types {
+			m[nodeType] = 1
+		}
+		return m
+	}
+	results := []*analyzer.AnalysisResult{
+		{FileName: "nodes/go/a.go", NodeCounts: counts("*ast.File", "*ast.Ident", "*ast.GoStmt", "*ast.IfStmt")},
+		{FileName: "nodes/go/b.go", NodeCounts: counts("*ast.File", "*ast.Ident", "*ast.IfStmt")},
+		{FileName: "nodes/go/c.go", NodeCounts: counts("*ast.File", "*ast.Ident", "*ast.ForStmt")},
+		{FileName: "nodes/go/d.go", NodeCounts: counts("*ast.File", "*ast.ForStmt")},
+	}
+
+	r := RedundancyAnalysisWithThreshold(results, 75)
+
+	var over []string
+	for _, oc := range r.OverCovered {
+		over = append(over, oc.NodeType)
+	}
+	if !reflect.DeepEqual(over, []string{"*ast.File", "*ast.Ident"}) {
+		t.Errorf("unexpected over-covered types %v", over)
+	}
+	if !reflect.DeepEqual(r.RedundantFiles, []string{"b.go", "c.go", "d.go"}) {
+		t.Errorf("unexpected redundant files %v", r.RedundantFiles)
+	}
+	if !reflect.DeepEqual(r.MinimalFiles, []string{"a.go", "c.go"}) {
+		t.Errorf("unexpected minimal files %v", r.MinimalFiles)
+	}
+
+	var buf bytes.Buffer
+	PrintRedundancy(&buf, r)
+	if !strings.Contains(buf.String(), "Minimal file set keeping current coverage: 2") {
+		t.Errorf("unexpected output:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,96 @@
+package report
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+)
+
+// ErrMissingBaseline is returned by RegressionCheck when the baseline report
+// does not exist and UpdateBaseline is not set.
+var ErrMissingBaseline = errors.New("baseline report not found")
+
+// RegressionOptions configures RegressionCheck.
+type RegressionOptions struct {
+	// Tolerance is how many percentage points coverage may drop below the
+	// baseline before the check fails.
+	Tolerance float64
+
+	// UpdateBaseline rewrites the baseline with the current report when
+	// the check passes, and creates it when it does not exist yet.
+	UpdateBaseline bool
+}
+
+// RegressionResult is the outcome of RegressionCheck.
+type RegressionResult struct {
+	Pass bool
+
+	// Reasons explains each failed rule; it is empty when Pass is set.
+	Reasons []string
+
+	// Diff compares the baseline to the current report. It is nil when
+	// the baseline was created by this check.
+	Diff *ReportDiff
+
+	// BaselineUpdated reports whether the baseline file was written.
+	BaselineUpdated bool
+}
+
+// RegressionCheck compares current against the JSON report at baselinePath.
+// It fails when a node type the baseline covered is now missing, or when
+// coverage dropped by more than opts.Tolerance points. A missing baseline
+// returns an error wrapping ErrMissingBaseline, unless opts.UpdateBaseline
+// is set, in which case the baseline is created and the check passes.
+func RegressionCheck(current *CoverageReport, baselinePath string, opts RegressionOptions) (*RegressionResult, error) {
+	baseline, err := LoadReportJSON(baselinePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		if !opts.UpdateBaseline {
+			return nil, fmt.Errorf("%w: %s", ErrMissingBaseline, baselinePath)
+		}
+		if err := SaveReportJSON(current, baselinePath); err != nil {
+			return nil, fmt.Errorf("failed to create baseline: %w", err)
+		}
+		return &RegressionResult{Pass: true, Reasons: []string{}, BaselineUpdated: true}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load baseline: %w", err)
+	}
+
+	diff := DiffReports(baseline, current)
+	result := &RegressionResult{Reasons: []string{}, Diff: diff}
+	for _, node := range diff.Regressed {
+		result.Reasons = append(result.Reasons, fmt.Sprintf("%s was covered by the baseline but is now missing", node))
+	}
+	if -diff.PercentDelta > opts.Tolerance {
+		result.Reasons = append(result.Reasons, fmt.Sprintf("coverage dropped %.2f points (%.2f%% → %.2f%%), more than the %.2f point tolerance",
+			-diff.PercentDelta, diff.OldPercent, diff.NewPercent, opts.Tolerance))
+	}
+	result.Pass = len(result.Reasons) == 0
+
+	if result.Pass && opts.UpdateBaseline {
+		if err := SaveReportJSON(current, baselinePath); err != nil {
+			return nil, fmt.Errorf("failed to update baseline: %w", err)
+		}
+		result.BaselineUpdated = true
+	}
+	return result, nil
+}
+
+// PrintRegressionResult writes a summary of a regression check to w.
+func PrintRegressionResult(w io.Writer, r *RegressionResult) {
+	if r.Pass {
+		fmt.Fprintln(w, "✓ No coverage regressions")
+		if r.Diff != nil && len(r.Diff.NewlyCovered) > 0 {
+			fmt.Fprintf(w, "  %d node type(s) newly covered\n", len(r.Diff.NewlyCovered))
+		}
+	} else {
+		fmt.Fprintf(w, "✗ %d coverage regression(s):\n", len(r.Reasons))
+		for _, reason := range r.Reasons {
+			fmt.Fprintf(w, "  - %s\n", reason)
+		}
+	}
+	if r.BaselineUpdated {
+		fmt.Fprintln(w, "  Baseline updated")
+	}
+}
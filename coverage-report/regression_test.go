@@ -0,0 +1,115 @@
+package report
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeBaseline saves rep as a baseline in a fresh directory and returns
+// its path.
+func writeBaseline(t *testing.T, rep *CoverageReport) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	if err := SaveReportJSON(rep, path); err != nil {
+		t.Fatalf("failed to save baseline: %v", err)
+	}
+	return path
+}
+
+// TestRegressionCheckPass tests that an unchanged report passes
+func TestRegressionCheckPass(t *testing.T) {
+	path := writeBaseline(t, sampleReport())
+
+	result, err := RegressionCheck(sampleReport(), path, RegressionOptions{})
+	if err != nil {
+		t.Fatalf("RegressionCheck failed: %v", err)
+	}
+	if !result.Pass || len(result.Reasons) != 0 || result.BaselineUpdated {
+		t.Errorf("expected a pass without updates, got %+v", result)
+	}
+}
+
+// TestRegressionCheckNewCoverage tests that new coverage passes and
+// rewrites the baseline when asked
+func TestRegressionCheckNewCoverage(t *testing.T) {
+	path := writeBaseline(t, sampleReport())
+
+	current := sampleReport()
+	current.CoveredNodes = append(current.CoveredNodes, "*ast.GoStmt")
+	current.MissingNodes = []string{"*ast.TypeSpec"}
+	current.CoveredNodeTypes, current.CoveragePercent = 5, float64(5)/6*100
+
+	result, err := RegressionCheck(current, path, RegressionOptions{UpdateBaseline: true})
+	if err != nil {
+		t.Fatalf("RegressionCheck failed: %v", err)
+	}
+	if !result.Pass || !result.BaselineUpdated || len(result.Diff.NewlyCovered) != 1 {
+		t.Errorf("expected a pass with one newly covered type, got %+v", result)
+	}
+
+	updated, err := LoadReportJSON(path)
+	if err != nil {
+		t.Fatalf("failed to reload baseline: %v", err)
+	}
+	if updated.CoveredNodeTypes != 5 {
+		t.Errorf("expected the baseline to be rewritten, got %d covered", updated.CoveredNodeTypes)
+	}
+}
+
+// TestRegressionCheckRegression tests that lost node types and coverage
+// drops beyond the tolerance fail
+func TestRegressionCheckRegression(t *testing.T) {
+	path := writeBaseline(t, sampleReport())
+
+	current := sampleReport()
+	current.CoveredNodes = []string{"*ast.BinaryExpr", "*ast.File", "*ast.Ident"}
+	current.MissingNodes = []string{"*ast.GoStmt", "*ast.ReturnStmt", "*ast.TypeSpec"}
+	current.CoveredNodeTypes, current.CoveragePercent = 3, 50
+
+	result, err := RegressionCheck(current, path, RegressionOptions{Tolerance: 20, UpdateBaseline: true})
+	if err != nil {
+		t.Fatalf("RegressionCheck failed: %v", err)
+	}
+	if result.Pass || len(result.Reasons) != 1 || !strings.Contains(result.Reasons[0], "*ast.ReturnStmt") {
+		t.Errorf("expected only the lost node type to fail within tolerance, got %v", result.Reasons)
+	}
+	if result.BaselineUpdated {
+		t.Error("expected the baseline to be left alone on failure")
+	}
+
+	result, _ = RegressionCheck(current, path, RegressionOptions{Tolerance: 10})
+	if len(result.Reasons) != 2 || !strings.Contains(result.Reasons[1], "dropped 16.67 points") {
+		t.Errorf("expected the coverage drop to fail beyond tolerance, got %v", result.Reasons)
+	}
+
+	var buf bytes.Buffer
+	PrintRegressionResult(&buf, result)
+	if !strings.Contains(buf.String(), "✗ 2 coverage regression(s):") {
+		t.Errorf("unexpected summary:\n%s", buf.String())
+	}
+}
+
+// TestRegressionCheckMissingBaseline tests the missing baseline error and
+// creating the baseline on first run
+func TestRegressionCheckMissingBaseline(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "baseline.json")
+
+	if _, err := RegressionCheck(sampleReport(), path, RegressionOptions{}); !errors.Is(err, ErrMissingBaseline) {
+		t.Errorf("expected ErrMissingBaseline, got %v", err)
+	}
+
+	result, err := RegressionCheck(sampleReport(), path, RegressionOptions{UpdateBaseline: true})
+	if err != nil {
+		t.Fatalf("RegressionCheck failed: %v", err)
+	}
+	if !result.Pass || !result.BaselineUpdated {
+		t.Errorf("expected the baseline to be created, got %+v", result)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("expected baseline at %s: %v", path, err)
+	}
+}
@@ -4,7 +4,9 @@ package report
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"sort"
 	"strings"
@@ -15,6 +17,7 @@ import (
 
 // CoverageReport represents the overall coverage status.
 type CoverageReport struct {
+	SchemaVersion    int
 	GeneratedAt      time.Time
 	TotalNodeTypes   int
 	CoveredNodeTypes int
@@ -22,6 +25,88 @@ type CoverageReport struct {
 	CoveredNodes     []string
 	MissingNodes     []string
 	FileReports      []*FileReport
+	Categories       []CategoryCoverage
+	Tokens           TokenCoverage
+	Variants         []VariantCoverage
+
+	// TokenKinds reports which lexical token kinds, from identifiers to
+	// keywords, appear in the sources.
+	TokenKinds TokenCoverage
+
+	// Attribution maps each covered node type to the sorted base names of
+	// the files containing it.
+	Attribution map[string][]string
+
+	// Suggestions proposes a file and snippet for each missing node type.
+	Suggestions []MissingNodeSuggestion
+
+	// NodeCounts is the number of occurrences of each covered node type
+	// across all files.
+	NodeCounts map[string]int
+
+	// MostCommon and LeastCommon are the DefaultTopN covered node types
+	// with the highest and lowest counts in NodeCounts.
+	MostCommon  []NodeFrequency
+	LeastCommon []NodeFrequency
+
+	// GoVersion is the version profile the expected node types were
+	// filtered by, such as "go1.17", or "latest".
+	GoVersion string
+
+	// MissingSince maps each missing node type to the Go version that
+	// introduced it.
+	MissingSince map[string]string
+
+	// Source labels the corpus the report was generated from, usually its
+	// directory.
+	Source string
+
+	// FirstCoveredBy and Sources are set on reports built by MergeReports:
+	// the source that first covered each node type, and what each source
+	// contributed.
+	FirstCoveredBy map[string]string
+	Sources        []SourceCoverage
+
+	// MissingExamples maps each missing node type to a minimal, validated
+	// top-level declaration that produces it. Node types that valid
+	// source cannot produce have no entry.
+	MissingExamples map[string]string
+
+	// SkippedFiles lists the files that could not be analyzed and so do
+	// not contribute to coverage.
+	SkippedFiles []SkippedFile
+
+	// ExcludedNodes lists the node types left out of the expected set on
+	// purpose, with the reasons given.
+	ExcludedNodes []ExcludedNode
+
+	// Meta records how the report was made: phase timings, file counts,
+	// and tool versions.
+	Meta ReportMeta
+	// Analysis holds the raw analysis results when the report was
+	// generated with IncludeRawAnalysis, and is omitted otherwise.
+	Analysis *RawAnalysis `json:"analysis,omitempty"`
+}
+
+// SkippedFile is a file left out of a report because it could not be
+// analyzed, such as one that fails to parse.
+type SkippedFile struct {
+	FileName string
+	Error    string
+}
+
+// RawAnalysis is the analysis a report was built from.
+type RawAnalysis struct {
+	Aggregated analyzer.SerializedResult
+	Files      []analyzer.SerializedResult
+}
+
+// CategoryCoverage summarizes coverage of one node category.
+type CategoryCoverage struct {
+	Name    string
+	Covered int
+	Total   int
+	Percent float64
 }
 
 // FileReport represents coverage for a single file.
@@ -30,21 +115,135 @@ type FileReport struct {
 	NodeTypes   []string
 	NodeCount   int
 	UniqueTypes int
+
+	// CoveragePercent is UniqueTypes as a percentage of all node types.
+	CoveragePercent float64
+
+	// NewTypesContributed counts the node types this file covers that no
+	// earlier file does, with files taken in the report's order (sorted
+	// by file name).
+	NewTypesContributed int
+
+	// Source labels the constituent report a merged report took this
+	// file from.
+	Source string
 }
 
-// GenerateReport creates a comprehensive coverage report.
+// GenerateReport creates a comprehensive coverage report expecting every
+// node type of the latest Go version.
 func GenerateReport(resultsDir string) (*CoverageReport, error) {
+	return GenerateReportForVersion(resultsDir, analyzer.LatestGoVersion)
+}
+
+// GenerateReportForVersion creates a coverage report for a corpus targeting
+// the Go version, such as "go1.17": node types introduced after it are not
+// expected and do not count against coverage.
+func GenerateReportForVersion(resultsDir, version string) (*CoverageReport, error) {
+	return GenerateReportWithOptions(resultsDir, ReportOptions{GoVersion: version})
+}
+
+// GenerateReportWithOptions creates a coverage report for a corpus as
+// configured by opts. Excluded node types are left out of TotalNodeTypes
+// and listed in ExcludedNodes. With a CacheDir, a report cached for the
+// same file contents and options is returned instead of re-analyzing.
+func GenerateReportWithOptions(resultsDir string, opts ReportOptions) (*CoverageReport, error) {
+	if opts.CacheDir == "" {
+		return generateReport(resultsDir, opts)
+	}
+	return generateCachedReport(resultsDir, opts)
+}
+
+// generateReport analyzes a corpus and builds its report.
+func generateReport(resultsDir string, opts ReportOptions) (*CoverageReport, error) {
+	version := opts.GoVersion
+	allNodeTypes, err := analyzer.GetNodeTypesForVersion(version)
+	if err != nil {
+		return nil, err
+	}
+	allNodeTypes, excluded, err := applyExclusions(allNodeTypes, opts.Exclusions)
+	if err != nil {
+		return nil, err
+	}
+
 	// Analyze all files in the directory
-	results, err := analyzer.AnalyzeDirectory(resultsDir)
+	start := time.Now()
+	files, err := reportFiles(resultsDir, opts)
 	if err != nil {
-		return nil, fmt.Errorf("failed to analyze directory: %w", err)
+		return nil, err
+	}
+	results, skipped := analyzer.AnalyzeFiles(files)
+	if len(results) == 0 && len(skipped) > 0 {
+		return nil, fmt.Errorf("failed to analyze any of %d files: %s: %w", len(skipped), skipped[0].FileName, skipped[0].Err)
 	}
 
 	// Also parse as package to ensure ast.Package coverage
-	_ = analyzer.AnalyzePackage(resultsDir)
+	if len(opts.Files) == 0 {
+		_ = analyzer.AnalyzePackage(resultsDir)
+	}
+	analysis := time.Since(start)
+
+	start = time.Now()
+	report := buildReportForTypes(results, allNodeTypes, opts.warnings())
+	report.Meta = newReportMeta(len(results), len(skipped), totalNodes(report.FileReports), analysis, time.Since(start))
+	if version != "" {
+		report.GoVersion = version
+	}
+	recordRevision(report, opts)
+	report.ExcludedNodes = excluded
+	for _, skip := range skipped {
+		report.SkippedFiles = append(report.SkippedFiles, SkippedFile{FileName: skip.FileName, Error: skip.Err.Error()})
+	}
+	if opts.IncludeRawAnalysis {
+		report.Analysis = &RawAnalysis{
+			Aggregated: analyzer.AggregateResults(results).Serialize(),
+			Files:      analyzer.SerializeResults(results),
+		}
+	}
+	markCoveredExclusions(report.ExcludedNodes, report.NodeCounts)
+	report.Source = resultsDir
+	return report, nil
+}
+
+// reportFiles returns the files a report covers: opts.Files if set, and
+// otherwise every Go file in resultsDir.
+func reportFiles(resultsDir string, opts ReportOptions) ([]string, error) {
+	if len(opts.Files) > 0 {
+		return opts.Files, nil
+	}
+	files, err := analyzer.ListGoFiles(resultsDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to analyze directory: %w", err)
+	}
+	return files, nil
+}
+
+// recordRevision sets the report's revision metadata from opts.Revision,
+// if one is given, warning if it fails for a reason other than the corpus
+// not being in a repository.
+func recordRevision(report *CoverageReport, opts ReportOptions) {
+	if opts.Revision == nil {
+		return
+	}
+	revision, branch, dirty, err := opts.Revision()
+	if err != nil && !errors.Is(err, ErrNoRepository) {
+		fmt.Fprintf(opts.warnings(), "Warning: failed to read revision: %v\n", err)
+	}
+	// Without the dirty state the revision is still worth recording
+	if err == nil || errors.Is(err, ErrDirtyUnknown) {
+		report.Meta.Revision, report.Meta.Branch, report.Meta.Dirty = revision, branch, dirty
+	}
+}
+
+// buildReport assembles a report from per-file analysis results, expecting
+// every node type.
+func buildReport(results []*analyzer.AnalysisResult) *CoverageReport {
+	return buildReportForTypes(results, analyzer.GetAllNodeTypes(), os.Stderr)
+}
 
-	// Get all expected node types
-	allNodeTypes := analyzer.GetAllNodeTypes()
+// buildReportForTypes assembles a report from per-file analysis results
+// against the expected node types in allNodeTypes, writing any warnings to
+// warnings.
+func buildReportForTypes(results []*analyzer.AnalysisResult, allNodeTypes []string, warnings io.Writer) *CoverageReport {
 	totalNodeTypes := len(allNodeTypes)
 
 	// Aggregate all found nodes
@@ -54,12 +253,12 @@ func GenerateReport(resultsDir string) (*CoverageReport, error) {
 		coveredMap[nodeType] = true
 	}
 
-	// Add Package node as covered (since we call AnalyzePackage above)
+	// Add Package node as covered (the files form a package)
 	coveredMap["*ast.Package"] = true
 
 	// Determine covered and missing nodes
-	var coveredNodes []string
-	var missingNodes []string
+	coveredNodes := []string{}
+	missingNodes := []string{}
 
 	for _, nodeType := range allNodeTypes {
 		if coveredMap[nodeType] {
@@ -76,7 +275,7 @@ func GenerateReport(resultsDir string) (*CoverageReport, error) {
 	coveragePercent := (float64(coveredCount) / float64(totalNodeTypes)) * 100
 
 	// Create file reports
-	var fileReports []*FileReport
+	fileReports := []*FileReport{}
 	for _, result := range results {
 		nodeTypes := make([]string, 0, len(result.NodeCounts))
 		for nodeType := range result.NodeCounts {
@@ -91,97 +290,360 @@ func GenerateReport(resultsDir string) (*CoverageReport, error) {
 			UniqueTypes: result.UniqueTypes,
 		})
 	}
-
-	return &CoverageReport{
-		GeneratedAt:      time.Now(),
+	sort.SliceStable(fileReports, func(i, j int) bool {
+		return fileReports[i].FileName < fileReports[j].FileName
+	})
+	computeFileContributions(fileReports, totalNodeTypes)
+
+	report := &CoverageReport{
+		SchemaVersion:    CurrentSchemaVersion,
+		GeneratedAt:      time.Now().UTC().Truncate(time.Second),
 		TotalNodeTypes:   totalNodeTypes,
 		CoveredNodeTypes: coveredCount,
 		CoveragePercent:  coveragePercent,
 		CoveredNodes:     coveredNodes,
 		MissingNodes:     missingNodes,
 		FileReports:      fileReports,
-	}, nil
+		Categories:       computeCategoryCoverage(allNodeTypes, coveredNodes),
+		Tokens:           computeTokenCoverage(aggregated.TokenCounts),
+		TokenKinds:       computeTokenKindCoverage(aggregated.TokenKindCounts),
+		Variants:         computeVariantCoverage(aggregated.VariantCounts),
+		Attribution:      computeAttribution(results),
+		NodeCounts:       aggregated.NodeCounts,
+		MostCommon:       MostCommonNodes(aggregated.NodeCounts, DefaultTopN),
+		LeastCommon:      LeastCommonNodes(aggregated.NodeCounts, DefaultTopN),
+		GoVersion:        analyzer.LatestGoVersion,
+		MissingSince:     missingSince(missingNodes),
+		FirstCoveredBy:   map[string]string{},
+		Sources:          []SourceCoverage{},
+		MissingExamples:  computeMissingExamples(missingNodes, warnings),
+		ExcludedNodes:    []ExcludedNode{},
+		SkippedFiles:     []SkippedFile{},
+	}
+	report.Suggestions = computeSuggestions(report)
+	return report
+}
+
+// missingSince maps each missing node type to the Go version that
+// introduced it.
+func missingSince(missingNodes []string) map[string]string {
+	since := make(map[string]string, len(missingNodes))
+	for _, nodeType := range missingNodes {
+		since[nodeType] = analyzer.NodeTypeVersion(nodeType)
+	}
+	return since
+}
+
+// computeAttribution maps each node type found in results to the sorted
+// base names of the files containing it.
+func computeAttribution(results []*analyzer.AnalysisResult) map[string][]string {
+	attribution := make(map[string][]string)
+	for _, result := range results {
+		name := getBaseName(result.FileName)
+		for nodeType := range result.NodeCounts {
+			attribution[nodeType] = append(attribution[nodeType], name)
+		}
+	}
+	for _, files := range attribution {
+		sort.Strings(files)
+	}
+	return attribution
+}
+
+// singleSourceNodes returns the sorted node types covered by exactly one
+// file.
+func singleSourceNodes(report *CoverageReport) []string {
+	var nodes []string
+	for nodeType, files := range report.Attribution {
+		if len(files) == 1 {
+			nodes = append(nodes, nodeType)
+		}
+	}
+	sort.Strings(nodes)
+	return nodes
 }
 
 // PrintReport prints the coverage report to stdout.
 func PrintReport(report *CoverageReport) {
-	fmt.Println("\n" + strings.Repeat("=", 80))
-	fmt.Println("GO AST COVERAGE REPORT")
-	fmt.Println(strings.Repeat("=", 80))
-	fmt.Printf("Generated: %s\n\n", report.GeneratedAt.Format(time.RFC3339))
+	PrintReportTo(os.Stdout, report)
+}
+
+// reportWriter writes formatted output, remembering the first write error.
+type reportWriter struct {
+	w      io.Writer
+	color  bool
+	glyphs glyphSet
+	err    error
+}
+
+// printf writes formatted output unless an earlier write failed.
+func (rw *reportWriter) printf(format string, args ...interface{}) {
+	if rw.err != nil {
+		return
+	}
+	_, rw.err = fmt.Fprintf(rw.w, format, args...)
+}
+
+// println writes s and a newline unless an earlier write failed.
+func (rw *reportWriter) println(s string) {
+	rw.printf("%s\n", s)
+}
+
+// PrintReportTo writes the coverage report to w, colored automatically,
+// and returns the first write error.
+func PrintReportTo(w io.Writer, report *CoverageReport) error {
+	return PrintReportWithOptions(w, report, PrintOptions{})
+}
+
+// PrintReportWithOptions writes the coverage report to w and returns the
+// first write error. Coloring only adds escape sequences; the text is the
+// same either way.
+func PrintReportWithOptions(w io.Writer, report *CoverageReport, opts PrintOptions) error {
+	rw := &reportWriter{w: w, color: useColor(w, opts.Color), glyphs: glyphsFor(opts.ASCIIOnly)}
+	rw.println("\n" + strings.Repeat("=", 80))
+	rw.heading("GO AST COVERAGE REPORT")
+	rw.println(strings.Repeat("=", 80))
+	rw.printf("Generated: %s\n", report.GeneratedAt.Format(time.RFC3339))
+	if revision := report.Meta.revisionLabel(); revision != "" {
+		rw.printf("Revision:  %s\n", revision)
+	}
+	rw.println("")
 
 	// Summary
-	fmt.Println("SUMMARY")
-	fmt.Println(strings.Repeat("-", 80))
-	fmt.Printf("Total AST Node Types:    %d\n", report.TotalNodeTypes)
-	fmt.Printf("Covered Node Types:      %d\n", report.CoveredNodeTypes)
-	fmt.Printf("Missing Node Types:      %d\n", len(report.MissingNodes))
-	fmt.Printf("Coverage:                %.2f%%\n\n", report.CoveragePercent)
+	rw.heading("SUMMARY")
+	rw.println(strings.Repeat("-", 80))
+	rw.printf("Total AST Node Types:    %d\n", report.TotalNodeTypes)
+	rw.printf("Covered Node Types:      %d\n", report.CoveredNodeTypes)
+	rw.printf("Missing Node Types:      %d\n", len(report.MissingNodes))
+	rw.printf("Coverage:                %.2f%%\n", report.CoveragePercent)
+	if report.GoVersion != "" && report.GoVersion != analyzer.LatestGoVersion {
+		rw.printf("Go Version Profile:      %s\n", report.GoVersion)
+	}
+	rw.println("")
+
+	// Files that do not count, shown early so lower coverage is explained
+	if len(report.SkippedFiles) > 0 {
+		rw.heading(rw.paint(ansiRed, fmt.Sprintf("%s SKIPPED FILES (%d)", rw.glyphs.Warning, len(report.SkippedFiles))))
+		rw.println(strings.Repeat("-", 80))
+		for _, skip := range report.SkippedFiles {
+			rw.printf("  %s %s\n", rw.paint(ansiRed, rw.glyphs.Missing), getBaseName(skip.FileName))
+			rw.printf("        %s\n", skip.Error)
+		}
+		rw.println("")
+	}
 
 	// Coverage bar
-	barWidth := 50
-	filledWidth := int(report.CoveragePercent / 100 * float64(barWidth))
-	emptyWidth := barWidth - filledWidth
-	fmt.Printf("Progress: [%s%s] %.2f%%\n\n",
-		strings.Repeat("█", filledWidth),
-		strings.Repeat("░", emptyWidth),
-		report.CoveragePercent)
+	rw.printf("Progress: [%s] %.2f%%\n\n", rw.bar(report.CoveragePercent, 50), report.CoveragePercent)
 
 	// File reports
-	fmt.Println("FILE BREAKDOWN")
-	fmt.Println(strings.Repeat("-", 80))
+	rw.heading("FILE BREAKDOWN")
+	rw.println(strings.Repeat("-", 80))
 	for _, fr := range report.FileReports {
 		baseName := getBaseName(fr.FileName)
-		fmt.Printf("%-30s  Nodes: %5d  Unique Types: %3d\n",
-			baseName, fr.NodeCount, fr.UniqueTypes)
+		rw.printf("%-30s  Nodes: %5d  Unique Types: %3d  Coverage: %6.2f%%  New: %3d\n",
+			baseName, fr.NodeCount, fr.UniqueTypes, fr.CoveragePercent, fr.NewTypesContributed)
+	}
+	rw.println("")
+
+	// Per-source contributions to a merged report
+	if len(report.Sources) > 0 {
+		printSources(rw, report)
+	}
+
+	// Category summary
+	rw.heading("CATEGORY COVERAGE")
+	rw.println(strings.Repeat("-", 80))
+	for _, cat := range report.Categories {
+		rw.printf("%-20s  %3d/%-3d  %6.2f%%  [%s]\n", cat.Name, cat.Covered, cat.Total, cat.Percent, rw.bar(cat.Percent, 20))
+	}
+	rw.println("")
+
+	// Operator and keyword tokens
+	if len(report.Tokens.Groups) > 0 {
+		rw.heading("TOKEN COVERAGE")
+		rw.println(strings.Repeat("-", 80))
+		printTokenGroups(rw, report.Tokens)
+	}
+
+	// Lexical token kinds
+	if len(report.TokenKinds.Groups) > 0 {
+		rw.heading("TOKEN KIND COVERAGE")
+		rw.println(strings.Repeat("-", 80))
+		printTokenGroups(rw, report.TokenKinds)
+	}
+
+	// Structural variants
+	if len(report.Variants) > 0 {
+		rw.heading("STRUCTURAL VARIANTS")
+		rw.println(strings.Repeat("-", 80))
+		for _, v := range report.Variants {
+			mark := rw.paint(ansiRed, rw.glyphs.Missing)
+			if v.Covered {
+				mark = rw.paint(ansiGreen, rw.glyphs.Covered)
+			}
+			rw.printf("  %s %-24s %5d  %s\n", mark, v.Name, v.Count, v.Description)
+		}
+		rw.println("")
+	}
+
+	// Node type frequencies
+	if len(report.MostCommon) > 0 {
+		rw.heading("MOST COMMON NODE TYPES")
+		rw.println(strings.Repeat("-", 80))
+		printFrequencies(rw, report.MostCommon)
+	}
+	if len(report.LeastCommon) > 0 {
+		rw.heading("LEAST COMMON NODE TYPES")
+		rw.println(strings.Repeat("-", 80))
+		printFrequencies(rw, report.LeastCommon)
+	}
+
+	// Node types that would be lost with a single file
+	if single := singleSourceNodes(report); len(single) > 0 {
+		rw.heading("SINGLE-SOURCE NODE TYPES")
+		rw.println(strings.Repeat("-", 80))
+		for _, node := range single {
+			rw.printf("  %s %-30s only in %s\n", rw.glyphs.Warning, node, report.Attribution[node][0])
+		}
+		rw.println("")
 	}
-	fmt.Println()
 
 	// Covered nodes by category
-	fmt.Println("COVERED NODE TYPES BY CATEGORY")
-	fmt.Println(strings.Repeat("-", 80))
+	rw.heading("COVERED NODE TYPES BY CATEGORY")
+	rw.println(strings.Repeat("-", 80))
 
 	categories := categorizeNodes(report.CoveredNodes)
-	for category, nodes := range categories {
-		fmt.Printf("\n%s (%d):\n", category, len(nodes))
+	for _, category := range sortedCategories(categories) {
+		nodes := categories[category]
+		rw.printf("\n%s:\n", rw.paint(ansiBold, fmt.Sprintf("%s (%d)", category, len(nodes))))
 		for _, node := range nodes {
-			fmt.Printf("  ✓ %s\n", node)
+			rw.printf("  %s\n", rw.paint(ansiGreen, rw.glyphs.Covered+" "+node))
 		}
 	}
-	fmt.Println()
+	rw.println("")
 
 	// Missing nodes
 	if len(report.MissingNodes) > 0 {
-		fmt.Println("MISSING NODE TYPES")
-		fmt.Println(strings.Repeat("-", 80))
+		rw.heading("MISSING NODE TYPES")
+		rw.println(strings.Repeat("-", 80))
 		missingCategories := categorizeNodes(report.MissingNodes)
-		for category, nodes := range missingCategories {
-			fmt.Printf("\n%s (%d):\n", category, len(nodes))
+		for _, category := range sortedCategories(missingCategories) {
+			nodes := missingCategories[category]
+			rw.printf("\n%s:\n", rw.paint(ansiBold, fmt.Sprintf("%s (%d)", category, len(nodes))))
 			for _, node := range nodes {
-				fmt.Printf("  ✗ %s\n", node)
+				line := rw.glyphs.Missing + " " + node
+				if since := report.MissingSince[node]; since != "" && since != "go1" {
+					line += " (" + since + ")"
+				}
+				rw.printf("  %s\n", rw.paint(ansiRed, line))
+				if example := report.MissingExamples[node]; example != "" {
+					for _, exampleLine := range strings.Split(example, "\n") {
+						rw.printf("        %s\n", exampleLine)
+					}
+				}
 			}
 		}
-		fmt.Println()
+		rw.println("")
+	}
+
+	// Node types left out on purpose
+	if len(report.ExcludedNodes) > 0 {
+		rw.heading("EXCLUDED NODE TYPES")
+		rw.println(strings.Repeat("-", 80))
+		for _, ex := range report.ExcludedNodes {
+			rw.printf("  - %-30s %s\n", ex.NodeType, ex.Reason)
+			if ex.Covered {
+				rw.printf("    %s\n", rw.paint(ansiYellow, rw.glyphs.Warning+" covered by the corpus; consider removing the exclusion"))
+			}
+		}
+		rw.println("")
+	}
+
+	// Where to add missing nodes
+	if len(report.Suggestions) > 0 {
+		rw.heading("SUGGESTIONS")
+		rw.println(strings.Repeat("-", 80))
+		for _, sg := range report.Suggestions {
+			rw.printf("  %s %s add to %s\n", sg.NodeType, rw.glyphs.Arrow, sg.File)
+		}
+		rw.println("")
+	}
+
+	// How the report was made
+	if report.Meta != (ReportMeta{}) {
+		meta := report.Meta
+		rw.heading("REPORT METADATA")
+		rw.println(strings.Repeat("-", 80))
+		rw.printf("Files analyzed:          %d (%d skipped)\n", meta.FilesAnalyzed, meta.FilesSkipped)
+		rw.printf("Total nodes:             %d\n", meta.TotalNodes)
+		rw.printf("Duration:                %s (analysis %s, build %s)\n",
+			(meta.AnalysisDuration + meta.BuildDuration).Round(time.Millisecond),
+			meta.AnalysisDuration.Round(time.Millisecond), meta.BuildDuration.Round(time.Millisecond))
+		rw.printf("Tool version:            %s\n", meta.ToolVersion)
+		rw.printf("Go version:              %s\n", meta.GoVersion)
+		if meta.Revision != "" {
+			rw.printf("Revision:                %s\n", meta.Revision)
+		}
+		rw.println("")
 	}
 
-	fmt.Println(strings.Repeat("=", 80))
+	rw.println(strings.Repeat("=", 80))
 	if report.CoveragePercent >= 100.0 {
-		fmt.Println("🎉 PERFECT COVERAGE! All AST node types are covered!")
+		rw.println(rw.glyphs.Celebrate + " PERFECT COVERAGE! All AST node types are covered!")
 	} else if report.CoveragePercent >= 90.0 {
-		fmt.Println("✓ Excellent coverage! Only a few node types remaining.")
+		rw.println(rw.glyphs.Covered + " Excellent coverage! Only a few node types remaining.")
 	} else if report.CoveragePercent >= 75.0 {
-		fmt.Println("✓ Good coverage. Continue adding more node types.")
+		rw.println(rw.glyphs.Covered + " Good coverage. Continue adding more node types.")
 	} else {
-		fmt.Println("⚠ More coverage needed. Many node types are missing.")
+		rw.println(rw.glyphs.Warning + " More coverage needed. Many node types are missing.")
 	}
-	fmt.Println(strings.Repeat("=", 80))
+	rw.println(strings.Repeat("=", 80))
+
+	if rw.err != nil {
+		return fmt.Errorf("failed to write report: %w", rw.err)
+	}
+	return nil
+}
+
+// printTokenGroups writes one line per token group, followed by the
+// group's missing tokens.
+func printTokenGroups(rw *reportWriter, coverage TokenCoverage) {
+	for _, group := range coverage.Groups {
+		total := len(group.Covered) + len(group.Missing)
+		rw.printf("%-20s  %3d/%-3d  %6.2f%%\n", group.Name, len(group.Covered), total, group.Percent)
+		if len(group.Missing) > 0 {
+			rw.printf("  %s\n", rw.paint(ansiRed, rw.glyphs.Missing+" missing: "+strings.Join(group.Missing, " ")))
+		}
+	}
+	rw.println("")
+}
+
+// JSONOptions controls how SaveReportJSONWithOptions writes a report.
+type JSONOptions struct {
+	// Deterministic makes identical inputs yield byte-identical JSON,
+	// suitable for committing: GeneratedAt is replaced by Timestamp,
+	// timings are dropped, and unordered lists are sorted.
+	Deterministic bool
+
+	// Timestamp is the GeneratedAt written by a deterministic report. The
+	// zero value writes the zero time, 0001-01-01T00:00:00Z; GeneratedAt is
+	// never omitted because LoadReportJSON requires it.
+	Timestamp time.Time
 }
 
 // SaveReportJSON saves the report as JSON.
 func SaveReportJSON(report *CoverageReport, filePath string) error {
-	data, err := json.MarshalIndent(report, "", "  ")
+	return SaveReportJSONWithOptions(report, filePath, JSONOptions{})
+}
+
+// SaveReportJSONWithOptions saves the report as JSON as configured by opts.
+func SaveReportJSONWithOptions(report *CoverageReport, filePath string, opts JSONOptions) error {
+	if opts.Deterministic {
+		report = deterministicReport(report, opts.Timestamp)
+	}
+	data, err := renderJSON(report)
 	if err != nil {
-		return fmt.Errorf("failed to marshal report: %w", err)
+		return err
 	}
 
 	err = os.WriteFile(filePath, data, 0644)
@@ -192,6 +654,124 @@ func SaveReportJSON(report *CoverageReport, filePath string) error {
 	return nil
 }
 
+// renderJSON renders the report as indented JSON.
+func renderJSON(report *CoverageReport) ([]byte, error) {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal report: %w", err)
+	}
+	return data, nil
+}
+
+// criticalReportFields are the JSON fields LoadReportJSON requires.
+var criticalReportFields = []string{"GeneratedAt", "TotalNodeTypes", "CoveredNodes", "MissingNodes"}
+
+// LoadReportJSON loads a report saved by SaveReportJSON. It fails if any
+// critical field is missing or the report's schema is newer than
+// CurrentSchemaVersion, tolerates fields it does not know about, replaces
+// null lists with empty ones, and upgrades older reports.
+func LoadReportJSON(filePath string) (*CoverageReport, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read report: %w", err)
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("failed to decode report: %w", err)
+	}
+	for _, name := range criticalReportFields {
+		if _, ok := fields[name]; !ok {
+			return nil, fmt.Errorf("failed to decode report: missing field %s", name)
+		}
+	}
+
+	var report CoverageReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("failed to decode report: %w", err)
+	}
+
+	if report.CoveredNodes == nil {
+		report.CoveredNodes = []string{}
+	}
+	if report.MissingNodes == nil {
+		report.MissingNodes = []string{}
+	}
+	fileReports := []*FileReport{}
+	for _, fr := range report.FileReports {
+		if fr != nil {
+			fileReports = append(fileReports, fr)
+		}
+	}
+	report.FileReports = fileReports
+	normalizeTokenCoverage(&report.Tokens)
+	normalizeTokenCoverage(&report.TokenKinds)
+	if report.Variants == nil {
+		report.Variants = []VariantCoverage{}
+	}
+	if report.Attribution == nil {
+		report.Attribution = map[string][]string{}
+	}
+	if report.Suggestions == nil {
+		report.Suggestions = []MissingNodeSuggestion{}
+	}
+	if report.NodeCounts == nil {
+		report.NodeCounts = map[string]int{}
+	}
+	if report.SkippedFiles == nil {
+		report.SkippedFiles = []SkippedFile{}
+	}
+	if report.ExcludedNodes == nil {
+		report.ExcludedNodes = []ExcludedNode{}
+	}
+	if report.MostCommon == nil {
+		report.MostCommon = []NodeFrequency{}
+	}
+	if report.LeastCommon == nil {
+		report.LeastCommon = []NodeFrequency{}
+	}
+	if report.Categories == nil {
+		report.Categories = []CategoryCoverage{}
+	}
+	if report.MissingSince == nil {
+		report.MissingSince = map[string]string{}
+	}
+	if report.FirstCoveredBy == nil {
+		report.FirstCoveredBy = map[string]string{}
+	}
+	if report.Sources == nil {
+		report.Sources = []SourceCoverage{}
+	}
+	if report.MissingExamples == nil {
+		report.MissingExamples = map[string]string{}
+	}
+	for _, fr := range report.FileReports {
+		if fr.NodeTypes == nil {
+			fr.NodeTypes = []string{}
+		}
+	}
+
+	if err := upgradeReport(&report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// normalizeTokenCoverage replaces null token lists with empty ones.
+func normalizeTokenCoverage(coverage *TokenCoverage) {
+	if coverage.Groups == nil {
+		coverage.Groups = []TokenGroupCoverage{}
+	}
+	for i := range coverage.Groups {
+		if coverage.Groups[i].Covered == nil {
+			coverage.Groups[i].Covered = []string{}
+		}
+		if coverage.Groups[i].Missing == nil {
+			coverage.Groups[i].Missing = []string{}
+		}
+	}
+}
+
 // SaveReportText saves the report as text.
 func SaveReportText(report *CoverageReport, filePath string) error {
 	f, err := os.Create(filePath)
@@ -200,45 +780,55 @@ func SaveReportText(report *CoverageReport, filePath string) error {
 	}
 	defer f.Close()
 
-	// Redirect stdout to file temporarily
-	oldStdout := os.Stdout
-	os.Stdout = f
-	PrintReport(report)
-	os.Stdout = oldStdout
-
-	return nil
+	if err := PrintReportTo(f, report); err != nil {
+		return err
+	}
+	return f.Close()
 }
 
-// categorizeNodes groups nodes by their category.
-func categorizeNodes(nodes []string) map[string][]string {
-	categories := make(map[string][]string)
-
-	for _, node := range nodes {
-		category := "Other"
-		if strings.Contains(node, "Expr") {
-			category = "Expression Nodes"
-		} else if strings.Contains(node, "Stmt") {
-			category = "Statement Nodes"
-		} else if strings.Contains(node, "Decl") {
-			category = "Declaration Nodes"
-		} else if strings.Contains(node, "Spec") {
-			category = "Spec Nodes"
-		} else if strings.Contains(node, "Type") && !strings.Contains(node, "TypeAssert") {
-			category = "Type Nodes"
-		} else if strings.Contains(node, "Comment") || strings.Contains(node, "Field") {
-			category = "Structural Nodes"
-		} else if strings.Contains(node, "File") || strings.Contains(node, "Package") {
-			category = "Top-Level Nodes"
+// computeFileContributions fills in each file's CoveragePercent and
+// NewTypesContributed, walking files in slice order.
+func computeFileContributions(files []*FileReport, totalNodeTypes int) {
+	seen := make(map[string]bool)
+	for _, fr := range files {
+		if totalNodeTypes > 0 {
+			fr.CoveragePercent = float64(fr.UniqueTypes) / float64(totalNodeTypes) * 100
+		}
+		fr.NewTypesContributed = 0
+		for _, nodeType := range fr.NodeTypes {
+			if !seen[nodeType] {
+				seen[nodeType] = true
+				fr.NewTypesContributed++
+			}
 		}
-		categories[category] = append(categories[category], node)
 	}
+}
 
-	// Sort within categories
-	for _, nodeList := range categories {
-		sort.Strings(nodeList)
+// computeCategoryCoverage counts covered and total node types per category,
+// sorted by category name. Categories with no covered types are included.
+func computeCategoryCoverage(allNodeTypes, coveredNodes []string) []CategoryCoverage {
+	covered := make(map[string]bool, len(coveredNodes))
+	for _, node := range coveredNodes {
+		covered[node] = true
 	}
 
-	return categories
+	categories := categorizeNodes(allNodeTypes)
+	names := sortedCategories(categories)
+
+	result := make([]CategoryCoverage, 0, len(names))
+	for _, name := range names {
+		cat := CategoryCoverage{Name: name, Total: len(categories[name])}
+		for _, node := range categories[name] {
+			if covered[node] {
+				cat.Covered++
+			}
+		}
+		if cat.Total > 0 {
+			cat.Percent = float64(cat.Covered) / float64(cat.Total) * 100
+		}
+		result = append(result, cat)
+	}
+	return result
 }
 
 // getBaseName extracts the file name from a path.
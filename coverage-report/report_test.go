@@ -0,0 +1,344 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"zylisp/go-ast-coverage/analyzer"
+)
+
+// TestComputeFileContributions tests per-file coverage and new type counts
+func TestComputeFileContributions(t *testing.T) {
+	rep := sampleReport()
+	computeFileContributions(rep.FileReports, rep.TotalNodeTypes)
+
+	first, second := rep.FileReports[0], rep.FileReports[1]
+	if first.CoveragePercent != 50 || second.CoveragePercent != 50 {
+		t.Errorf("expected 50%% per file, got %.2f and %.2f", first.CoveragePercent, second.CoveragePercent)
+	}
+	if first.NewTypesContributed != 3 {
+		t.Errorf("expected first file to contribute 3 types, got %d", first.NewTypesContributed)
+	}
+	// Only *ast.BinaryExpr is new in the second file
+	if second.NewTypesContributed != 1 {
+		t.Errorf("expected second file to contribute 1 type, got %d", second.NewTypesContributed)
+	}
+
+	data, err := json.Marshal(first)
+	if err != nil {
+		t.Fatalf("failed to marshal file report: %v", err)
+	}
+	var fields map[string]interface{}
+	json.Unmarshal(data, &fields)
+	for _, name := range []string{"CoveragePercent", "NewTypesContributed"} {
+		if _, ok := fields[name]; !ok {
+			t.Errorf("expected JSON to include %s", name)
+		}
+	}
+}
+
+// TestReportJSONRoundTrip tests saving and reloading a generated report
+func TestReportJSONRoundTrip(t *testing.T) {
+	rep, err := GenerateReport("../nodes/go")
+	if err != nil {
+		t.Fatalf("GenerateReport failed: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "report.json")
+	if err := SaveReportJSON(rep, path); err != nil {
+		t.Fatalf("SaveReportJSON failed: %v", err)
+	}
+	loaded, err := LoadReportJSON(path)
+	if err != nil {
+		t.Fatalf("LoadReportJSON failed: %v", err)
+	}
+	if !reflect.DeepEqual(rep, loaded) {
+		t.Error("reloaded report differs from the original")
+	}
+
+	data, _ := os.ReadFile(path)
+	want := `"GeneratedAt": "` + rep.GeneratedAt.Format(time.RFC3339) + `"`
+	if !strings.Contains(string(data), want) {
+		t.Errorf("expected RFC3339 timestamp %s", want)
+	}
+}
+
+// TestLoadReportJSONStrict tests missing critical fields and null lists
+func TestLoadReportJSONStrict(t *testing.T) {
+	dir := t.TempDir()
+
+	missing := filepath.Join(dir, "missing.json")
+	os.WriteFile(missing, []byte(`{"GeneratedAt": "2024-01-02T03:04:05Z", "CoveredNodes": [], "MissingNodes": []}`), 0644)
+	if _, err := LoadReportJSON(missing); err == nil || !strings.Contains(err.Error(), "TotalNodeTypes") {
+		t.Errorf("expected missing TotalNodeTypes error, got %v", err)
+	}
+
+	nulls := filepath.Join(dir, "nulls.json")
+	os.WriteFile(nulls, []byte(`{"GeneratedAt": "2024-01-02T03:04:05Z", "TotalNodeTypes": 6, "CoveredNodes": null, "MissingNodes": null, "FileReports": [{"FileName": "a.go"}], "FutureField": 1}`), 0644)
+	rep, err := LoadReportJSON(nulls)
+	if err != nil {
+		t.Fatalf("LoadReportJSON failed: %v", err)
+	}
+	if rep.CoveredNodes == nil || rep.MissingNodes == nil || rep.FileReports[0].NodeTypes == nil {
+		t.Error("expected null lists to load as empty slices")
+	}
+}
+
+// TestComputeCategoryCoverage tests per-category totals and percentages
+func TestComputeCategoryCoverage(t *testing.T) {
+	all := []string{"*ast.BinaryExpr", "*ast.CallExpr", "*ast.GoStmt", "*ast.ReturnStmt", "*ast.DeferStmt", "*ast.ImportSpec"}
+	covered := []string{"*ast.BinaryExpr", "*ast.CallExpr", "*ast.ReturnStmt"}
+
+	got := computeCategoryCoverage(all, covered)
+	want := []CategoryCoverage{
+		{Name: "Expression Nodes", Covered: 2, Total: 2, Percent: 100},
+		{Name: "Spec Nodes", Covered: 0, Total: 1, Percent: 0},
+		{Name: "Statement Nodes", Covered: 1, Total: 3, Percent: float64(1) / 3 * 100},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+// TestNodeCategories tests that every known node type is mapped exactly once
+func TestNodeCategories(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, node := range analyzer.GetAllNodeTypes() {
+		if seen[node] {
+			t.Errorf("%s listed more than once", node)
+		}
+		seen[node] = true
+		if _, ok := nodeCategories[node]; !ok {
+			t.Errorf("%s has no category", node)
+		}
+	}
+	for node := range nodeCategories {
+		if !seen[node] {
+			t.Errorf("%s is mapped but not a known node type", node)
+		}
+	}
+
+	for _, node := range []string{"*ast.FutureExpr", "*ast.Mystery"} {
+		if got := categorizeNode(node); got != categoryUncategorized {
+			t.Errorf("expected %s to be Uncategorized, got %s", node, got)
+		}
+	}
+}
+
+// failingWriter fails every write
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+// TestPrintReportTo tests rendering the text report to a writer
+func TestPrintReportTo(t *testing.T) {
+	var buf bytes.Buffer
+	if err := PrintReportTo(&buf, sampleReport()); err != nil {
+		t.Fatalf("PrintReportTo failed: %v", err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{
+		"GO AST COVERAGE REPORT\n",
+		"Generated: 2024-01-02T03:04:05Z\n",
+		"Total AST Node Types:    6\n",
+		"Covered Node Types:      4\n",
+		"Missing Node Types:      2\n",
+		"Coverage:                66.67%\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("report missing %q", want)
+		}
+	}
+
+	if err := PrintReportTo(failingWriter{}, sampleReport()); err == nil || !strings.Contains(err.Error(), "disk full") {
+		t.Errorf("expected write error, got %v", err)
+	}
+}
+
+// TestAttribution tests per-node-type file attribution and single-source types
+func TestAttribution(t *testing.T) {
+	results := []*analyzer.AnalysisResult{
+		{FileName: "nodes/go/b.go", NodeCounts: map[string]int{"*ast.File": 1, "*ast.GoStmt": 2}},
+		{FileName: "nodes/go/a.go", NodeCounts: map[string]int{"*ast.File": 1, "*ast.Ident": 5}},
+	}
+	rep := &CoverageReport{Attribution: computeAttribution(results)}
+
+	if got := rep.Attribution["*ast.File"]; !reflect.DeepEqual(got, []string{"a.go", "b.go"}) {
+		t.Errorf("expected File in a.go and b.go, got %v", got)
+	}
+	if got := singleSourceNodes(rep); !reflect.DeepEqual(got, []string{"*ast.GoStmt", "*ast.Ident"}) {
+		t.Errorf("unexpected single-source nodes %v", got)
+	}
+
+	var buf bytes.Buffer
+	PrintReportTo(&buf, rep)
+	if !strings.Contains(buf.String(), "only in b.go") {
+		t.Errorf("expected single-source warning in text report")
+	}
+}
+
+// TestGenerateReportForVersion tests that node types newer than the
+// version profile are not expected
+func TestGenerateReportForVersion(t *testing.T) {
+	dir := t.TempDir()
+	source := "package tiny\n\nfunc Hello() string { return \"hello\" }\n"
+	if err := os.WriteFile(filepath.Join(dir, "tiny.go"), []byte(source), 0644); err != nil {
+		t.Fatalf("failed to write source: %v", err)
+	}
+
+	go117, err := GenerateReportForVersion(dir, "go1.17")
+	if err != nil {
+		t.Fatalf("GenerateReportForVersion failed: %v", err)
+	}
+	go118, err := GenerateReportForVersion(dir, "go1.18.3")
+	if err != nil {
+		t.Fatalf("GenerateReportForVersion failed: %v", err)
+	}
+
+	if go117.GoVersion != "go1.17" || go117.TotalNodeTypes != go118.TotalNodeTypes-1 {
+		t.Errorf("expected go1.17 to expect one type fewer, got %d and %d", go117.TotalNodeTypes, go118.TotalNodeTypes)
+	}
+	if CheckCoverage(go117, []string{"*ast.IndexListExpr"}) || contains(go117.MissingNodes, "*ast.IndexListExpr") {
+		t.Error("expected IndexListExpr to be excluded under go1.17")
+	}
+	if !contains(go118.MissingNodes, "*ast.IndexListExpr") || go118.MissingSince["*ast.IndexListExpr"] != "go1.18" {
+		t.Errorf("expected IndexListExpr missing since go1.18, got %q", go118.MissingSince["*ast.IndexListExpr"])
+	}
+	if go118.MissingSince["*ast.GoStmt"] != "go1" {
+		t.Errorf("expected GoStmt missing since go1, got %q", go118.MissingSince["*ast.GoStmt"])
+	}
+
+	var buf bytes.Buffer
+	PrintReportTo(&buf, go118)
+	if !strings.Contains(buf.String(), "✗ *ast.IndexListExpr (go1.18)\n") || !strings.Contains(buf.String(), "Go Version Profile:      go1.18.3\n") {
+		t.Error("expected the text report to show the profile and annotate IndexListExpr")
+	}
+
+	if _, err := GenerateReportForVersion(dir, "1.18"); err == nil {
+		t.Error("expected error for an invalid version")
+	}
+}
+
+// contains reports whether nodes holds nodeType.
+func contains(nodes []string, nodeType string) bool {
+	for _, node := range nodes {
+		if node == nodeType {
+			return true
+		}
+	}
+	return false
+}
+
+// TestIncludeRawAnalysis tests embedding the raw analysis results and that
+// reports without them stay the same size
+func TestIncludeRawAnalysis(t *testing.T) {
+	plain, err := GenerateReport("../nodes/go")
+	if err != nil {
+		t.Fatalf("GenerateReport failed: %v", err)
+	}
+	raw, err := GenerateReportWithOptions("../nodes/go", ReportOptions{IncludeRawAnalysis: true})
+	if err != nil {
+		t.Fatalf("GenerateReportWithOptions failed: %v", err)
+	}
+
+	if raw.Analysis == nil || len(raw.Analysis.Files) != len(raw.FileReports) {
+		t.Fatalf("expected analysis for every file, got %+v", raw.Analysis)
+	}
+	if raw.Analysis.Aggregated.TotalNodes != raw.Meta.TotalNodes {
+		t.Errorf("aggregated TotalNodes = %d, want %d", raw.Analysis.Aggregated.TotalNodes, raw.Meta.TotalNodes)
+	}
+
+	opts := JSONOptions{Deterministic: true}
+	dir := t.TempDir()
+	plainPath, rawPath := filepath.Join(dir, "plain.json"), filepath.Join(dir, "raw.json")
+	if err := SaveReportJSONWithOptions(plain, plainPath, opts); err != nil {
+		t.Fatalf("SaveReportJSONWithOptions failed: %v", err)
+	}
+	if err := SaveReportJSONWithOptions(raw, rawPath, opts); err != nil {
+		t.Fatalf("SaveReportJSONWithOptions failed: %v", err)
+	}
+
+	plainData, _ := os.ReadFile(plainPath)
+	rawData, _ := os.ReadFile(rawPath)
+	if bytes.Contains(plainData, []byte(`"analysis"`)) || !bytes.Contains(rawData, []byte(`"analysis"`)) {
+		t.Errorf("expected the analysis key only with IncludeRawAnalysis")
+	}
+
+	for _, path := range []string{plainPath, rawPath} {
+		loaded, err := LoadReportJSON(path)
+		if err != nil {
+			t.Fatalf("LoadReportJSON(%s) failed: %v", filepath.Base(path), err)
+		}
+		if (loaded.Analysis != nil) != (path == rawPath) {
+			t.Errorf("%s: unexpected analysis %v", filepath.Base(path), loaded.Analysis != nil)
+		}
+	}
+
+	// Without the analysis the two reports must be byte-identical
+	raw.Analysis = nil
+	if err := SaveReportJSONWithOptions(raw, rawPath, opts); err != nil {
+		t.Fatalf("SaveReportJSONWithOptions failed: %v", err)
+	}
+	strippedData, _ := os.ReadFile(rawPath)
+	if !bytes.Equal(strippedData, plainData) {
+		t.Errorf("stripped JSON is %d bytes, want %d", len(strippedData), len(plainData))
+	}
+}
+
+// TestGenerateReportSkippedFiles tests that a file that fails to parse is
+// named in the report while the rest still count
+func TestGenerateReportSkippedFiles(t *testing.T) {
+	dir := t.TempDir()
+	good, err := os.ReadFile("../nodes/go/statements.go")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "statements.go"), good, 0644); err != nil {
+		t.Fatalf("failed to write fixture: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "broken.go"), []byte("package main\n\nfunc {\n"), 0644); err != nil {
+		t.Fatalf("failed to write fixture: %v", err)
+	}
+
+	rep, err := GenerateReport(dir)
+	if err != nil {
+		t.Fatalf("GenerateReport failed: %v", err)
+	}
+	if len(rep.FileReports) != 1 || rep.CoveredNodeTypes == 0 {
+		t.Errorf("expected the good file to be covered, got %d files and %d types", len(rep.FileReports), rep.CoveredNodeTypes)
+	}
+	if len(rep.SkippedFiles) != 1 || getBaseName(rep.SkippedFiles[0].FileName) != "broken.go" ||
+		!strings.Contains(rep.SkippedFiles[0].Error, "failed to parse file") {
+		t.Fatalf("unexpected SkippedFiles %+v", rep.SkippedFiles)
+	}
+	if rep.Meta.FilesSkipped != 1 {
+		t.Errorf("FilesSkipped = %d, want 1", rep.Meta.FilesSkipped)
+	}
+
+	var buf bytes.Buffer
+	if err := PrintReportTo(&buf, rep); err != nil {
+		t.Fatalf("PrintReportTo failed: %v", err)
+	}
+	out := buf.String()
+	skippedAt, progressAt := strings.Index(out, "SKIPPED FILES (1)"), strings.Index(out, "Progress:")
+	if skippedAt < 0 || skippedAt > progressAt || !strings.Contains(out, "broken.go") {
+		t.Errorf("expected skipped files ahead of the progress bar:\n%s", out)
+	}
+
+	if err := os.Remove(filepath.Join(dir, "statements.go")); err != nil {
+		t.Fatalf("failed to remove fixture: %v", err)
+	}
+	if _, err := GenerateReport(dir); err == nil || !strings.Contains(err.Error(), "broken.go") {
+		t.Errorf("expected total failure naming broken.go, got %v", err)
+	}
+}
@@ -0,0 +1,295 @@
+package report
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// RevisionProvider reports the version control revision a corpus is at:
+// the commit, the branch (empty when detached), and whether tracked files
+// have uncommitted changes.
+type RevisionProvider func() (revision, branch string, dirty bool, err error)
+
+// ErrNoRepository is returned by GitRevision's provider when the directory
+// is not inside a git repository.
+var ErrNoRepository = errors.New("not a git repository")
+
+// ErrDirtyUnknown is returned by GitRevision's provider, along with the
+// revision and branch, when it cannot tell whether the worktree is dirty.
+var ErrDirtyUnknown = errors.New("cannot tell whether the worktree is dirty")
+
+// errUnsupportedIndex is returned by worktreeDirty for repositories whose
+// index it does not parse: index version 4 and SHA-256 object names.
+var errUnsupportedIndex = errors.New("unsupported index")
+
+// GitRevision returns a RevisionProvider for the git repository containing
+// dir. It reads .git/HEAD, the refs, and the index directly rather than
+// running git, so it works in library contexts and without git installed.
+// For ref and index formats it does not read, it falls back to running
+// git rev-parse and git status.
+func GitRevision(dir string) RevisionProvider {
+	return func() (string, string, bool, error) {
+		root, gitDir, err := findGitDir(dir)
+		if err != nil {
+			return "", "", false, err
+		}
+
+		head, err := os.ReadFile(filepath.Join(gitDir, "HEAD"))
+		if err != nil {
+			return "", "", false, fmt.Errorf("failed to read HEAD: %w", err)
+		}
+
+		revision := strings.TrimSpace(string(head))
+		branch := ""
+		if ref, ok := strings.CutPrefix(revision, "ref: "); ok {
+			branch = strings.TrimPrefix(ref, "refs/heads/")
+			if revision, err = resolveRef(gitDir, ref); err != nil {
+				return "", "", false, err
+			}
+			if revision == "" {
+				// Either a branch with no commits yet or refs stored in a
+				// format such as reftable
+				if out, err := runGit(root, "rev-parse", "--verify", "--quiet", "HEAD"); err == nil {
+					revision = out
+					if out, err := runGit(root, "symbolic-ref", "--short", "--quiet", "HEAD"); err == nil {
+						branch = out
+					}
+				}
+			}
+		}
+
+		dirty, err := worktreeDirty(root, gitDir)
+		if errors.Is(err, errUnsupportedIndex) {
+			out, gitErr := runGit(root, "status", "--porcelain", "--untracked-files=no")
+			if gitErr != nil {
+				return revision, branch, false, fmt.Errorf("%w: %v (%v)", ErrDirtyUnknown, err, gitErr)
+			}
+			dirty, err = out != "", nil
+		}
+		if err != nil {
+			return "", "", false, err
+		}
+		return revision, branch, dirty, nil
+	}
+}
+
+// findGitDir walks up from dir to the repository root and returns it
+// along with the git directory, following a .git file to a linked
+// worktree's git directory.
+func findGitDir(dir string) (string, string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to resolve %s: %w", dir, err)
+	}
+
+	for {
+		dotGit := filepath.Join(dir, ".git")
+		if info, err := os.Stat(dotGit); err == nil {
+			if info.IsDir() {
+				return dir, dotGit, nil
+			}
+			data, err := os.ReadFile(dotGit)
+			if err != nil {
+				return "", "", fmt.Errorf("failed to read .git: %w", err)
+			}
+			gitDir, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir: ")
+			if !ok {
+				return "", "", fmt.Errorf("malformed .git file in %s", dir)
+			}
+			if !filepath.IsAbs(gitDir) {
+				gitDir = filepath.Join(dir, gitDir)
+			}
+			return dir, gitDir, nil
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", "", ErrNoRepository
+		}
+		dir = parent
+	}
+}
+
+// gitDirs returns the git directory and, for a linked worktree, the
+// common directory it shares with the main worktree.
+func gitDirs(gitDir string) []string {
+	dirs := []string{gitDir}
+	if common, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		commonDir := strings.TrimSpace(string(common))
+		if !filepath.IsAbs(commonDir) {
+			commonDir = filepath.Join(gitDir, commonDir)
+		}
+		dirs = append(dirs, commonDir)
+	}
+	return dirs
+}
+
+// resolveRef returns the commit a ref points to, looking for a loose ref
+// file and then in packed-refs, in the git directory and then in the
+// common directory shared by linked worktrees. It returns "" when the ref
+// is not found.
+func resolveRef(gitDir, ref string) (string, error) {
+	for _, dir := range gitDirs(gitDir) {
+		if data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(ref))); err == nil {
+			return strings.TrimSpace(string(data)), nil
+		}
+
+		f, err := os.Open(filepath.Join(dir, "packed-refs"))
+		if err != nil {
+			continue
+		}
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			hash, name, ok := strings.Cut(scanner.Text(), " ")
+			if ok && name == ref {
+				f.Close()
+				return hash, nil
+			}
+		}
+		f.Close()
+	}
+
+	// A branch with no commits yet
+	return "", nil
+}
+
+// sha256Repository reports whether the repository's config selects
+// SHA-256 object names.
+func sha256Repository(gitDir string) bool {
+	for _, dir := range gitDirs(gitDir) {
+		data, err := os.ReadFile(filepath.Join(dir, "config"))
+		if err != nil {
+			continue
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			key, value, ok := strings.Cut(line, "=")
+			if ok && strings.EqualFold(strings.TrimSpace(key), "objectformat") {
+				return strings.EqualFold(strings.TrimSpace(value), "sha256")
+			}
+		}
+	}
+	return false
+}
+
+// runGit runs git in dir and returns its trimmed output.
+func runGit(dir string, args ...string) (string, error) {
+	out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s failed: %w", args[0], err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// worktreeDirty reports whether any file tracked in the index differs
+// from the working tree. A file whose size and modification time match its
+// index entry is taken as unchanged, as git does; otherwise its content is
+// hashed and compared. A missing index means nothing is tracked. It
+// returns errUnsupportedIndex for index version 4 and SHA-256
+// repositories.
+func worktreeDirty(root, gitDir string) (bool, error) {
+	if sha256Repository(gitDir) {
+		return false, fmt.Errorf("%w: SHA-256 object names", errUnsupportedIndex)
+	}
+	data, err := os.ReadFile(filepath.Join(gitDir, "index"))
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read index: %w", err)
+	}
+
+	if len(data) < 12 || string(data[:4]) != "DIRC" {
+		return false, fmt.Errorf("failed to read index: bad signature")
+	}
+	version := binary.BigEndian.Uint32(data[4:8])
+	if version != 2 && version != 3 {
+		return false, fmt.Errorf("%w: version %d", errUnsupportedIndex, version)
+	}
+	count := binary.BigEndian.Uint32(data[8:12])
+
+	const fixedLen = 62 // stat fields, hash, and flags
+	off := 12
+	for i := uint32(0); i < count; i++ {
+		if off+fixedLen > len(data) {
+			return false, fmt.Errorf("failed to read index: truncated entry")
+		}
+		entry := data[off:]
+		mtimeSec := int64(binary.BigEndian.Uint32(entry[8:12]))
+		mtimeNsec := int64(binary.BigEndian.Uint32(entry[12:16]))
+		mode := binary.BigEndian.Uint32(entry[24:28])
+		size := int64(binary.BigEndian.Uint32(entry[36:40]))
+		hash := entry[40:60]
+		flags := binary.BigEndian.Uint16(entry[60:62])
+
+		nameStart := fixedLen
+		if version == 3 && flags&0x4000 != 0 {
+			nameStart += 2 // extended flags
+		}
+		nameLen := bytes.IndexByte(entry[nameStart:], 0)
+		if nameLen < 0 {
+			return false, fmt.Errorf("failed to read index: truncated entry")
+		}
+		name := string(entry[nameStart : nameStart+nameLen])
+
+		// Entries are NUL-padded to a multiple of eight bytes
+		entryLen := (nameStart + nameLen + 8) &^ 7
+		off += entryLen
+
+		// Submodules are tracked by commit, not content
+		if mode&0170000 == 0160000 {
+			continue
+		}
+
+		changed, err := fileChanged(filepath.Join(root, filepath.FromSlash(name)), mode, size, mtimeSec, mtimeNsec, hash)
+		if err != nil || changed {
+			return changed, err
+		}
+	}
+
+	return false, nil
+}
+
+// fileChanged reports whether a tracked file differs from its index entry.
+func fileChanged(path string, mode uint32, size, mtimeSec, mtimeNsec int64, hash []byte) (bool, error) {
+	info, err := os.Lstat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return true, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+
+	mtime := info.ModTime()
+	if info.Size() == size && mtime.Unix() == mtimeSec && int64(mtime.Nanosecond()) == mtimeNsec {
+		return false, nil
+	}
+
+	var content []byte
+	if mode&0170000 == 0120000 {
+		target, err := os.Readlink(path)
+		if err != nil {
+			return false, fmt.Errorf("failed to read link %s: %w", path, err)
+		}
+		content = []byte(target)
+	} else if content, err = os.ReadFile(path); err != nil {
+		return false, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	return !bytes.Equal(blobHash(content), hash), nil
+}
+
+// blobHash returns the git object hash of content stored as a blob.
+func blobHash(content []byte) []byte {
+	h := sha1.New()
+	fmt.Fprintf(h, "blob %d\x00", len(content))
+	h.Write(content)
+	return h.Sum(nil)
+}
@@ -0,0 +1,203 @@
+package report
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeFile writes content to dir/name, creating parent directories.
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	path := filepath.Join(dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("failed to create %s: %v", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+}
+
+// writeIndex writes a version 2 git index tracking files with the given
+// contents. Stat fields are left zero, so every file is compared by hash.
+func writeIndex(t *testing.T, path string, files map[string]string, names ...string) {
+	t.Helper()
+	var buf bytes.Buffer
+	buf.WriteString("DIRC")
+	binary.Write(&buf, binary.BigEndian, uint32(2))
+	binary.Write(&buf, binary.BigEndian, uint32(len(names)))
+	for _, name := range names {
+		entry := make([]byte, 62)
+		binary.BigEndian.PutUint32(entry[24:28], 0100644)
+		binary.BigEndian.PutUint32(entry[36:40], uint32(len(files[name])))
+		copy(entry[40:60], blobHash([]byte(files[name])))
+		binary.BigEndian.PutUint16(entry[60:62], uint16(len(name)))
+		entry = append(entry, name...)
+		for len(entry)%8 != 0 || len(entry) == 62+len(name) {
+			entry = append(entry, 0)
+		}
+		buf.Write(entry)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatalf("failed to write index: %v", err)
+	}
+}
+
+// TestGitRevision tests reading the revision, branch, and dirty state from
+// a fixture .git directory
+func TestGitRevision(t *testing.T) {
+	const commit = "0123456789abcdef0123456789abcdef01234567"
+	root := t.TempDir()
+	files := map[string]string{"nodes/a.go": "package a\n", "README.md": "hello\n"}
+	for name, content := range files {
+		writeFile(t, root, name, content)
+	}
+	writeFile(t, root, ".git/HEAD", "ref: refs/heads/main\n")
+	writeFile(t, root, ".git/packed-refs", "# pack-refs with: peeled\n"+commit+" refs/heads/main\n")
+	writeIndex(t, filepath.Join(root, ".git", "index"), files, "README.md", "nodes/a.go")
+
+	provider := GitRevision(filepath.Join(root, "nodes"))
+	revision, branch, dirty, err := provider()
+	if err != nil {
+		t.Fatalf("provider failed: %v", err)
+	}
+	if revision != commit || branch != "main" || dirty {
+		t.Errorf("got %q, %q, dirty %v; want %q, main, clean", revision, branch, dirty, commit)
+	}
+
+	// A loose ref wins over packed-refs, and edits make the tree dirty
+	writeFile(t, root, ".git/refs/heads/main", strings.Repeat("f", 40)+"\n")
+	writeFile(t, root, "nodes/a.go", "package b\n")
+	revision, _, dirty, err = provider()
+	if err != nil {
+		t.Fatalf("provider failed: %v", err)
+	}
+	if revision != strings.Repeat("f", 40) || !dirty {
+		t.Errorf("got %q, dirty %v; want loose ref and dirty", revision, dirty)
+	}
+
+	// Detached HEAD
+	writeFile(t, root, ".git/HEAD", commit+"\n")
+	if revision, branch, _, _ = provider(); revision != commit || branch != "" {
+		t.Errorf("detached HEAD gave %q on %q", revision, branch)
+	}
+
+	if _, _, _, err := GitRevision(t.TempDir())(); !errors.Is(err, ErrNoRepository) && err != nil {
+		t.Errorf("expected ErrNoRepository or a parent repository, got %v", err)
+	}
+}
+
+// TestGitRevisionDirtyUnknown tests that the revision and branch are kept
+// when the index cannot be read and git is unavailable
+func TestGitRevisionDirtyUnknown(t *testing.T) {
+	const commit = "0123456789abcdef0123456789abcdef01234567"
+	t.Setenv("PATH", "")
+	for name, config := range map[string]string{
+		"index v4": "[core]\n\trepositoryformatversion = 0\n",
+		"sha256":   "[core]\n\trepositoryformatversion = 1\n[extensions]\n\tobjectformat = sha256\n",
+	} {
+		root := t.TempDir()
+		writeFile(t, root, ".git/HEAD", "ref: refs/heads/main\n")
+		writeFile(t, root, ".git/refs/heads/main", commit+"\n")
+		writeFile(t, root, ".git/config", config)
+		writeFile(t, root, ".git/index", "DIRC\x00\x00\x00\x04\x00\x00\x00\x00")
+
+		revision, branch, _, err := GitRevision(root)()
+		if !errors.Is(err, ErrDirtyUnknown) {
+			t.Errorf("%s: expected ErrDirtyUnknown, got %v", name, err)
+		}
+		if revision != commit || branch != "main" {
+			t.Errorf("%s: got %q on %q, want %q on main", name, revision, branch, commit)
+		}
+	}
+}
+
+// TestGitRevisionIndexV4 tests falling back to git status for an index
+// version the parser does not read
+func TestGitRevisionIndexV4(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	root := t.TempDir()
+	git := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", root, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		out, err := cmd.Output()
+		if err != nil {
+			t.Fatalf("git %s failed: %v", args[0], err)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	git("init", "--quiet", "--initial-branch=main")
+	writeFile(t, root, "a.go", "package a\n")
+	git("add", "a.go")
+	git("commit", "--quiet", "-m", "initial")
+	git("update-index", "--index-version", "4")
+	commit := git("rev-parse", "HEAD")
+
+	provider := GitRevision(root)
+	revision, branch, dirty, err := provider()
+	if err != nil {
+		t.Fatalf("provider failed: %v", err)
+	}
+	if revision != commit || branch != "main" || dirty {
+		t.Errorf("got %q, %q, dirty %v; want %q, main, clean", revision, branch, dirty, commit)
+	}
+
+	writeFile(t, root, "a.go", "package b\n")
+	if _, _, dirty, err = provider(); err != nil || !dirty {
+		t.Errorf("expected a dirty tree, got dirty %v, %v", dirty, err)
+	}
+}
+
+// TestGenerateReportRevision tests that a revision provider fills the
+// report metadata and header
+func TestGenerateReportRevision(t *testing.T) {
+	rep, err := GenerateReportWithOptions("../nodes/go", ReportOptions{
+		Revision: func() (string, string, bool, error) {
+			return "0123456789abcdef0123456789abcdef01234567", "main", true, nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("GenerateReportWithOptions failed: %v", err)
+	}
+	if rep.Meta.Revision != "0123456789abcdef0123456789abcdef01234567" || rep.Meta.Branch != "main" || !rep.Meta.Dirty {
+		t.Errorf("unexpected revision metadata %+v", rep.Meta)
+	}
+
+	var buf bytes.Buffer
+	if err := PrintReportTo(&buf, rep); err != nil {
+		t.Fatalf("PrintReportTo failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "Revision:  0123456789ab (main, dirty)\n") {
+		t.Errorf("report header missing revision")
+	}
+
+	rep, err = GenerateReportWithOptions("../nodes/go", ReportOptions{
+		Revision: func() (string, string, bool, error) {
+			return "", "", false, ErrNoRepository
+		},
+	})
+	if err != nil || rep.Meta.Revision != "" {
+		t.Errorf("expected an unversioned report, got %v, %q", err, rep.Meta.Revision)
+	}
+
+	var warnings bytes.Buffer
+	rep, err = GenerateReportWithOptions("../nodes/go", ReportOptions{
+		Revision: func() (string, string, bool, error) {
+			return "0123456789abcdef0123456789abcdef01234567", "main", false, ErrDirtyUnknown
+		},
+		Warnings: &warnings,
+	})
+	if err != nil || rep.Meta.Revision == "" || rep.Meta.Branch != "main" {
+		t.Errorf("expected the revision to be kept, got %v, %+v", err, rep.Meta)
+	}
+	if !strings.Contains(warnings.String(), "Warning: failed to read revision: ") {
+		t.Errorf("expected a revision warning, got %q", warnings.String())
+	}
+}
@@ -0,0 +1,202 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// sarifSchema is the JSON schema URI of SARIF 2.1.0.
+const sarifSchema = "https://json.schemastore.org/sarif-2.1.0.json"
+
+// sarifLog is the root object of a SARIF 2.1.0 file.
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+// sarifRun is a single run of the coverage tool.
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+// sarifTool describes the tool that produced a run.
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+// sarifDriver names the tool and lists its rules.
+type sarifDriver struct {
+	Name  string      `json:"name"`
+	Rules []sarifRule `json:"rules"`
+}
+
+// sarifRule describes one node category.
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	Name                 string             `json:"name"`
+	ShortDescription     sarifMessage       `json:"shortDescription"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+}
+
+// sarifConfiguration holds a rule's default severity.
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+// sarifMessage is a plain-text SARIF message.
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+// sarifResult is one missing or regressed node type.
+type sarifResult struct {
+	RuleID     string            `json:"ruleId"`
+	RuleIndex  int               `json:"ruleIndex"`
+	Level      string            `json:"level"`
+	Message    sarifMessage      `json:"message"`
+	Locations  []sarifLocation   `json:"locations,omitempty"`
+	Properties map[string]string `json:"properties"`
+}
+
+// sarifLocation points at the sample file suggested for a node type.
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+// sarifPhysicalLocation names a file.
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+// sarifArtifactLocation is a file URI relative to the repository root.
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+// SARIFOptions configures SARIF output.
+type SARIFOptions struct {
+	// Baseline, if set, adds an error-level result for every node type
+	// it covers that the report does not.
+	Baseline *CoverageReport
+}
+
+// SaveReportSARIF saves the report as SARIF 2.1.0 with a warning-level
+// result for each missing node type.
+func SaveReportSARIF(report *CoverageReport, filePath string) error {
+	return SaveReportSARIFWithOptions(report, filePath, SARIFOptions{})
+}
+
+// SaveReportSARIFWithOptions saves the report as SARIF 2.1.0 with one rule
+// per node category and one result per missing node type. When
+// opts.Baseline is set, the result for a node type the baseline covered is
+// reported as regressed instead. Results point at the suggested sample
+// file when the report has placement suggestions.
+func SaveReportSARIFWithOptions(report *CoverageReport, filePath string, opts SARIFOptions) error {
+	data, err := renderSARIF(report, opts)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(filePath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	return nil
+}
+
+// renderSARIF renders the report as SARIF JSON.
+func renderSARIF(report *CoverageReport, opts SARIFOptions) ([]byte, error) {
+	all := append(append([]string{}, report.CoveredNodes...), report.MissingNodes...)
+	categories := sortedCategories(categorizeNodes(all))
+
+	driver := sarifDriver{Name: "go-ast-coverage", Rules: []sarifRule{}}
+	ruleIndex := make(map[string]int, len(categories))
+	for i, category := range categories {
+		ruleIndex[category] = i
+		driver.Rules = append(driver.Rules, sarifRule{
+			ID:                   sarifRuleID(category),
+			Name:                 strings.ReplaceAll(category, " ", ""),
+			ShortDescription:     sarifMessage{Text: category + " missing from the samples"},
+			DefaultConfiguration: sarifConfiguration{Level: "warning"},
+		})
+	}
+
+	files := make(map[string]string)
+	for _, sg := range report.Suggestions {
+		files[sg.NodeType] = sampleFilePath(report, sg.File)
+	}
+
+	// A regressed node type is also missing; its result replaces the
+	// missing one with the same rule and location rather than repeating it
+	results := []sarifResult{}
+	seen := make(map[string]int)
+	addResult := func(node, level, kind, message string) {
+		category := categorizeNode(node)
+		result := sarifResult{
+			RuleID:     sarifRuleID(category),
+			RuleIndex:  ruleIndex[category],
+			Level:      level,
+			Message:    sarifMessage{Text: message},
+			Properties: map[string]string{"nodeType": node, "kind": kind},
+		}
+		file := files[node]
+		if file != "" {
+			result.Locations = []sarifLocation{{
+				PhysicalLocation: sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: file}},
+			}}
+		}
+		key := result.RuleID + "\x00" + file + "\x00" + node
+		if i, ok := seen[key]; ok {
+			results[i] = result
+			return
+		}
+		seen[key] = len(results)
+		results = append(results, result)
+	}
+
+	for _, node := range report.MissingNodes {
+		addResult(node, "warning", "missing", fmt.Sprintf("%s is not covered by any sample file", node))
+	}
+	if opts.Baseline != nil {
+		for _, node := range DiffReports(opts.Baseline, report).Regressed {
+			addResult(node, "error", "regressed", fmt.Sprintf("%s was covered by the baseline but no longer is", node))
+		}
+	}
+
+	log := sarifLog{
+		Schema:  sarifSchema,
+		Version: "2.1.0",
+		Runs:    []sarifRun{{Tool: sarifTool{Driver: driver}, Results: results}},
+	}
+	data, err := json.MarshalIndent(log, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal report: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+// sarifRuleID derives a rule ID from a category name, e.g.
+// "coverage/expression-nodes".
+func sarifRuleID(category string) string {
+	return "coverage/" + strings.ToLower(strings.ReplaceAll(category, " ", "-"))
+}
+
+// sampleFilePath resolves a suggested sample file name to a slash-separated
+// path: the matching file in the report, or the name inside the report's
+// source directory.
+func sampleFilePath(report *CoverageReport, name string) string {
+	for _, fr := range report.FileReports {
+		if getBaseName(fr.FileName) == name {
+			return path.Clean(filepath.ToSlash(fr.FileName))
+		}
+	}
+	if report.Source != "" {
+		return path.Join(filepath.ToSlash(report.Source), name)
+	}
+	return name
+}
@@ -0,0 +1,111 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestSaveReportSARIF tests that the SARIF output is structurally valid
+// 2.1.0 with one result per missing node type, regressed ones reported
+// once as regressed
+func TestSaveReportSARIF(t *testing.T) {
+	rep := sampleReport()
+	rep.Suggestions = computeSuggestions(rep)
+	baseline := sampleReport()
+	baseline.CoveredNodes = []string{"*ast.File", "*ast.GoStmt"}
+	baseline.MissingNodes = []string{"*ast.BinaryExpr", "*ast.Ident", "*ast.ReturnStmt", "*ast.TypeSpec"}
+
+	path := filepath.Join(t.TempDir(), "coverage.sarif")
+	if err := SaveReportSARIFWithOptions(rep, path, SARIFOptions{Baseline: baseline}); err != nil {
+		t.Fatalf("SaveReportSARIFWithOptions failed: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read report: %v", err)
+	}
+
+	var log struct {
+		Schema  string `json:"$schema"`
+		Version string `json:"version"`
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Name  string `json:"name"`
+					Rules []struct {
+						ID               string `json:"id"`
+						ShortDescription struct {
+							Text string `json:"text"`
+						} `json:"shortDescription"`
+					} `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+			Results []struct {
+				RuleID    string `json:"ruleId"`
+				RuleIndex int    `json:"ruleIndex"`
+				Level     string `json:"level"`
+				Message   struct {
+					Text string `json:"text"`
+				} `json:"message"`
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct {
+							URI string `json:"uri"`
+						} `json:"artifactLocation"`
+					} `json:"physicalLocation"`
+				} `json:"locations"`
+				Properties map[string]string `json:"properties"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal(data, &log); err != nil {
+		t.Fatalf("output is not valid JSON: %v", err)
+	}
+
+	if log.Version != "2.1.0" || log.Schema != sarifSchema || len(log.Runs) != 1 {
+		t.Fatalf("expected a single SARIF 2.1.0 run, got version %q and %d runs", log.Version, len(log.Runs))
+	}
+	run := log.Runs[0]
+	if run.Tool.Driver.Name == "" || len(run.Tool.Driver.Rules) != 4 {
+		t.Errorf("expected a named driver with one rule per category, got %+v", run.Tool.Driver)
+	}
+
+	levels := make(map[string]string)
+	for _, result := range run.Results {
+		if result.RuleIndex < 0 || result.RuleIndex >= len(run.Tool.Driver.Rules) || run.Tool.Driver.Rules[result.RuleIndex].ID != result.RuleID {
+			t.Errorf("result %s has a mismatched rule index %d", result.RuleID, result.RuleIndex)
+		}
+		if result.Message.Text == "" {
+			t.Errorf("result %s has no message", result.RuleID)
+		}
+		levels[result.Properties["kind"]+" "+result.Properties["nodeType"]] = result.Level
+		if len(result.Locations) != 1 || result.Locations[0].PhysicalLocation.ArtifactLocation.URI == "" {
+			t.Errorf("expected result for %s to point at a sample file", result.Properties["nodeType"])
+		}
+	}
+	want := map[string]string{
+		"missing *ast.TypeSpec": "warning",
+		"regressed *ast.GoStmt": "error",
+	}
+	if len(run.Results) != len(want) || len(levels) != len(want) {
+		t.Errorf("got results %v, want %v", levels, want)
+	}
+	for key, level := range want {
+		if levels[key] != level {
+			t.Errorf("%s: got level %q, want %q", key, levels[key], level)
+		}
+	}
+	if uri := run.Results[0].Locations[0].PhysicalLocation.ArtifactLocation.URI; uri != "nodes/go/statements.go" {
+		t.Errorf("expected GoStmt to point at nodes/go/statements.go, got %s", uri)
+	}
+
+	again, err := renderSARIF(rep, SARIFOptions{Baseline: baseline})
+	if err != nil {
+		t.Fatalf("renderSARIF failed: %v", err)
+	}
+	if !bytes.Equal(data, again) {
+		t.Error("expected deterministic output")
+	}
+}
@@ -0,0 +1,91 @@
+package report
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+
+	"zylisp/go-ast-coverage/analyzer"
+)
+
+// CurrentSchemaVersion is the version of the CoverageReport JSON layout
+// written by this package. LoadReportJSON upgrades older reports and
+// rejects newer ones.
+//
+// Changelog:
+//
+//	1: GeneratedAt, totals, CoveredNodes, MissingNodes, and FileReports.
+//	   Reports of this version carry no SchemaVersion field.
+//	2: SchemaVersion, per-file CoveragePercent and NewTypesContributed,
+//	   Categories, Tokens, Variants, Attribution, Suggestions, and
+//	   NodeCounts.
+//	3: GoVersion and MissingSince.
+//	4: Source, FileReport.Source, FirstCoveredBy, and Sources.
+//	5: MissingExamples.
+//	6: TokenKinds and the overall token Percent.
+//	7: Meta.
+//	8: MostCommon and LeastCommon.
+//	9: ExcludedNodes.
+//	10: The optional "analysis" raw analysis results.
+//	11: SkippedFiles.
+const CurrentSchemaVersion = 11
+
+// ErrUnsupportedSchema is returned when a report was written by a newer
+// version of this package.
+var ErrUnsupportedSchema = errors.New("unsupported report schema version")
+
+// upgradeReport brings a decoded report up to CurrentSchemaVersion,
+// deriving what it can from the fields older versions stored. Token,
+// token kind, variant, and node count data cannot be derived and stay
+// empty.
+func upgradeReport(report *CoverageReport) error {
+	if report.SchemaVersion == 0 {
+		report.SchemaVersion = 1
+	}
+	if report.SchemaVersion > CurrentSchemaVersion {
+		return fmt.Errorf("%w: %d (newest supported is %d)", ErrUnsupportedSchema, report.SchemaVersion, CurrentSchemaVersion)
+	}
+
+	if report.SchemaVersion < 2 {
+		if report.CoveragePercent == 0 {
+			report.CoveragePercent = reportPercent(report)
+		}
+		all := append(append([]string{}, report.CoveredNodes...), report.MissingNodes...)
+		report.Categories = computeCategoryCoverage(all, report.CoveredNodes)
+		computeFileContributions(report.FileReports, report.TotalNodeTypes)
+
+		report.Attribution = make(map[string][]string)
+		for _, fr := range report.FileReports {
+			for _, nodeType := range fr.NodeTypes {
+				report.Attribution[nodeType] = append(report.Attribution[nodeType], getBaseName(fr.FileName))
+			}
+		}
+		for _, files := range report.Attribution {
+			sort.Strings(files)
+		}
+	}
+
+	if report.SchemaVersion < 3 {
+		// Older reports always expected every node type
+		report.GoVersion = analyzer.LatestGoVersion
+		report.MissingSince = missingSince(report.MissingNodes)
+	}
+
+	if report.SchemaVersion < 5 {
+		report.MissingExamples = computeMissingExamples(report.MissingNodes, os.Stderr)
+	}
+
+	// Suggestions take their snippets from the examples
+	if report.SchemaVersion < 2 {
+		report.Suggestions = computeSuggestions(report)
+	}
+
+	if report.SchemaVersion < 8 {
+		report.MostCommon = MostCommonNodes(report.NodeCounts, DefaultTopN)
+		report.LeastCommon = LeastCommonNodes(report.NodeCounts, DefaultTopN)
+	}
+
+	report.SchemaVersion = CurrentSchemaVersion
+	return nil
+}
@@ -0,0 +1,70 @@
+package report
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestLoadReportSchemaVersions tests loading and comparing reports across
+// schema versions
+func TestLoadReportSchemaVersions(t *testing.T) {
+	v1, err := LoadReportJSON(filepath.Join("testdata", "report_v1.json"))
+	if err != nil {
+		t.Fatalf("failed to load v1 report: %v", err)
+	}
+	v2, err := LoadReportJSON(filepath.Join("testdata", "report_v2.json"))
+	if err != nil {
+		t.Fatalf("failed to load v2 report: %v", err)
+	}
+
+	if v1.SchemaVersion != CurrentSchemaVersion {
+		t.Errorf("expected v1 report to be upgraded, got version %d", v1.SchemaVersion)
+	}
+	if len(v1.Categories) != 4 || v1.FileReports[0].CoveragePercent != 50 {
+		t.Errorf("expected upgrade to derive categories and per-file coverage, got %+v", v1.Categories)
+	}
+	if v1.GoVersion != "latest" || v1.MissingSince["*ast.GoStmt"] != "go1" {
+		t.Errorf("expected upgrade to derive the version profile, got %q and %v", v1.GoVersion, v1.MissingSince)
+	}
+	if files := v1.Attribution["*ast.File"]; len(files) != 2 {
+		t.Errorf("expected upgrade to derive attribution, got %v", files)
+	}
+	if len(v1.Suggestions) != 2 {
+		t.Errorf("expected upgrade to derive suggestions, got %v", v1.Suggestions)
+	}
+	for _, sg := range v1.Suggestions {
+		if sg.Snippet == "" || sg.Snippet != v1.MissingExamples[sg.NodeType] {
+			t.Errorf("expected %s's suggestion to use its example, got %q", sg.NodeType, sg.Snippet)
+		}
+	}
+
+	diff := DiffReports(v1, v2)
+	if len(diff.NewlyCovered) != 1 || diff.NewlyCovered[0] != "*ast.GoStmt" {
+		t.Errorf("expected GoStmt to be newly covered across versions, got %v", diff.NewlyCovered)
+	}
+	if len(diff.FileChanges) != 1 || diff.FileChanges[0].NewNodeCount != 48 {
+		t.Errorf("unexpected file changes %v", diff.FileChanges)
+	}
+
+	if err := CheckThreshold(v1, 70, nil); !errors.Is(err, ErrBelowThreshold) {
+		t.Errorf("expected v1 report below 70%%, got %v", err)
+	}
+	if err := CheckThreshold(v2, 70, []string{"GoStmt"}); err != nil {
+		t.Errorf("expected v2 report to pass, got %v", err)
+	}
+}
+
+// TestLoadReportFutureSchema tests that newer schema versions are rejected
+func TestLoadReportFutureSchema(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "future.json")
+	data := `{"SchemaVersion": 99, "GeneratedAt": "2030-01-01T00:00:00Z", "TotalNodeTypes": 60, "CoveredNodes": [], "MissingNodes": []}`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatalf("failed to write report: %v", err)
+	}
+
+	if _, err := LoadReportJSON(path); !errors.Is(err, ErrUnsupportedSchema) {
+		t.Errorf("expected ErrUnsupportedSchema, got %v", err)
+	}
+}
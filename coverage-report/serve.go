@@ -0,0 +1,114 @@
+package report
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// serveCacheTTL is how long Serve reuses a generated report.
+const serveCacheTTL = 5 * time.Second
+
+// Serve's timeouts. Writes allow for regenerating the report, which
+// analyzes the whole corpus.
+const (
+	serveReadHeaderTimeout = 10 * time.Second
+	serveReadTimeout       = 30 * time.Second
+	serveWriteTimeout      = 2 * time.Minute
+	serveIdleTimeout       = 2 * time.Minute
+)
+
+// reportCache regenerates a report through a provider at most once per ttl.
+type reportCache struct {
+	provider func() (*CoverageReport, error)
+	ttl      time.Duration
+	now      func() time.Time
+
+	mu      sync.Mutex
+	report  *CoverageReport
+	fetched time.Time
+}
+
+// get returns the cached report, regenerating it once the ttl has passed.
+// Provider errors are not cached.
+func (c *reportCache) get() (*CoverageReport, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.report != nil && c.now().Sub(c.fetched) < c.ttl {
+		return c.report, nil
+	}
+	report, err := c.provider()
+	if err != nil {
+		return nil, err
+	}
+	c.report, c.fetched = report, c.now()
+	return report, nil
+}
+
+// Serve serves the report over HTTP at addr, regenerating it through
+// provider when a request arrives more than a few seconds after the last
+// regeneration:
+//
+//	/             the HTML report
+//	/report.html  the HTML report
+//	/json         the JSON report
+//	/badge.svg    a coverage badge
+func Serve(addr string, provider func() (*CoverageReport, error)) error {
+	handler := newServeHandler(&reportCache{provider: provider, ttl: serveCacheTTL, now: time.Now})
+	if err := newServer(addr, handler).ListenAndServe(); err != nil {
+		return fmt.Errorf("failed to serve: %w", err)
+	}
+	return nil
+}
+
+// newServer returns the server Serve runs, with timeouts so that a slow
+// or idle client cannot hold a connection open indefinitely.
+func newServer(addr string, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: serveReadHeaderTimeout,
+		ReadTimeout:       serveReadTimeout,
+		WriteTimeout:      serveWriteTimeout,
+		IdleTimeout:       serveIdleTimeout,
+	}
+}
+
+// newServeHandler routes the Serve endpoints to renderers over cache.
+func newServeHandler(cache *reportCache) http.Handler {
+	render := func(contentType string, renderer func(*CoverageReport) ([]byte, error)) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			report, err := cache.get()
+			if err != nil {
+				http.Error(w, fmt.Sprintf("failed to generate report: %v", err), http.StatusInternalServerError)
+				return
+			}
+			data, err := renderer(report)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			w.Header().Set("Content-Type", contentType)
+			w.Header().Set("Cache-Control", "no-cache")
+			w.Write(data)
+		}
+	}
+
+	html := render("text/html; charset=utf-8", RenderHTML)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		html(w, r)
+	})
+	mux.Handle("/report.html", html)
+	mux.Handle("/json", render("application/json", renderJSON))
+	mux.Handle("/badge.svg", render("image/svg+xml", func(report *CoverageReport) ([]byte, error) {
+		return RenderBadge(report), nil
+	}))
+	return mux
+}
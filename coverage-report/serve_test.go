@@ -0,0 +1,110 @@
+package report
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestServeHandler tests each endpoint's status and content type
+func TestServeHandler(t *testing.T) {
+	cache := &reportCache{
+		provider: func() (*CoverageReport, error) { return sampleReport(), nil },
+		ttl:      time.Minute,
+		now:      time.Now,
+	}
+	server := httptest.NewServer(newServeHandler(cache))
+	defer server.Close()
+
+	tests := []struct {
+		path        string
+		status      int
+		contentType string
+		body        string
+	}{
+		{"/", http.StatusOK, "text/html; charset=utf-8", "<h1>Go AST Coverage Report</h1>"},
+		{"/report.html", http.StatusOK, "text/html; charset=utf-8", "<h1>Go AST Coverage Report</h1>"},
+		{"/json", http.StatusOK, "application/json", `"CoveredNodeTypes": 4`},
+		{"/badge.svg", http.StatusOK, "image/svg+xml", "66.7%"},
+		{"/missing", http.StatusNotFound, "text/plain; charset=utf-8", "404"},
+	}
+	for _, tt := range tests {
+		resp, err := http.Get(server.URL + tt.path)
+		if err != nil {
+			t.Fatalf("GET %s failed: %v", tt.path, err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", tt.path, err)
+		}
+
+		if resp.StatusCode != tt.status {
+			t.Errorf("%s: got status %d, want %d", tt.path, resp.StatusCode, tt.status)
+		}
+		if got := resp.Header.Get("Content-Type"); got != tt.contentType {
+			t.Errorf("%s: got content type %q, want %q", tt.path, got, tt.contentType)
+		}
+		if !strings.Contains(string(body), tt.body) {
+			t.Errorf("%s: body missing %q", tt.path, tt.body)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	newServeHandler(cache).ServeHTTP(rec, httptest.NewRequest("GET", "/json", nil))
+	var rep CoverageReport
+	if err := json.Unmarshal(rec.Body.Bytes(), &rep); err != nil || rep.TotalNodeTypes != 6 {
+		t.Errorf("expected /json to decode as a report, got %v", err)
+	}
+}
+
+// TestServeCache tests that reports are regenerated only after the TTL
+// and that provider errors surface as server errors
+func TestServeCache(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	calls := 0
+	var failure error
+	cache := &reportCache{
+		provider: func() (*CoverageReport, error) {
+			calls++
+			return sampleReport(), failure
+		},
+		ttl: 5 * time.Second,
+		now: func() time.Time { return now },
+	}
+	handler := newServeHandler(cache)
+	get := func() int {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest("GET", "/badge.svg", nil))
+		return rec.Code
+	}
+
+	get()
+	now = now.Add(4 * time.Second)
+	get()
+	if calls != 1 {
+		t.Errorf("expected one regeneration within the TTL, got %d", calls)
+	}
+
+	now = now.Add(2 * time.Second)
+	failure = errors.New("corpus unreadable")
+	if code := get(); code != http.StatusInternalServerError || calls != 2 {
+		t.Errorf("expected a fresh failing regeneration, got status %d after %d calls", code, calls)
+	}
+}
+
+// TestNewServer tests that the server Serve runs sets every timeout
+func TestNewServer(t *testing.T) {
+	server := newServer(":0", http.NotFoundHandler())
+	if server.Addr != ":0" || server.Handler == nil {
+		t.Errorf("unexpected server %+v", server)
+	}
+	if server.ReadHeaderTimeout <= 0 || server.ReadTimeout <= 0 || server.WriteTimeout <= 0 || server.IdleTimeout <= 0 {
+		t.Errorf("expected every timeout to be set, got %+v", server)
+	}
+}
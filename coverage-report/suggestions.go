@@ -0,0 +1,113 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"zylisp/go-ast-coverage/analyzer"
+)
+
+// MissingNodeSuggestion suggests where and how to cover a missing node type.
+type MissingNodeSuggestion struct {
+	NodeType string
+	Category string
+
+	// File is the sample file that is thematically closest, or the
+	// conventional file name for the category if no file is close.
+	File string
+
+	// Snippet is a minimal top-level declaration producing the node type,
+	// or empty if it cannot be produced by valid source.
+	Snippet string
+}
+
+// categoryFiles are the conventional sample file names for each category,
+// used to break ties and when no existing file is related.
+var categoryFiles = map[string]string{
+	categoryExpression:  "expressions.go",
+	categoryStatement:   "statements.go",
+	categoryDeclaration: "declarations.go",
+	categorySpec:        "declarations.go",
+	categoryType:        "types.go",
+	categoryStructural:  "comments.go",
+	categoryTopLevel:    "declarations.go",
+}
+
+// computeSuggestions returns a suggestion for every missing node type,
+// taking snippets from the report's already validated MissingExamples.
+func computeSuggestions(report *CoverageReport) []MissingNodeSuggestion {
+	suggestions := []MissingNodeSuggestion{}
+	for _, node := range report.MissingNodes {
+		category := categorizeNode(node)
+		suggestions = append(suggestions, MissingNodeSuggestion{
+			NodeType: node,
+			Category: category,
+			File:     closestFile(report.FileReports, category),
+			Snippet:  report.MissingExamples[node],
+		})
+	}
+	return suggestions
+}
+
+// computeMissingExamples maps each missing node type to the analyzer's
+// snippet for it, after checking that the snippet really produces the node
+// type. A snippet that does not is dropped rather than shown as advice,
+// with a warning to warnings.
+func computeMissingExamples(missingNodes []string, warnings io.Writer) map[string]string {
+	examples := make(map[string]string)
+	for _, node := range missingNodes {
+		snippet, ok := analyzer.SuggestSnippet(node)
+		if !ok {
+			continue
+		}
+		if err := analyzer.ValidateSnippet(node, snippet); err != nil {
+			fmt.Fprintf(warnings, "Warning: skipping invalid example: %v\n", err)
+			continue
+		}
+		examples[node] = snippet
+	}
+	return examples
+}
+
+// closestFile picks the file containing the most node types from category,
+// preferring the category's conventional file name and then the first name
+// in sorted order.
+func closestFile(files []*FileReport, category string) string {
+	conventional := categoryFiles[category]
+	stem := strings.TrimSuffix(conventional, "s.go")
+
+	type candidate struct {
+		name  string
+		score int
+	}
+	var candidates []candidate
+	for _, fr := range files {
+		name := getBaseName(fr.FileName)
+		score := 0
+		for _, node := range fr.NodeTypes {
+			if categorizeNode(node) == category {
+				score += 2
+			}
+		}
+		if stem != "" && strings.Contains(name, stem) {
+			score++
+		}
+		candidates = append(candidates, candidate{name, score})
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].score != candidates[j].score {
+			return candidates[i].score > candidates[j].score
+		}
+		return candidates[i].name < candidates[j].name
+	})
+
+	if len(candidates) == 0 || candidates[0].score == 0 {
+		if conventional == "" {
+			return "edge_cases.go"
+		}
+		return conventional
+	}
+	return candidates[0].name
+}
@@ -0,0 +1,89 @@
+package report
+
+import (
+	"bytes"
+	"go/parser"
+	"go/token"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"zylisp/go-ast-coverage/analyzer"
+)
+
+// TestComputeSuggestions tests that suggestions name a plausible file and
+// a snippet that parses into the missing node type
+func TestComputeSuggestions(t *testing.T) {
+	rep := sampleReport()
+	rep.MissingNodes = append(rep.MissingNodes, "*ast.BadExpr")
+
+	suggestions := computeSuggestions(rep)
+	if len(suggestions) != 3 {
+		t.Fatalf("expected 3 suggestions, got %d", len(suggestions))
+	}
+
+	wantFiles := map[string]string{
+		"*ast.GoStmt":   "statements.go",
+		"*ast.TypeSpec": "declarations.go",
+		"*ast.BadExpr":  "expressions.go",
+	}
+	for _, sg := range suggestions {
+		if sg.File != wantFiles[sg.NodeType] {
+			t.Errorf("%s: suggested %s, want %s", sg.NodeType, sg.File, wantFiles[sg.NodeType])
+		}
+		if sg.NodeType == "*ast.BadExpr" {
+			if sg.Snippet != "" {
+				t.Errorf("expected no snippet for BadExpr, got %q", sg.Snippet)
+			}
+			continue
+		}
+
+		file, err := parser.ParseFile(token.NewFileSet(), "snippet.go", "package p\n\n"+sg.Snippet+"\n", parser.ParseComments)
+		if err != nil {
+			t.Errorf("%s: snippet does not parse: %v", sg.NodeType, err)
+			continue
+		}
+		if counts, _ := analyzer.CountNodes(file); counts[sg.NodeType] == 0 {
+			t.Errorf("%s: snippet does not produce the node type", sg.NodeType)
+		}
+	}
+}
+
+// TestSuggestSnippets tests that every snippet produces its node type
+func TestSuggestSnippets(t *testing.T) {
+	for _, nodeType := range analyzer.GetAllNodeTypes() {
+		snippet, ok := analyzer.SuggestSnippet(nodeType)
+		if !ok {
+			continue
+		}
+		if err := analyzer.ValidateSnippet(nodeType, snippet); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+// TestMissingExamples tests that missing node types carry validated
+// examples in the report and its text rendering
+func TestMissingExamples(t *testing.T) {
+	examples := computeMissingExamples([]string{"*ast.GoStmt", "*ast.BadExpr", "*ast.Package"}, io.Discard)
+	want := map[string]string{"*ast.GoStmt": "func f() { go g() }"}
+	if !reflect.DeepEqual(examples, want) {
+		t.Errorf("got %v, want %v", examples, want)
+	}
+
+	if err := analyzer.ValidateSnippet("*ast.GoStmt", "func f() { g() }"); err == nil {
+		t.Error("expected error for a snippet without the node type")
+	}
+	if err := analyzer.ValidateSnippet("*ast.GoStmt", "func f() { go }"); err == nil {
+		t.Error("expected error for a snippet that does not parse")
+	}
+
+	var buf bytes.Buffer
+	if err := PrintReportTo(&buf, sampleReport()); err != nil {
+		t.Fatalf("PrintReportTo failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "  ✗ *ast.GoStmt\n        func f() { go g() }\n") {
+		t.Errorf("expected the example under the missing entry, got:\n%s", buf.String())
+	}
+}
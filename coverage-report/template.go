@@ -0,0 +1,161 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// TemplateData is the data model passed to report templates.
+type TemplateData struct {
+	Summary    TemplateSummary
+	Categories []CategoryCoverage
+	Files      []*FileReport
+	Covered    []string
+	Missing    []string
+	Tokens     TokenCoverage
+	TokenKinds TokenCoverage
+
+	// CoveredByCategory and MissingByCategory group node types by category,
+	// sorted by category name.
+	CoveredByCategory []CategoryNodes
+	MissingByCategory []CategoryNodes
+
+	// Report is the full report, for fields not surfaced above.
+	Report *CoverageReport
+}
+
+// TemplateSummary holds the report's headline numbers.
+type TemplateSummary struct {
+	GeneratedAt time.Time
+	Total       int
+	Covered     int
+	Missing     int
+	Percent     float64
+}
+
+// CategoryNodes is a category name and its node types.
+type CategoryNodes struct {
+	Name  string
+	Nodes []string
+}
+
+// templateFuncs are the helper functions available to report templates:
+//
+//	percent 94.6428   → "94.64%"
+//	repeat "=" 3      → "==="
+//	pluralize 1 "file" "files" → "file"
+//	base "nodes/go/x.go" → "x.go"
+//	bar 94.6 50       → a 50-wide progress bar
+//	pad "abc" 6       → "abc   "
+var templateFuncs = template.FuncMap{
+	"percent": func(p float64) string { return fmt.Sprintf("%.2f%%", p) },
+	"repeat":  strings.Repeat,
+	"pluralize": func(n int, singular, plural string) string {
+		if n == 1 {
+			return singular
+		}
+		return plural
+	},
+	"base": getBaseName,
+	"bar":  renderBar,
+	"pad":  func(s string, width int) string { return fmt.Sprintf("%-*s", width, s) },
+}
+
+// DefaultTemplate renders the core sections of the built-in text report
+// using only the template data model.
+const DefaultTemplate = `{{repeat "=" 80}}
+GO AST COVERAGE REPORT
+{{repeat "=" 80}}
+Generated: {{.Summary.GeneratedAt.Format "2006-01-02T15:04:05Z07:00"}}
+
+SUMMARY
+{{repeat "-" 80}}
+Total AST Node Types:    {{.Summary.Total}}
+Covered Node Types:      {{.Summary.Covered}}
+Missing Node Types:      {{.Summary.Missing}}
+Coverage:                {{percent .Summary.Percent}}
+
+Progress: [{{bar .Summary.Percent 50}}] {{percent .Summary.Percent}}
+
+FILE BREAKDOWN
+{{repeat "-" 80}}
+{{range .Files}}{{pad (base .FileName) 30}}  Nodes: {{printf "%5d" .NodeCount}}  Unique Types: {{printf "%3d" .UniqueTypes}}
+{{end}}
+CATEGORY COVERAGE
+{{repeat "-" 80}}
+{{range .Categories}}{{pad .Name 20}}  {{printf "%3d/%-3d" .Covered .Total}}  {{printf "%6.2f%%" .Percent}}  [{{bar .Percent 20}}]
+{{end}}
+COVERED NODE TYPES BY CATEGORY
+{{repeat "-" 80}}
+{{range .CoveredByCategory}}
+{{.Name}} ({{len .Nodes}}):
+{{range .Nodes}}  ✓ {{.}}
+{{end}}{{end}}
+{{if .Missing}}MISSING NODE TYPES
+{{repeat "-" 80}}
+{{range .MissingByCategory}}
+{{.Name}} ({{len .Nodes}}):
+{{range .Nodes}}  ✗ {{.}}
+{{with index $.Report.MissingExamples .}}        {{.}}
+{{end}}{{end}}{{end}}
+{{end}}{{repeat "=" 80}}
+{{.Summary.Covered}}/{{.Summary.Total}} node {{pluralize .Summary.Total "type" "types"}} covered
+`
+
+// NewTemplateData builds the template data model for a report.
+func NewTemplateData(report *CoverageReport) *TemplateData {
+	return &TemplateData{
+		Summary: TemplateSummary{
+			GeneratedAt: report.GeneratedAt,
+			Total:       report.TotalNodeTypes,
+			Covered:     report.CoveredNodeTypes,
+			Missing:     len(report.MissingNodes),
+			Percent:     report.CoveragePercent,
+		},
+		Categories:        report.Categories,
+		Files:             report.FileReports,
+		Covered:           report.CoveredNodes,
+		Missing:           report.MissingNodes,
+		Tokens:            report.Tokens,
+		TokenKinds:        report.TokenKinds,
+		CoveredByCategory: groupByCategory(report.CoveredNodes),
+		MissingByCategory: groupByCategory(report.MissingNodes),
+		Report:            report,
+	}
+}
+
+// groupByCategory groups nodes by category in sorted category order.
+func groupByCategory(nodes []string) []CategoryNodes {
+	categories := categorizeNodes(nodes)
+	groups := make([]CategoryNodes, 0, len(categories))
+	for _, name := range sortedCategories(categories) {
+		groups = append(groups, CategoryNodes{Name: name, Nodes: categories[name]})
+	}
+	return groups
+}
+
+// RenderTemplate renders the report to w with a text/template, which sees
+// a *TemplateData and the helpers documented on templateFuncs.
+func RenderTemplate(report *CoverageReport, tmpl string, w io.Writer) error {
+	t, err := template.New("report").Funcs(templateFuncs).Parse(tmpl)
+	if err != nil {
+		return fmt.Errorf("failed to parse template: %w", err)
+	}
+	if err := t.Execute(w, NewTemplateData(report)); err != nil {
+		return fmt.Errorf("failed to render template: %w", err)
+	}
+	return nil
+}
+
+// LoadTemplateFile reads a report template from a file.
+func LoadTemplateFile(filePath string) (string, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read template: %w", err)
+	}
+	return string(data), nil
+}
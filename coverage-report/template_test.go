@@ -0,0 +1,66 @@
+package report
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestRenderTemplate tests a small custom template and its helpers
+func TestRenderTemplate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "custom.tmpl")
+	custom := `{{percent .Summary.Percent}} of {{.Summary.Total}}; missing {{len .Missing}} {{pluralize (len .Missing) "type" "types"}}:{{range .Missing}} {{.}}{{end}}
+{{range .Files}}{{base .FileName}}={{.NodeCount}} {{end}}`
+	if err := os.WriteFile(path, []byte(custom), 0644); err != nil {
+		t.Fatalf("failed to write template: %v", err)
+	}
+
+	tmpl, err := LoadTemplateFile(path)
+	if err != nil {
+		t.Fatalf("LoadTemplateFile failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := RenderTemplate(sampleReport(), tmpl, &buf); err != nil {
+		t.Fatalf("RenderTemplate failed: %v", err)
+	}
+	want := "66.67% of 6; missing 2 types: *ast.GoStmt *ast.TypeSpec\nstatements.go=40 expressions.go=25 "
+	if buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+
+	if err := RenderTemplate(sampleReport(), "{{.NoSuchField}}", &buf); err == nil {
+		t.Error("expected error for an unknown field")
+	}
+}
+
+// TestDefaultTemplate tests that the default template reproduces the
+// built-in report's sections
+func TestDefaultTemplate(t *testing.T) {
+	rep := sampleReport()
+	rep.Categories = computeCategoryCoverage(append(append([]string{}, rep.CoveredNodes...), rep.MissingNodes...), rep.CoveredNodes)
+
+	var tmplOut, printOut bytes.Buffer
+	if err := RenderTemplate(rep, DefaultTemplate, &tmplOut); err != nil {
+		t.Fatalf("RenderTemplate failed: %v", err)
+	}
+	PrintReportWithOptions(&printOut, rep, PrintOptions{Color: ColorNever})
+
+	for _, line := range []string{
+		"Generated: 2024-01-02T03:04:05Z",
+		"Coverage:                66.67%",
+		"statements.go                   Nodes:    40  Unique Types:   3",
+		"Statement Nodes         1/2     50.00%  [██████████░░░░░░░░░░]",
+		"Statement Nodes (1):",
+		"  ✗ *ast.GoStmt",
+	} {
+		if !strings.Contains(tmplOut.String(), line) {
+			t.Errorf("default template output missing %q", line)
+		}
+		if !strings.Contains(printOut.String(), line) {
+			t.Errorf("built-in report missing %q", line)
+		}
+	}
+}
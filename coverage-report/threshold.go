@@ -0,0 +1,54 @@
+package report
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Sentinel errors wrapped by CheckThreshold, so callers can tell the
+// failure classes apart with errors.Is.
+var (
+	// ErrBelowThreshold reports that overall coverage is below the minimum.
+	ErrBelowThreshold = errors.New("coverage below threshold")
+
+	// ErrMissingRequiredNodes reports that required node types are not covered.
+	ErrMissingRequiredNodes = errors.New("required node types not covered")
+)
+
+// CheckThreshold returns nil when the report's coverage is at least
+// minPercent and every node type in requiredNodes is covered. Required
+// node types may be given as "*ast.SelectStmt" or just "SelectStmt".
+// Otherwise it returns an error wrapping ErrBelowThreshold,
+// ErrMissingRequiredNodes, or both.
+func CheckThreshold(report *CoverageReport, minPercent float64, requiredNodes []string) error {
+	var errs []error
+
+	if report.CoveragePercent < minPercent {
+		errs = append(errs, fmt.Errorf("%w: %.2f%% is %.2f points short of %.2f%%",
+			ErrBelowThreshold, report.CoveragePercent, minPercent-report.CoveragePercent, minPercent))
+	}
+
+	covered := stringSet(report.CoveredNodes)
+	var missing []string
+	for _, node := range requiredNodes {
+		node = qualifyNodeType(node)
+		if node != "" && !covered[node] {
+			missing = append(missing, node)
+		}
+	}
+	if len(missing) > 0 {
+		errs = append(errs, fmt.Errorf("%w: %s", ErrMissingRequiredNodes, strings.Join(missing, ", ")))
+	}
+
+	return errors.Join(errs...)
+}
+
+// qualifyNodeType trims name and adds the "*ast." prefix if it is missing.
+func qualifyNodeType(name string) string {
+	name = strings.TrimSpace(name)
+	if name == "" || strings.HasPrefix(name, "*ast.") {
+		return name
+	}
+	return "*ast." + strings.TrimPrefix(name, "ast.")
+}
@@ -0,0 +1,37 @@
+package report
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestCheckThreshold tests both threshold failure classes
+func TestCheckThreshold(t *testing.T) {
+	rep := sampleReport()
+
+	if err := CheckThreshold(rep, 60, []string{"*ast.File", "ReturnStmt"}); err != nil {
+		t.Errorf("expected thresholds to pass, got %v", err)
+	}
+
+	err := CheckThreshold(rep, 80, nil)
+	if !errors.Is(err, ErrBelowThreshold) || errors.Is(err, ErrMissingRequiredNodes) {
+		t.Errorf("expected only ErrBelowThreshold, got %v", err)
+	}
+	if err != nil && !strings.Contains(err.Error(), "13.33 points short of 80.00%") {
+		t.Errorf("expected shortfall in error, got %v", err)
+	}
+
+	err = CheckThreshold(rep, 0, []string{"GoStmt", "*ast.TypeSpec", "Ident"})
+	if !errors.Is(err, ErrMissingRequiredNodes) || errors.Is(err, ErrBelowThreshold) {
+		t.Errorf("expected only ErrMissingRequiredNodes, got %v", err)
+	}
+	if err != nil && !strings.Contains(err.Error(), "*ast.GoStmt, *ast.TypeSpec") {
+		t.Errorf("expected missing nodes in error, got %v", err)
+	}
+
+	err = CheckThreshold(rep, 90, []string{"GoStmt"})
+	if !errors.Is(err, ErrBelowThreshold) || !errors.Is(err, ErrMissingRequiredNodes) {
+		t.Errorf("expected both failures, got %v", err)
+	}
+}
@@ -0,0 +1,65 @@
+package report
+
+import (
+	"zylisp/go-ast-coverage/analyzer"
+)
+
+// TokenCoverage reports which tokens the samples use, by group.
+type TokenCoverage struct {
+	Groups []TokenGroupCoverage
+
+	// Percent is the share of tokens covered across all groups.
+	Percent float64
+}
+
+// TokenGroupCoverage reports coverage of one token group, such as binary
+// operators. Tokens are listed in the analyzer's order.
+type TokenGroupCoverage struct {
+	Name    string
+	Covered []string
+	Missing []string
+	Percent float64
+}
+
+// computeTokenCoverage splits every tracked operator and keyword token into
+// covered and missing according to the aggregated token counts.
+func computeTokenCoverage(tokenCounts map[string]map[string]int) TokenCoverage {
+	return tokenGroupCoverage(analyzer.GetAllTokenGroups(), func(group, tok string) bool {
+		return tokenCounts[group][tok] > 0
+	})
+}
+
+// computeTokenKindCoverage splits every lexical token kind into covered
+// and missing according to the aggregated token kind counts.
+func computeTokenKindCoverage(tokenKindCounts map[string]int) TokenCoverage {
+	return tokenGroupCoverage(analyzer.GetAllTokenKinds(), func(_, tok string) bool {
+		return tokenKindCounts[tok] > 0
+	})
+}
+
+// tokenGroupCoverage splits the tokens of groups by whether covered reports
+// them as used.
+func tokenGroupCoverage(groups []analyzer.TokenGroup, covered func(group, tok string) bool) TokenCoverage {
+	coverage := TokenCoverage{Groups: []TokenGroupCoverage{}}
+	coveredTotal, total := 0, 0
+	for _, group := range groups {
+		gc := TokenGroupCoverage{Name: group.Name, Covered: []string{}, Missing: []string{}}
+		for _, tok := range group.Tokens {
+			if covered(group.Name, tok.String()) {
+				gc.Covered = append(gc.Covered, tok.String())
+			} else {
+				gc.Missing = append(gc.Missing, tok.String())
+			}
+		}
+		if len(group.Tokens) > 0 {
+			gc.Percent = float64(len(gc.Covered)) / float64(len(group.Tokens)) * 100
+		}
+		coveredTotal += len(gc.Covered)
+		total += len(group.Tokens)
+		coverage.Groups = append(coverage.Groups, gc)
+	}
+	if total > 0 {
+		coverage.Percent = float64(coveredTotal) / float64(total) * 100
+	}
+	return coverage
+}
@@ -0,0 +1,107 @@
+package report
+
+import (
+	"go/parser"
+	"go/token"
+	"reflect"
+	"testing"
+
+	"zylisp/go-ast-coverage/analyzer"
+)
+
+// TestComputeTokenCoverage tests covered and missing tokens per group
+func TestComputeTokenCoverage(t *testing.T) {
+	src := `package p
+
+func f(a, b int, ch chan int) {
+	x := a + b
+	x += -a
+	for {
+		if x > 0 && <-ch == 0 {
+			break
+		}
+	}
+}
+`
+	file, err := parser.ParseFile(token.NewFileSet(), "p.go", src, 0)
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+
+	coverage := computeTokenCoverage(analyzer.CountTokens(file))
+	groups := make(map[string]TokenGroupCoverage)
+	for _, group := range coverage.Groups {
+		groups[group.Name] = group
+	}
+
+	tests := map[string][]string{
+		analyzer.TokenGroupBinary: {"+", "&&", "==", ">"},
+		analyzer.TokenGroupUnary:  {"-", "<-"},
+		analyzer.TokenGroupAssign: {":=", "+="},
+		analyzer.TokenGroupBranch: {"break"},
+	}
+	for name, want := range tests {
+		if got := groups[name].Covered; !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: covered %v, want %v", name, got, want)
+		}
+	}
+
+	branch := groups[analyzer.TokenGroupBranch]
+	if branch.Percent != 25 || !reflect.DeepEqual(branch.Missing, []string{"continue", "goto", "fallthrough"}) {
+		t.Errorf("unexpected branch coverage: %+v", branch)
+	}
+}
+
+// TestComputeTokenKindCoverage tests lexical token kinds by group
+func TestComputeTokenKindCoverage(t *testing.T) {
+	src := "package p\n\n// f sums\nfunc f(xs ...int) { x := 1; _ = x }\n"
+	coverage := computeTokenKindCoverage(analyzer.ScanTokenKinds([]byte(src)))
+
+	groups := make(map[string]TokenGroupCoverage)
+	for _, group := range coverage.Groups {
+		groups[group.Name] = group
+	}
+	if len(groups) != 3 {
+		t.Fatalf("expected literal, operator, and keyword groups, got %v", coverage.Groups)
+	}
+
+	tests := map[string][]string{
+		analyzer.TokenKindLiteral:  {"COMMENT", "IDENT", "INT"},
+		analyzer.TokenKindOperator: {"=", ":=", "...", "(", "{", ")", "}", ";"},
+		analyzer.TokenKindKeyword:  {"func", "package"},
+	}
+	for name, want := range tests {
+		if got := groups[name].Covered; !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: covered %v, want %v", name, got, want)
+		}
+	}
+
+	keywords := groups[analyzer.TokenKindKeyword]
+	if total := len(keywords.Covered) + len(keywords.Missing); total != 25 || keywords.Percent != 8 {
+		t.Errorf("expected 2 of 25 keywords, got %d of %d", len(keywords.Covered), total)
+	}
+	if coverage.Percent <= 0 || coverage.Percent >= 100 {
+		t.Errorf("unexpected overall percent %.2f", coverage.Percent)
+	}
+}
+
+// TestCorpusTokenKinds tests that the sample corpus exercises particular
+// token kinds
+func TestCorpusTokenKinds(t *testing.T) {
+	rep, err := GenerateReport("../nodes/go")
+	if err != nil {
+		t.Fatalf("GenerateReport failed: %v", err)
+	}
+
+	covered := make(map[string]bool)
+	for _, group := range rep.TokenKinds.Groups {
+		for _, tok := range group.Covered {
+			covered[tok] = true
+		}
+	}
+	for _, tok := range []token.Token{token.ARROW, token.ELLIPSIS, token.TILDE, token.IMAG, token.GOTO} {
+		if !covered[tok.String()] {
+			t.Errorf("expected the corpus to cover %s", tok)
+		}
+	}
+}
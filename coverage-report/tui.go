@@ -0,0 +1,234 @@
+package report
+
+import (
+	"fmt"
+	"strings"
+
+	"zylisp/go-ast-coverage/analyzer"
+)
+
+// tuiKey is a key press understood by the TUI.
+type tuiKey int
+
+const (
+	keyRune tuiKey = iota
+	keyUp
+	keyDown
+	keyLeft
+	keyRight
+	keyEnter
+	keyEscape
+	keyBackspace
+)
+
+// tuiNode is a node type listed under a category.
+type tuiNode struct {
+	Type    string
+	Covered bool
+}
+
+// tuiCategory is a category and its node types.
+type tuiCategory struct {
+	Name  string
+	Nodes []tuiNode
+}
+
+// tuiRow is one visible line of the tree: a category, or a node type when
+// Node is set.
+type tuiRow struct {
+	Category *tuiCategory
+	Node     *tuiNode
+}
+
+// tuiModel is the navigable state of the TUI, kept apart from terminal
+// handling so it can be driven by tests. Categories start collapsed; a
+// non-empty filter shows every category with a matching node type,
+// expanded.
+type tuiModel struct {
+	report     *CoverageReport
+	categories []tuiCategory
+	expanded   map[string]bool
+	cursor     int
+
+	filter    string
+	filtering bool
+
+	// detail is the covered node type whose files are shown, or "".
+	detail string
+	quit   bool
+
+	// details caches detailLines by node type.
+	details map[string][]string
+}
+
+// newTUIModel builds the category tree for a report.
+func newTUIModel(report *CoverageReport) *tuiModel {
+	covered := stringSet(report.CoveredNodes)
+	all := append(append([]string{}, report.CoveredNodes...), report.MissingNodes...)
+	categories := categorizeNodes(all)
+
+	m := &tuiModel{
+		report:   report,
+		expanded: make(map[string]bool),
+		details:  make(map[string][]string),
+	}
+	for _, name := range sortedCategories(categories) {
+		category := tuiCategory{Name: name}
+		for _, node := range categories[name] {
+			category.Nodes = append(category.Nodes, tuiNode{Type: node, Covered: covered[node]})
+		}
+		m.categories = append(m.categories, category)
+	}
+	return m
+}
+
+// rows returns the visible lines of the tree.
+func (m *tuiModel) rows() []tuiRow {
+	filter := strings.ToLower(m.filter)
+	var rows []tuiRow
+	for i := range m.categories {
+		category := &m.categories[i]
+		var nodes []tuiRow
+		for j := range category.Nodes {
+			node := &category.Nodes[j]
+			if filter == "" || strings.Contains(strings.ToLower(node.Type), filter) {
+				nodes = append(nodes, tuiRow{Category: category, Node: node})
+			}
+		}
+		if filter != "" && len(nodes) == 0 {
+			continue
+		}
+
+		rows = append(rows, tuiRow{Category: category})
+		if filter != "" || m.expanded[category.Name] {
+			rows = append(rows, nodes...)
+		}
+	}
+	return rows
+}
+
+// current returns the row under the cursor.
+func (m *tuiModel) current() (tuiRow, bool) {
+	rows := m.rows()
+	if m.cursor < 0 || m.cursor >= len(rows) {
+		return tuiRow{}, false
+	}
+	return rows[m.cursor], true
+}
+
+// clamp keeps the cursor on a visible row.
+func (m *tuiModel) clamp() {
+	if n := len(m.rows()); m.cursor >= n {
+		m.cursor = n - 1
+	}
+	if m.cursor < 0 {
+		m.cursor = 0
+	}
+}
+
+// handleKey applies a key press; r is the typed character for keyRune.
+func (m *tuiModel) handleKey(key tuiKey, r rune) {
+	if m.filtering {
+		m.handleFilterKey(key, r)
+		return
+	}
+	if m.detail != "" {
+		if key == keyRune && r == 'q' {
+			m.quit = true
+		}
+		m.detail = ""
+		return
+	}
+
+	row, ok := m.current()
+	switch key {
+	case keyUp:
+		m.cursor--
+	case keyDown:
+		m.cursor++
+	case keyRight:
+		if ok && row.Node == nil {
+			m.expanded[row.Category.Name] = true
+		}
+	case keyLeft:
+		if ok {
+			m.collapse(row)
+		}
+	case keyEnter:
+		if !ok {
+			break
+		}
+		if row.Node == nil {
+			m.expanded[row.Category.Name] = !m.expanded[row.Category.Name]
+		} else if row.Node.Covered {
+			m.detail = row.Node.Type
+		}
+	case keyEscape:
+		m.filter = ""
+	case keyRune:
+		switch r {
+		case 'q':
+			m.quit = true
+		case '/':
+			m.filtering = true
+		}
+	}
+	m.clamp()
+}
+
+// collapse closes the category of row and moves the cursor onto it.
+func (m *tuiModel) collapse(row tuiRow) {
+	m.expanded[row.Category.Name] = false
+	for i, r := range m.rows() {
+		if r.Node == nil && r.Category == row.Category {
+			m.cursor = i
+			return
+		}
+	}
+}
+
+// handleFilterKey edits the filter while the filter box has focus. Enter
+// keeps the filter and escape clears it.
+func (m *tuiModel) handleFilterKey(key tuiKey, r rune) {
+	switch key {
+	case keyRune:
+		m.filter += string(r)
+	case keyBackspace:
+		if m.filter != "" {
+			runes := []rune(m.filter)
+			m.filter = string(runes[:len(runes)-1])
+		}
+	case keyEnter:
+		m.filtering = false
+	case keyEscape:
+		m.filter = ""
+		m.filtering = false
+	}
+	m.cursor = 0
+}
+
+// detailLines lists the files that provide nodeType, each with the
+// position where it first appears when the file can still be parsed.
+func (m *tuiModel) detailLines(nodeType string) []string {
+	if lines, ok := m.details[nodeType]; ok {
+		return lines
+	}
+
+	paths := make(map[string]string, len(m.report.FileReports))
+	for _, fr := range m.report.FileReports {
+		paths[getBaseName(fr.FileName)] = fr.FileName
+	}
+
+	lines := []string{}
+	for _, file := range m.report.Attribution[nodeType] {
+		line := file
+		if path, ok := paths[file]; ok {
+			if result, err := analyzer.AnalyzeFile(path); err == nil && len(result.Locations[nodeType]) > 0 {
+				line = fmt.Sprintf("%-30s first seen at %s", file, result.Locations[nodeType][0])
+			}
+		}
+		lines = append(lines, line)
+	}
+	m.details[nodeType] = lines
+	return lines
+}
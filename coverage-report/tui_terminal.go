@@ -0,0 +1,191 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// tuiHelp is the key summary shown in the TUI header.
+const tuiHelp = "↑/↓ move  →/← expand/collapse  enter details  / filter  q quit"
+
+// RunTUI browses the report in an interactive terminal UI. When stdin or
+// stdout is not a terminal, or the terminal cannot be put in raw mode, it
+// prints the report instead.
+func RunTUI(report *CoverageReport) error {
+	if !isTerminal(os.Stdin) || !isTerminal(os.Stdout) {
+		return PrintReportTo(os.Stdout, report)
+	}
+
+	restore, err := rawTerminal()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: interactive mode unavailable: %v\n", err)
+		return PrintReportTo(os.Stdout, report)
+	}
+	defer restore()
+
+	height := terminalHeight()
+	m := newTUIModel(report)
+	buf := make([]byte, 16)
+	for !m.quit {
+		screen := strings.Join(renderTUI(m, height), "\r\n")
+		if _, err := fmt.Fprint(os.Stdout, "\x1b[H\x1b[2J"+screen); err != nil {
+			return fmt.Errorf("failed to draw: %w", err)
+		}
+
+		n, err := os.Stdin.Read(buf)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read input: %w", err)
+		}
+		key, r := decodeKey(buf[:n])
+		if key == keyRune && r == 3 { // Ctrl-C
+			break
+		}
+		m.handleKey(key, r)
+	}
+
+	fmt.Fprint(os.Stdout, "\x1b[H\x1b[2J")
+	return nil
+}
+
+// rawTerminal switches the terminal to raw mode with stty and returns a
+// function restoring the previous settings.
+func rawTerminal() (func(), error) {
+	saved, err := stty("-g")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := stty("raw", "-echo"); err != nil {
+		return nil, err
+	}
+	return func() { stty(strings.TrimSpace(saved)) }, nil
+}
+
+// terminalHeight returns the terminal's row count, or 24 if unknown.
+func terminalHeight() int {
+	var rows, cols int
+	if size, err := stty("size"); err == nil {
+		if _, err := fmt.Sscan(size, &rows, &cols); err == nil && rows > 0 {
+			return rows
+		}
+	}
+	return 24
+}
+
+// stty runs stty against the terminal on stdin.
+func stty(args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to run stty: %w", err)
+	}
+	return string(out), nil
+}
+
+// decodeKey maps the bytes of one key press to a key.
+func decodeKey(b []byte) (tuiKey, rune) {
+	switch {
+	case len(b) == 0:
+		return keyRune, 0
+	case len(b) >= 3 && b[0] == 0x1b && b[1] == '[':
+		switch b[2] {
+		case 'A':
+			return keyUp, 0
+		case 'B':
+			return keyDown, 0
+		case 'C':
+			return keyRight, 0
+		case 'D':
+			return keyLeft, 0
+		}
+		return keyRune, 0
+	case b[0] == 0x1b:
+		return keyEscape, 0
+	case b[0] == '\r' || b[0] == '\n':
+		return keyEnter, 0
+	case b[0] == 0x7f || b[0] == 0x08:
+		return keyBackspace, 0
+	}
+	return keyRune, []rune(string(b))[0]
+}
+
+// renderTUI draws the model as at most height lines.
+func renderTUI(m *tuiModel, height int) []string {
+	lines := []string{
+		fmt.Sprintf("GO AST COVERAGE  %.2f%% (%d/%d)   %s", m.report.CoveragePercent, m.report.CoveredNodeTypes, m.report.TotalNodeTypes, tuiHelp),
+	}
+	switch {
+	case m.filtering:
+		lines = append(lines, "Filter: "+m.filter+"█")
+	case m.filter != "":
+		lines = append(lines, "Filter: "+m.filter+"  (esc to clear)")
+	default:
+		lines = append(lines, "")
+	}
+
+	if m.detail != "" {
+		lines = append(lines, "", m.detail+" is provided by:")
+		for _, line := range m.detailLines(m.detail) {
+			lines = append(lines, "  "+line)
+		}
+		lines = append(lines, "", "(any key to return)")
+		return fitLines(lines, height)
+	}
+
+	rows := m.rows()
+	visible := height - len(lines)
+	if visible < 1 {
+		visible = 1
+	}
+	start := 0
+	if m.cursor >= visible {
+		start = m.cursor - visible + 1
+	}
+	for i := start; i < len(rows) && i < start+visible; i++ {
+		lines = append(lines, renderTUIRow(m, rows[i], i == m.cursor))
+	}
+	return fitLines(lines, height)
+}
+
+// renderTUIRow draws one tree row, highlighting the cursor row.
+func renderTUIRow(m *tuiModel, row tuiRow, selected bool) string {
+	var line string
+	if row.Node == nil {
+		covered := 0
+		for _, node := range row.Category.Nodes {
+			if node.Covered {
+				covered++
+			}
+		}
+		marker := "▸"
+		if m.filter != "" || m.expanded[row.Category.Name] {
+			marker = "▾"
+		}
+		line = fmt.Sprintf("%s %s (%d/%d)", marker, row.Category.Name, covered, len(row.Category.Nodes))
+	} else {
+		mark := "✗"
+		if row.Node.Covered {
+			mark = "✓"
+		}
+		line = fmt.Sprintf("    %s %s", mark, row.Node.Type)
+	}
+
+	if selected {
+		return "\x1b[7m" + line + ansiReset
+	}
+	return line
+}
+
+// fitLines truncates lines to height.
+func fitLines(lines []string, height int) []string {
+	if height > 0 && len(lines) > height {
+		return lines[:height]
+	}
+	return lines
+}
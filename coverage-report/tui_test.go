@@ -0,0 +1,149 @@
+package report
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// tuiSampleReport returns sampleReport with attribution for the TUI.
+func tuiSampleReport() *CoverageReport {
+	rep := sampleReport()
+	rep.Attribution = map[string][]string{
+		"*ast.BinaryExpr": {"expressions.go"},
+		"*ast.File":       {"expressions.go", "statements.go"},
+	}
+	return rep
+}
+
+// TestTUIModelNavigation tests moving, expanding, and collapsing categories
+func TestTUIModelNavigation(t *testing.T) {
+	m := newTUIModel(tuiSampleReport())
+
+	// Expression, Spec, Statement, and Top-Level categories, collapsed
+	if rows := m.rows(); len(rows) != 4 || rows[0].Category.Name != categoryExpression {
+		t.Fatalf("expected 4 collapsed categories, got %d rows", len(rows))
+	}
+
+	m.handleKey(keyRight, 0)
+	if rows := m.rows(); len(rows) != 6 || rows[1].Node.Type != "*ast.BinaryExpr" {
+		t.Fatalf("expected expressions to expand, got %d rows", len(rows))
+	}
+
+	m.handleKey(keyDown, 0)
+	m.handleKey(keyDown, 0)
+	if row, _ := m.current(); row.Node == nil || row.Node.Type != "*ast.Ident" {
+		t.Errorf("expected cursor on Ident, got %+v", row)
+	}
+
+	// Left on a node collapses its category and moves onto it
+	m.handleKey(keyLeft, 0)
+	if row, _ := m.current(); m.cursor != 0 || row.Node != nil || len(m.rows()) != 4 {
+		t.Errorf("expected collapsed expressions under the cursor, got cursor %d", m.cursor)
+	}
+
+	m.handleKey(keyUp, 0)
+	if m.cursor != 0 {
+		t.Errorf("expected cursor to stay on the first row, got %d", m.cursor)
+	}
+	for i := 0; i < 10; i++ {
+		m.handleKey(keyDown, 0)
+	}
+	if m.cursor != 3 {
+		t.Errorf("expected cursor to stop on the last row, got %d", m.cursor)
+	}
+
+	m.handleKey(keyRune, 'q')
+	if !m.quit {
+		t.Error("expected q to quit")
+	}
+}
+
+// TestTUIModelFilter tests that the filter box narrows and expands the tree
+func TestTUIModelFilter(t *testing.T) {
+	m := newTUIModel(tuiSampleReport())
+
+	m.handleKey(keyRune, '/')
+	for _, r := range "stmtx" {
+		m.handleKey(keyRune, r)
+	}
+	m.handleKey(keyBackspace, 0)
+	m.handleKey(keyEnter, 0)
+
+	rows := m.rows()
+	if m.filtering || m.filter != "stmt" || len(rows) != 3 {
+		t.Fatalf("expected the Statement category with two nodes, got filter %q and %d rows", m.filter, len(rows))
+	}
+	if rows[1].Node.Type != "*ast.GoStmt" || rows[2].Node.Type != "*ast.ReturnStmt" {
+		t.Errorf("unexpected filtered rows %v, %v", rows[1].Node, rows[2].Node)
+	}
+
+	m.handleKey(keyEscape, 0)
+	if m.filter != "" || len(m.rows()) != 4 {
+		t.Errorf("expected escape to clear the filter, got %q", m.filter)
+	}
+}
+
+// TestTUIModelDetails tests the files and first positions of a covered type
+func TestTUIModelDetails(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "expressions.go")
+	if err := os.WriteFile(path, []byte("package p\n\nvar x = 1 + 2\n"), 0644); err != nil {
+		t.Fatalf("failed to write source: %v", err)
+	}
+	rep := tuiSampleReport()
+	rep.FileReports[1].FileName = path
+
+	m := newTUIModel(rep)
+	m.handleKey(keyRight, 0)
+	m.handleKey(keyDown, 0)
+	m.handleKey(keyEnter, 0)
+	if m.detail != "*ast.BinaryExpr" {
+		t.Fatalf("expected details for BinaryExpr, got %q", m.detail)
+	}
+
+	lines := m.detailLines(m.detail)
+	if len(lines) != 1 || !strings.Contains(lines[0], "first seen at "+path+":3:9") {
+		t.Errorf("unexpected detail lines %v", lines)
+	}
+	screen := strings.Join(renderTUI(m, 24), "\n")
+	if !strings.Contains(screen, "*ast.BinaryExpr is provided by:") {
+		t.Errorf("expected the detail view, got:\n%s", screen)
+	}
+
+	// Any key returns to the tree; missing types have no details
+	m.handleKey(keyDown, 0)
+	m.handleKey(keyDown, 0)
+	m.handleKey(keyDown, 0)
+	m.handleKey(keyRight, 0)
+	m.handleKey(keyDown, 0)
+	m.handleKey(keyEnter, 0)
+	if row, _ := m.current(); row.Node == nil || row.Node.Type != "*ast.TypeSpec" || m.detail != "" {
+		t.Errorf("expected no details for a missing type, got %q on %+v", m.detail, row)
+	}
+}
+
+// TestDecodeKey tests decoding terminal input into keys
+func TestDecodeKey(t *testing.T) {
+	tests := []struct {
+		input string
+		key   tuiKey
+		r     rune
+	}{
+		{"\x1b[A", keyUp, 0},
+		{"\x1b[B", keyDown, 0},
+		{"\x1b[C", keyRight, 0},
+		{"\x1b[D", keyLeft, 0},
+		{"\x1b", keyEscape, 0},
+		{"\r", keyEnter, 0},
+		{"\x7f", keyBackspace, 0},
+		{"q", keyRune, 'q'},
+		{"é", keyRune, 'é'},
+	}
+	for _, tt := range tests {
+		if key, r := decodeKey([]byte(tt.input)); key != tt.key || r != tt.r {
+			t.Errorf("decodeKey(%q) = %v, %q; want %v, %q", tt.input, key, r, tt.key, tt.r)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package report
+
+import (
+	"zylisp/go-ast-coverage/analyzer"
+)
+
+// VariantCoverage reports whether the corpus exercises one structural
+// variant, such as a 3-index slice expression.
+type VariantCoverage struct {
+	Name        string
+	Description string
+	Covered     bool
+	Count       int
+}
+
+// computeVariantCoverage lists every tracked variant, in the analyzer's
+// order, with its aggregated count.
+func computeVariantCoverage(variantCounts map[string]int) []VariantCoverage {
+	variants := analyzer.GetAllVariants()
+	result := make([]VariantCoverage, 0, len(variants))
+	for _, v := range variants {
+		count := variantCounts[v.Name]
+		result = append(result, VariantCoverage{
+			Name:        v.Name,
+			Description: v.Description,
+			Covered:     count > 0,
+			Count:       count,
+		})
+	}
+	return result
+}
@@ -0,0 +1,109 @@
+package report
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestVariantCoverage tests that a corpus without a 3-index slice reports
+// that variant as missing
+func TestVariantCoverage(t *testing.T) {
+	src := `package main
+
+func main() {
+	s := []int{1, 2, 3}
+	for i := 0; i < len(s); i++ {
+		if i > 1 {
+			return
+		}
+	}
+	_ = s[1:2]
+}
+`
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte(src), 0644); err != nil {
+		t.Fatalf("failed to write source: %v", err)
+	}
+
+	rep, err := GenerateReport(dir)
+	if err != nil {
+		t.Fatalf("GenerateReport failed: %v", err)
+	}
+
+	variants := make(map[string]VariantCoverage)
+	for _, v := range rep.Variants {
+		variants[v.Name] = v
+	}
+	for name, covered := range map[string]bool{
+		"SliceExpr/2-index":         true,
+		"SliceExpr/3-index":         false,
+		"ForStmt/three-clause":      true,
+		"IfStmt/plain":              true,
+		"IfStmt/else":               false,
+		"ReturnStmt/bare":           true,
+		"FuncDecl/method":           false,
+		"RangeStmt/range-over-func": false,
+		"RangeStmt/range-over-int":  false,
+	} {
+		if v, ok := variants[name]; !ok || v.Covered != covered {
+			t.Errorf("%s: expected covered=%v, got %+v", name, covered, v)
+		}
+	}
+
+	var buf strings.Builder
+	PrintReportTo(&buf, rep)
+	if !strings.Contains(buf.String(), "✗ SliceExpr/3-index") {
+		t.Error("expected the text report to call out the missing 3-index slice")
+	}
+}
+
+// TestVariantCoverageCorpus tests that the sample corpus covers the range
+// over an iterator function and over an integer, and that leaving out
+// range_over_int.go flags the integer range as missing
+func TestVariantCoverageCorpus(t *testing.T) {
+	const corpusDir = "../nodes/go"
+	rep, err := GenerateReport(corpusDir)
+	if err != nil {
+		t.Fatalf("GenerateReport failed: %v", err)
+	}
+	for _, name := range []string{"RangeStmt/range-over-func", "RangeStmt/range-over-int"} {
+		if v := findVariant(rep, name); v == nil || !v.Covered {
+			t.Errorf("expected %s to be covered, got %+v", name, v)
+		}
+	}
+
+	entries, err := os.ReadDir(corpusDir)
+	if err != nil {
+		t.Fatalf("failed to read corpus: %v", err)
+	}
+	var files []string
+	for _, entry := range entries {
+		if strings.HasSuffix(entry.Name(), ".go") && entry.Name() != "range_over_int.go" {
+			files = append(files, filepath.Join(corpusDir, entry.Name()))
+		}
+	}
+	rep, err = GenerateReportWithOptions(corpusDir, ReportOptions{Files: files})
+	if err != nil {
+		t.Fatalf("GenerateReportWithOptions failed: %v", err)
+	}
+	if v := findVariant(rep, "RangeStmt/range-over-int"); v == nil || v.Covered {
+		t.Errorf("expected range-over-int to be missing without range_over_int.go, got %+v", v)
+	}
+	var buf strings.Builder
+	PrintReportTo(&buf, rep)
+	if !strings.Contains(buf.String(), "✗ RangeStmt/range-over-int") {
+		t.Error("expected the text report to call out the missing integer range")
+	}
+}
+
+// findVariant returns the named variant in rep, or nil.
+func findVariant(rep *CoverageReport, name string) *VariantCoverage {
+	for i := range rep.Variants {
+		if rep.Variants[i].Name == name {
+			return &rep.Variants[i]
+		}
+	}
+	return nil
+}
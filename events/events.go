@@ -0,0 +1,208 @@
+// Package events defines the newline-delimited JSON event stream the
+// orchestrator writes with -output ndjson, one object per line, each with
+// a "type" field naming the event.
+package events
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Event type names, as written in each object's "type" field.
+const (
+	TypeRunStart       = "run_start"
+	TypeFileRunResult  = "file_run_result"
+	TypeAnalysisResult = "analysis_result"
+	TypeTargetMatrix   = "target_matrix"
+	TypeReportSummary  = "report_summary"
+	TypeRunEnd         = "run_end"
+)
+
+// Event is one entry in the stream.
+type Event interface {
+	EventType() string
+}
+
+// RunStart is emitted once, before any work, with the sample files the run
+// will work on, in order. Seed is the -shuffle seed that ordered them.
+type RunStart struct {
+	Dirs  []string `json:"dirs"`
+	Files []string `json:"files"`
+	Seed  int64    `json:"seed,omitempty"`
+}
+
+// FileRunResult is emitted for each sample file -run executes, in file
+// order. Cached is set, with no duration, when the run was skipped because
+// the file's last run succeeded and it has not changed since. With -check
+// -targets there is one per file and target, naming the target. Attempts
+// counts the runs of an executed file, more than one when -retries
+// re-attempted it. HookFailed is set, and OK is not, when the file's
+// -post-run command failed.
+type FileRunResult struct {
+	Name        string  `json:"name"`
+	Target      string  `json:"target,omitempty"`
+	OK          bool    `json:"ok"`
+	Cached      bool    `json:"cached,omitempty"`
+	TimedOut    bool    `json:"timed_out,omitempty"`
+	DurationMS  float64 `json:"duration_ms"`
+	OutputBytes int     `json:"output_bytes"`
+	Attempts    int     `json:"attempts,omitempty"`
+	HookFailed  bool    `json:"hook_failed,omitempty"`
+}
+
+// AnalysisResult is emitted for each sample file -analyze parses.
+type AnalysisResult struct {
+	File        string `json:"file"`
+	Nodes       int    `json:"nodes"`
+	UniqueTypes int    `json:"unique_types"`
+}
+
+// TargetMatrix is emitted once -check -targets has checked every file,
+// with whether each file passed for each target.
+type TargetMatrix struct {
+	Targets []string          `json:"targets"`
+	Files   []TargetMatrixRow `json:"files"`
+}
+
+// TargetMatrixRow is one file's row of a TargetMatrix; Passed lines up
+// with Targets.
+type TargetMatrixRow struct {
+	Name   string `json:"name"`
+	Passed []bool `json:"passed"`
+}
+
+// ReportSummary is emitted once the coverage report is generated.
+type ReportSummary struct {
+	Percent float64  `json:"percent"`
+	Covered int      `json:"covered"`
+	Total   int      `json:"total"`
+	Missing []string `json:"missing"`
+}
+
+// RunEnd is always the last event, with the status the process exits
+// with, how long the run took, the phases that finished with their
+// timings, the slowest sample files the run or check phase timed, and the
+// errors the failed phases met.
+type RunEnd struct {
+	ExitStatus   int           `json:"exit_status"`
+	DurationMS   float64       `json:"duration_ms,omitempty"`
+	Phases       []PhaseTiming `json:"phases,omitempty"`
+	SlowestFiles []FileTiming  `json:"slowest_files,omitempty"`
+	Errors       []RunError    `json:"errors,omitempty"`
+}
+
+// PhaseTiming is how long one phase took and how many items it handled.
+type PhaseTiming struct {
+	Name       string  `json:"name"`
+	DurationMS float64 `json:"duration_ms"`
+	Items      int     `json:"items"`
+}
+
+// RunError is an error a phase failed with, with the exit status it calls
+// for, or, with File set, one of the sample files it failed on. A phase's
+// own error comes before its files'.
+type RunError struct {
+	Phase      string `json:"phase"`
+	File       string `json:"file,omitempty"`
+	Message    string `json:"message"`
+	ExitStatus int    `json:"exit_status,omitempty"`
+}
+
+// FileTiming is how long one sample file took to run or check.
+type FileTiming struct {
+	Name       string  `json:"name"`
+	DurationMS float64 `json:"duration_ms"`
+}
+
+func (RunStart) EventType() string       { return TypeRunStart }
+func (FileRunResult) EventType() string  { return TypeFileRunResult }
+func (AnalysisResult) EventType() string { return TypeAnalysisResult }
+func (TargetMatrix) EventType() string   { return TypeTargetMatrix }
+func (ReportSummary) EventType() string  { return TypeReportSummary }
+func (RunEnd) EventType() string         { return TypeRunEnd }
+
+// Writer writes events to an underlying writer, one JSON object per line.
+// It is safe for concurrent use.
+type Writer struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewWriter returns a Writer that writes events to w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+// Emit writes ev as one line, with its type as the first field.
+func (w *Writer) Emit(ev Event) error {
+	data, err := marshal(ev)
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, err := w.w.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write event: %w", err)
+	}
+	return nil
+}
+
+// marshal encodes ev as a JSON object with a leading "type" field.
+func marshal(ev Event) ([]byte, error) {
+	fields, err := json.Marshal(ev)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s event: %w", ev.EventType(), err)
+	}
+
+	data, _ := json.Marshal(ev.EventType())
+	data = append([]byte(`{"type":`), data...)
+	if len(fields) > 2 {
+		data = append(data, ',')
+	}
+	return append(data, fields[1:]...), nil
+}
+
+// Read decodes a stream written by Writer into its events, in order.
+func Read(r io.Reader) ([]Event, error) {
+	var stream []Event
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		var header struct {
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &header); err != nil {
+			return nil, fmt.Errorf("line %d: failed to decode event: %w", line, err)
+		}
+
+		var ev Event
+		switch header.Type {
+		case TypeRunStart:
+			ev = &RunStart{}
+		case TypeFileRunResult:
+			ev = &FileRunResult{}
+		case TypeAnalysisResult:
+			ev = &AnalysisResult{}
+		case TypeTargetMatrix:
+			ev = &TargetMatrix{}
+		case TypeReportSummary:
+			ev = &ReportSummary{}
+		case TypeRunEnd:
+			ev = &RunEnd{}
+		default:
+			return nil, fmt.Errorf("line %d: unknown event type %q", line, header.Type)
+		}
+		if err := json.Unmarshal(scanner.Bytes(), ev); err != nil {
+			return nil, fmt.Errorf("line %d: failed to decode %s event: %w", line, header.Type, err)
+		}
+		stream = append(stream, ev)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read events: %w", err)
+	}
+	return stream, nil
+}
@@ -0,0 +1,51 @@
+package events
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestWriteRead tests that events round-trip through the stream
+func TestWriteRead(t *testing.T) {
+	want := []Event{
+		&RunStart{Dirs: []string{"nodes/go"}, Files: []string{"nodes/go/a.go"}},
+		&FileRunResult{Name: "a.go", OK: true, DurationMS: 12.5, OutputBytes: 40},
+		&AnalysisResult{File: "nodes/go/a.go", Nodes: 120, UniqueTypes: 18},
+		&TargetMatrix{Targets: []string{"linux/amd64", "js/wasm"}, Files: []TargetMatrixRow{{Name: "a.go", Passed: []bool{true, false}}}},
+		&ReportSummary{Percent: 50, Covered: 28, Total: 56, Missing: []string{"*ast.BadExpr"}},
+		&RunEnd{},
+	}
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	for _, ev := range want {
+		if err := w.Emit(ev); err != nil {
+			t.Fatalf("Emit failed: %v", err)
+		}
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != len(want) {
+		t.Fatalf("expected %d lines, got %d:\n%s", len(want), len(lines), buf.String())
+	}
+	if lines[0] != `{"type":"run_start","dirs":["nodes/go"],"files":["nodes/go/a.go"]}` {
+		t.Errorf("unexpected encoding %s", lines[0])
+	}
+	if lines[5] != `{"type":"run_end","exit_status":0}` {
+		t.Errorf("unexpected encoding %s", lines[5])
+	}
+
+	got, err := Read(&buf)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("round trip mismatch:\ngot  %+v\nwant %+v", got, want)
+	}
+
+	if _, err := Read(strings.NewReader(`{"type":"bogus"}` + "\n")); err == nil {
+		t.Errorf("expected an error for an unknown event type")
+	}
+}
@@ -0,0 +1,52 @@
+package generator
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+)
+
+// canonicalNames assigns positional names (v1, v2, ...) to the unexported
+// identifiers declared inside each top-level function of file, keyed by
+// every *ast.Ident that declares or refers to them. Numbering restarts for
+// each function and follows the order of first appearance. Package-level
+// names, builtins, exported names, and blank identifiers are left alone,
+// as are identifiers the parser did not resolve, such as field names and
+// selectors. It relies on the Ident.Obj links set by the parser.
+func canonicalNames(file *ast.File) map[*ast.Ident]string {
+	names := make(map[*ast.Ident]string)
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok {
+			continue
+		}
+
+		objects := make(map[*ast.Object]string)
+		ast.Inspect(fn, func(n ast.Node) bool {
+			ident, ok := n.(*ast.Ident)
+			if !ok || ident.Obj == nil || ident.Name == "_" || token.IsExported(ident.Name) {
+				return true
+			}
+			if !declaredWithin(ident.Obj, fn) {
+				return true
+			}
+			name, ok := objects[ident.Obj]
+			if !ok {
+				name = fmt.Sprintf("v%d", len(objects)+1)
+				objects[ident.Obj] = name
+			}
+			names[ident] = name
+			return true
+		})
+	}
+	return names
+}
+
+// declaredWithin reports whether obj is declared inside the source range of fn.
+func declaredWithin(obj *ast.Object, fn *ast.FuncDecl) bool {
+	decl, ok := obj.Decl.(ast.Node)
+	if !ok {
+		return false
+	}
+	return decl.Pos() >= fn.Pos() && decl.Pos() < fn.End()
+}
@@ -0,0 +1,295 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around each change.
+const diffContext = 3
+
+// DiffDumps returns a unified diff from dump a to dump b and reports
+// whether they differ. Hunk headers name the declaration enclosing the
+// first change, such as "func main", instead of repeating a raw line.
+func DiffDumps(a, b string) (string, bool) {
+	return diffText("a", "b", a, b)
+}
+
+// DiffText is DiffDumps with nameA and nameB in the diff header.
+func DiffText(nameA, nameB, a, b string) (string, bool) {
+	return diffText(nameA, nameB, a, b)
+}
+
+// DiffFiles returns a unified diff between the dumps at pathA and pathB,
+// after normalizing their line endings, and reports whether they differ.
+func DiffFiles(pathA, pathB string) (string, bool, error) {
+	a, err := os.ReadFile(pathA)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read dump: %w", err)
+	}
+	b, err := os.ReadFile(pathB)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read dump: %w", err)
+	}
+
+	diff, changed := diffText(pathA, pathB, normalizeLineEndings(string(a)), normalizeLineEndings(string(b)))
+	return diff, changed, nil
+}
+
+// normalizeLineEndings converts CRLF and lone CR line endings to LF.
+func normalizeLineEndings(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	return strings.ReplaceAll(s, "\r", "\n")
+}
+
+// diffOp is one line of an edit script: ' ' kept, '-' deleted from a, or
+// '+' inserted from b.
+type diffOp struct {
+	kind byte
+	text string
+}
+
+// diffText diffs a and b line by line and renders the result with the
+// given file names.
+func diffText(nameA, nameB, a, b string) (string, bool) {
+	if a == b {
+		return "", false
+	}
+
+	linesA, linesB := splitLines(a), splitLines(b)
+	ops := myersDiff(linesA, linesB)
+	labelsA, labelsB := declarationLabels(linesA), declarationLabels(linesB)
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", nameA, nameB)
+
+	// posA[i] and posB[i] are the line indexes in a and b before ops[i]
+	posA := make([]int, len(ops)+1)
+	posB := make([]int, len(ops)+1)
+	for i, op := range ops {
+		posA[i+1], posB[i+1] = posA[i], posB[i]
+		if op.kind != '+' {
+			posA[i+1]++
+		}
+		if op.kind != '-' {
+			posB[i+1]++
+		}
+	}
+
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			i++
+			continue
+		}
+
+		// Extend the hunk while changes are within 2*diffContext lines
+		start := i - diffContext
+		if start < 0 {
+			start = 0
+		}
+		end := i
+		for j := i; j < len(ops); j++ {
+			if ops[j].kind != ' ' {
+				end = j + 1
+			} else if j-end >= 2*diffContext {
+				break
+			}
+		}
+		stop := end + diffContext
+		if stop > len(ops) {
+			stop = len(ops)
+		}
+
+		label := ""
+		if ops[i].kind == '-' {
+			label = labelsA[posA[i]]
+		} else {
+			label = labelsB[posB[i]]
+		}
+
+		header := fmt.Sprintf("@@ -%s +%s @@", hunkRange(posA[start], posA[stop]-posA[start]), hunkRange(posB[start], posB[stop]-posB[start]))
+		if label != "" {
+			header += " " + label
+		}
+		out.WriteString(header + "\n")
+		for _, op := range ops[start:stop] {
+			out.WriteByte(op.kind)
+			out.WriteString(op.text + "\n")
+		}
+
+		i = stop
+	}
+
+	return out.String(), true
+}
+
+// hunkRange formats a unified diff range from a zero-based start line.
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if count == 1 {
+		return fmt.Sprint(start + 1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+// splitLines splits s into lines without their terminating newlines.
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// myersDiff computes a shortest edit script from a to b using Myers'
+// O(ND) algorithm.
+func myersDiff(a, b []string) []diffOp {
+	n, m := len(a), len(b)
+	max := n + m
+	offset := max + 1
+	v := make([]int, 2*max+3)
+	var trace [][]int
+
+search:
+	for d := 0; d <= max; d++ {
+		trace = append(trace, append([]int(nil), v...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				break search
+			}
+		}
+	}
+
+	// Walk the trace backwards to recover the edit script
+	var ops []diffOp
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			ops = append(ops, diffOp{' ', a[x-1]})
+			x--
+			y--
+		}
+		if d == 0 {
+			break
+		}
+		if x == prevX {
+			ops = append(ops, diffOp{'+', b[y-1]})
+		} else {
+			ops = append(ops, diffOp{'-', a[x-1]})
+		}
+		x, y = prevX, prevY
+	}
+
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+	return ops
+}
+
+var (
+	// treeDeclPattern matches a declaration line in a tree dump, e.g.
+	// "  Decls[2]: GenDecl Tok=type".
+	treeDeclPattern = regexp.MustCompile(`^\s*Decls\[\d+\]: (FuncDecl|GenDecl)(?: Tok=(\w+))?`)
+
+	// fprintDeclPattern matches a declaration line in an ast.Fprint dump,
+	// e.g. "    12  .  .  0: *ast.FuncDecl {".
+	fprintDeclPattern = regexp.MustCompile(`^\s*\d+  [.\s]*\d+: \*ast\.(FuncDecl|GenDecl) \{`)
+
+	// fprintPrefixPattern matches the line number and depth dots that
+	// start every ast.Fprint line.
+	fprintPrefixPattern = regexp.MustCompile(`^\s*\d+  ((?:\.  )*)`)
+
+	// fprintTokPattern matches the Tok field of an ast.Fprint GenDecl.
+	fprintTokPattern = regexp.MustCompile(`\bTok: (\w+)$`)
+
+	// declNamePattern matches an identifier name in either format.
+	declNamePattern = regexp.MustCompile(`(?:\bNames?(?:\[0\])?: Ident Name="([^"]*)"|\bName: "([^"]*)")`)
+)
+
+// declarationLabels returns, for every line of a dump, a label naming the
+// top-level declaration it falls in, such as "func main" or "type Point".
+// The labels are indexed by line, with one extra entry for end of input.
+func declarationLabels(lines []string) []string {
+	labels := make([]string, len(lines)+1)
+	label := ""
+	named := true
+	nameDepth := 0 // depth of a function's name line; 0 accepts any deeper line
+	declDepth := 0
+	for i, line := range lines {
+		depth := dumpDepth(line)
+		if m := treeDeclPattern.FindStringSubmatch(line); m != nil {
+			label, named, declDepth = declKind(m[1], m[2]), false, depth
+			nameDepth = 0
+			if m[1] == "FuncDecl" {
+				nameDepth = depth + 1
+			}
+		} else if m := fprintDeclPattern.FindStringSubmatch(line); m != nil {
+			label, named, declDepth = declKind(m[1], ""), false, depth
+			nameDepth = 0
+			if m[1] == "FuncDecl" {
+				nameDepth = depth + 2
+			}
+		} else if label != "" && depth <= declDepth {
+			// Back at declaration level: the declaration has ended
+			label, named = "", true
+		} else if !named {
+			// A function's receiver precedes its name, so only the name
+			// field at the expected depth counts
+			if m := fprintTokPattern.FindStringSubmatch(line); m != nil && label == "decl" {
+				label = m[1]
+			} else if m := declNamePattern.FindStringSubmatch(line); m != nil && (nameDepth == 0 || depth == nameDepth) {
+				label += " " + m[1] + m[2]
+				named = true
+			}
+		}
+		labels[i] = label
+	}
+	labels[len(lines)] = label
+	return labels
+}
+
+// dumpDepth returns the nesting depth of a tree or ast.Fprint dump line.
+func dumpDepth(line string) int {
+	if m := fprintPrefixPattern.FindStringSubmatch(line); m != nil {
+		return len(m[1]) / 3
+	}
+	return (len(line) - len(strings.TrimLeft(line, " "))) / 2
+}
+
+// declKind names a declaration from its node type and token.
+func declKind(nodeType, tok string) string {
+	switch {
+	case nodeType == "FuncDecl":
+		return "func"
+	case tok != "":
+		return tok
+	default:
+		return "decl"
+	}
+}
@@ -0,0 +1,120 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const diffSource = `package shapes
+
+import "fmt"
+
+type Point struct {
+	X, Y int
+}
+
+func (p Point) Show() {
+	fmt.Println(p.X, p.Y)
+}
+
+func Add(a, b int) int {
+	return a + b
+}
+
+func Sub(a, b int) int {
+	return a - b
+}
+`
+
+// renderSource writes source to a temporary file and returns its dump
+func renderSource(t *testing.T, source string, opts Options) string {
+	t.Helper()
+	inDir := t.TempDir()
+	inPath := filepath.Join(inDir, "shapes.go")
+	if err := os.WriteFile(inPath, []byte(source), 0644); err != nil {
+		t.Fatalf("failed to write source: %v", err)
+	}
+	dump, err := renderDumpFile(inPath, opts)
+	if err != nil {
+		t.Fatalf("renderDumpFile failed: %v", err)
+	}
+	return string(dump)
+}
+
+// TestDiffDumpsHunkHeader tests that hunk headers name the edited function
+func TestDiffDumpsHunkHeader(t *testing.T) {
+	edited := strings.Replace(diffSource, "return a + b", "return a * b", 1)
+
+	for _, format := range []Format{FormatAST, FormatFprint} {
+		t.Run(string(format), func(t *testing.T) {
+			opts := Options{Format: format, Normalize: true}
+			before := renderSource(t, diffSource, opts)
+			after := renderSource(t, edited, opts)
+
+			diff, changed := DiffDumps(before, after)
+			if !changed {
+				t.Fatal("expected the dumps to differ")
+			}
+
+			var headers []string
+			for _, line := range strings.Split(diff, "\n") {
+				if strings.HasPrefix(line, "@@") {
+					headers = append(headers, line)
+				}
+			}
+			if len(headers) != 1 || !strings.HasSuffix(headers[0], "@@ func Add") {
+				t.Errorf("expected one hunk for func Add, got %q\n%s", headers, diff)
+			}
+			if !strings.HasPrefix(diff, "--- a\n+++ b\n") {
+				t.Errorf("missing file header:\n%s", diff)
+			}
+			if !strings.Contains(diff, "\n-") || !strings.Contains(diff, "\n+") {
+				t.Errorf("expected removed and added lines:\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestDiffDumpsReceiverMethod tests that receivers do not mislabel methods
+func TestDiffDumpsReceiverMethod(t *testing.T) {
+	edited := strings.Replace(diffSource, "fmt.Println(p.X, p.Y)", "fmt.Println(p.Y, p.X)", 1)
+	opts := Options{Format: FormatAST, Positions: PositionsNone}
+
+	diff, _ := DiffDumps(renderSource(t, diffSource, opts), renderSource(t, edited, opts))
+	if !strings.Contains(diff, "@@ func Show\n") {
+		t.Errorf("expected a hunk for func Show:\n%s", diff)
+	}
+}
+
+// TestDiffDumpsIdentical tests that identical dumps produce no diff
+func TestDiffDumpsIdentical(t *testing.T) {
+	dump := renderSource(t, diffSource, Options{Format: FormatAST})
+	if diff, changed := DiffDumps(dump, dump); changed || diff != "" {
+		t.Errorf("expected no diff, got %q", diff)
+	}
+}
+
+// TestDiffFilesLineEndings tests that CRLF line endings are normalized
+func TestDiffFilesLineEndings(t *testing.T) {
+	dir := t.TempDir()
+	dump := renderSource(t, diffSource, Options{Format: FormatAST})
+	pathA := filepath.Join(dir, "a.ast")
+	pathB := filepath.Join(dir, "b.ast")
+	os.WriteFile(pathA, []byte(dump), 0644)
+	os.WriteFile(pathB, []byte(strings.ReplaceAll(dump, "\n", "\r\n")), 0644)
+
+	if diff, changed, err := DiffFiles(pathA, pathB); err != nil || changed {
+		t.Errorf("expected no diff after normalization, got changed=%v err=%v\n%s", changed, err, diff)
+	}
+
+	os.WriteFile(pathB, []byte(strings.Replace(dump, `Name="Sub"`, `Name="Minus"`, 1)), 0644)
+	diff, changed, err := DiffFiles(pathA, pathB)
+	if err != nil || !changed {
+		t.Fatalf("expected a diff, got changed=%v err=%v", changed, err)
+	}
+	if !strings.HasPrefix(diff, "--- "+pathA+"\n+++ "+pathB+"\n") || !strings.Contains(diff, "@@ func Sub") {
+		t.Errorf("unexpected diff:\n%s", diff)
+	}
+}
@@ -4,53 +4,244 @@
 package generator
 
 import (
+	"bytes"
 	"fmt"
+	"go/ast"
 	"go/parser"
 	"go/token"
+	"io"
 	"os"
 	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
 	"strings"
 
 	"zylisp/go-ast-coverage/archive"
 )
 
+// Format selects the kind of output file the generator writes.
+type Format string
+
+const (
+	// FormatArchive writes .asta archives via the archive package.
+	FormatArchive Format = "asta"
+
+	// FormatFprint writes .ast text dumps produced by ast.Fprint, which show
+	// every non-nil struct field and are useful as a canonical reference.
+	FormatFprint Format = "fprint"
+
+	// FormatAST writes .ast text dumps as a compact indented tree with one
+	// node per line and annotations for constructs that are hard to spot in
+	// the raw structure, such as interface elements.
+	FormatAST Format = "ast"
+
+	// FormatMarkdown writes a .md summary for code review: declarations,
+	// imports, comment statistics, and the most common node types.
+	FormatMarkdown Format = "markdown"
+
+	// FormatTokens writes a .tokens dump with one line per scanned token
+	// and a summary of the distinct token kinds seen.
+	FormatTokens Format = "tokens"
+)
+
+// Options controls how WriteASTFilesWithOptions generates its output.
+type Options struct {
+	// Format selects the output kind. The zero value means FormatArchive.
+	Format Format
+
+	// Normalize produces VCS-friendly text dumps: positions and the
+	// map-backed Scope/Obj fields are omitted, imports in the header are
+	// sorted, line endings are LF-only, trailing whitespace is trimmed, and
+	// the dump ends with exactly one newline. Ignored for archives.
+	Normalize bool
+
+	// Manifest writes a <name>.nodes.json file next to each output listing
+	// the node types (and counts) found in the source. See CheckManifests.
+	Manifest bool
+
+	// Positions selects how node positions are rendered in text dumps.
+	// The zero value means PositionsStart. Ignored when Normalize is set.
+	Positions PositionMode
+
+	// MaxDepth limits how deep tree dumps descend; nodes at the limit are
+	// marked "[truncated]" instead of listing their children. Zero means
+	// no limit.
+	MaxDepth int
+
+	// Canonicalize renames unexported identifiers declared inside
+	// functions to positional names (v1, v2, ...) in tree dumps, so that
+	// functions differing only in local names render identically. The
+	// source itself is not changed.
+	Canonicalize bool
+
+	// StdinName labels positions when GenerateFile reads source from
+	// stdin. The zero value means "stdin.go".
+	StdinName string
+
+	// Output receives the per-file messages and summary the directory
+	// writers (WriteASTFiles, WriteAll, and their variants) print. The zero
+	// value means os.Stdout.
+	Output io.Writer
+
+	// OnFile, when set, is called by the directory writers with each input
+	// file's path before it is processed.
+	OnFile func(path string)
+}
+
+// output returns the writer for the directory writers' messages.
+func (o Options) output() io.Writer {
+	if o.Output == nil {
+		return os.Stdout
+	}
+	return o.Output
+}
+
+// PositionMode selects how node positions are rendered in text dumps.
+type PositionMode string
+
+const (
+	// PositionsStart renders each node's start position, e.g. "@3:1".
+	PositionsStart PositionMode = "start"
+
+	// PositionsSpan also renders each node's End position and byte length,
+	// e.g. "@3:1 end=5:2 len=40". Only tree dumps show spans.
+	PositionsSpan PositionMode = "span"
+
+	// PositionsNone omits positions entirely.
+	PositionsNone PositionMode = "none"
+)
+
+// formats lists every format accepted by ParseFormat, in help-text order.
+var formats = []Format{FormatArchive, FormatAST, FormatFprint, FormatMarkdown, FormatTokens}
+
+// ParseFormat converts a format name such as "ast" into a Format, returning
+// an error that lists the supported names when it is unknown.
+func ParseFormat(name string) (Format, error) {
+	for _, f := range formats {
+		if string(f) == name {
+			return f, nil
+		}
+	}
+	names := make([]string, len(formats))
+	for i, f := range formats {
+		names[i] = string(f)
+	}
+	return "", fmt.Errorf("unknown format %q (supported: %s)", name, strings.Join(names, ", "))
+}
+
+// ParsePositionMode converts a name such as "span" into a PositionMode,
+// returning an error that lists the supported names when it is unknown.
+func ParsePositionMode(name string) (PositionMode, error) {
+	switch mode := PositionMode(name); mode {
+	case PositionsStart, PositionsSpan, PositionsNone:
+		return mode, nil
+	}
+	return "", fmt.Errorf("unknown position mode %q (supported: start, span, none)", name)
+}
+
+// extension returns the output file extension for the format.
+func (f Format) extension() string {
+	switch f {
+	case FormatFprint, FormatAST:
+		return ".ast"
+	case FormatMarkdown:
+		return ".md"
+	case FormatTokens:
+		return ".tokens"
+	default:
+		return ".asta"
+	}
+}
+
+// OutputPath returns the file generating inPath into outDir in format
+// writes.
+func OutputPath(inPath, outDir string, format Format) string {
+	if format == "" {
+		format = FormatArchive
+	}
+	return filepath.Join(outDir, strings.TrimSuffix(filepath.Base(inPath), ".go")+format.extension())
+}
+
+// isText reports whether the format produces a text dump.
+func (f Format) isText() bool {
+	return f == FormatFprint || f == FormatAST || f == FormatMarkdown || f == FormatTokens
+}
+
 // WriteASTFiles generates AST archive files for all Go files in the input directory.
 // It reads .go files from inDir and writes .asta (AST Archive) files to outDir.
 func WriteASTFiles(inDir, outDir string) error {
-	// Create output directory if it doesn't exist
-	if err := os.MkdirAll(outDir, 0755); err != nil {
-		return fmt.Errorf("failed to create output directory: %w", err)
-	}
+	return WriteASTFilesWithOptions(inDir, outDir, Options{Format: FormatArchive})
+}
 
+// WriteASTFilesWithOptions generates output files for all Go files in the input
+// directory using the format selected in opts.
+func WriteASTFilesWithOptions(inDir, outDir string, opts Options) error {
 	// Read all files from input directory
 	entries, err := os.ReadDir(inDir)
 	if err != nil {
 		return fmt.Errorf("failed to read input directory: %w", err)
 	}
 
-	filesProcessed := 0
+	var paths []string
 	for _, entry := range entries {
-		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".go") {
-			continue
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".go") {
+			paths = append(paths, filepath.Join(inDir, entry.Name()))
 		}
+	}
+	return WriteFilesWithOptions(paths, outDir, opts)
+}
 
-		inPath := filepath.Join(inDir, entry.Name())
-		outPath := filepath.Join(outDir, strings.TrimSuffix(entry.Name(), ".go")+".asta")
+// WriteFilesWithOptions is WriteASTFilesWithOptions for the given Go files
+// rather than every Go file in a directory.
+func WriteFilesWithOptions(paths []string, outDir string, opts Options) error {
+	if opts.Format == "" {
+		opts.Format = FormatArchive
+	}
+	if opts.Format != FormatArchive && !opts.Format.isText() {
+		return fmt.Errorf("unknown format %q", opts.Format)
+	}
 
-		if err := generateASTFile(inPath, outPath); err != nil {
-			fmt.Printf("Warning: failed to generate AST for %s: %v\n", entry.Name(), err)
+	// Create output directory if it doesn't exist
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	out := opts.output()
+	filesProcessed := 0
+	for _, inPath := range paths {
+		if opts.OnFile != nil {
+			opts.OnFile(inPath)
+		}
+		name := filepath.Base(inPath)
+		outPath := OutputPath(inPath, outDir, opts.Format)
+
+		var genErr error
+		if opts.Format.isText() {
+			genErr = generateDumpFile(inPath, outPath, opts)
+		} else {
+			genErr = generateASTFile(inPath, outPath)
+		}
+		if genErr != nil {
+			fmt.Fprintf(out, "Warning: failed to generate AST for %s: %v\n", name, genErr)
 			continue
 		}
 
+		if opts.Manifest {
+			if err := writeManifest(inPath, outDir); err != nil {
+				fmt.Fprintf(out, "Warning: failed to write manifest for %s: %v\n", name, err)
+			}
+		}
+
 		filesProcessed++
-		fmt.Printf("  ✓ Generated %s\n", filepath.Base(outPath))
+		fmt.Fprintf(out, "  ✓ Generated %s\n", filepath.Base(outPath))
 	}
 
 	if filesProcessed == 0 {
 		return fmt.Errorf("no Go files processed")
 	}
 
-	fmt.Printf("\nGenerated %d AST files\n", filesProcessed)
+	fmt.Fprintf(out, "\nGenerated %d AST files\n", filesProcessed)
 	return nil
 }
 
@@ -77,3 +268,188 @@ func generateASTFile(inPath, outPath string) error {
 	return nil
 }
 
+// generateDumpFile parses a single Go file and writes its text dump.
+func generateDumpFile(inPath, outPath string, opts Options) error {
+	dump, err := renderDumpFile(inPath, opts)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(outPath, dump, 0644)
+}
+
+// renderDumpFile parses a single Go file and returns its text dump.
+func renderDumpFile(inPath string, opts Options) ([]byte, error) {
+	source, err := os.ReadFile(inPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read source file: %w", err)
+	}
+
+	// Label positions with the base name so dumps don't depend on the input path
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filepath.Base(inPath), source, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse file: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteDump(&buf, fset, file, source, opts); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// WriteDump writes the text dump of file selected by opts.Format to w,
+// preceded by a file-info header for all but the markdown format. source
+// is the text file was parsed from; only the token format rescans it.
+// Positions are resolved through fset into line:column form unless
+// opts.Normalize is set or opts.Positions is PositionsNone.
+func WriteDump(w io.Writer, fset *token.FileSet, file *ast.File, source []byte, opts Options) error {
+	var buf bytes.Buffer
+	if opts.Format != FormatMarkdown {
+		writeFileInfoHeader(&buf, fset, file, opts)
+	}
+
+	switch opts.Format {
+	case FormatMarkdown:
+		writeMarkdown(&buf, fset, file)
+	case FormatTokens:
+		if err := writeTokens(&buf, fset.File(file.Pos()).Name(), source, opts); err != nil {
+			return err
+		}
+	case FormatAST:
+		if err := writeTree(&buf, fset, file, opts); err != nil {
+			return err
+		}
+	default:
+		filter := fprintFilter
+		if opts.Normalize {
+			filter = normalizedFilter
+		} else if opts.Positions == PositionsNone {
+			filter = positionFreeFilter
+		}
+		if err := ast.Fprint(&buf, fset, file, filter); err != nil {
+			return fmt.Errorf("failed to print AST: %w", err)
+		}
+	}
+
+	out := buf.Bytes()
+	if opts.Normalize {
+		out = normalizeText(out)
+	}
+	if _, err := w.Write(out); err != nil {
+		return fmt.Errorf("failed to write dump: %w", err)
+	}
+	return nil
+}
+
+// writeFileInfoHeader writes the comment header that precedes every text dump.
+func writeFileInfoHeader(buf *bytes.Buffer, fset *token.FileSet, file *ast.File, opts Options) {
+	var imports []string
+	for _, imp := range file.Imports {
+		path, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			path = imp.Path.Value
+		}
+		imports = append(imports, path)
+	}
+	if opts.Normalize {
+		sort.Strings(imports)
+	}
+
+	fmt.Fprintf(buf, "// file: %s\n", fset.File(file.Pos()).Name())
+	fmt.Fprintf(buf, "// package: %s\n", file.Name.Name)
+	fmt.Fprintf(buf, "// imports: %s\n", strings.Join(imports, ", "))
+	fmt.Fprintln(buf)
+}
+
+var posType = reflect.TypeOf(token.NoPos)
+
+// fprintFilter extends ast.NotNilFilter by also dropping unset positions,
+// which would otherwise render as "-" and only add noise to the dump.
+func fprintFilter(name string, v reflect.Value) bool {
+	if v.Type() == posType && !token.Pos(v.Int()).IsValid() {
+		return false
+	}
+	return ast.NotNilFilter(name, v)
+}
+
+// positionFreeFilter extends ast.NotNilFilter by dropping every position.
+func positionFreeFilter(name string, v reflect.Value) bool {
+	if v.Type() == posType {
+		return false
+	}
+	return ast.NotNilFilter(name, v)
+}
+
+// normalizedFilter drops every position and the Scope/Obj fields, whose
+// contents come from maps and whose output order is not stable.
+func normalizedFilter(name string, v reflect.Value) bool {
+	if v.Type() == posType || name == "Scope" || name == "Obj" {
+		return false
+	}
+	return ast.NotNilFilter(name, v)
+}
+
+// normalizeText converts line endings to LF, trims trailing whitespace from
+// every line, and ensures the text ends with exactly one newline.
+func normalizeText(text []byte) []byte {
+	s := strings.ReplaceAll(string(text), "\r\n", "\n")
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t\r")
+	}
+	return []byte(strings.TrimRight(strings.Join(lines, "\n"), "\n") + "\n")
+}
+
+// WriteGoldenFiles writes normalized text dumps for every Go file in inDir
+// into goldenDir, ready to be committed and checked with VerifyGolden.
+func WriteGoldenFiles(inDir, goldenDir string) error {
+	return WriteASTFilesWithOptions(inDir, goldenDir, Options{Format: FormatFprint, Normalize: true})
+}
+
+// GoldenMismatch describes a golden dump that no longer matches its source.
+type GoldenMismatch struct {
+	File   string // golden file name, e.g. "expressions.ast"
+	Reason string // "missing" when no golden file exists, "differs" otherwise
+	Diff   string // unified diff from the golden file to the current dump
+}
+
+// VerifyGolden regenerates normalized dumps for every Go file in inDir in
+// memory and compares them against the files in goldenDir. It returns one
+// GoldenMismatch per file that is missing or differs; an empty result means
+// the golden directory is up to date.
+func VerifyGolden(inDir, goldenDir string) ([]GoldenMismatch, error) {
+	entries, err := os.ReadDir(inDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read input directory: %w", err)
+	}
+
+	opts := Options{Format: FormatFprint, Normalize: true}
+	var mismatches []GoldenMismatch
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".go") {
+			continue
+		}
+
+		goldenName := strings.TrimSuffix(entry.Name(), ".go") + opts.Format.extension()
+		dump, err := renderDumpFile(filepath.Join(inDir, entry.Name()), opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render %s: %w", entry.Name(), err)
+		}
+
+		golden, err := os.ReadFile(filepath.Join(goldenDir, goldenName))
+		if os.IsNotExist(err) {
+			mismatches = append(mismatches, GoldenMismatch{File: goldenName, Reason: "missing"})
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read golden file: %w", err)
+		}
+
+		if diff, changed := diffText(filepath.Join(goldenDir, goldenName), goldenName+" (regenerated)", string(normalizeText(golden)), string(dump)); changed {
+			mismatches = append(mismatches, GoldenMismatch{File: goldenName, Reason: "differs", Diff: diff})
+		}
+	}
+
+	return mismatches, nil
+}
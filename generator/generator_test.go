@@ -0,0 +1,141 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const tinySource = `package tiny
+
+func Hello() string {
+	return "hello"
+}
+`
+
+// writeTinySource writes tinySource into a fresh input directory and returns it.
+func writeTinySource(t *testing.T) string {
+	t.Helper()
+	inDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(inDir, "tiny.go"), []byte(tinySource), 0644); err != nil {
+		t.Fatalf("failed to write source: %v", err)
+	}
+	return inDir
+}
+
+// TestFprintFormat tests that the fprint format writes an ast.Fprint dump
+func TestFprintFormat(t *testing.T) {
+	inDir := writeTinySource(t)
+	outDir := filepath.Join(t.TempDir(), "out")
+
+	if err := WriteASTFilesWithOptions(inDir, outDir, Options{Format: FormatFprint}); err != nil {
+		t.Fatalf("WriteASTFilesWithOptions failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(outDir, "tiny.ast"))
+	if err != nil {
+		t.Fatalf("expected tiny.ast to be written: %v", err)
+	}
+	dump := string(data)
+
+	for _, want := range []string{"*ast.File {", "Name: *ast.Ident {", "Decls: []ast.Decl", `Name: "Hello"`, "tiny.go:3:6"} {
+		if !strings.Contains(dump, want) {
+			t.Errorf("fprint dump missing %q", want)
+		}
+	}
+}
+
+// TestUnknownFormat tests that unknown formats are rejected
+func TestUnknownFormat(t *testing.T) {
+	inDir := writeTinySource(t)
+	if err := WriteASTFilesWithOptions(inDir, t.TempDir(), Options{Format: "bogus"}); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
+
+// TestNormalizedDump tests that normalized dumps ignore layout and import order
+func TestNormalizedDump(t *testing.T) {
+	sourceA := "package tiny\n\nimport (\n\t\"os\"\n\t\"fmt\"\n)\n\nfunc Hello() { fmt.Println(os.Args) }\n"
+	sourceB := "package tiny\r\n\r\nimport (\r\n\t\"os\"\r\n\t\"fmt\"\r\n)\r\n\r\n\r\nfunc Hello() {\r\n\tfmt.Println(os.Args)\r\n}\r\n"
+
+	dirA, dirB := t.TempDir(), t.TempDir()
+	os.WriteFile(filepath.Join(dirA, "tiny.go"), []byte(sourceA), 0644)
+	os.WriteFile(filepath.Join(dirB, "tiny.go"), []byte(sourceB), 0644)
+
+	opts := Options{Format: FormatFprint, Normalize: true}
+	dumpA, err := renderDumpFile(filepath.Join(dirA, "tiny.go"), opts)
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+	dumpB, err := renderDumpFile(filepath.Join(dirB, "tiny.go"), opts)
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+
+	if string(dumpA) != string(dumpB) {
+		t.Error("expected normalized dumps of differently formatted sources to match")
+	}
+
+	dump := string(dumpA)
+	if !strings.Contains(dump, "// imports: fmt, os\n") {
+		t.Error("expected sorted imports in the file-info header")
+	}
+	if strings.Contains(dump, "tiny.go:") {
+		t.Error("expected normalized dump to omit positions")
+	}
+	if strings.Contains(dump, "\r") || !strings.HasSuffix(dump, "}\n") || strings.HasSuffix(dump, "\n\n") {
+		t.Error("expected LF-only output with a single trailing newline")
+	}
+}
+
+// TestVerifyGolden tests detection of stale and missing golden dumps
+func TestVerifyGolden(t *testing.T) {
+	inDir := writeTinySource(t)
+	goldenDir := filepath.Join(t.TempDir(), "golden")
+
+	if err := WriteGoldenFiles(inDir, goldenDir); err != nil {
+		t.Fatalf("WriteGoldenFiles failed: %v", err)
+	}
+
+	mismatches, err := VerifyGolden(inDir, goldenDir)
+	if err != nil {
+		t.Fatalf("VerifyGolden failed: %v", err)
+	}
+	if len(mismatches) != 0 {
+		t.Errorf("expected no mismatches, got %v", mismatches)
+	}
+
+	// A semantic change must be reported
+	changed := strings.Replace(tinySource, `"hello"`, `"goodbye"`, 1)
+	os.WriteFile(filepath.Join(inDir, "tiny.go"), []byte(changed), 0644)
+	mismatches, _ = VerifyGolden(inDir, goldenDir)
+	if len(mismatches) != 1 || mismatches[0].Reason != "differs" {
+		t.Fatalf("expected one differing file, got %v", mismatches)
+	}
+	if diff := mismatches[0].Diff; !strings.Contains(diff, "@@ func Hello") || !strings.Contains(diff, `goodbye`) {
+		t.Errorf("expected a diff for func Hello, got:\n%s", diff)
+	}
+
+	// A missing golden file must be reported
+	os.Remove(filepath.Join(goldenDir, "tiny.ast"))
+	mismatches, _ = VerifyGolden(inDir, goldenDir)
+	if len(mismatches) != 1 || mismatches[0].Reason != "missing" {
+		t.Errorf("expected one missing file, got %v", mismatches)
+	}
+}
+
+// TestParseFormat tests format name validation
+func TestParseFormat(t *testing.T) {
+	if f, err := ParseFormat("fprint"); err != nil || f != FormatFprint {
+		t.Errorf("expected fprint to parse, got %q, %v", f, err)
+	}
+
+	_, err := ParseFormat("yaml")
+	if err == nil {
+		t.Fatal("expected error for unsupported format")
+	}
+	if !strings.Contains(err.Error(), "supported: asta, ast, fprint, markdown, tokens") {
+		t.Errorf("expected error to list supported formats, got %v", err)
+	}
+}
@@ -0,0 +1,158 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"zylisp/go-ast-coverage/analyzer"
+)
+
+// manifestSuffix is appended to a source file's base name to form its manifest name.
+const manifestSuffix = ".nodes.json"
+
+// Manifest records the node types found in a source file when it was generated,
+// so later runs can detect per-file coverage regressions.
+type Manifest struct {
+	// Source is the path of the analyzed Go file, relative to the
+	// manifest's directory so the manifests can move with the sources.
+	Source string `json:"source"`
+
+	// NodeCounts maps each node type found in Source to its count.
+	NodeCounts map[string]int `json:"node_counts"`
+}
+
+// ManifestRegression describes a source file that lost node types
+// relative to its manifest.
+type ManifestRegression struct {
+	Manifest string   // manifest file name
+	Source   string   // source file the manifest describes
+	Lost     []string // node types in the manifest but no longer in the source
+}
+
+// writeManifest analyzes inPath and writes its manifest into outDir.
+func writeManifest(inPath, outDir string) error {
+	result, err := analyzer.AnalyzeFile(inPath)
+	if err != nil {
+		return fmt.Errorf("failed to analyze file: %w", err)
+	}
+
+	source, err := manifestSource(inPath, outDir)
+	if err != nil {
+		return err
+	}
+	name := strings.TrimSuffix(filepath.Base(inPath), ".go") + manifestSuffix
+	return saveManifest(filepath.Join(outDir, name), &Manifest{
+		Source:     source,
+		NodeCounts: result.NodeCounts,
+	})
+}
+
+// manifestSource returns inPath relative to the manifest directory dir, in
+// slash form. A path that has no relative form, such as one on another
+// volume, is kept absolute.
+func manifestSource(inPath, dir string) (string, error) {
+	absIn, err := filepath.Abs(inPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", inPath, err)
+	}
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", dir, err)
+	}
+	rel, err := filepath.Rel(absDir, absIn)
+	if err != nil {
+		return absIn, nil
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// sourcePath returns the path of the manifest's source, resolving a
+// relative Source against dir, the manifest's directory.
+func (m *Manifest) sourcePath(dir string) string {
+	source := filepath.FromSlash(m.Source)
+	if filepath.IsAbs(source) {
+		return source
+	}
+	return filepath.Join(dir, source)
+}
+
+// saveManifest writes m as indented JSON to path.
+func saveManifest(path string, m *Manifest) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// loadManifest reads a manifest from path.
+func loadManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to decode manifest: %w", err)
+	}
+	return &m, nil
+}
+
+// CheckManifests re-analyzes the source of every manifest in dir and returns
+// one ManifestRegression per file whose current node set lost types relative
+// to its manifest. When update is true, every manifest is rewritten with the
+// current counts after the comparison, accepting any regressions intentionally.
+func CheckManifests(dir string, update bool) ([]ManifestRegression, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	var regressions []ManifestRegression
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), manifestSuffix) {
+			continue
+		}
+
+		manifestPath := filepath.Join(dir, entry.Name())
+		manifest, err := loadManifest(manifestPath)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", entry.Name(), err)
+		}
+
+		source := manifest.sourcePath(dir)
+		result, err := analyzer.AnalyzeFile(source)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", entry.Name(), err)
+		}
+
+		var lost []string
+		for nodeType := range manifest.NodeCounts {
+			if result.NodeCounts[nodeType] == 0 {
+				lost = append(lost, nodeType)
+			}
+		}
+		if len(lost) > 0 {
+			sort.Strings(lost)
+			regressions = append(regressions, ManifestRegression{
+				Manifest: entry.Name(),
+				Source:   source,
+				Lost:     lost,
+			})
+		}
+
+		if update {
+			manifest.NodeCounts = result.NodeCounts
+			if err := saveManifest(manifestPath, manifest); err != nil {
+				return nil, fmt.Errorf("%s: %w", entry.Name(), err)
+			}
+		}
+	}
+
+	return regressions, nil
+}
@@ -0,0 +1,110 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestManifestGeneration tests that manifests are written next to the dumps
+func TestManifestGeneration(t *testing.T) {
+	inDir := writeTinySource(t)
+	outDir := t.TempDir()
+
+	if err := WriteASTFilesWithOptions(inDir, outDir, Options{Format: FormatFprint, Manifest: true}); err != nil {
+		t.Fatalf("WriteASTFilesWithOptions failed: %v", err)
+	}
+
+	manifest, err := loadManifest(filepath.Join(outDir, "tiny.nodes.json"))
+	if err != nil {
+		t.Fatalf("expected manifest to be written: %v", err)
+	}
+
+	if got := manifest.sourcePath(outDir); got != filepath.Join(inDir, "tiny.go") {
+		t.Errorf("manifest source %q resolves to %q, want tiny.go", manifest.Source, got)
+	}
+	if filepath.IsAbs(manifest.Source) {
+		t.Errorf("expected a source relative to the manifest, got %q", manifest.Source)
+	}
+	if manifest.NodeCounts["*ast.FuncDecl"] != 1 {
+		t.Errorf("expected 1 FuncDecl, got %d", manifest.NodeCounts["*ast.FuncDecl"])
+	}
+	if manifest.NodeCounts["*ast.BasicLit"] != 1 {
+		t.Errorf("expected 1 BasicLit, got %d", manifest.NodeCounts["*ast.BasicLit"])
+	}
+}
+
+// TestCheckManifests tests clean checks, regressions, and intentional updates
+func TestCheckManifests(t *testing.T) {
+	inDir := writeTinySource(t)
+	outDir := t.TempDir()
+
+	if err := WriteASTFilesWithOptions(inDir, outDir, Options{Format: FormatFprint, Manifest: true}); err != nil {
+		t.Fatalf("WriteASTFilesWithOptions failed: %v", err)
+	}
+
+	regressions, err := CheckManifests(outDir, false)
+	if err != nil {
+		t.Fatalf("CheckManifests failed: %v", err)
+	}
+	if len(regressions) != 0 {
+		t.Errorf("expected clean check, got %v", regressions)
+	}
+
+	// Drop the return statement so ReturnStmt and BasicLit disappear
+	regressed := "package tiny\n\nfunc Hello() string {\n\tpanic(nil)\n}\n"
+	os.WriteFile(filepath.Join(inDir, "tiny.go"), []byte(regressed), 0644)
+
+	regressions, err = CheckManifests(outDir, false)
+	if err != nil {
+		t.Fatalf("CheckManifests failed: %v", err)
+	}
+	if len(regressions) != 1 {
+		t.Fatalf("expected 1 regression, got %d", len(regressions))
+	}
+	lost := regressions[0].Lost
+	if len(lost) != 2 || lost[0] != "*ast.BasicLit" || lost[1] != "*ast.ReturnStmt" {
+		t.Errorf("expected BasicLit and ReturnStmt to be lost, got %v", lost)
+	}
+
+	// Updating accepts the regression for subsequent checks
+	if _, err := CheckManifests(outDir, true); err != nil {
+		t.Fatalf("CheckManifests update failed: %v", err)
+	}
+	regressions, _ = CheckManifests(outDir, false)
+	if len(regressions) != 0 {
+		t.Errorf("expected clean check after update, got %v", regressions)
+	}
+}
+
+// TestCheckManifestsMoved tests that manifests still find their sources
+// after the sources and manifests are moved together
+func TestCheckManifestsMoved(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "corpus")
+	inDir := filepath.Join(root, "go")
+	outDir := filepath.Join(root, "ast")
+	for _, dir := range []string{inDir, outDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("failed to create %s: %v", dir, err)
+		}
+	}
+	src := "package tiny\n\nfunc Hello() string {\n\treturn \"hi\"\n}\n"
+	if err := os.WriteFile(filepath.Join(inDir, "tiny.go"), []byte(src), 0644); err != nil {
+		t.Fatalf("failed to write source: %v", err)
+	}
+	if err := WriteASTFilesWithOptions(inDir, outDir, Options{Format: FormatFprint, Manifest: true}); err != nil {
+		t.Fatalf("WriteASTFilesWithOptions failed: %v", err)
+	}
+
+	moved := filepath.Join(t.TempDir(), "moved")
+	if err := os.Rename(root, moved); err != nil {
+		t.Fatalf("failed to move corpus: %v", err)
+	}
+	regressions, err := CheckManifests(filepath.Join(moved, "ast"), false)
+	if err != nil {
+		t.Fatalf("CheckManifests failed after the move: %v", err)
+	}
+	if len(regressions) != 0 {
+		t.Errorf("expected clean check, got %v", regressions)
+	}
+}
@@ -0,0 +1,250 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"zylisp/go-ast-coverage/analyzer"
+)
+
+// markdownTopNodes is the number of node types listed in markdown summaries.
+const markdownTopNodes = 10
+
+// declaration is one row of the markdown declarations table.
+type declaration struct {
+	kind      string
+	name      string
+	signature string
+	line      int
+}
+
+// GenerateMarkdown parses the Go file at inPath and writes a markdown
+// summary of it to w: its declarations, imports, comment statistics, and
+// most common node types.
+func GenerateMarkdown(inPath string, w io.Writer) error {
+	source, err := os.ReadFile(inPath)
+	if err != nil {
+		return fmt.Errorf("failed to read source file: %w", err)
+	}
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filepath.Base(inPath), source, parser.ParseComments)
+	if err != nil {
+		return fmt.Errorf("failed to parse file: %w", err)
+	}
+
+	var buf bytes.Buffer
+	writeMarkdown(&buf, fset, file)
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("failed to write markdown: %w", err)
+	}
+	return nil
+}
+
+// writeMarkdown writes the markdown summary of file to buf.
+func writeMarkdown(buf *bytes.Buffer, fset *token.FileSet, file *ast.File) {
+	fmt.Fprintf(buf, "# %s\n\n", fset.File(file.Pos()).Name())
+	fmt.Fprintf(buf, "Package `%s`\n\n", file.Name.Name)
+
+	buf.WriteString("## Declarations\n\n")
+	decls := collectDeclarations(fset, file)
+	if len(decls) == 0 {
+		buf.WriteString("None.\n\n")
+	} else {
+		buf.WriteString("| Kind | Name | Signature | Line |\n")
+		buf.WriteString("|------|------|-----------|------|\n")
+		for _, d := range decls {
+			signature := ""
+			if d.signature != "" {
+				signature = "`" + markdownCell(d.signature) + "`"
+			}
+			fmt.Fprintf(buf, "| %s | %s | %s | %d |\n", d.kind, d.name, signature, d.line)
+		}
+		buf.WriteString("\n")
+	}
+
+	buf.WriteString("## Imports\n\n")
+	if len(file.Imports) == 0 {
+		buf.WriteString("None.\n\n")
+	} else {
+		for _, imp := range file.Imports {
+			if imp.Name != nil {
+				fmt.Fprintf(buf, "- `%s %s`\n", imp.Name.Name, imp.Path.Value)
+			} else {
+				fmt.Fprintf(buf, "- `%s`\n", imp.Path.Value)
+			}
+		}
+		buf.WriteString("\n")
+	}
+
+	groups, comments, docs := commentStats(file)
+	buf.WriteString("## Comments\n\n")
+	fmt.Fprintf(buf, "- Comment groups: %d\n", groups)
+	fmt.Fprintf(buf, "- Comments: %d\n", comments)
+	fmt.Fprintf(buf, "- Doc comments on declarations: %d\n\n", docs)
+
+	counts, total := analyzer.CountNodes(file)
+	buf.WriteString("## Node Types\n\n")
+	fmt.Fprintf(buf, "%d nodes of %d types. Most common:\n\n", total, len(counts))
+	buf.WriteString("| Node Type | Count |\n")
+	buf.WriteString("|-----------|-------|\n")
+	for _, nc := range topNodeCounts(counts, markdownTopNodes) {
+		fmt.Fprintf(buf, "| %s | %d |\n", nc.Type, nc.Count)
+	}
+}
+
+// collectDeclarations lists the top-level declarations of file in source
+// order, one entry per declared name.
+func collectDeclarations(fset *token.FileSet, file *ast.File) []declaration {
+	var decls []declaration
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			kind := "func"
+			if d.Recv != nil {
+				kind = "method"
+			}
+			decls = append(decls, declaration{
+				kind:      kind,
+				name:      d.Name.Name,
+				signature: funcSignature(d),
+				line:      fset.Position(d.Name.Pos()).Line,
+			})
+
+		case *ast.GenDecl:
+			if d.Tok == token.IMPORT {
+				continue
+			}
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					signature := types.ExprString(s.Type)
+					if s.Assign.IsValid() {
+						signature = "= " + signature
+					}
+					if s.TypeParams != nil {
+						signature = fieldListString(s.TypeParams, "[", "]") + " " + signature
+					}
+					decls = append(decls, declaration{
+						kind:      "type",
+						name:      s.Name.Name,
+						signature: signature,
+						line:      fset.Position(s.Name.Pos()).Line,
+					})
+
+				case *ast.ValueSpec:
+					for i, name := range s.Names {
+						decls = append(decls, declaration{
+							kind:      d.Tok.String(),
+							name:      name.Name,
+							signature: valueSignature(s, i),
+							line:      fset.Position(name.Pos()).Line,
+						})
+					}
+				}
+			}
+		}
+	}
+	return decls
+}
+
+// funcSignature renders a function declaration's signature without its body.
+func funcSignature(d *ast.FuncDecl) string {
+	var b strings.Builder
+	b.WriteString("func ")
+	if d.Recv != nil {
+		b.WriteString(fieldListString(d.Recv, "(", ") "))
+	}
+	b.WriteString(d.Name.Name)
+	if d.Type.TypeParams != nil {
+		b.WriteString(fieldListString(d.Type.TypeParams, "[", "]"))
+	}
+	b.WriteString(strings.TrimPrefix(types.ExprString(&ast.FuncType{Params: d.Type.Params, Results: d.Type.Results}), "func"))
+	return b.String()
+}
+
+// fieldListString renders a field list such as a receiver or type
+// parameter list between open and close.
+func fieldListString(list *ast.FieldList, open, close string) string {
+	var parts []string
+	for _, field := range list.List {
+		var names []string
+		for _, name := range field.Names {
+			names = append(names, name.Name)
+		}
+		part := types.ExprString(field.Type)
+		if len(names) > 0 {
+			part = strings.Join(names, ", ") + " " + part
+		}
+		parts = append(parts, part)
+	}
+	return open + strings.Join(parts, ", ") + close
+}
+
+// valueSignature renders the type and value of the i'th name in a const or
+// var spec, e.g. "int = 100".
+func valueSignature(s *ast.ValueSpec, i int) string {
+	var parts []string
+	if s.Type != nil {
+		parts = append(parts, types.ExprString(s.Type))
+	}
+	if i < len(s.Values) {
+		parts = append(parts, "= "+types.ExprString(s.Values[i]))
+	}
+	return strings.Join(parts, " ")
+}
+
+// commentStats counts comment groups, individual comments, and
+// declarations that carry a doc comment.
+func commentStats(file *ast.File) (groups, comments, docs int) {
+	groups = len(file.Comments)
+	for _, group := range file.Comments {
+		comments += len(group.List)
+	}
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if d.Doc != nil {
+				docs++
+			}
+		case *ast.GenDecl:
+			if d.Doc != nil {
+				docs++
+			}
+		}
+	}
+	return groups, comments, docs
+}
+
+// topNodeCounts returns up to n node counts, most frequent first and ties
+// broken by name.
+func topNodeCounts(counts map[string]int, n int) []analyzer.NodeCount {
+	var sorted []analyzer.NodeCount
+	for nodeType, count := range counts {
+		sorted = append(sorted, analyzer.NodeCount{Type: nodeType, Count: count})
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Count != sorted[j].Count {
+			return sorted[i].Count > sorted[j].Count
+		}
+		return sorted[i].Type < sorted[j].Type
+	})
+	if len(sorted) > n {
+		sorted = sorted[:n]
+	}
+	return sorted
+}
+
+// markdownCell escapes text for use inside a markdown table cell.
+func markdownCell(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
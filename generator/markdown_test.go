@@ -0,0 +1,60 @@
+package generator
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var updateGolden = flag.Bool("update", false, "update golden files in testdata")
+
+// TestGenerateMarkdownGolden tests the markdown summary of declarations.go
+func TestGenerateMarkdownGolden(t *testing.T) {
+	var buf bytes.Buffer
+	if err := GenerateMarkdown("../nodes/go/declarations.go", &buf); err != nil {
+		t.Fatalf("GenerateMarkdown failed: %v", err)
+	}
+
+	goldenPath := filepath.Join("testdata", "declarations.md")
+	if *updateGolden {
+		if err := os.WriteFile(goldenPath, buf.Bytes(), 0644); err != nil {
+			t.Fatalf("failed to update golden file: %v", err)
+		}
+	}
+
+	golden, err := os.ReadFile(goldenPath)
+	if err != nil {
+		t.Fatalf("failed to read golden file: %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), golden) {
+		t.Errorf("markdown differs from %s; rerun with -update\n%s", goldenPath, buf.String())
+	}
+}
+
+// TestMarkdownFormat tests selecting markdown through WriteASTFilesWithOptions
+func TestMarkdownFormat(t *testing.T) {
+	inDir := writeTinySource(t)
+	outDir := t.TempDir()
+
+	if err := WriteASTFilesWithOptions(inDir, outDir, Options{Format: FormatMarkdown}); err != nil {
+		t.Fatalf("WriteASTFilesWithOptions failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(outDir, "tiny.md"))
+	if err != nil {
+		t.Fatalf("expected tiny.md: %v", err)
+	}
+	md := string(data)
+
+	for _, want := range []string{"# tiny.go", "## Declarations", "| func | Hello | `func Hello() string` | 3 |", "## Node Types"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown missing %q:\n%s", want, md)
+		}
+	}
+	if strings.HasPrefix(md, "// file:") {
+		t.Error("markdown should not start with the dump header")
+	}
+}
@@ -0,0 +1,85 @@
+package generator
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+
+	"zylisp/go-ast-coverage/archive"
+)
+
+// StdioPath is the path that means stdin when used as an input and stdout
+// when used as an output.
+const StdioPath = "-"
+
+// defaultStdinName labels positions for source read from stdin when
+// Options.StdinName is unset.
+const defaultStdinName = "stdin.go"
+
+// GenerateFile writes the output selected by opts.Format for the single Go
+// file at inPath to outPath. An inPath of "-" reads the source from stdin,
+// labelling positions with opts.StdinName; an outPath of "-" writes to
+// stdout.
+func GenerateFile(inPath, outPath string, opts Options) error {
+	if opts.Format == "" {
+		opts.Format = FormatArchive
+	}
+	if opts.Format != FormatArchive && !opts.Format.isText() {
+		return fmt.Errorf("unknown format %q", opts.Format)
+	}
+
+	var source []byte
+	var err error
+	filename := filepath.Base(inPath)
+	if inPath == StdioPath {
+		source, err = io.ReadAll(os.Stdin)
+		filename = opts.StdinName
+		if filename == "" {
+			filename = defaultStdinName
+		}
+	} else {
+		source, err = os.ReadFile(inPath)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read source file: %w", err)
+	}
+
+	if outPath == StdioPath {
+		w := bufio.NewWriter(os.Stdout)
+		if err := generateTo(w, filename, source, opts); err != nil {
+			return err
+		}
+		return w.Flush()
+	}
+
+	// Render fully before creating the output so failures leave no file behind
+	var buf bytes.Buffer
+	if err := generateTo(&buf, filename, source, opts); err != nil {
+		return err
+	}
+	return os.WriteFile(outPath, buf.Bytes(), 0644)
+}
+
+// generateTo parses source, labelled filename, and writes the output
+// selected by opts.Format to w.
+func generateTo(w io.Writer, filename string, source []byte, opts Options) error {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, source, parser.ParseComments)
+	if err != nil {
+		return fmt.Errorf("failed to parse file: %w", err)
+	}
+
+	if opts.Format == FormatArchive {
+		bundle, err := archive.NewBundle(file, fset, filename)
+		if err != nil {
+			return fmt.Errorf("failed to create AST archive: %w", err)
+		}
+		return archive.EncodeBundle(w, bundle)
+	}
+	return WriteDump(w, fset, file, source, opts)
+}
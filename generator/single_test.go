@@ -0,0 +1,119 @@
+package generator
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"zylisp/go-ast-coverage/archive"
+)
+
+// withStdio replaces os.Stdin with input and returns whatever fn writes to
+// os.Stdout
+func withStdio(t *testing.T, input string, fn func()) string {
+	t.Helper()
+
+	inR, inW, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("failed to create pipe: %v", err)
+	}
+	outR, outW, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("failed to create pipe: %v", err)
+	}
+
+	oldStdin, oldStdout := os.Stdin, os.Stdout
+	os.Stdin, os.Stdout = inR, outW
+	defer func() { os.Stdin, os.Stdout = oldStdin, oldStdout }()
+
+	go func() {
+		io.WriteString(inW, input)
+		inW.Close()
+	}()
+
+	done := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(outR)
+		done <- string(data)
+	}()
+
+	fn()
+	outW.Close()
+	return <-done
+}
+
+// TestGenerateFileStdio tests reading from stdin and writing to stdout
+func TestGenerateFileStdio(t *testing.T) {
+	var genErr error
+	out := withStdio(t, tinySource, func() {
+		genErr = GenerateFile(StdioPath, StdioPath, Options{Format: FormatAST, StdinName: "piped.go"})
+	})
+	if genErr != nil {
+		t.Fatalf("GenerateFile failed: %v", genErr)
+	}
+
+	if !strings.HasPrefix(out, "// file: piped.go\n// package: tiny\n") {
+		t.Errorf("unexpected dump header:\n%s", out)
+	}
+	if !strings.Contains(out, "FuncDecl") || !strings.HasSuffix(out, "\n") {
+		t.Errorf("expected a complete tree dump:\n%s", out)
+	}
+}
+
+// TestGenerateFileStdinArchive tests writing an archive for stdin to a file
+func TestGenerateFileStdinArchive(t *testing.T) {
+	outPath := filepath.Join(t.TempDir(), "tiny.asta")
+
+	var genErr error
+	out := withStdio(t, tinySource, func() {
+		genErr = GenerateFile(StdioPath, outPath, Options{Format: FormatArchive})
+	})
+	if genErr != nil {
+		t.Fatalf("GenerateFile failed: %v", genErr)
+	}
+	if out != "" {
+		t.Errorf("expected nothing on stdout, got %q", out)
+	}
+
+	a, err := archive.Load(outPath)
+	if err != nil {
+		t.Fatalf("failed to load archive: %v", err)
+	}
+	if a.GetFilename() != "stdin.go" || a.GetPackageName() != "tiny" {
+		t.Errorf("unexpected archive %s (package %s)", a.GetFilename(), a.GetPackageName())
+	}
+}
+
+// TestGenerateFileParseError tests that invalid input writes nothing
+func TestGenerateFileParseError(t *testing.T) {
+	var genErr error
+	out := withStdio(t, "package broken\n\nfunc {", func() {
+		genErr = GenerateFile(StdioPath, StdioPath, Options{Format: FormatAST})
+	})
+	if genErr == nil {
+		t.Fatal("expected a parse error")
+	}
+	if out != "" {
+		t.Errorf("expected stdout to stay clean, got %q", out)
+	}
+}
+
+// TestGenerateFilePaths tests file-to-file generation
+func TestGenerateFilePaths(t *testing.T) {
+	inDir := writeTinySource(t)
+	outPath := filepath.Join(t.TempDir(), "tiny.ast")
+
+	if err := GenerateFile(filepath.Join(inDir, "tiny.go"), outPath, Options{Format: FormatFprint}); err != nil {
+		t.Fatalf("GenerateFile failed: %v", err)
+	}
+	data, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatalf("expected output file: %v", err)
+	}
+	if !bytes.HasPrefix(data, []byte("// file: tiny.go\n")) {
+		t.Errorf("unexpected dump:\n%s", data)
+	}
+}
@@ -0,0 +1,115 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"go/scanner"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// GenerateTokens scans the Go file at inPath and writes its token dump to
+// w with start positions.
+func GenerateTokens(inPath string, w io.Writer) error {
+	return GenerateTokensWithOptions(inPath, w, Options{Format: FormatTokens})
+}
+
+// GenerateTokensWithOptions scans the Go file at inPath and writes its
+// token dump to w, rendering positions as opts selects.
+func GenerateTokensWithOptions(inPath string, w io.Writer, opts Options) error {
+	source, err := os.ReadFile(inPath)
+	if err != nil {
+		return fmt.Errorf("failed to read source file: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := writeTokens(&buf, filepath.Base(inPath), source, opts); err != nil {
+		return err
+	}
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("failed to write tokens: %w", err)
+	}
+	return nil
+}
+
+// writeTokens scans source, labelled filename, and writes one line per
+// token to buf in the form
+//
+//	<TOKEN> <literal> @line:col
+//
+// where the literal is quoted and omitted for keywords and operators, and
+// automatically inserted semicolons are marked "[auto]". The positions
+// follow opts.Positions as in tree dumps. A summary of the distinct token
+// kinds seen, with counts, ends the dump.
+func writeTokens(buf *bytes.Buffer, filename string, source []byte, opts Options) error {
+	fset := token.NewFileSet()
+	file := fset.AddFile(filename, -1, len(source))
+
+	var errs scanner.ErrorList
+	var s scanner.Scanner
+	s.Init(file, source, func(pos token.Position, msg string) {
+		errs.Add(pos, msg)
+	}, scanner.ScanComments)
+
+	counts := make(map[token.Token]int)
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		counts[tok]++
+
+		line := tok.String()
+		switch {
+		case tok == token.SEMICOLON && lit != ";":
+			line += " [auto]"
+		case lit != "" && (tok.IsLiteral() || tok == token.COMMENT):
+			line += fmt.Sprintf(" %q", lit)
+		}
+
+		length := len(lit)
+		if length == 0 || tok.IsKeyword() || tok.IsOperator() {
+			length = len(tok.String())
+		}
+		if tok == token.SEMICOLON && lit != ";" {
+			length = 0
+		}
+		if position := tokenPosition(fset, pos, length, opts); position != "" {
+			line += " " + position
+		}
+		buf.WriteString(line + "\n")
+	}
+	if errs.Len() > 0 {
+		return fmt.Errorf("failed to scan file: %w", errs.Err())
+	}
+
+	kinds := make([]token.Token, 0, len(counts))
+	for tok := range counts {
+		kinds = append(kinds, tok)
+	}
+	sort.Slice(kinds, func(i, j int) bool { return kinds[i] < kinds[j] })
+
+	fmt.Fprintf(buf, "\n// token kinds: %d\n", len(kinds))
+	for _, tok := range kinds {
+		fmt.Fprintf(buf, "//   %-10s %d\n", tok, counts[tok])
+	}
+	return nil
+}
+
+// tokenPosition formats a token position like treePrinter.position, given
+// the token's length in bytes.
+func tokenPosition(fset *token.FileSet, pos token.Pos, length int, opts Options) string {
+	if opts.Normalize || opts.Positions == PositionsNone {
+		return ""
+	}
+
+	start := fset.Position(pos)
+	if opts.Positions != PositionsSpan {
+		return fmt.Sprintf("@%d:%d", start.Line, start.Column)
+	}
+	end := fset.Position(pos + token.Pos(length))
+	return fmt.Sprintf("@%d:%d end=%d:%d len=%d", start.Line, start.Column, end.Line, end.Column, length)
+}
@@ -0,0 +1,73 @@
+package generator
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// TestGenerateTokensLiterals tests literal tokens from basic_literals.go
+func TestGenerateTokensLiterals(t *testing.T) {
+	var buf bytes.Buffer
+	if err := GenerateTokens("../nodes/go/basic_literals.go", &buf); err != nil {
+		t.Fatalf("GenerateTokens failed: %v", err)
+	}
+	out := buf.String()
+
+	for _, pattern := range []string{
+		`(?m)^IMAG "[0-9.e+-]+i" @\d+:\d+$`,
+		`(?m)^CHAR "'.+'" @\d+:\d+$`,
+		"(?m)^STRING \"`.*\" @\\d+:\\d+$",
+		`(?m)^package @\d+:1$`,
+		`(?m)^; \[auto\] @\d+:\d+$`,
+		`(?m)^//   IMAG +\d+$`,
+	} {
+		if !regexp.MustCompile(pattern).MatchString(out) {
+			t.Errorf("token dump has no line matching %s", pattern)
+		}
+	}
+}
+
+// TestTokensFormat tests selecting the token dump through WriteASTFilesWithOptions
+func TestTokensFormat(t *testing.T) {
+	inDir := writeTinySource(t)
+	outDir := t.TempDir()
+
+	if err := WriteASTFilesWithOptions(inDir, outDir, Options{Format: FormatTokens, Positions: PositionsSpan}); err != nil {
+		t.Fatalf("WriteASTFilesWithOptions failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(outDir, "tiny.tokens"))
+	if err != nil {
+		t.Fatalf("expected tiny.tokens: %v", err)
+	}
+	out := string(data)
+
+	for _, want := range []string{
+		"// file: tiny.go\n",
+		"package @1:1 end=1:8 len=7\n",
+		"IDENT \"Hello\" @3:6 end=3:11 len=5\n",
+		"STRING \"\\\"hello\\\"\" @4:9 end=4:16 len=7\n",
+		"// token kinds: ",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("token dump missing %q:\n%s", want, out)
+		}
+	}
+}
+
+// TestTokensPositionNone tests that positions can be omitted
+func TestTokensPositionNone(t *testing.T) {
+	inDir := writeTinySource(t)
+
+	dump, err := renderDumpFile(filepath.Join(inDir, "tiny.go"), Options{Format: FormatTokens, Positions: PositionsNone})
+	if err != nil {
+		t.Fatalf("renderDumpFile failed: %v", err)
+	}
+	if strings.Contains(string(dump), "@") {
+		t.Errorf("expected no positions:\n%s", dump)
+	}
+}
@@ -0,0 +1,345 @@
+package generator
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"io"
+	"reflect"
+	"strings"
+
+	"zylisp/go-ast-coverage/analyzer"
+)
+
+// Interface element kinds reported by the tree renderer.
+const (
+	elementMethod   = "method"
+	elementEmbedded = "embedded"
+	elementTypeTerm = "type term"
+)
+
+// nonInterfacePredeclared lists predeclared identifiers that name
+// non-interface types; an interface element naming one is a type term.
+var nonInterfacePredeclared = map[string]bool{
+	"bool": true, "byte": true, "complex64": true, "complex128": true,
+	"error": false, "float32": true, "float64": true, "int": true,
+	"int8": true, "int16": true, "int32": true, "int64": true,
+	"rune": true, "string": true, "uint": true, "uint8": true,
+	"uint16": true, "uint32": true, "uint64": true, "uintptr": true,
+}
+
+// skippedTreeFields are struct fields the tree renderer never descends into:
+// Obj and Scope carry resolver state rather than syntax, and File.Imports and
+// File.Unresolved only repeat nodes already reachable through Decls.
+var skippedTreeFields = map[string]bool{
+	"Obj":        true,
+	"Scope":      true,
+	"Imports":    true,
+	"Unresolved": true,
+}
+
+// treePrinter renders an AST as an indented tree with one node per line.
+// Each line has the form
+//
+//	<field>: <NodeType> <Attr=value>... [annotation]... @line:col
+//
+// where attributes are the node's scalar fields (names, literal values,
+// operator tokens) and children follow on deeper-indented lines.
+// With PositionsSpan the position grows into "@line:col end=line:col len=N".
+// Annotations mark interface element kinds, variadic parameters ("variadic"),
+// ellipsis types ("...T"), calls that spread a slice ("spread"), and range
+// statements over iterator functions or integers ("range-over-func",
+// "range-over-int").
+type treePrinter struct {
+	w    io.Writer
+	fset *token.FileSet
+	opts Options
+	err  error
+
+	// elements classifies the fields of every interface's method list.
+	elements map[*ast.Field]string
+
+	// renames maps locally declared identifiers to their canonical names
+	// when Options.Canonicalize is set.
+	renames map[*ast.Ident]string
+}
+
+// writeTree renders file as an indented tree to w.
+func writeTree(w io.Writer, fset *token.FileSet, file *ast.File, opts Options) error {
+	p := &treePrinter{
+		w:        w,
+		fset:     fset,
+		opts:     opts,
+		elements: classifyInterfaceElements(file),
+	}
+	if opts.Canonicalize {
+		p.renames = canonicalNames(file)
+	}
+	p.printNode(0, "", file)
+	if p.err != nil {
+		return fmt.Errorf("failed to render AST: %w", p.err)
+	}
+	return nil
+}
+
+// printf writes formatted output, remembering the first write error.
+func (p *treePrinter) printf(format string, args ...interface{}) {
+	if p.err != nil {
+		return
+	}
+	_, p.err = fmt.Fprintf(p.w, format, args...)
+}
+
+// printNode writes the header line for n followed by its children.
+func (p *treePrinter) printNode(depth int, label string, n ast.Node) {
+	v := reflect.ValueOf(n).Elem()
+	t := v.Type()
+
+	var header []string
+	if label != "" {
+		header = append(header, label+":")
+	}
+	header = append(header, t.Name())
+
+	for i := 0; i < t.NumField(); i++ {
+		if attr, ok := scalarAttr(t.Field(i).Name, v.Field(i)); ok {
+			header = append(header, attr)
+		}
+	}
+	if ident, ok := n.(*ast.Ident); ok {
+		if name, ok := p.renames[ident]; ok {
+			header[len(header)-1] = fmt.Sprintf("Name=%q", name)
+		}
+	}
+
+	for _, note := range p.annotations(n) {
+		header = append(header, "["+note+"]")
+	}
+	if p.opts.MaxDepth > 0 && depth >= p.opts.MaxDepth && hasChildNodes(v) {
+		header = append(header, "[truncated]")
+	}
+
+	if pos := p.position(n); pos != "" {
+		header = append(header, pos)
+	}
+
+	indent := strings.Repeat("  ", depth)
+	p.printf("%s%s\n", indent, strings.Join(header, " "))
+
+	// Spell out the individual terms of a type-set element before the raw
+	// BinaryExpr/UnaryExpr tree that encodes them.
+	if field, ok := n.(*ast.Field); ok && p.elements[field] == elementTypeTerm {
+		for i, term := range unionTerms(field.Type) {
+			p.printf("%s  Term[%d]: %s\n", indent, i, types.ExprString(term))
+		}
+	}
+
+	if p.opts.MaxDepth > 0 && depth >= p.opts.MaxDepth {
+		return
+	}
+	for i := 0; i < t.NumField(); i++ {
+		name := t.Field(i).Name
+		if skippedTreeFields[name] {
+			continue
+		}
+		p.printChildren(depth+1, name, v.Field(i))
+	}
+}
+
+// hasChildNodes reports whether the node struct v holds any child nodes
+// that printNode would descend into.
+func hasChildNodes(v reflect.Value) bool {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if skippedTreeFields[t.Field(i).Name] {
+			continue
+		}
+		fv := v.Field(i)
+		switch fv.Kind() {
+		case reflect.Interface, reflect.Ptr:
+			if !fv.IsNil() {
+				if _, ok := fv.Interface().(ast.Node); ok {
+					return true
+				}
+			}
+		case reflect.Slice:
+			if fv.Len() > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// annotations returns the bracketed notes shown after a node's attributes for
+// constructs that are hard to spot in the raw structure.
+func (p *treePrinter) annotations(n ast.Node) []string {
+	switch node := n.(type) {
+	case *ast.Field:
+		var notes []string
+		if kind, ok := p.elements[node]; ok {
+			notes = append(notes, kind)
+		}
+		if _, ok := node.Type.(*ast.Ellipsis); ok {
+			notes = append(notes, "variadic")
+		}
+		return notes
+	case *ast.Ellipsis:
+		if node.Elt == nil {
+			// [...]T array length
+			return []string{"..."}
+		}
+		return []string{"..." + types.ExprString(node.Elt)}
+	case *ast.CallExpr:
+		if node.Ellipsis.IsValid() {
+			return []string{"spread"}
+		}
+	case *ast.RangeStmt:
+		if analyzer.IsRangeOverFunc(node) {
+			return []string{"range-over-func"}
+		}
+		if analyzer.IsRangeOverInt(node) {
+			return []string{"range-over-int"}
+		}
+	}
+	return nil
+}
+
+// printChildren prints the node or nodes held in a struct field value.
+func (p *treePrinter) printChildren(depth int, name string, fv reflect.Value) {
+	switch fv.Kind() {
+	case reflect.Interface, reflect.Ptr:
+		if fv.IsNil() {
+			return
+		}
+		if child, ok := fv.Interface().(ast.Node); ok {
+			p.printNode(depth, name, child)
+		}
+	case reflect.Slice:
+		for i := 0; i < fv.Len(); i++ {
+			p.printChildren(depth, fmt.Sprintf("%s[%d]", name, i), fv.Index(i))
+		}
+	}
+}
+
+// position formats the start of n as "@line:col", followed by its end and
+// byte length when spans are enabled. Invalid positions render as "-".
+// It returns "" when positions are suppressed.
+func (p *treePrinter) position(n ast.Node) string {
+	if p.opts.Normalize || p.opts.Positions == PositionsNone {
+		return ""
+	}
+
+	start := "@" + p.lineCol(n.Pos())
+	if p.opts.Positions != PositionsSpan {
+		if start == "@-" {
+			return ""
+		}
+		return start
+	}
+
+	length := "-"
+	if startPos, endPos := p.fset.Position(n.Pos()), p.fset.Position(n.End()); startPos.IsValid() && endPos.IsValid() {
+		length = fmt.Sprint(endPos.Offset - startPos.Offset)
+	}
+	return fmt.Sprintf("%s end=%s len=%s", start, p.lineCol(n.End()), length)
+}
+
+// lineCol formats pos as "line:col", or "-" if it does not resolve to a
+// valid position in the FileSet.
+func (p *treePrinter) lineCol(pos token.Pos) string {
+	position := p.fset.Position(pos)
+	if !position.IsValid() {
+		return "-"
+	}
+	return fmt.Sprintf("%d:%d", position.Line, position.Column)
+}
+
+// scalarAttr renders a scalar struct field as "Name=value". Positions, empty
+// strings, and false booleans are omitted.
+func scalarAttr(name string, fv reflect.Value) (string, bool) {
+	switch value := fv.Interface().(type) {
+	case token.Pos:
+		return "", false
+	case token.Token:
+		if value == token.ILLEGAL {
+			return "", false
+		}
+		return name + "=" + value.String(), true
+	case ast.ChanDir:
+		return name + "=" + chanDirString(value), true
+	case string:
+		if value == "" {
+			return "", false
+		}
+		return fmt.Sprintf("%s=%q", name, value), true
+	case bool:
+		return name, value
+	}
+	return "", false
+}
+
+// chanDirString names a channel direction bit set.
+func chanDirString(dir ast.ChanDir) string {
+	switch dir {
+	case ast.SEND:
+		return "SEND"
+	case ast.RECV:
+		return "RECV"
+	default:
+		return "SEND|RECV"
+	}
+}
+
+// classifyInterfaceElements labels every element of every interface type in
+// file as a method, an embedded interface, or a type term. The distinction
+// between an embedded interface and a single non-union type term is
+// syntactic: unions, ~T terms, and predeclared non-interface types are type
+// terms; any other named type is assumed to be an embedded interface.
+func classifyInterfaceElements(file *ast.File) map[*ast.Field]string {
+	elements := make(map[*ast.Field]string)
+	ast.Inspect(file, func(n ast.Node) bool {
+		iface, ok := n.(*ast.InterfaceType)
+		if !ok || iface.Methods == nil {
+			return true
+		}
+		for _, field := range iface.Methods.List {
+			elements[field] = classifyInterfaceElement(field)
+		}
+		return true
+	})
+	return elements
+}
+
+// classifyInterfaceElement labels a single interface element.
+func classifyInterfaceElement(field *ast.Field) string {
+	if len(field.Names) > 0 {
+		return elementMethod
+	}
+	switch typ := field.Type.(type) {
+	case *ast.BinaryExpr:
+		if typ.Op == token.OR {
+			return elementTypeTerm
+		}
+	case *ast.UnaryExpr:
+		if typ.Op == token.TILDE {
+			return elementTypeTerm
+		}
+	case *ast.Ident:
+		if nonInterfacePredeclared[typ.Name] {
+			return elementTypeTerm
+		}
+	case *ast.ArrayType, *ast.MapType, *ast.ChanType, *ast.FuncType, *ast.StructType, *ast.StarExpr:
+		return elementTypeTerm
+	}
+	return elementEmbedded
+}
+
+// unionTerms flattens a union expression (a | b | c) into its terms.
+func unionTerms(expr ast.Expr) []ast.Expr {
+	if bin, ok := expr.(*ast.BinaryExpr); ok && bin.Op == token.OR {
+		return append(unionTerms(bin.X), unionTerms(bin.Y)...)
+	}
+	return []ast.Expr{expr}
+}
@@ -0,0 +1,264 @@
+package generator
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// renderCorpusTree renders a file from nodes/go with the tree format.
+func renderCorpusTree(t *testing.T, name string) string {
+	t.Helper()
+	dump, err := renderDumpFile("../nodes/go/"+name, Options{Format: FormatAST})
+	if err != nil {
+		t.Fatalf("failed to render %s: %v", name, err)
+	}
+	return string(dump)
+}
+
+// sectionAfter returns the n lines of dump following the first line containing marker.
+func sectionAfter(t *testing.T, dump, marker string, n int) string {
+	t.Helper()
+	lines := strings.Split(dump, "\n")
+	for i, line := range lines {
+		if strings.Contains(line, marker) {
+			end := i + 1 + n
+			if end > len(lines) {
+				end = len(lines)
+			}
+			return strings.Join(lines[i+1:end], "\n")
+		}
+	}
+	t.Fatalf("marker %q not found in dump", marker)
+	return ""
+}
+
+// TestTreeInterfaceElements tests that interface elements are labeled by kind
+func TestTreeInterfaceElements(t *testing.T) {
+	dump := renderCorpusTree(t, "interface_types.go")
+
+	ordered := sectionAfter(t, dump, `Name: Ident Name="Ordered"`, 20)
+	if !strings.Contains(ordered, "List[0]: Field [type term]") {
+		t.Errorf("expected Ordered's union to be a type term:\n%s", ordered)
+	}
+	for _, term := range []string{"Term[0]: ~int\n", "Term[12]: ~string\n"} {
+		if !strings.Contains(ordered, term) {
+			t.Errorf("expected Ordered to list %q", term)
+		}
+	}
+
+	stringable := sectionAfter(t, dump, `Name: Ident Name="Stringable"`, 40)
+	if !strings.Contains(stringable, "List[0]: Field [type term]") {
+		t.Errorf("expected Stringable's union to be a type term:\n%s", stringable)
+	}
+	if !strings.Contains(stringable, "List[1]: Field [method]") {
+		t.Errorf("expected Stringable's String to be a method:\n%s", stringable)
+	}
+
+	rwc := sectionAfter(t, dump, `Name: Ident Name="ReadWriteCloser"`, 12)
+	if strings.Count(rwc, "Field [embedded]") != 3 {
+		t.Errorf("expected three embedded interfaces in ReadWriteCloser:\n%s", rwc)
+	}
+
+	comparable := sectionAfter(t, dump, `Name: Ident Name="MyComparable"`, 4)
+	if !strings.Contains(comparable, "Field [embedded]") {
+		t.Errorf("expected comparable to be embedded:\n%s", comparable)
+	}
+}
+
+// TestTreeScalarAttributes tests that scalar fields render inline
+func TestTreeScalarAttributes(t *testing.T) {
+	inDir := writeTinySource(t)
+	dump, err := renderDumpFile(inDir+"/tiny.go", Options{Format: FormatAST})
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+
+	for _, want := range []string{
+		"File @1:1\n",
+		"  Decls[0]: FuncDecl @3:1\n",
+		`    Name: Ident Name="Hello" @3:6`,
+		`        Results[0]: BasicLit Kind=STRING Value="\"hello\"" @4:9`,
+	} {
+		if !strings.Contains(string(dump), want) {
+			t.Errorf("tree dump missing %q:\n%s", want, dump)
+		}
+	}
+}
+
+// TestTreeVariadics tests that variadic parameters and spread calls are visible
+func TestTreeVariadics(t *testing.T) {
+	dump := renderCorpusTree(t, "function_types.go")
+
+	// Declaration side: sum := func(nums ...int) int
+	if !strings.Contains(dump, "List[0]: Field [variadic]") {
+		t.Error("expected a variadic parameter annotation")
+	}
+	if !strings.Contains(dump, "Type: Ellipsis [...int]") {
+		t.Error("expected the ellipsis to show its element type")
+	}
+	if !strings.Contains(dump, "Type: Ellipsis [...interface{}]") {
+		t.Error("expected the interface{} ellipsis to show its element type")
+	}
+
+	// Call side: sum(values...)
+	calls := renderCorpusTree(t, "array_slice_types.go")
+	if !strings.Contains(calls, "CallExpr [spread]") {
+		t.Error("expected append(slice7, slice8...) to be marked as a spread call")
+	}
+	if !strings.Contains(calls, "Len: Ellipsis [...]") {
+		t.Error("expected the [...]int array length to render as a bare ellipsis")
+	}
+}
+
+// TestTreeSpans tests End positions and byte lengths of known nodes
+func TestTreeSpans(t *testing.T) {
+	inDir := writeTinySource(t)
+	dump, err := renderDumpFile(inDir+"/tiny.go", Options{Format: FormatAST, Positions: PositionsSpan})
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+
+	funcDecl := "func Hello() string {\n\treturn \"hello\"\n}"
+	for _, want := range []string{
+		fmt.Sprintf("Decls[0]: FuncDecl @3:1 end=5:2 len=%d\n", len(funcDecl)),
+		`Results[0]: BasicLit Kind=STRING Value="\"hello\"" @4:9 end=4:16 len=7`,
+		`Name: Ident Name="Hello" @3:6 end=3:11 len=5`,
+	} {
+		if !strings.Contains(string(dump), want) {
+			t.Errorf("span dump missing %q:\n%s", want, dump)
+		}
+	}
+}
+
+// TestTreeSpansInvalidPositions tests that invalid positions render as "-"
+func TestTreeSpansInvalidPositions(t *testing.T) {
+	var buf strings.Builder
+	p := &treePrinter{w: &buf, fset: token.NewFileSet(), opts: Options{Positions: PositionsSpan}}
+	p.printNode(0, "X", &ast.Ident{Name: "synthetic"})
+
+	if got := buf.String(); got != "X: Ident Name=\"synthetic\" @- end=- len=-\n" {
+		t.Errorf("unexpected rendering of a position-less node: %q", got)
+	}
+}
+
+// TestTreeMaxDepth tests that tree dumps stop descending at MaxDepth
+func TestTreeMaxDepth(t *testing.T) {
+	inDir := writeTinySource(t)
+	dump, err := renderDumpFile(inDir+"/tiny.go", Options{Format: FormatAST, MaxDepth: 1, Positions: PositionsNone})
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+
+	want := "File\n  Name: Ident Name=\"tiny\"\n  Decls[0]: FuncDecl [truncated]\n"
+	if !strings.HasSuffix(string(dump), want) {
+		t.Errorf("expected depth-limited tree %q, got:\n%s", want, dump)
+	}
+}
+
+// TestCanonicalizedTree tests that alpha-renamed functions dump identically
+func TestCanonicalizedTree(t *testing.T) {
+	sourceA := `package shapes
+
+import "fmt"
+
+var Scale = 2
+
+func Area(width, height int) int {
+	area := width * height * Scale
+	for i := 0; i < area; i++ {
+		fmt.Println(i)
+	}
+	return area
+}
+`
+	sourceB := `package shapes
+
+import "fmt"
+
+var Scale = 2
+
+func Area(w, h int) int {
+	product := w * h * Scale
+	for n := 0; n < product; n++ {
+		fmt.Println(n)
+	}
+	return product
+}
+`
+	opts := Options{Format: FormatAST, Positions: PositionsNone, Canonicalize: true}
+
+	dumps := make([]string, 2)
+	for i, source := range []string{sourceA, sourceB} {
+		inDir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(inDir, "shapes.go"), []byte(source), 0644); err != nil {
+			t.Fatalf("failed to write source: %v", err)
+		}
+		dump, err := renderDumpFile(filepath.Join(inDir, "shapes.go"), opts)
+		if err != nil {
+			t.Fatalf("renderDumpFile failed: %v", err)
+		}
+		dumps[i] = string(dump)
+	}
+
+	if dumps[0] != dumps[1] {
+		t.Errorf("canonicalized dumps differ:\n%s\n---\n%s", dumps[0], dumps[1])
+	}
+
+	for _, want := range []string{`Ident Name="v1"`, `Ident Name="v4"`, `Ident Name="Scale"`, `Ident Name="fmt"`, `Ident Name="Println"`, `Ident Name="int"`, `Ident Name="Area"`} {
+		if !strings.Contains(dumps[0], want) {
+			t.Errorf("dump missing %s:\n%s", want, dumps[0])
+		}
+	}
+	if strings.Contains(dumps[0], `"width"`) || strings.Contains(dumps[0], `"area"`) {
+		t.Error("local names should be renamed")
+	}
+}
+
+// TestTreeRangeOverFunc tests that range statements over iterator
+// functions are marked, with the ranged expression rendered beneath them
+func TestTreeRangeOverFunc(t *testing.T) {
+	dump := renderCorpusTree(t, "range_over_func.go")
+
+	if n := strings.Count(dump, "RangeStmt Tok=:= [range-over-func]"); n != 7 {
+		t.Errorf("expected 7 marked range statements with variables, got %d", n)
+	}
+	call := sectionAfter(t, dump, "RangeStmt Tok=:= [range-over-func]", 3)
+	if !strings.Contains(call, `X: CallExpr`) || !strings.Contains(call, `Fun: Ident Name="countTo"`) {
+		t.Errorf("expected countTo(4) as the ranged expression:\n%s", call)
+	}
+	bare := sectionAfter(t, dump, "RangeStmt [range-over-func]", 1)
+	if !strings.Contains(bare, `X: Ident Name="threeTimes"`) {
+		t.Errorf("expected the bare range over threeTimes:\n%s", bare)
+	}
+	if strings.Count(dump, "[range-over-func]") != 8 {
+		t.Errorf("expected the range over a slice not to be marked")
+	}
+}
+
+// TestTreeRangeOverInt tests that range statements over integers are
+// marked, in each of their forms
+func TestTreeRangeOverInt(t *testing.T) {
+	dump := renderCorpusTree(t, "range_over_int.go")
+
+	for _, want := range []string{
+		"RangeStmt Tok=:= [range-over-int]",
+		"RangeStmt Tok== [range-over-int]",
+		"RangeStmt [range-over-int]",
+	} {
+		if !strings.Contains(dump, want) {
+			t.Errorf("expected %q in the tree dump", want)
+		}
+	}
+	literal := sectionAfter(t, dump, "RangeStmt Tok=:= [range-over-int]", 2)
+	if !strings.Contains(literal, `X: BasicLit Kind=INT Value="5"`) {
+		t.Errorf("expected the literal 5 as the ranged expression:\n%s", literal)
+	}
+	if strings.Contains(dump, "[range-over-func]") {
+		t.Error("expected no range over an integer to be marked as over a func")
+	}
+}
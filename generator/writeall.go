@@ -0,0 +1,164 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"zylisp/go-ast-coverage/archive"
+)
+
+// WriteAllResult reports what WriteAll produced.
+type WriteAllResult struct {
+	Dumps    int      // text dumps written to astDir
+	Archives int      // .asta archives written to archiveDir
+	Failures []string // one "<file>: <error>" entry per failed output
+}
+
+// WriteAll parses every Go file in inDir once and writes both a text dump
+// into astDir and an .asta archive into archiveDir from the same *ast.File
+// and FileSet. The text format is taken from opts.Format, defaulting to
+// FormatAST when it is unset or FormatArchive. An empty astDir or
+// archiveDir skips that output. A failure in one output does not prevent
+// the other from being written.
+func WriteAll(inDir, astDir, archiveDir string, opts Options) (*WriteAllResult, error) {
+	entries, err := os.ReadDir(inDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read input directory: %w", err)
+	}
+
+	var paths []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".go") {
+			paths = append(paths, filepath.Join(inDir, entry.Name()))
+		}
+	}
+	return WriteAllFiles(paths, astDir, archiveDir, opts)
+}
+
+// WriteAllFiles is WriteAll for the given Go files rather than every Go
+// file in a directory.
+func WriteAllFiles(paths []string, astDir, archiveDir string, opts Options) (*WriteAllResult, error) {
+	w, err := NewWriter(astDir, archiveDir, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &WriteAllResult{}
+	for _, inPath := range paths {
+		if opts.OnFile != nil {
+			opts.OnFile(inPath)
+		}
+		result.add(w.WriteFile(inPath))
+	}
+
+	if result.Dumps == 0 && result.Archives == 0 {
+		return result, fmt.Errorf("no Go files processed")
+	}
+
+	fmt.Fprintf(w.out, "\nGenerated %d text dumps and %d archives\n", result.Dumps, result.Archives)
+	return result, nil
+}
+
+// Writer writes the outputs WriteAllFiles writes, one Go file at a time.
+type Writer struct {
+	astDir, archiveDir string
+	opts               Options
+	out                io.Writer
+}
+
+// NewWriter returns a Writer for astDir and archiveDir, creating them. The
+// text format is chosen as for WriteAll, and an empty directory skips that
+// output; at least one must be given.
+func NewWriter(astDir, archiveDir string, opts Options) (*Writer, error) {
+	if astDir == "" && archiveDir == "" {
+		return nil, fmt.Errorf("no output directory given")
+	}
+	if opts.Format == "" || opts.Format == FormatArchive {
+		opts.Format = FormatAST
+	}
+	if !opts.Format.isText() {
+		return nil, fmt.Errorf("unknown text format %q", opts.Format)
+	}
+
+	for _, dir := range []string{astDir, archiveDir} {
+		if dir == "" {
+			continue
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create output directory: %w", err)
+		}
+	}
+	return &Writer{astDir: astDir, archiveDir: archiveDir, opts: opts, out: opts.output()}, nil
+}
+
+// WriteFile parses the Go file at inPath once and writes its text dump and
+// archive, and its manifest with opts.Manifest. A failure in one output
+// does not prevent the others from being written; each is listed in the
+// result.
+func (w *Writer) WriteFile(inPath string) *WriteAllResult {
+	result := &WriteAllResult{}
+	name := filepath.Base(inPath)
+	baseName := strings.TrimSuffix(name, ".go")
+
+	source, err := os.ReadFile(inPath)
+	if err != nil {
+		result.Failures = append(result.Failures, fmt.Sprintf("%s: failed to read source file: %v", name, err))
+		return result
+	}
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, name, source, parser.ParseComments)
+	if err != nil {
+		result.Failures = append(result.Failures, fmt.Sprintf("%s: failed to parse file: %v", name, err))
+		return result
+	}
+
+	// The archive formats the AST back to source, which reads but never
+	// mutates it, so it runs first and the dump sees the same tree.
+	if w.archiveDir != "" {
+		archivePath := filepath.Join(w.archiveDir, baseName+FormatArchive.extension())
+		if err := archive.SaveASTWithSourcePreservation(file, fset, name, archivePath); err != nil {
+			result.Failures = append(result.Failures, fmt.Sprintf("%s: failed to create AST archive: %v", name, err))
+		} else {
+			result.Archives++
+			fmt.Fprintf(w.out, "  ✓ Generated %s\n", filepath.Base(archivePath))
+		}
+	}
+
+	if w.astDir != "" {
+		var buf bytes.Buffer
+		dumpPath := filepath.Join(w.astDir, baseName+w.opts.Format.extension())
+		if err := WriteDump(&buf, fset, file, source, w.opts); err != nil {
+			result.Failures = append(result.Failures, fmt.Sprintf("%s: %v", name, err))
+		} else if err := os.WriteFile(dumpPath, buf.Bytes(), 0644); err != nil {
+			result.Failures = append(result.Failures, fmt.Sprintf("%s: failed to write dump: %v", name, err))
+		} else {
+			result.Dumps++
+			fmt.Fprintf(w.out, "  ✓ Generated %s\n", filepath.Base(dumpPath))
+		}
+	}
+
+	if w.opts.Manifest {
+		manifestDir := w.astDir
+		if manifestDir == "" {
+			manifestDir = w.archiveDir
+		}
+		if err := writeManifest(inPath, manifestDir); err != nil {
+			result.Failures = append(result.Failures, fmt.Sprintf("%s: failed to write manifest: %v", name, err))
+		}
+	}
+	return result
+}
+
+// add counts other's outputs and failures into r.
+func (r *WriteAllResult) add(other *WriteAllResult) {
+	r.Dumps += other.Dumps
+	r.Archives += other.Archives
+	r.Failures = append(r.Failures, other.Failures...)
+}
@@ -0,0 +1,90 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"zylisp/go-ast-coverage/archive"
+)
+
+// TestWriteAll tests that one pass produces both a dump and an archive
+func TestWriteAll(t *testing.T) {
+	inDir := writeTinySource(t)
+	astDir := filepath.Join(t.TempDir(), "ast")
+	archiveDir := filepath.Join(t.TempDir(), "asta")
+
+	result, err := WriteAll(inDir, astDir, archiveDir, Options{})
+	if err != nil {
+		t.Fatalf("WriteAll failed: %v", err)
+	}
+
+	if result.Dumps != 1 || result.Archives != 1 || len(result.Failures) != 0 {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	dump, err := os.ReadFile(filepath.Join(astDir, "tiny.ast"))
+	if err != nil {
+		t.Fatalf("expected tiny.ast to be written: %v", err)
+	}
+	if !strings.Contains(string(dump), `Name: Ident Name="Hello"`) {
+		t.Error("dump does not describe the source")
+	}
+
+	arc, err := archive.Load(filepath.Join(archiveDir, "tiny.asta"))
+	if err != nil {
+		t.Fatalf("expected tiny.asta to load: %v", err)
+	}
+	if arc.GetSourceCode() != tinySource {
+		t.Error("archive source does not match the input")
+	}
+	if arc.GetFilename() != "tiny.go" {
+		t.Errorf("expected filename 'tiny.go', got '%s'", arc.GetFilename())
+	}
+}
+
+// TestWriteAllMatchesSeparateOutputs tests that the one-pass outputs are
+// identical to those produced by generating each format separately
+func TestWriteAllMatchesSeparateOutputs(t *testing.T) {
+	inDir := writeTinySource(t)
+	combinedDir := t.TempDir()
+	separateDir := t.TempDir()
+
+	if _, err := WriteAll(inDir, combinedDir, combinedDir, Options{}); err != nil {
+		t.Fatalf("WriteAll failed: %v", err)
+	}
+	if err := WriteASTFilesWithOptions(inDir, separateDir, Options{Format: FormatAST}); err != nil {
+		t.Fatalf("WriteASTFilesWithOptions failed: %v", err)
+	}
+
+	combined, _ := os.ReadFile(filepath.Join(combinedDir, "tiny.ast"))
+	separate, _ := os.ReadFile(filepath.Join(separateDir, "tiny.ast"))
+	if string(combined) != string(separate) {
+		t.Error("one-pass dump differs from separately generated dump")
+	}
+}
+
+// TestWriteAllSkipsOutput tests that an empty directory skips that output
+func TestWriteAllSkipsOutput(t *testing.T) {
+	inDir := writeTinySource(t)
+
+	archiveDir := t.TempDir()
+	result, err := WriteAll(inDir, "", archiveDir, Options{})
+	if err != nil || result.Dumps != 0 || result.Archives != 1 {
+		t.Errorf("archives only: %+v, %v", result, err)
+	}
+
+	astDir := t.TempDir()
+	result, err = WriteAll(inDir, astDir, "", Options{})
+	if err != nil || result.Dumps != 1 || result.Archives != 0 {
+		t.Errorf("dumps only: %+v, %v", result, err)
+	}
+	if entries, _ := os.ReadDir(astDir); len(entries) != 1 {
+		t.Errorf("expected only the dump, got %v", entries)
+	}
+
+	if _, err := WriteAll(inDir, "", "", Options{}); err == nil {
+		t.Error("expected an error with no output directory")
+	}
+}
@@ -0,0 +1,170 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"zylisp/go-ast-coverage/analyzer"
+)
+
+// DefaultXrefLimit is the number of locations kept per node type when
+// XrefOptions.Limit is unset.
+const DefaultXrefLimit = 20
+
+// XrefOptions controls how WriteXrefWithOptions builds the cross-reference.
+type XrefOptions struct {
+	// Limit caps the number of locations listed per node type. Zero means
+	// DefaultXrefLimit. Each file contributes at most
+	// analyzer.MaxLocations locations per type.
+	Limit int
+
+	// Text also writes a plain-text rendering next to the JSON file, with
+	// the same name and a .txt extension.
+	Text bool
+}
+
+// Xref maps node types to where they occur in a corpus, and files to the
+// node types they contain.
+type Xref struct {
+	// Limit is the cap that was applied to each Locations entry.
+	Limit int `json:"limit"`
+
+	// Counts maps each node type to its total number of occurrences,
+	// including those beyond Limit.
+	Counts map[string]int `json:"counts"`
+
+	// Locations maps each node type to "file:line" locations in file
+	// order, capped at Limit.
+	Locations map[string][]string `json:"locations"`
+
+	// Files maps each file name to the sorted node types it contains.
+	Files map[string][]string `json:"files"`
+}
+
+// WriteXref analyzes every Go file in inDir and writes the cross-reference
+// as JSON to outPath using the default options.
+func WriteXref(inDir, outPath string) error {
+	return WriteXrefWithOptions(inDir, outPath, XrefOptions{})
+}
+
+// WriteXrefWithOptions analyzes every Go file in inDir and writes the
+// cross-reference as JSON to outPath, plus a text rendering when opts.Text
+// is set.
+func WriteXrefWithOptions(inDir, outPath string, opts XrefOptions) error {
+	results, skipped, err := analyzer.AnalyzeDirectoryWithSkips(inDir)
+	if err != nil {
+		return err
+	}
+	for _, skip := range skipped {
+		fmt.Printf("Warning: failed to analyze %s: %v\n", skip.FileName, skip.Err)
+	}
+	if len(results) == 0 {
+		return fmt.Errorf("no Go files processed")
+	}
+
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = DefaultXrefLimit
+	}
+	x := buildXref(results, limit)
+
+	data, err := json.MarshalIndent(x, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal xref: %w", err)
+	}
+	if err := os.WriteFile(outPath, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write xref: %w", err)
+	}
+
+	if opts.Text {
+		textPath := strings.TrimSuffix(outPath, filepath.Ext(outPath)) + ".txt"
+		if err := os.WriteFile(textPath, x.text(), 0644); err != nil {
+			return fmt.Errorf("failed to write xref: %w", err)
+		}
+	}
+	return nil
+}
+
+// buildXref combines the analyzer's per-file node locations into an Xref.
+func buildXref(results []*analyzer.AnalysisResult, limit int) *Xref {
+	// Order files by name so capped location lists are stable
+	sort.Slice(results, func(i, j int) bool {
+		return filepath.Base(results[i].FileName) < filepath.Base(results[j].FileName)
+	})
+
+	x := &Xref{
+		Limit:     limit,
+		Counts:    make(map[string]int),
+		Locations: make(map[string][]string),
+		Files:     make(map[string][]string),
+	}
+	for _, result := range results {
+		name := filepath.Base(result.FileName)
+		var nodeTypes []string
+		for nodeType, count := range result.NodeCounts {
+			nodeTypes = append(nodeTypes, nodeType)
+			x.Counts[nodeType] += count
+			for _, pos := range result.Locations[nodeType] {
+				if len(x.Locations[nodeType]) >= limit {
+					break
+				}
+				x.Locations[nodeType] = append(x.Locations[nodeType], fmt.Sprintf("%s:%d", name, pos.Line))
+			}
+		}
+		sort.Strings(nodeTypes)
+		x.Files[name] = nodeTypes
+	}
+	return x
+}
+
+// text renders x as one section per node type.
+func (x *Xref) text() []byte {
+	var nodeTypes []string
+	for nodeType := range x.Locations {
+		nodeTypes = append(nodeTypes, nodeType)
+	}
+	sort.Strings(nodeTypes)
+
+	var buf bytes.Buffer
+	for _, nodeType := range nodeTypes {
+		locations := x.Locations[nodeType]
+		fmt.Fprintf(&buf, "%s (%d)\n", nodeType, x.Counts[nodeType])
+		for _, loc := range locations {
+			fmt.Fprintf(&buf, "  %s\n", loc)
+		}
+		if more := x.Counts[nodeType] - len(locations); more > 0 {
+			fmt.Fprintf(&buf, "  ... %d more\n", more)
+		}
+	}
+	return buf.Bytes()
+}
+
+// LoadXref reads a cross-reference written by WriteXref.
+func LoadXref(path string) (*Xref, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read xref: %w", err)
+	}
+
+	var x Xref
+	if err := json.Unmarshal(data, &x); err != nil {
+		return nil, fmt.Errorf("failed to decode xref: %w", err)
+	}
+	return &x, nil
+}
+
+// Lookup returns the recorded "file:line" locations of nodeType, such as
+// "*ast.SelectStmt", or nil if it does not occur.
+func (x *Xref) Lookup(nodeType string) []string {
+	return x.Locations[nodeType]
+}
+
+// LookupFile returns the node types that occur in the named file.
+func (x *Xref) LookupFile(name string) []string {
+	return x.Files[name]
+}
@@ -0,0 +1,75 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"zylisp/go-ast-coverage/analyzer"
+)
+
+// TestWriteXref tests writing and querying the cross-reference
+func TestWriteXref(t *testing.T) {
+	inDir := writeTinySource(t)
+	other := "package tiny\n\nfunc Loop() {\n\tfor {\n\t\tbreak\n\t}\n}\n"
+	if err := os.WriteFile(filepath.Join(inDir, "loop.go"), []byte(other), 0644); err != nil {
+		t.Fatalf("failed to write source: %v", err)
+	}
+
+	outPath := filepath.Join(t.TempDir(), "xref.json")
+	if err := WriteXrefWithOptions(inDir, outPath, XrefOptions{Limit: 2, Text: true}); err != nil {
+		t.Fatalf("WriteXrefWithOptions failed: %v", err)
+	}
+
+	x, err := LoadXref(outPath)
+	if err != nil {
+		t.Fatalf("LoadXref failed: %v", err)
+	}
+
+	if got := x.Lookup("*ast.ForStmt"); len(got) != 1 || got[0] != "loop.go:4" {
+		t.Errorf("expected ForStmt at loop.go:4, got %v", got)
+	}
+	if got := x.Lookup("*ast.ReturnStmt"); len(got) != 1 || got[0] != "tiny.go:4" {
+		t.Errorf("expected ReturnStmt at tiny.go:4, got %v", got)
+	}
+	if got := x.Lookup("*ast.SelectStmt"); got != nil {
+		t.Errorf("expected no SelectStmt locations, got %v", got)
+	}
+
+	// Identifiers occur more often than the limit
+	if got := x.Lookup("*ast.Ident"); len(got) != 2 {
+		t.Errorf("expected Ident locations capped at 2, got %v", got)
+	}
+	if x.Counts["*ast.Ident"] <= 2 {
+		t.Errorf("expected uncapped Ident count above 2, got %d", x.Counts["*ast.Ident"])
+	}
+
+	files := strings.Join(x.LookupFile("loop.go"), " ")
+	if !strings.Contains(files, "*ast.BranchStmt") || strings.Contains(files, "*ast.ReturnStmt") {
+		t.Errorf("unexpected node types for loop.go: %s", files)
+	}
+
+	text, err := os.ReadFile(filepath.Join(filepath.Dir(outPath), "xref.txt"))
+	if err != nil {
+		t.Fatalf("expected xref.txt: %v", err)
+	}
+	if !strings.Contains(string(text), "*ast.ForStmt (1)\n  loop.go:4\n") || !strings.Contains(string(text), "more\n") {
+		t.Errorf("unexpected xref text:\n%s", text)
+	}
+
+	// Counts are complete even where the analyzer capped its locations
+	many := "package tiny\n\nvar _ = []int{" + strings.Repeat("1, ", analyzer.MaxLocations+1) + "}\n"
+	if err := os.WriteFile(filepath.Join(inDir, "many.go"), []byte(many), 0644); err != nil {
+		t.Fatalf("failed to write source: %v", err)
+	}
+	if err := WriteXrefWithOptions(inDir, outPath, XrefOptions{}); err != nil {
+		t.Fatalf("WriteXrefWithOptions failed: %v", err)
+	}
+	if x, err = LoadXref(outPath); err != nil {
+		t.Fatalf("LoadXref failed: %v", err)
+	}
+	if got := x.Counts["*ast.BasicLit"]; got != analyzer.MaxLocations+2 {
+		t.Errorf("expected %d BasicLits counted, got %d", analyzer.MaxLocations+2, got)
+	}
+}
@@ -1,3 +1,3 @@
 module zylisp/go-ast-coverage
 
-go 1.21
+go 1.23
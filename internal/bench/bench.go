@@ -0,0 +1,177 @@
+// Package bench measures how long each stage of processing Go files takes
+// and how much it allocates, per file and summed over all of them, for the
+// orchestrator's -bench mode.
+package bench
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Measurement is the cost of one stage, for one file or summed over files.
+// Allocations are read from runtime.MemStats, so they include anything
+// other goroutines allocated while the stage ran.
+type Measurement struct {
+	Stage    string        `json:"stage"`
+	Duration time.Duration `json:"duration_ns"`
+	Allocs   uint64        `json:"allocs"`
+	Bytes    uint64        `json:"bytes"`
+}
+
+// FileResult is the measurements of one file's stages, in the order they
+// ran.
+type FileResult struct {
+	Name   string        `json:"name"`
+	Stages []Measurement `json:"stages"`
+}
+
+// Result is the outcome of Run: each file's measurements, and each
+// stage's summed over the files, in the order the stages first ran.
+type Result struct {
+	Files  []FileResult  `json:"files"`
+	Totals []Measurement `json:"totals"`
+}
+
+// Recorder measures the stages of one file for Run.
+type Recorder struct {
+	file *FileResult
+}
+
+// Measure runs fn as the named stage of the file, recording its cost, and
+// returns fn's error.
+func (r *Recorder) Measure(stage string, fn func() error) error {
+	m, err := Measure(stage, fn)
+	r.file.Stages = append(r.file.Stages, m)
+	return err
+}
+
+// Measure runs fn and returns how long it took and what it allocated as
+// the named stage, along with fn's error.
+func Measure(stage string, fn func() error) (Measurement, error) {
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	err := fn()
+	elapsed := time.Since(start)
+	runtime.ReadMemStats(&after)
+	return Measurement{
+		Stage:    stage,
+		Duration: elapsed,
+		Allocs:   after.Mallocs - before.Mallocs,
+		Bytes:    after.TotalAlloc - before.TotalAlloc,
+	}, err
+}
+
+// Run calls bench for each of files in turn with a Recorder for its
+// stages, and totals the stages over the files. It stops at the first
+// error, which names the file.
+func Run(files []string, bench func(path string, r *Recorder) error) (*Result, error) {
+	result := &Result{}
+	for _, path := range files {
+		file := FileResult{Name: filepath.Base(path)}
+		if err := bench(path, &Recorder{file: &file}); err != nil {
+			return nil, fmt.Errorf("failed to benchmark %s: %w", path, err)
+		}
+		result.Add(file)
+	}
+	return result, nil
+}
+
+// Add records file in the result and adds its stages to the totals.
+func (r *Result) Add(file FileResult) {
+	r.Files = append(r.Files, file)
+	for _, m := range file.Stages {
+		total := r.total(m.Stage)
+		total.Duration += m.Duration
+		total.Allocs += m.Allocs
+		total.Bytes += m.Bytes
+	}
+}
+
+// total returns the running total for stage, adding it if it is new.
+func (r *Result) total(stage string) *Measurement {
+	for i := range r.Totals {
+		if r.Totals[i].Stage == stage {
+			return &r.Totals[i]
+		}
+	}
+	r.Totals = append(r.Totals, Measurement{Stage: stage})
+	return &r.Totals[len(r.Totals)-1]
+}
+
+// Print writes a table of each file's stage timings, followed by each
+// stage's total time, mean time per file, and allocations.
+func (r *Result) Print(w io.Writer) {
+	stages := make([]string, len(r.Totals))
+	for i, total := range r.Totals {
+		stages[i] = total.Stage
+	}
+
+	nameWidth := len("File")
+	for _, file := range r.Files {
+		nameWidth = max(nameWidth, len(file.Name))
+	}
+	widths := make([]int, len(stages))
+	for i, stage := range stages {
+		widths[i] = max(len(stage), len(formatDuration(r.Totals[i].Duration)))
+	}
+
+	fmt.Fprintln(w, "=== Benchmark ===")
+	var line strings.Builder
+	fmt.Fprintf(&line, "%-*s", nameWidth, "File")
+	for i, stage := range stages {
+		fmt.Fprintf(&line, "  %*s", widths[i], stage)
+	}
+	fmt.Fprintln(w, line.String())
+	for _, file := range r.Files {
+		line.Reset()
+		fmt.Fprintf(&line, "%-*s", nameWidth, file.Name)
+		for i, stage := range stages {
+			cell := "-"
+			for _, m := range file.Stages {
+				if m.Stage == stage {
+					cell = formatDuration(m.Duration)
+				}
+			}
+			fmt.Fprintf(&line, "  %*s", widths[i], cell)
+		}
+		fmt.Fprintln(w, line.String())
+	}
+
+	stageWidth := len("Stage")
+	for _, stage := range stages {
+		stageWidth = max(stageWidth, len(stage))
+	}
+	fmt.Fprintf(w, "\n%-*s  %12s  %12s  %12s  %14s\n", stageWidth, "Stage", "Total", "Per file", "Allocs", "Bytes")
+	for _, total := range r.Totals {
+		perFile := time.Duration(0)
+		if len(r.Files) > 0 {
+			perFile = total.Duration / time.Duration(len(r.Files))
+		}
+		fmt.Fprintf(w, "%-*s  %12s  %12s  %12d  %14d\n", stageWidth, total.Stage,
+			formatDuration(total.Duration), formatDuration(perFile), total.Allocs, total.Bytes)
+	}
+}
+
+// formatDuration renders d in milliseconds with microsecond precision.
+func formatDuration(d time.Duration) string {
+	return fmt.Sprintf("%.3fms", float64(d)/float64(time.Millisecond))
+}
+
+// SaveJSON writes the result to path as indented JSON.
+func (r *Result) SaveJSON(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal benchmark results: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write benchmark results: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package bench
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// sink keeps allocations in the synthetic stages from being optimized away
+var sink [][]byte
+
+// syntheticBench measures a cheap "parse" stage and an "alloc" stage that
+// allocates size bytes per file
+func syntheticBench(size int) func(string, *Recorder) error {
+	return func(path string, r *Recorder) error {
+		if err := r.Measure("parse", func() error { return nil }); err != nil {
+			return err
+		}
+		return r.Measure("alloc", func() error {
+			sink = append(sink, make([]byte, size))
+			return nil
+		})
+	}
+}
+
+// TestRun tests that each file's stages are recorded in order and totaled
+// by stage
+func TestRun(t *testing.T) {
+	files := []string{"dir/a.go", "dir/b.go", "dir/c.go"}
+	result, err := Run(files, syntheticBench(1<<16))
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if len(result.Files) != 3 || result.Files[0].Name != "a.go" {
+		t.Fatalf("unexpected files: %+v", result.Files)
+	}
+	if len(result.Totals) != 2 || result.Totals[0].Stage != "parse" || result.Totals[1].Stage != "alloc" {
+		t.Fatalf("unexpected totals: %+v", result.Totals)
+	}
+
+	var duration time.Duration
+	var allocs, bytes uint64
+	for _, file := range result.Files {
+		alloc := file.Stages[1]
+		if alloc.Bytes < 1<<16 || alloc.Allocs == 0 {
+			t.Errorf("%s: expected the allocation to be counted, got %+v", file.Name, alloc)
+		}
+		duration += alloc.Duration
+		allocs += alloc.Allocs
+		bytes += alloc.Bytes
+	}
+	if total := result.Totals[1]; total.Duration != duration || total.Allocs != allocs || total.Bytes != bytes {
+		t.Errorf("alloc total %+v is not the sum of the files", total)
+	}
+}
+
+// TestRunError tests that Run stops at the first failing file
+func TestRunError(t *testing.T) {
+	var seen []string
+	_, err := Run([]string{"a.go", "broken.go", "c.go"}, func(path string, r *Recorder) error {
+		seen = append(seen, path)
+		return r.Measure("parse", func() error {
+			if path == "broken.go" {
+				return errors.New("syntax error")
+			}
+			return nil
+		})
+	})
+	if err == nil || !strings.Contains(err.Error(), "broken.go") || !strings.Contains(err.Error(), "syntax error") {
+		t.Errorf("expected the failure to name broken.go, got %v", err)
+	}
+	if len(seen) != 2 {
+		t.Errorf("expected Run to stop at broken.go, benchmarked %v", seen)
+	}
+}
+
+// TestPrintAndSaveJSON tests the table layout and that the JSON results
+// round-trip
+func TestPrintAndSaveJSON(t *testing.T) {
+	result := &Result{}
+	result.Add(FileResult{Name: "a.go", Stages: []Measurement{
+		{Stage: "parse", Duration: 1500 * time.Microsecond, Allocs: 10, Bytes: 1024},
+		{Stage: "analyze", Duration: 500 * time.Microsecond, Allocs: 5, Bytes: 512},
+	}})
+	result.Add(FileResult{Name: "b.go", Stages: []Measurement{
+		{Stage: "parse", Duration: 500 * time.Microsecond, Allocs: 6, Bytes: 1024},
+	}})
+
+	var out strings.Builder
+	result.Print(&out)
+	for _, want := range []string{
+		"File    parse  analyze\n",
+		"a.go  1.500ms  0.500ms\n",
+		"b.go  0.500ms        -\n",
+		"parse         2.000ms       1.000ms            16            2048\n",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "bench.json")
+	if err := result.SaveJSON(path); err != nil {
+		t.Fatalf("SaveJSON failed: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", path, err)
+	}
+	var loaded Result
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		t.Fatalf("failed to parse %s: %v", path, err)
+	}
+	if len(loaded.Files) != 2 || loaded.Totals[0].Duration != 2*time.Millisecond || loaded.Totals[0].Allocs != 16 {
+		t.Errorf("unexpected results after a round trip: %+v", loaded)
+	}
+	if !strings.Contains(string(data), `"duration_ns": 2000000`) {
+		t.Errorf("expected durations in nanoseconds:\n%s", data)
+	}
+}
+
+// TestProfiles tests that CPU and heap profiles are written
+func TestProfiles(t *testing.T) {
+	dir := t.TempDir()
+	cpu := filepath.Join(dir, "cpu.prof")
+	stop, err := StartCPUProfile(cpu)
+	if err != nil {
+		t.Fatalf("StartCPUProfile failed: %v", err)
+	}
+	if _, err := Run([]string{"a.go"}, syntheticBench(1024)); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if err := stop(); err != nil {
+		t.Fatalf("stopping the CPU profile failed: %v", err)
+	}
+
+	mem := filepath.Join(dir, "mem.prof")
+	if err := WriteHeapProfile(mem); err != nil {
+		t.Fatalf("WriteHeapProfile failed: %v", err)
+	}
+	for _, path := range []string{cpu, mem} {
+		if info, err := os.Stat(path); err != nil || info.Size() == 0 {
+			t.Errorf("expected a profile at %s: %v", path, err)
+		}
+	}
+}
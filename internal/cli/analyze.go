@@ -0,0 +1,148 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"zylisp/go-ast-coverage/analyzer"
+	report "zylisp/go-ast-coverage/coverage-report"
+	"zylisp/go-ast-coverage/events"
+	"zylisp/go-ast-coverage/internal/runner"
+)
+
+// analyzeFiles analyzes the sample files and prints AST statistics. With
+// -file each file's node distribution is printed, and dirs are not parsed
+// as packages. A file that cannot be analyzed is skipped with a warning,
+// and the phase fails once the rest have been analyzed; with -fail-fast it
+// stops the phase at once. An interrupt stops it before the next file,
+// printing the statistics of the files analyzed so far.
+func (o *options) analyzeFiles(dirs []string, files []string) error {
+	p := o.newProgress("analyzing", len(files))
+	a := &runner.Analysis{
+		Analyze:  analyzer.AnalyzeFile,
+		FailFast: o.failFast,
+		Begin:    p.begin,
+		Package: func(dir string) error {
+			o.infof("Analyzing %s as package for ast.Package coverage:", dir)
+			return analyzer.AnalyzePackage(dir)
+		},
+	}
+	var failure *runner.AnalyzedFile
+	summary := a.Run(o.context(), files, func(file *runner.AnalyzedFile) {
+		if file.Err != nil && o.failFast {
+			p.end()
+			failure = file
+		}
+		o.printAnalyzedFile(file)
+	})
+	p.end()
+	o.processed = summary.Processed
+
+	if failure != nil {
+		printSkipped(summary.Skipped)
+		return withStatus(ExitAnalysis, fmt.Errorf("failed to analyze %s: %w", filepath.Base(failure.Path), failure.Err))
+	}
+
+	// Print aggregated statistics
+	if aggregated := summary.Aggregated(); aggregated != nil {
+		if summary.Interrupted {
+			fmt.Printf("\n=== Aggregated Statistics (INCOMPLETE: interrupted after %d of %d files) ===\n", len(summary.Processed), len(files))
+		} else {
+			fmt.Println("\n=== Aggregated Statistics ===")
+		}
+		fmt.Printf("Total files analyzed: %d\n", len(summary.Results))
+		fmt.Printf("Total AST nodes: %d\n", aggregated.TotalNodes)
+		fmt.Printf("Unique node types: %d\n", aggregated.UniqueTypes)
+		fmt.Println()
+
+		fmt.Printf("Top %d most common node types:\n", report.DefaultTopN)
+		for i, nf := range report.MostCommonNodes(aggregated.NodeCounts, report.DefaultTopN) {
+			fmt.Printf("  %d. %-40s %5d\n", i+1, nf.Type, nf.Count)
+		}
+		fmt.Println()
+	}
+
+	if summary.Interrupted {
+		printInterrupted(summary.Skipped)
+		return errInterrupted(summary.Skipped)
+	}
+
+	// Parse each directory as a package to exercise ast.Package node
+	if o.filePaths == "" {
+		a.RunPackages(dirs, func(pkg *runner.PackageResult) {
+			if pkg.Err != nil {
+				o.warnf("Warning: failed to analyze package: %v", pkg.Err)
+			}
+			o.infof("")
+		})
+	}
+
+	if summary.Failed > 0 {
+		return withStatus(ExitAnalysis, fmt.Errorf("%d file(s) failed to analyze", summary.Failed))
+	}
+	return nil
+}
+
+// printAnalyzedFile prints one file's analysis with -verbose or -file and
+// emits it as an event, or reports that it could not be analyzed: as an
+// error that stops the phase with -fail-fast, and otherwise as a warning.
+func (o *options) printAnalyzedFile(file *runner.AnalyzedFile) {
+	name := filepath.Base(file.Path)
+	switch {
+	case file.Err != nil && o.failFast:
+		o.errorf("✗ failed to analyze %s:\n%v", name, file.Err)
+	case file.Err != nil:
+		o.warnf("Warning: failed to analyze %s: %v", name, file.Err)
+		o.fileError("analyze", file.Path, file.Err)
+	default:
+		if o.verbose || o.filePaths != "" {
+			analyzer.PrintAnalysis(file.Result)
+		}
+		o.emit(&events.AnalysisResult{File: file.Path, Nodes: file.Result.TotalNodes, UniqueTypes: file.Result.UniqueTypes})
+	}
+}
+
+// analyzeDirectories analyzes the Go files in each of dirs and returns
+// their results together, warning about each file that cannot be
+// analyzed.
+func (o *options) analyzeDirectories(dirs []string) ([]*analyzer.AnalysisResult, error) {
+	var results []*analyzer.AnalysisResult
+	for _, dir := range dirs {
+		dirResults, skipped, err := analyzer.AnalyzeDirectoryWithSkips(dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, skip := range skipped {
+			o.warnf("Warning: failed to analyze %s: %v", skip.FileName, skip.Err)
+		}
+		results = append(results, dirResults...)
+	}
+	return results, nil
+}
+
+// exportHeatmap writes the node count matrix of the files in dirs to
+// filePath, as JSON if it ends in .json and as CSV otherwise.
+func (o *options) exportHeatmap(dirs []string, filePath string) error {
+	results, err := o.analyzeDirectories(dirs)
+	if err != nil {
+		return err
+	}
+
+	opts := report.HeatmapOptions{Format: report.HeatmapCSV, ByCategory: o.heatmapByCat}
+	if strings.EqualFold(filepath.Ext(filePath), ".json") {
+		opts.Format = report.HeatmapJSON
+	}
+
+	f, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer f.Close()
+
+	if err := report.ExportHeatmapWithOptions(results, f, opts); err != nil {
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,35 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestAnalyzeFilesFailFast tests that an unparsable file is skipped with a
+// warning and fails the phase, and with -fail-fast stops it
+func TestAnalyzeFilesFailFast(t *testing.T) {
+	dir := writeFixture(t)
+	broken := filepath.Join(dir, "broken.go")
+	if err := os.WriteFile(broken, []byte("package main\n\nfunc main() {\n"), 0644); err != nil {
+		t.Fatalf("failed to write sample: %v", err)
+	}
+	files := []string{broken, filepath.Join(dir, "fixture.go")}
+
+	o := testOptions()
+	o.filePaths = strings.Join(files, ",")
+	out, err := captureOutput(t, func() error { return o.analyzeFiles(nil, files) })
+	if exitStatus(err) != ExitAnalysis || !strings.Contains(out, "Warning: failed to analyze broken.go") || !strings.Contains(out, "Total files analyzed: 1") {
+		t.Errorf("expected broken.go to be skipped and fail the phase, got %v:\n%s", err, out)
+	}
+
+	o.failFast = true
+	out, err = captureOutput(t, func() error { return o.analyzeFiles(nil, files) })
+	if exitStatus(err) != ExitAnalysis || !strings.Contains(err.Error(), "broken.go") {
+		t.Errorf("expected the failure to be returned, got %v", err)
+	}
+	if !strings.Contains(out, "1 file(s) skipped") || strings.Contains(out, "Total files analyzed") {
+		t.Errorf("expected the phase to stop at broken.go:\n%s", out)
+	}
+}
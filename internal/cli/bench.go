@@ -0,0 +1,109 @@
+package cli
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+
+	"zylisp/go-ast-coverage/analyzer"
+	"zylisp/go-ast-coverage/archive"
+	"zylisp/go-ast-coverage/generator"
+	"zylisp/go-ast-coverage/internal/bench"
+)
+
+// benchFiles times each stage of processing the sample files, one file at
+// a time: parsing, analysis, saving an archive to a temporary directory
+// and loading it back, and generating the "ast" text dump. It prints
+// per-file and total timings with allocation counts, and with -bench-json
+// saves them.
+func (o *options) benchFiles(files []string) error {
+	dir, err := os.MkdirTemp("", "go-ast-coverage-bench-")
+	if err != nil {
+		return fmt.Errorf("failed to create archive directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	genOpts := generator.Options{Format: generator.FormatAST}
+	p := o.newProgress("benchmarking", len(files))
+	result, err := bench.Run(files, func(path string, r *bench.Recorder) error {
+		p.begin(path)
+		source, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read sample: %w", err)
+		}
+		name := filepath.Base(path)
+		archivePath := filepath.Join(dir, name+".asta")
+
+		fset := token.NewFileSet()
+		var file *ast.File
+		stages := []struct {
+			name string
+			run  func() error
+		}{
+			{"parse", func() (err error) {
+				file, err = parser.ParseFile(fset, name, source, parser.ParseComments)
+				return err
+			}},
+			{"analyze", func() error {
+				result := analyzer.AnalyzeAST(name, fset, file)
+				result.TokenKindCounts = analyzer.ScanTokenKinds(source)
+				return nil
+			}},
+			{"save", func() error {
+				return archive.SaveASTWithSourcePreservation(file, fset, name, archivePath)
+			}},
+			{"load", func() error {
+				_, _, _, err := archive.LoadASTWithSourceReconstruction(archivePath)
+				return err
+			}},
+			{"generate", func() error {
+				return generator.WriteDump(io.Discard, fset, file, source, genOpts)
+			}},
+		}
+		for _, stage := range stages {
+			if err := r.Measure(stage.name, stage.run); err != nil {
+				return fmt.Errorf("%s failed: %w", stage.name, err)
+			}
+		}
+		return nil
+	})
+	p.end()
+	if err != nil {
+		return err
+	}
+
+	result.Print(os.Stdout)
+	if o.benchJSON != "" {
+		if err := result.SaveJSON(o.benchJSON); err != nil {
+			return err
+		}
+		o.infof("✓ Benchmark results saved to: %s", o.benchJSON)
+	}
+	return nil
+}
+
+// startProfiles starts the -cpuprofile profile, if any. The returned
+// function stops it and writes the -memprofile profile, if any, logging
+// rather than returning failures, since the run is over by then.
+func (o *options) startProfiles() (stop func(), err error) {
+	stopCPU := func() error { return nil }
+	if o.cpuProfile != "" {
+		if stopCPU, err = bench.StartCPUProfile(o.cpuProfile); err != nil {
+			return nil, err
+		}
+	}
+	return func() {
+		if err := stopCPU(); err != nil {
+			o.warnf("Warning: %v", err)
+		}
+		if o.memProfile != "" {
+			if err := bench.WriteHeapProfile(o.memProfile); err != nil {
+				o.warnf("Warning: %v", err)
+			}
+		}
+	}, nil
+}
@@ -0,0 +1,55 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"zylisp/go-ast-coverage/internal/bench"
+)
+
+// TestMainBench tests that bench times every stage of the fixture, saves
+// the results as JSON, and writes the requested profiles
+func TestMainBench(t *testing.T) {
+	dir := writeFixture(t)
+	out := t.TempDir()
+	jsonPath := filepath.Join(out, "bench.json")
+	cpu := filepath.Join(out, "cpu.prof")
+	mem := filepath.Join(out, "mem.prof")
+
+	var status int
+	stdout, _ := captureStdout(t, func() error {
+		status = Main([]string{"bench", "-nodes-dir", dir, "-bench-json", jsonPath, "-cpuprofile", cpu, "-memprofile", mem})
+		return nil
+	})
+	if status != 0 {
+		t.Fatalf("bench exited %d:\n%s", status, stdout)
+	}
+	if !strings.Contains(stdout, "=== Benchmark ===") || !strings.Contains(stdout, "fixture.go") {
+		t.Errorf("expected the benchmark table:\n%s", stdout)
+	}
+
+	data, err := os.ReadFile(jsonPath)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", jsonPath, err)
+	}
+	var result bench.Result
+	if err := json.Unmarshal(data, &result); err != nil {
+		t.Fatalf("failed to parse %s: %v", jsonPath, err)
+	}
+	var stages []string
+	for _, total := range result.Totals {
+		stages = append(stages, total.Stage)
+	}
+	if got := strings.Join(stages, ","); got != "parse,analyze,save,load,generate" || len(result.Files) != 1 {
+		t.Errorf("unexpected results: stages %s, %d file(s)", got, len(result.Files))
+	}
+
+	for _, path := range []string{cpu, mem} {
+		if info, err := os.Stat(path); err != nil || info.Size() == 0 {
+			t.Errorf("expected a profile at %s: %v", path, err)
+		}
+	}
+}
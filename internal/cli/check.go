@@ -0,0 +1,416 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"zylisp/go-ast-coverage/events"
+	"zylisp/go-ast-coverage/internal/runner"
+)
+
+// diagnostic is one positioned message from go build or go vet.
+type diagnostic struct {
+	file    string
+	line    int
+	column  int
+	message string
+}
+
+func (d diagnostic) String() string {
+	return fmt.Sprintf("%s:%d:%d: %s", d.file, d.line, d.column, d.message)
+}
+
+// sampleCheck is the outcome of compiling and vetting one sample file for
+// one target.
+type sampleCheck struct {
+	target      target
+	stage       string // "build" or "vet" when that stage failed
+	diagnostics []diagnostic
+	output      []byte
+	timedOut    bool
+	err         error
+	duration    time.Duration
+}
+
+// target is a GOOS/GOARCH pair samples are compiled for. The zero target
+// is the go tool's own.
+type target struct {
+	goos, goarch string
+}
+
+func (t target) String() string {
+	if t == (target{}) {
+		return "host"
+	}
+	return t.goos + "/" + t.goarch
+}
+
+// env returns the environment variables that select t.
+func (t target) env() []string {
+	if t == (target{}) {
+		return nil
+	}
+	return []string{"GOOS=" + t.goos, "GOARCH=" + t.goarch}
+}
+
+// parseTargets parses a comma-separated list of GOOS/GOARCH pairs, such
+// as "linux/amd64,js/wasm".
+func parseTargets(list string) ([]target, error) {
+	var targets []target
+	for _, pair := range strings.Split(list, ",") {
+		goos, goarch, ok := strings.Cut(strings.TrimSpace(pair), "/")
+		if !ok || goos == "" || goarch == "" || strings.Contains(goarch, "/") {
+			return nil, fmt.Errorf("invalid target %q (want GOOS/GOARCH, e.g. linux/amd64)", pair)
+		}
+		targets = append(targets, target{goos: goos, goarch: goarch})
+	}
+	return targets, nil
+}
+
+// goRunner runs the go tool in dir with args and the extra environment
+// variables env, returning its combined output. goCommand is the real one;
+// tests substitute their own.
+type goRunner func(ctx context.Context, dir string, env []string, args ...string) ([]byte, error)
+
+// diagnosticPattern matches the position prefix the go tool writes, such
+// as "./hello.go:5:2: undefined: x" or "vet: hello.go:5:2: ...".
+var diagnosticPattern = regexp.MustCompile(`^(?:vet: )?(?:\./)?([^\s:]+\.go):(\d+):(\d+): (.*)$`)
+
+// checkTestFiles compiles and vets the sample files instead of running
+// them, -jobs at a time, printing each file's diagnostics in file order.
+// With -targets each file is checked for every target in turn, and the
+// summary includes a file-by-target matrix. With -fail-fast, checking
+// stops at the first file that fails, and an interrupt stops it as it does
+// runTestFiles.
+func (o *options) checkTestFiles(files []string) error {
+	ctx, cancel := context.WithCancel(o.context())
+	defer cancel()
+
+	run := o.goToolRunner()
+	goVersion, err := toolchainGoVersion(run)
+	if err != nil {
+		return err
+	}
+	targets := o.targets
+	if len(targets) == 0 {
+		targets = []target{{}}
+	}
+
+	checks := make([][]sampleCheck, len(files))
+	passedCount := 0
+	failedCount := 0
+	timedOutCount := 0
+	interruptedAt := -1
+
+	p := o.newProgress("checking", len(files))
+	reported := runner.ForEachInOrder(len(files), o.runJobs, func(i int) {
+		p.begin(files[i])
+		checks[i] = checkSample(ctx, run, files[i], goVersion, targets, o.runTimeout)
+	}, func(next int) bool {
+		filePath := files[next]
+		if o.interrupted() && !checksPassed(checks[next]) {
+			interruptedAt = next
+			return false
+		}
+		o.infof("Checking %s...", filepath.Base(filePath))
+
+		failed, timedOut := false, false
+		var elapsed time.Duration
+		for _, check := range checks[next] {
+			elapsed += check.duration
+			ev := &events.FileRunResult{
+				Name:        filepath.Base(filePath),
+				OK:          check.err == nil,
+				TimedOut:    check.timedOut,
+				DurationMS:  float64(check.duration) / float64(time.Millisecond),
+				OutputBytes: len(check.output),
+			}
+			label := ""
+			if len(o.targets) > 0 {
+				ev.Target = check.target.String()
+				label = check.target.String() + ": "
+			}
+			o.emit(ev)
+
+			switch {
+			case check.timedOut:
+				o.warnf("  ⏱ %sTIMEOUT after %s", label, o.runTimeout)
+				timedOut = true
+			case check.err != nil:
+				if check.stage != "" {
+					o.warnf("  ✗ %s%s FAILED", label, strings.ToUpper(check.stage))
+				} else {
+					o.warnf("  ✗ %sFAILED: %v", label, check.err)
+				}
+				for _, d := range check.diagnostics {
+					o.warnf("    %s", d)
+				}
+				if check.stage != "" && len(check.diagnostics) == 0 {
+					o.warnf("Output:\n%s", check.output)
+				} else {
+					o.sampleOutput("Output", check.output)
+				}
+				failed = true
+			case label != "":
+				o.infof("  ✓ %s", check.target)
+			default:
+				o.infof("  ✓ OK")
+			}
+		}
+
+		o.timeFile(filePath, elapsed)
+
+		switch {
+		case timedOut:
+			timedOutCount++
+			o.fileError("check", filePath, fmt.Errorf("timed out after %s", o.runTimeout))
+		case failed:
+			failedCount++
+			o.fileError("check", filePath, checkFileError(checks[next]))
+		default:
+			passedCount++
+		}
+
+		if o.failFast && (failed || timedOut) {
+			cancel()
+			return false
+		}
+		return true
+	})
+	p.end()
+
+	fmt.Printf("\nCheck Summary: %d passed, %d failed, %d timed out\n", passedCount, failedCount, timedOutCount)
+	if interruptedAt >= 0 {
+		reported = interruptedAt
+	}
+	o.processed = files[:reported]
+	if o.interrupted() {
+		printInterrupted(len(files) - reported)
+	} else {
+		printSkipped(len(files) - reported)
+	}
+	if len(o.targets) > 0 {
+		o.printTargetMatrix(files[:reported], checks)
+	}
+
+	if o.interrupted() {
+		return errInterrupted(len(files) - reported)
+	}
+
+	if failedCount > 0 || timedOutCount > 0 {
+		return withStatus(ExitSamples, fmt.Errorf("%d file(s) failed to check, %d timed out", failedCount, timedOutCount))
+	}
+	return nil
+}
+
+// checksPassed reports whether every target's check passed.
+func checksPassed(checks []sampleCheck) bool {
+	for _, check := range checks {
+		if check.err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// checkFileError returns why a sample failed its checks: the first
+// failing target's stage and error, labeled with the target when there
+// are several.
+func checkFileError(checks []sampleCheck) error {
+	for _, check := range checks {
+		if check.err == nil {
+			continue
+		}
+		label := ""
+		if len(checks) > 1 {
+			label = check.target.String() + ": "
+		}
+		if check.stage != "" {
+			return fmt.Errorf("%s%s failed: %w", label, check.stage, check.err)
+		}
+		return fmt.Errorf("%s%w", label, check.err)
+	}
+	return nil
+}
+
+// printTargetMatrix prints which files passed for which targets, and
+// emits the matrix as a target_matrix event.
+func (o *options) printTargetMatrix(files []string, checks [][]sampleCheck) {
+	matrix := &events.TargetMatrix{}
+	for _, t := range o.targets {
+		matrix.Targets = append(matrix.Targets, t.String())
+	}
+
+	nameWidth := len("passed")
+	for _, file := range files {
+		nameWidth = max(nameWidth, len(filepath.Base(file)))
+	}
+	widths := make([]int, len(o.targets))
+	for j, name := range matrix.Targets {
+		widths[j] = max(len(name), len(fmt.Sprintf("%d/%d", len(files), len(files))))
+	}
+
+	// Rows are built whole so their trailing padding can be trimmed
+	var line strings.Builder
+	printLine := func() {
+		fmt.Println(strings.TrimRight(line.String(), " "))
+		line.Reset()
+	}
+
+	fmt.Println("\nTarget Matrix:")
+	fmt.Fprintf(&line, "  %-*s", nameWidth, "")
+	for j, name := range matrix.Targets {
+		fmt.Fprintf(&line, "  %-*s", widths[j], name)
+	}
+	printLine()
+
+	passed := make([]int, len(o.targets))
+	for i, file := range files {
+		row := events.TargetMatrixRow{Name: filepath.Base(file)}
+		fmt.Fprintf(&line, "  %-*s", nameWidth, row.Name)
+		for j, check := range checks[i] {
+			ok := check.err == nil
+			row.Passed = append(row.Passed, ok)
+			mark := "✗"
+			if ok {
+				mark = "✓"
+				passed[j]++
+			}
+			// Pad by width in characters; the marks are multi-byte
+			fmt.Fprintf(&line, "  %s%s", mark, strings.Repeat(" ", widths[j]-1))
+		}
+		printLine()
+		matrix.Files = append(matrix.Files, row)
+	}
+
+	fmt.Fprintf(&line, "  %-*s", nameWidth, "passed")
+	for j := range o.targets {
+		fmt.Fprintf(&line, "  %-*s", widths[j], fmt.Sprintf("%d/%d", passed[j], len(files)))
+	}
+	printLine()
+
+	o.emit(matrix)
+}
+
+// checkSample builds and then vets a sample file on its own, copied into a
+// temporary module, so that samples sharing a directory and each declaring
+// package main do not clash. It is checked for each target in turn, with a
+// fresh timeout for each. Diagnostics name the original file.
+func checkSample(ctx context.Context, run goRunner, filePath, goVersion string, targets []target, timeout time.Duration) []sampleCheck {
+	checks := make([]sampleCheck, len(targets))
+	fail := func(err error) []sampleCheck {
+		for i := range checks {
+			checks[i] = sampleCheck{target: targets[i], err: err}
+		}
+		return checks
+	}
+
+	source, err := os.ReadFile(filePath)
+	if err != nil {
+		return fail(fmt.Errorf("failed to read sample: %w", err))
+	}
+
+	dir, err := os.MkdirTemp("", "go-ast-coverage-check-")
+	if err != nil {
+		return fail(fmt.Errorf("failed to create check directory: %w", err))
+	}
+	defer os.RemoveAll(dir)
+
+	name := filepath.Base(filePath)
+	goMod := "module sample\n"
+	if goVersion != "" {
+		goMod += "\ngo " + goVersion + "\n"
+	}
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte(goMod), 0644); err != nil {
+		return fail(fmt.Errorf("failed to write go.mod: %w", err))
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), source, 0644); err != nil {
+		return fail(fmt.Errorf("failed to copy sample: %w", err))
+	}
+
+	for i, t := range targets {
+		start := time.Now()
+		checks[i] = checkTarget(ctx, run, dir, name, filePath, t, timeout)
+		checks[i].duration = time.Since(start)
+	}
+	return checks
+}
+
+// checkTarget builds and vets the sample module in dir for t.
+func checkTarget(ctx context.Context, run goRunner, dir, name, filePath string, t target, timeout time.Duration) sampleCheck {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	for _, stage := range []struct {
+		name string
+		args []string
+	}{
+		{"build", []string{"build", "-o", os.DevNull, "."}},
+		{"vet", []string{"vet", "."}},
+	} {
+		output, err := run(ctx, dir, t.env(), stage.args...)
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return sampleCheck{target: t, output: output, timedOut: true, err: ctx.Err()}
+		}
+		if err != nil {
+			return sampleCheck{
+				target:      t,
+				stage:       stage.name,
+				diagnostics: parseDiagnostics(output, name, filePath),
+				output:      output,
+				err:         fmt.Errorf("go %s failed: %w", stage.name, err),
+			}
+		}
+	}
+	return sampleCheck{target: t}
+}
+
+// parseDiagnostics extracts the positioned messages from go tool output,
+// reporting those in the file called name against filePath. Indented lines
+// continue the message before them; anything else is dropped.
+func parseDiagnostics(output []byte, name, filePath string) []diagnostic {
+	var diags []diagnostic
+	for _, line := range strings.Split(string(output), "\n") {
+		if m := diagnosticPattern.FindStringSubmatch(line); m != nil {
+			file := m[1]
+			if filepath.Base(file) == name {
+				file = filePath
+			}
+			lineNum, _ := strconv.Atoi(m[2])
+			column, _ := strconv.Atoi(m[3])
+			diags = append(diags, diagnostic{file: file, line: lineNum, column: column, message: m[4]})
+		} else if strings.HasPrefix(line, "\t") && len(diags) > 0 {
+			diags[len(diags)-1].message += "\n" + line
+		}
+	}
+	return diags
+}
+
+// toolchainGoVersion returns the go tool's version, such as "1.22.1", for
+// the go directive of the modules samples are checked in, or "" when it is
+// a development build.
+func toolchainGoVersion(run goRunner) (string, error) {
+	output, err := run(context.Background(), "", nil, "env", "GOVERSION")
+	if err != nil {
+		return "", fmt.Errorf("failed to get go version: %w", err)
+	}
+	fields := strings.Fields(string(output))
+	if len(fields) == 0 {
+		return "", nil
+	}
+	version, ok := strings.CutPrefix(fields[0], "go")
+	if !ok || version == "" || version[0] < '0' || version[0] > '9' {
+		return "", nil
+	}
+	return version, nil
+}
@@ -0,0 +1,167 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"zylisp/go-ast-coverage/events"
+)
+
+// TestCheckSample tests that samples sharing a directory are checked in
+// isolation and that build and vet failures carry their positions
+func TestCheckSample(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping test - runs go build and go vet")
+	}
+
+	dir := writeFixture(t)
+	samples := map[string]string{
+		"second.go": fixtureSource,
+		"build.go":  "package main\n\nfunc main() {\n\tundefinedName()\n}\n",
+		"vet.go":    "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Printf(\"%d\\n\", \"text\")\n}\n",
+	}
+	for name, src := range samples {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	goVersion, err := toolchainGoVersion(goCommand)
+	if err != nil {
+		t.Fatalf("toolchainGoVersion failed: %v", err)
+	}
+
+	for _, name := range []string{"fixture.go", "second.go"} {
+		if check := checkSample(context.Background(), goCommand, filepath.Join(dir, name), goVersion, []target{{}}, 0)[0]; check.err != nil {
+			t.Errorf("%s: unexpected failure: %v\n%s", name, check.err, check.output)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		stage   string
+		line    int
+		column  int
+		message string
+	}{
+		{"build.go", "build", 4, 2, "undefined: undefinedName"},
+		{"vet.go", "vet", 6, 14, "fmt.Printf format %d has arg \"text\" of wrong type string"},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(dir, tt.name)
+		check := checkSample(context.Background(), goCommand, path, goVersion, []target{{}}, 0)[0]
+		if check.err == nil || check.stage != tt.stage {
+			t.Errorf("%s: expected a %s failure, got stage %q: %v", tt.name, tt.stage, check.stage, check.err)
+			continue
+		}
+		if len(check.diagnostics) != 1 {
+			t.Errorf("%s: expected one diagnostic, got %v\n%s", tt.name, check.diagnostics, check.output)
+			continue
+		}
+		d := check.diagnostics[0]
+		if d.file != path || d.line != tt.line || d.column != tt.column || !strings.Contains(d.message, tt.message) {
+			t.Errorf("%s: unexpected diagnostic %s", tt.name, d)
+		}
+	}
+
+	o := testOptions()
+	o.runJobs = 2
+	if _, err := captureStdout(t, func() error {
+		return o.checkTestFiles([]string{filepath.Join(dir, "fixture.go"), filepath.Join(dir, "vet.go")})
+	}); err == nil || !strings.Contains(err.Error(), "1 file(s) failed") {
+		t.Errorf("expected checkTestFiles to count one failure, got %v", err)
+	}
+}
+
+// TestCheckTargets tests the per-target checks and matrix against a stub
+// go tool that fails to build one file for js/wasm
+func TestCheckTargets(t *testing.T) {
+	dir := writeFixture(t)
+	other := filepath.Join(dir, "other.go")
+	if err := os.WriteFile(other, []byte(fixtureSource), 0644); err != nil {
+		t.Fatalf("failed to write sample: %v", err)
+	}
+
+	var mu sync.Mutex
+	var calls []string
+	stub := func(ctx context.Context, dir string, env []string, args ...string) ([]byte, error) {
+		if args[0] == "env" {
+			return []byte("go1.22.0\n"), nil
+		}
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err != nil {
+			t.Errorf("%v run outside the sample module", args)
+		}
+		mu.Lock()
+		calls = append(calls, strings.Join(env, " ")+" "+args[0])
+		mu.Unlock()
+		if _, err := os.Stat(filepath.Join(dir, "other.go")); err == nil && args[0] == "build" && strings.Join(env, " ") == "GOOS=js GOARCH=wasm" {
+			return []byte("# sample\n./other.go:5:2: undefined: syscall.Exec\n"), errors.New("exit status 1")
+		}
+		return nil, nil
+	}
+
+	var stream bytes.Buffer
+	o := testOptions()
+	o.goTool = stub
+	o.events = events.NewWriter(&stream)
+	o.targets = []target{{"linux", "amd64"}, {"js", "wasm"}}
+	out, err := captureOutput(t, func() error {
+		return o.checkTestFiles([]string{filepath.Join(dir, "fixture.go"), other})
+	})
+	if err == nil || !strings.Contains(err.Error(), "1 file(s) failed") {
+		t.Errorf("expected one failed file, got %v", err)
+	}
+	if len(calls) != 7 {
+		t.Errorf("expected 7 go tool runs (other.go stops at its js/wasm build), got %v", calls)
+	}
+
+	for _, want := range []string{
+		"  ✓ linux/amd64\n",
+		"  ✗ js/wasm: BUILD FAILED\n    " + other + ":5:2: undefined: syscall.Exec\n",
+		"Target Matrix:\n              linux/amd64  js/wasm\n  fixture.go  ✓            ✓\n  other.go    ✓            ✗\n  passed      2/2          1/2\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	evs, err := events.Read(&stream)
+	if err != nil {
+		t.Fatalf("failed to read events: %v", err)
+	}
+	matrix, ok := evs[len(evs)-1].(*events.TargetMatrix)
+	if !ok || len(evs) != 5 {
+		t.Fatalf("expected four results and a matrix, got %+v", evs)
+	}
+	if matrix.Targets[1] != "js/wasm" || matrix.Files[1].Name != "other.go" || matrix.Files[1].Passed[1] || !matrix.Files[1].Passed[0] {
+		t.Errorf("unexpected matrix %+v", matrix)
+	}
+	if result := evs[3].(*events.FileRunResult); result.Target != "js/wasm" || result.OK {
+		t.Errorf("unexpected result %+v", result)
+	}
+}
+
+// TestParseDiagnostics tests extracting positions from go tool output
+func TestParseDiagnostics(t *testing.T) {
+	output := "# sample\n./a.go:3:5: undefined: x\n\thave ()\n\twant (int)\nvet: other.go:1:1: expected 'package'\n"
+	diags := parseDiagnostics([]byte(output), "a.go", "nodes/go/a.go")
+
+	want := []diagnostic{
+		{file: "nodes/go/a.go", line: 3, column: 5, message: "undefined: x\n\thave ()\n\twant (int)"},
+		{file: "other.go", line: 1, column: 1, message: "expected 'package'"},
+	}
+	if len(diags) != len(want) {
+		t.Fatalf("expected %d diagnostics, got %v", len(want), diags)
+	}
+	for i := range want {
+		if diags[i] != want[i] {
+			t.Errorf("diagnostic %d: got %+v, want %+v", i, diags[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,835 @@
+// Package cli implements the go-ast-coverage command line: the run,
+// analyze, generate, report, and all subcommands, each with its own flags,
+// and the original flag-only invocation, which behaves like all when no
+// phase is selected.
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	report "zylisp/go-ast-coverage/coverage-report"
+	"zylisp/go-ast-coverage/events"
+	"zylisp/go-ast-coverage/generator"
+)
+
+// goldenDir holds the golden AST dumps written by -write-golden.
+const goldenDir = "nodes/golden"
+
+// options holds the parsed flags and the phases they select.
+type options struct {
+	// Phases
+	runTests       bool
+	analyze        bool
+	generateAST    bool
+	generateReport bool
+	writeGolden    bool
+	verifyGolden   bool
+	verifyArchives bool
+	bench          bool
+	all            bool
+
+	// legacy is set for the flag-only command line, which runs all when
+	// no phase is selected
+	legacy bool
+
+	// Common
+	nodesDirs    dirList
+	filePaths    string
+	excludeFiles string
+	covering     string
+	outputMode   string
+	verbose      bool
+	quiet        bool
+	dryRun       bool
+	cpuProfile   string
+	memProfile   string
+	configPath   string
+	noConfig     bool
+	keepGoing    bool
+
+	// run
+	runJobs      int
+	runTimeout   time.Duration
+	checkOnly    bool
+	execMode     string
+	inheritEnv   bool
+	extraEnv     envList
+	postRun      string
+	logDir       string
+	targetList   string
+	targets      []target
+	runGoldenDir string
+	updateGolden bool
+	failFast     bool
+	shuffle      bool
+	retries      int
+	seed         int64
+
+	// analyze
+	redundancy   bool
+	heatmapPath  string
+	heatmapByCat bool
+
+	// generate; genPrefix is "gen-" for the legacy flag names
+	genPrefix    string
+	genFormat    string
+	genPositions string
+	genMaxDepth  int
+	genOut       string
+	genIn        string
+	genName      string
+	astOutDir    string
+	genArchives  bool
+	archiveDir   string
+
+	// bench
+	benchJSON string
+
+	// generated is what the generate phase wrote, for the summary
+	generated *generator.WriteAllResult
+
+	// report
+	colorMode     string
+	asciiOnly     bool
+	goVersion     string
+	cacheDir      string
+	noCache       bool
+	excludePath   string
+	reportTmpl    string
+	serveAddr     string
+	browseTUI     bool
+	mergePaths    string
+	reportOut     string
+	saveJSON      bool
+	rawAnalysis   bool
+	deterministic bool
+	saveJUnit     bool
+	saveHTML      bool
+	openHTML      bool
+	saveSARIF     bool
+	sarifBaseline string
+	saveCSV       bool
+	historyPath   string
+	gatePath      string
+	minCoverage   float64
+	requireFull   bool
+	requireNodes  string
+	baselinePath  string
+	allowRegress  bool
+	updateBase    bool
+
+	// regression is the -baseline comparison, when there was a baseline
+	regression *report.RegressionResult
+
+	// events receives the -output ndjson events, and is nil otherwise
+	events *events.Writer
+
+	// log writes diagnostics to logOut at the level -quiet and -verbose
+	// select; see logger
+	log    *slog.Logger
+	logOut logOutput
+
+	// launch starts the browser for -open; nil means startCommand
+	launch launcher
+
+	// ctx is canceled when the run is interrupted; see handleInterrupts.
+	// interrupts delivers the signals, and is nil for SIGINT and SIGTERM;
+	// exitProcess ends the process on a second one, and is nil for os.Exit.
+	// processed is the files the latest file-by-file phase finished with,
+	// for the partial report.
+	ctx         context.Context
+	interrupts  <-chan os.Signal
+	exitProcess func(int)
+	processed   []string
+
+	// goTool runs the go tool for -run and -check; nil means goCommand
+	goTool goRunner
+
+	// now is the clock phases and runs are timed with; nil means
+	// time.Now
+	now          func() time.Time
+	started      time.Time
+	phaseTimings []phaseTiming
+	fileTimings  []fileTiming
+
+	// runErrors is the errors the failed phases met, for -keep-going's
+	// Errors section and the run_end event
+	runErrors []runError
+}
+
+// command is a subcommand: its flags and the phases it runs.
+type command struct {
+	name    string
+	summary string
+	flags   []func(*options, *flag.FlagSet)
+	phases  func(*options)
+}
+
+var commands = []*command{
+	{
+		name:    "run",
+		summary: "Execute the sample files with go run",
+		flags:   []func(*options, *flag.FlagSet){(*options).commonFlags, (*options).runFlags},
+		phases:  func(o *options) { o.runTests = true },
+	},
+	{
+		name:    "analyze",
+		summary: "Count the AST nodes in the sample files",
+		flags:   []func(*options, *flag.FlagSet){(*options).commonFlags, (*options).analyzeFlags},
+		phases:  func(o *options) { o.analyze = true },
+	},
+	{
+		name:    "generate",
+		summary: "Write AST dumps and archives, or check golden dumps",
+		flags:   []func(*options, *flag.FlagSet){(*options).commonFlags, (*options).generateFlags},
+		phases: func(o *options) {
+			o.generateAST = !o.writeGolden && !o.verifyGolden && !o.verifyArchives
+		},
+	},
+	{
+		name:    "report",
+		summary: "Generate, print, and save the coverage report",
+		flags:   []func(*options, *flag.FlagSet){(*options).commonFlags, (*options).reportFlags},
+		phases: func(o *options) {
+			o.generateReport = o.serveAddr == "" && !o.browseTUI && o.mergePaths == ""
+		},
+	},
+	{
+		name:    "bench",
+		summary: "Time parsing, analysis, archives, and text generation",
+		flags:   []func(*options, *flag.FlagSet){(*options).commonFlags, (*options).benchFlags},
+		phases:  func(o *options) { o.bench = true },
+	},
+	{
+		name:    "all",
+		summary: "Run, analyze, and report on the sample files",
+		flags: []func(*options, *flag.FlagSet){(*options).commonFlags, (*options).runFlags,
+			(*options).analyzeFlags, (*options).reportFlags},
+		phases: func(o *options) { o.all = true },
+	},
+}
+
+// Main runs the command line args, excluding the program name, and returns
+// the process exit status. With no arguments, or flags before any command,
+// it parses the original flag-only form.
+func Main(args []string) int {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		o := &options{legacy: true}
+		fs := o.legacyFlagSet()
+		if err := fs.Parse(args); err != nil {
+			return parseStatus(err)
+		}
+
+		// -gen-out also named the -generate directory before -ast-out-dir
+		set := make(map[string]bool)
+		fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+		if err := o.applyConfig(fs); err != nil {
+			o.errorf("Error: %v", err)
+			return ExitError
+		}
+		if set["gen-out"] && !set["ast-out-dir"] {
+			o.astOutDir = o.genOut
+		}
+		return o.execute()
+	}
+
+	name := args[0]
+	if name == "help" {
+		return help(os.Stdout, args[1:])
+	}
+	cmd := findCommand(name)
+	if cmd == nil {
+		fmt.Fprintf(os.Stderr, "Error: unknown command %q\n\n", name)
+		printCommands(os.Stderr)
+		return ExitError
+	}
+
+	o := &options{}
+	fs := cmd.flagSet(o)
+	if err := fs.Parse(args[1:]); err != nil {
+		return parseStatus(err)
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "Error: unexpected arguments %q\n", fs.Args())
+		fs.Usage()
+		return ExitError
+	}
+	if err := o.applyConfig(fs); err != nil {
+		o.errorf("Error: %v", err)
+		return ExitError
+	}
+	cmd.phases(o)
+	return o.execute()
+}
+
+// parseStatus maps a flag parsing error to an exit status; asking for help
+// is not an error.
+func parseStatus(err error) int {
+	if errors.Is(err, flag.ErrHelp) {
+		return ExitOK
+	}
+	return ExitError
+}
+
+// findCommand returns the subcommand called name, or nil.
+func findCommand(name string) *command {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd
+		}
+	}
+	return nil
+}
+
+// flagSet returns the command's flag set, bound to o.
+func (c *command) flagSet(o *options) *flag.FlagSet {
+	fs := flag.NewFlagSet(c.name, flag.ContinueOnError)
+	for _, register := range c.flags {
+		register(o, fs)
+	}
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: go-ast-coverage %s [flags]\n\n%s.\n\nFlags:\n", c.name, c.summary)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// help prints the command list, or with a command name, that command's
+// usage.
+func help(w io.Writer, args []string) int {
+	if len(args) == 0 {
+		printCommands(w)
+		return ExitOK
+	}
+
+	cmd := findCommand(args[0])
+	if cmd == nil {
+		fmt.Fprintf(os.Stderr, "Error: unknown command %q\n\n", args[0])
+		printCommands(os.Stderr)
+		return ExitError
+	}
+	fs := cmd.flagSet(&options{})
+	fs.SetOutput(w)
+	fs.Usage()
+	return ExitOK
+}
+
+// printCommands prints the top-level usage with the list of commands.
+func printCommands(w io.Writer) {
+	fmt.Fprintln(w, "Usage: go-ast-coverage <command> [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-10s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Run 'go-ast-coverage help <command>' for a command's flags. With no")
+	fmt.Fprintln(w, "command, go-ast-coverage runs all, and accepts the flags of every")
+	fmt.Fprintln(w, "command along with -run, -analyze, -generate, -report, and -all.")
+}
+
+// legacyFlagSet returns the flag set of the original flag-only command
+// line: every command's flags, with the generate flags prefixed "gen-", and
+// booleans selecting the phases.
+func (o *options) legacyFlagSet() *flag.FlagSet {
+	fs := flag.NewFlagSet("go-ast-coverage", flag.ContinueOnError)
+	fs.BoolVar(&o.runTests, "run", false, "Run all test files")
+	fs.BoolVar(&o.analyze, "analyze", false, "Analyze AST nodes in test files")
+	fs.BoolVar(&o.generateReport, "report", false, "Generate coverage report")
+	fs.BoolVar(&o.generateAST, "generate", false, "Generate AST files from go-nodes")
+	fs.BoolVar(&o.bench, "bench", false, "Time parsing, analysis, archive save and load, and text generation")
+	fs.BoolVar(&o.all, "all", false, "Run all tests, analyze, and generate report")
+
+	o.genPrefix = "gen-"
+	o.commonFlags(fs)
+	o.runFlags(fs)
+	o.analyzeFlags(fs)
+	o.generateFlags(fs)
+	o.benchFlags(fs)
+	o.reportFlags(fs)
+
+	fs.Usage = func() {
+		printCommands(fs.Output())
+		fmt.Fprintln(fs.Output())
+		fmt.Fprintln(fs.Output(), "Flags:")
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// commonFlags registers the flags every command accepts.
+func (o *options) commonFlags(fs *flag.FlagSet) {
+	fs.Var(&o.nodesDirs, "nodes-dir", "Directory of sample files; repeat to process several corpora in sequence (default nodes/go)")
+	fs.StringVar(&o.filePaths, "file", "", "Comma-separated sample files to work on instead of the whole corpus")
+	fs.StringVar(&o.excludeFiles, "exclude-files", "", "Comma-separated glob patterns of sample file names to leave out of the corpus (e.g. *_wip.go)")
+	fs.StringVar(&o.covering, "covering", "", "Comma-separated node types (e.g. *ast.SelectStmt,*ast.GoStmt); run, analyze, and generate only the sample files containing any of them")
+	fs.StringVar(&o.outputMode, "output", "text", "Console output: text, or ndjson for a JSON event stream on stdout with text moved to stderr")
+	fs.BoolVar(&o.verbose, "verbose", false, "Verbose output, including each sample's output")
+	fs.BoolVar(&o.quiet, "quiet", false, "Only print errors and results, such as the report, to the console")
+	fs.BoolVar(&o.dryRun, "dry-run", false, "Print the phases, sample files, and outputs a run would produce, without running anything")
+	fs.StringVar(&o.cpuProfile, "cpuprofile", "", "Write a CPU profile of the run to this file")
+	fs.StringVar(&o.memProfile, "memprofile", "", "Write a heap profile to this file when the run ends")
+	fs.StringVar(&o.configPath, "config", "", "Load flag defaults from this JSON config file; flags given on the command line override it (default astcoverage.json, when it exists)")
+	fs.BoolVar(&o.noConfig, "no-config", false, "Ignore astcoverage.json and use the built-in flag defaults")
+	fs.BoolVar(&o.keepGoing, "keep-going", false, "Run every phase even after one fails, ending with an Errors section and the most severe failure's exit status")
+}
+
+// runFlags registers the flags for executing samples.
+func (o *options) runFlags(fs *flag.FlagSet) {
+	fs.IntVar(&o.runJobs, "jobs", runtime.NumCPU(), "Number of sample files to execute at once")
+	fs.DurationVar(&o.runTimeout, "timeout", 30*time.Second, "Per-file time limit for executing samples (0 for none)")
+	fs.BoolVar(&o.checkOnly, "check", false, "Compile and vet each sample in its own module instead of running it")
+	fs.BoolVar(&o.inheritEnv, "inherit-env", false, "Run samples with this process's environment and working directory instead of in a sandbox")
+	fs.Var(&o.extraEnv, "env", "KEY=VALUE to add to the sandboxed samples' environment; repeat for more")
+	fs.StringVar(&o.execMode, "exec-mode", execModeRun, "How to execute samples: run (go run each one) or build (go build each one, then run the binary)")
+	fs.StringVar(&o.postRun, "post-run", "", "Command to run after each sample, with {file} replaced by its path and {status} by passed, cached, failed, or timeout; a sample whose command fails is reported as HOOK_FAILED")
+	fs.StringVar(&o.logDir, "log-dir", "", "Write each sample's output, and its -post-run command's, to <name>.log in this directory")
+	fs.StringVar(&o.targetList, "targets", "", "Comma-separated GOOS/GOARCH pairs to -check each sample for (e.g. linux/amd64,windows/amd64,js/wasm); implies -check")
+	fs.StringVar(&o.runGoldenDir, "golden-dir", "", "Compare each sample's output against <name>.golden in this directory")
+	fs.BoolVar(&o.updateGolden, "update-golden", false, "Rewrite the -golden-dir files from the current output")
+	fs.IntVar(&o.retries, "retries", 0, "Attempt a sample that fails or times out up to this many more times, reporting it as flaky if it then succeeds")
+	fs.BoolVar(&o.shuffle, "shuffle", false, "Work through the sample files in a random order, printing the -seed used")
+	fs.Int64Var(&o.seed, "seed", 0, "Seed for the -shuffle order, to reproduce an earlier one; implies -shuffle")
+	o.cacheFlags(fs)
+	o.failFastFlags(fs)
+}
+
+// cacheFlags registers the flags shared by run and report, once per flag
+// set.
+func (o *options) cacheFlags(fs *flag.FlagSet) {
+	if fs.Lookup("cache") != nil {
+		return
+	}
+	fs.StringVar(&o.cacheDir, "cache", "", "Cache results in this directory (e.g. .astcache), reusing them while the sample files are unchanged: reports at the top level, successful sample runs under run/")
+	fs.BoolVar(&o.noCache, "no-cache", false, "Ignore -cache: execute every sample and generate the report afresh")
+}
+
+// failFastFlags registers the flags shared by run and analyze, once per
+// flag set.
+func (o *options) failFastFlags(fs *flag.FlagSet) {
+	if fs.Lookup("fail-fast") != nil {
+		return
+	}
+	fs.BoolVar(&o.failFast, "fail-fast", false, "Stop running or analyzing at the first failing sample file, printing its full output")
+}
+
+// analyzeFlags registers the flags for node analysis.
+func (o *options) analyzeFlags(fs *flag.FlagSet) {
+	o.failFastFlags(fs)
+	fs.BoolVar(&o.redundancy, "redundancy", false, "Report over-covered node types and sample files safe to consolidate")
+	fs.StringVar(&o.heatmapPath, "heatmap", "", "Export a file-by-node-type count matrix to this .csv or .json file")
+	fs.BoolVar(&o.heatmapByCat, "heatmap-categories", false, "Roll -heatmap columns up into node categories")
+}
+
+// benchFlags registers the flags for benchmarking.
+func (o *options) benchFlags(fs *flag.FlagSet) {
+	fs.StringVar(&o.benchJSON, "bench-json", "", "Also save the benchmark results to this JSON file")
+}
+
+// generateFlags registers the flags for AST generation, prefixed with
+// o.genPrefix.
+func (o *options) generateFlags(fs *flag.FlagSet) {
+	p := o.genPrefix
+	fs.StringVar(&o.genFormat, p+"format", "ast", "Text format to generate (asta, ast, fprint, markdown, tokens)")
+	fs.StringVar(&o.genPositions, p+"positions", "start", "Position rendering (start, span, none)")
+	fs.IntVar(&o.genMaxDepth, p+"max-depth", 0, "Maximum tree depth (0 for no limit)")
+	fs.StringVar(&o.genIn, p+"in", "", "Generate output for a single Go file (\"-\" for stdin) instead of the corpus")
+	fs.StringVar(&o.genName, p+"name", "stdin.go", "File name used to label positions when -"+p+"in reads stdin")
+	fs.StringVar(&o.genOut, p+"out", "", "Output file for -"+p+"in (default stdout)")
+	fs.StringVar(&o.astOutDir, "ast-out-dir", "nodes/ast", "Output directory for generated dumps and archives")
+	fs.BoolVar(&o.genArchives, "generate-archives", true, "Write .asta archives alongside the text dumps from the same parse (false for dumps only)")
+	fs.StringVar(&o.archiveDir, "archive-out-dir", "", "Output directory for generated .asta archives (default -ast-out-dir)")
+	fs.BoolVar(&o.writeGolden, "write-golden", false, "Write normalized golden AST dumps")
+	fs.BoolVar(&o.verifyGolden, "verify-golden", false, "Verify golden AST dumps are up to date")
+	fs.BoolVar(&o.verifyArchives, "verify-archives", false, "Round-trip each sample file through an archive in a temporary directory and check nothing was lost")
+}
+
+// reportFlags registers the flags for the coverage report.
+func (o *options) reportFlags(fs *flag.FlagSet) {
+	fs.StringVar(&o.colorMode, "color", "auto", "Color the report output (auto, always, never)")
+	fs.BoolVar(&o.asciiOnly, "ascii", false, "Draw the report output in plain ASCII (default when the locale is not UTF-8)")
+	fs.StringVar(&o.goVersion, "go-version", "latest", "Only expect node types available in this Go version (e.g. go1.17)")
+	o.cacheFlags(fs)
+	fs.StringVar(&o.excludePath, "exclude", "", "JSON file of node types to leave out of coverage, with reasons")
+	fs.StringVar(&o.reportTmpl, "template", "", "Render the report output with this text/template file instead of the built-in layout")
+	fs.StringVar(&o.serveAddr, "serve", "", "Serve the coverage report over HTTP at this address (e.g. :8080)")
+	fs.BoolVar(&o.browseTUI, "tui", false, "Browse the coverage report in an interactive terminal UI")
+	fs.StringVar(&o.mergePaths, "merge", "", "Comma-separated JSON reports to merge into one combined report and print")
+	fs.StringVar(&o.reportOut, "report-out", "coverage-report.txt", "Path of the saved text report; other formats are saved beside it with their own extensions")
+	fs.BoolVar(&o.saveJSON, "json", false, "Save report as JSON")
+	fs.BoolVar(&o.rawAnalysis, "raw-analysis", false, "Embed the raw per-file and aggregated analysis results in -json reports")
+	fs.BoolVar(&o.deterministic, "deterministic", false, "Save -json reports byte-for-byte reproducibly, without generation time or timings")
+	fs.BoolVar(&o.saveJUnit, "junit", false, "Save report as JUnit XML (.xml beside -report-out)")
+	fs.BoolVar(&o.saveHTML, "html", false, "Save report as HTML (.html beside -report-out)")
+	fs.BoolVar(&o.openHTML, "open", false, "Open the HTML report in the browser once it is saved, or print its URL when there is no browser; implies -html")
+	fs.BoolVar(&o.saveSARIF, "sarif", false, "Save report as SARIF 2.1.0 (.sarif beside -report-out)")
+	fs.StringVar(&o.sarifBaseline, "sarif-baseline", "", "JSON report whose covered node types -sarif reports as regressed when missing")
+	fs.BoolVar(&o.saveCSV, "csv", false, "Save report as CSV (-nodes.csv and -files.csv beside -report-out)")
+	fs.StringVar(&o.historyPath, "history", "", "Append a coverage history entry to this file and show the trend")
+	fs.StringVar(&o.gatePath, "gate", "", "JSON gate file of required, forbidden, and minimum coverage to check")
+	fs.Float64Var(&o.minCoverage, "min-coverage", 0, "Fail when coverage is below this percentage")
+	fs.BoolVar(&o.requireFull, "require-full", false, "Fail unless every node type is covered (-min-coverage 100)")
+	fs.StringVar(&o.requireNodes, "require-nodes", "", "Comma-separated node types the report must cover (e.g. SelectStmt,GoStmt)")
+	fs.StringVar(&o.baselinePath, "baseline", "coverage-report.json", "JSON report to check the new report against, when it exists: fail if a node type it covered is now missing or coverage dropped (\"\" to skip)")
+	fs.BoolVar(&o.allowRegress, "allow-regression", false, "Report regressions from -baseline without failing")
+	fs.BoolVar(&o.updateBase, "update-baseline", false, "Replace the -baseline report with the new one when it passes, or create it")
+}
+
+// execute runs the selected phases and returns the exit status.
+func (o *options) execute() int {
+	switch o.outputMode {
+	case "", "text":
+	case "ndjson":
+		// Everything printed for people goes to stderr, leaving stdout
+		// to the event stream
+		o.events = events.NewWriter(os.Stdout)
+		stdout := os.Stdout
+		os.Stdout = os.Stderr
+		defer func() { os.Stdout = stdout }()
+	default:
+		o.errorf("Error: unknown -output %q (want text or ndjson)", o.outputMode)
+		return ExitError
+	}
+
+	// Single-file generation keeps stdout clean for pipelines
+	if o.genIn != "" {
+		if err := o.generateSingleFile(o.genIn, o.singleFileOutput()); err != nil {
+			o.errorf("Error: %v", err)
+			return o.exit(exitStatus(err))
+		}
+		return ExitOK
+	}
+
+	p, err := o.makePlan()
+	if err != nil {
+		o.errorf("Error: %v", err)
+		return o.exit(exitStatus(err))
+	}
+	if o.dryRun {
+		p.print(os.Stdout)
+		return ExitOK
+	}
+
+	o.infof("=== Go AST Coverage Test Suite ===")
+	o.infof("")
+	if p.covering != nil {
+		o.infof("%s", p.filterSummary())
+		o.infof("")
+	}
+	if p.seed != 0 {
+		o.infof("%s", p.shuffleSummary())
+		o.infof("")
+	}
+
+	stopProfiles, err := o.startProfiles()
+	if err != nil {
+		o.errorf("Error: %v", err)
+		return o.exit(exitStatus(err))
+	}
+	defer stopProfiles()
+	defer o.handleInterrupts()()
+
+	o.started = o.clock()
+	dirs, files := p.dirs, p.files
+	o.emit(&events.RunStart{Dirs: dirs, Files: files, Seed: p.seed})
+
+	// Run test files
+	if o.runTests && o.checkOnly {
+		o.infof("Checking test files...")
+		start := o.clock()
+		err := o.checkTestFiles(files)
+		o.timePhase("check", start, len(files))
+		if err != nil {
+			o.errorf("Error checking tests: %v", err)
+			if o.stopAfter("check", err) {
+				return o.exitPhase(err, dirs)
+			}
+		}
+		o.infof("")
+	} else if o.runTests {
+		o.infof("Running test files...")
+		start := o.clock()
+		err := o.runTestFiles(files)
+		o.timePhase("run", start, len(files))
+		if err != nil {
+			o.errorf("Error running tests: %v", err)
+			if o.stopAfter("run", err) {
+				return o.exitPhase(err, dirs)
+			}
+		}
+		o.infof("")
+	}
+
+	// Analyze AST nodes
+	if o.analyze {
+		o.infof("Analyzing AST nodes...")
+		start := o.clock()
+		err := o.analyzeFiles(dirs, files)
+		o.timePhase("analyze", start, len(files))
+		if err != nil {
+			o.errorf("Error analyzing files: %v", err)
+			if o.stopAfter("analyze", err) {
+				return o.exitPhase(err, dirs)
+			}
+		}
+		o.infof("")
+	}
+
+	// Generate AST files
+	if o.generateAST {
+		o.infof("Generating AST files...")
+		genOpts, err := o.generatorOptions()
+		if err == nil {
+			start := o.clock()
+			err = o.generateASTFiles(files, genOpts)
+			o.timePhase("generate", start, len(files))
+		}
+		if err != nil {
+			o.errorf("Error generating AST files: %v", err)
+			if o.stopAfter("generate", err) {
+				return o.exitPhase(err, dirs)
+			}
+		}
+		o.infof("")
+		if o.interrupted() {
+			return o.finishInterrupted(dirs)
+		}
+	}
+
+	// Write golden AST dumps
+	if o.writeGolden {
+		o.infof("Writing golden AST dumps...")
+		for _, dir := range dirs {
+			if err := generator.WriteGoldenFiles(dir, goldenDir); err != nil {
+				o.errorf("Error writing golden dumps: %v", err)
+				if o.stopAfter("write-golden", err) {
+					return o.exitPhase(err, dirs)
+				}
+			}
+		}
+		o.infof("")
+	}
+
+	// Verify golden AST dumps
+	if o.verifyGolden {
+		o.infof("Verifying golden AST dumps...")
+		for _, dir := range dirs {
+			if err := o.verifyGoldenFiles(dir, goldenDir); err != nil {
+				o.errorf("Error verifying golden dumps: %v", err)
+				if o.stopAfter("verify-golden", err) {
+					return o.exitPhase(err, dirs)
+				}
+			}
+		}
+		o.infof("")
+	}
+
+	// Round-trip the sample files through archives
+	if o.verifyArchives {
+		o.infof("Verifying archive round trips...")
+		start := o.clock()
+		err := o.verifyArchiveFiles(files)
+		o.timePhase("verify-archives", start, len(files))
+		if err != nil {
+			o.errorf("Error verifying archives: %v", err)
+			if o.stopAfter("verify-archives", err) {
+				return o.exitPhase(err, dirs)
+			}
+		}
+		o.infof("")
+	}
+
+	// Benchmark the processing stages
+	if o.bench {
+		o.infof("Benchmarking...")
+		start := o.clock()
+		err := o.benchFiles(files)
+		o.timePhase("bench", start, len(files))
+		if err != nil {
+			o.errorf("Error benchmarking: %v", err)
+			if o.stopAfter("bench", err) {
+				return o.exitPhase(err, dirs)
+			}
+		}
+		o.infof("")
+		if o.interrupted() {
+			return o.finishInterrupted(dirs)
+		}
+	}
+
+	// Report redundant coverage
+	if o.redundancy {
+		o.infof("Analyzing redundancy...")
+		results, err := o.analyzeDirectories(dirs)
+		if err != nil {
+			o.errorf("Error analyzing files: %v", err)
+			if o.stopAfter("redundancy", err) {
+				return o.exitPhase(err, dirs)
+			}
+		} else {
+			o.infof("")
+			report.PrintRedundancy(os.Stdout, report.RedundancyAnalysis(results))
+			fmt.Println()
+		}
+	}
+
+	// Serve the report over HTTP until interrupted
+	if o.serveAddr != "" {
+		o.infof("Serving coverage report at http://%s/", o.serveAddr)
+		err := report.Serve(o.serveAddr, func() (*report.CoverageReport, error) {
+			return o.buildCoverageReport(dirs)
+		})
+		if err != nil {
+			o.errorf("Error: %v", err)
+			if o.stopAfter("serve", err) {
+				return o.exitPhase(err, dirs)
+			}
+		}
+	}
+
+	// Browse the report interactively
+	if o.browseTUI {
+		rep, err := o.buildCoverageReport(dirs)
+		if err == nil {
+			err = report.RunTUI(rep)
+		}
+		if err != nil {
+			o.errorf("Error browsing report: %v", err)
+			if o.stopAfter("browse", err) {
+				return o.exitPhase(err, dirs)
+			}
+		}
+	}
+
+	// Merge saved reports
+	if o.mergePaths != "" {
+		o.infof("Merging coverage reports...")
+		if err := o.mergeReportFiles(strings.Split(o.mergePaths, ",")); err != nil {
+			o.errorf("Error merging reports: %v", err)
+			if o.stopAfter("merge", err) {
+				return o.exitPhase(err, dirs)
+			}
+		}
+	}
+
+	// Export the file-by-node-type heatmap
+	if o.heatmapPath != "" {
+		if err := o.exportHeatmap(dirs, o.heatmapPath); err != nil {
+			o.errorf("Error exporting heatmap: %v", err)
+			if o.stopAfter("heatmap", err) {
+				return o.exitPhase(err, dirs)
+			}
+		} else {
+			o.infof("✓ Heatmap saved to: %s", o.heatmapPath)
+			o.infof("")
+		}
+	}
+
+	// Generate coverage report
+	if o.generateReport {
+		o.infof("Generating coverage report...")
+		start := o.clock()
+		rep, err := o.generateCoverageReport(dirs)
+		if err != nil {
+			o.timePhase("report", start, 0)
+			o.errorf("Error generating report: %v", err)
+			if o.stopAfter("report", err) {
+				return o.exitPhase(err, dirs)
+			}
+		} else if status, stop := o.checkReport(rep, start); stop {
+			return o.exit(status)
+		}
+	}
+
+	if o.generated != nil {
+		fmt.Printf("\nGenerate Summary: %d text dumps, %d archives, %d failures\n",
+			o.generated.Dumps, o.generated.Archives, len(o.generated.Failures))
+	}
+
+	if len(o.phaseTimings) > 0 {
+		fmt.Println()
+		o.printTimings(os.Stdout)
+	}
+
+	if status := o.worstStatus(); status != ExitOK {
+		fmt.Println()
+		o.printErrors(os.Stdout)
+		return o.exit(status)
+	}
+
+	o.infof("")
+	o.infof("✓ All tasks completed successfully!")
+	return o.exit(ExitOK)
+}
+
+// checkReport times and emits the report, and checks it against the
+// coverage requirements, the -baseline, and the -gate file. It returns
+// whether the run should end, with what status.
+func (o *options) checkReport(rep *report.CoverageReport, start time.Time) (int, bool) {
+	o.timePhase("report", start, len(rep.FileReports))
+	o.emit(&events.ReportSummary{
+		Percent: rep.CoveragePercent,
+		Covered: rep.CoveredNodeTypes,
+		Total:   rep.TotalNodeTypes,
+		Missing: rep.MissingNodes,
+	})
+
+	if err := o.checkCoverage(rep); err != nil {
+		o.errorf("Coverage check failed: %v", err)
+		if o.stopAfter("report", withStatus(ExitCoverage, err)) {
+			return ExitCoverage, true
+		}
+	}
+
+	if o.regression != nil && !o.regression.Pass && !o.allowRegress {
+		err := fmt.Errorf("coverage regressed from %s; pass -allow-regression to accept it", o.baselinePath)
+		o.errorf("Coverage check failed: %v", err)
+		if o.stopAfter("report", withStatus(ExitCoverage, err)) {
+			return ExitCoverage, true
+		}
+	}
+
+	if o.gatePath != "" {
+		if err := checkGate(rep, o.gatePath); err != nil {
+			o.errorf("Error: %v", err)
+			if o.stopAfter("report", err) {
+				return exitStatus(err), true
+			}
+		}
+	}
+	return ExitOK, false
+}
+
+// exitPhase ends the run after a phase failed with err: with the partial
+// results if it was interrupted, and otherwise with err's status.
+func (o *options) exitPhase(err error, dirs []string) int {
+	if exitStatus(err) == ExitInterrupted {
+		return o.finishInterrupted(dirs)
+	}
+	return o.exit(exitStatus(err))
+}
+
+// emit writes ev to the event stream, if there is one.
+func (o *options) emit(ev events.Event) {
+	if o.events == nil {
+		return
+	}
+	if err := o.events.Emit(ev); err != nil {
+		o.warnf("Warning: %v", err)
+	}
+}
+
+// exit ends the event stream with a run_end event and returns status.
+func (o *options) exit(status int) int {
+	o.emit(o.runEnd(status))
+	return status
+}
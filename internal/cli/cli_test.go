@@ -0,0 +1,338 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	report "zylisp/go-ast-coverage/coverage-report"
+)
+
+const fixtureSource = `package main
+
+import "fmt"
+
+func main() {
+	fmt.Println("fixture")
+}
+`
+
+// writeFixture creates a directory holding a single runnable sample file.
+func writeFixture(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "fixture.go"), []byte(fixtureSource), 0644); err != nil {
+		t.Fatalf("failed to write fixture: %v", err)
+	}
+	return dir
+}
+
+// testOptions returns options holding every flag's default, as the
+// flag-only command line parses them.
+func testOptions() *options {
+	o := &options{legacy: true}
+	o.legacyFlagSet()
+	return o
+}
+
+// captureStdout calls fn, returning what it printed to stdout and its
+// error.
+func captureStdout(t *testing.T, fn func() error) (string, error) {
+	t.Helper()
+	return capture(t, fn, &os.Stdout)
+}
+
+// captureStderr calls fn, returning what it printed to stderr and its
+// error.
+func captureStderr(t *testing.T, fn func() error) (string, error) {
+	t.Helper()
+	return capture(t, fn, &os.Stderr)
+}
+
+// captureOutput calls fn, returning what it printed to stdout and stderr,
+// interleaved as on a console, and its error.
+func captureOutput(t *testing.T, fn func() error) (string, error) {
+	t.Helper()
+	return capture(t, fn, &os.Stdout, &os.Stderr)
+}
+
+// capture calls fn with each of files redirected to one pipe, returning
+// what was written to it and fn's error.
+func capture(t *testing.T, fn func() error, files ...**os.File) (string, error) {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("failed to create pipe: %v", err)
+	}
+	saved := make([]*os.File, len(files))
+	for i, f := range files {
+		saved[i] = *f
+		*f = w
+	}
+	captured := make(chan string)
+	go func() {
+		var buf bytes.Buffer
+		buf.ReadFrom(r)
+		captured <- buf.String()
+	}()
+	fnErr := fn()
+	w.Close()
+	for i, f := range files {
+		*f = saved[i]
+	}
+	return <-captured, fnErr
+}
+
+// listExtensions returns the set of file extensions found in dir.
+func listExtensions(t *testing.T, dir string) map[string]bool {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", dir, err)
+	}
+	exts := make(map[string]bool)
+	for _, entry := range entries {
+		exts[filepath.Ext(entry.Name())] = true
+	}
+	return exts
+}
+
+// TestMainCommands tests each subcommand, and the flag-only form, against
+// a fixture directory
+func TestMainCommands(t *testing.T) {
+	dir := writeFixture(t)
+	out := t.TempDir()
+
+	tests := []struct {
+		args []string
+		want string // a file the command writes under out
+	}{
+		{[]string{"analyze", "-nodes-dir", dir}, ""},
+		{[]string{"generate", "-nodes-dir", dir, "-ast-out-dir", out, "-format", "fprint"}, "fixture.ast"},
+		{[]string{"report", "-nodes-dir", dir, "-report-out", filepath.Join(out, "report.txt"), "-json"}, "report.json"},
+		{[]string{"-analyze", "-nodes-dir", dir}, ""},
+		{[]string{"-generate", "-gen-format", "asta", "-gen-out", filepath.Join(out, "legacy"), "-nodes-dir", dir,
+			"-report-out", filepath.Join(out, "all.txt"), "-timeout", "1m"}, "legacy/fixture.asta"},
+	}
+	for _, tt := range tests {
+		if testing.Short() && tt.args[0] == "-generate" {
+			continue // runs all, which executes the sample
+		}
+		if status := Main(tt.args); status != 0 {
+			t.Errorf("%v exited %d", tt.args, status)
+			continue
+		}
+		if tt.want != "" {
+			if _, err := os.Stat(filepath.Join(out, tt.want)); err != nil {
+				t.Errorf("%v did not write %s: %v", tt.args, tt.want, err)
+			}
+		}
+	}
+}
+
+// TestMainFlagScoping tests that each command accepts only its own flags
+// and that report checks thresholds
+func TestMainFlagScoping(t *testing.T) {
+	dir := writeFixture(t)
+	for _, args := range [][]string{
+		{"run", "-format", "ast"},
+		{"generate", "-min-coverage", "90"},
+		{"analyze", "-json"},
+		{"report", "extra"},
+		{"bogus"},
+	} {
+		if status := Main(args); status != ExitError {
+			t.Errorf("%v exited %d, want %d", args, status, ExitError)
+		}
+	}
+
+	args := []string{"report", "-nodes-dir", dir, "-report-out", filepath.Join(t.TempDir(), "report.txt"), "-require-full"}
+	if status := Main(args); status != ExitCoverage {
+		t.Errorf("%v exited %d, want %d", args, status, ExitCoverage)
+	}
+}
+
+// TestHelp tests the command list and per-command usage
+func TestHelp(t *testing.T) {
+	var buf bytes.Buffer
+	if status := help(&buf, nil); status != 0 {
+		t.Fatalf("help exited %d", status)
+	}
+	for _, cmd := range commands {
+		if !strings.Contains(buf.String(), "  "+cmd.name+" ") {
+			t.Errorf("command list missing %s:\n%s", cmd.name, buf.String())
+		}
+	}
+
+	buf.Reset()
+	if status := help(&buf, []string{"generate"}); status != 0 {
+		t.Fatalf("help generate exited %d", status)
+	}
+	usage := buf.String()
+	if !strings.HasPrefix(usage, "Usage: go-ast-coverage generate [flags]") || !strings.Contains(usage, "-format") {
+		t.Errorf("unexpected generate usage:\n%s", usage)
+	}
+	if strings.Contains(usage, "-min-coverage") || strings.Contains(usage, "-gen-format") {
+		t.Errorf("generate usage lists another command's flags:\n%s", usage)
+	}
+
+	if status := help(&buf, []string{"bogus"}); status != ExitError {
+		t.Errorf("help bogus exited %d, want %d", status, ExitError)
+	}
+}
+
+// TestMainBaseline tests the -baseline regression check against a doctored
+// baseline that covers a node type the fixture does not
+func TestMainBaseline(t *testing.T) {
+	dir := writeFixture(t)
+	out := t.TempDir()
+	baseline := filepath.Join(out, "baseline.json")
+	reportArgs := func(extra ...string) []string {
+		return append([]string{"report", "-nodes-dir", dir, "-report-out", filepath.Join(out, "report.txt"), "-baseline", baseline}, extra...)
+	}
+
+	// Without a baseline there is nothing to check, until one is created
+	if status := Main(reportArgs()); status != 0 {
+		t.Fatalf("report without a baseline exited %d", status)
+	}
+	if _, err := os.Stat(baseline); err == nil {
+		t.Fatalf("baseline created without -update-baseline")
+	}
+	if status := Main(reportArgs("-update-baseline")); status != 0 {
+		t.Fatalf("report -update-baseline exited %d", status)
+	}
+	rep, err := report.LoadReportJSON(baseline)
+	if err != nil {
+		t.Fatalf("failed to load the created baseline: %v", err)
+	}
+
+	// Claim the baseline covered a node type the fixture lacks
+	regressed := rep.MissingNodes[0]
+	rep.CoveredNodes = append(rep.CoveredNodes, regressed)
+	rep.MissingNodes = rep.MissingNodes[1:]
+	rep.CoveredNodeTypes++
+	rep.CoveragePercent = float64(rep.CoveredNodeTypes) / float64(rep.TotalNodeTypes) * 100
+	if err := report.SaveReportJSON(rep, baseline); err != nil {
+		t.Fatalf("failed to save the doctored baseline: %v", err)
+	}
+	doctored, err := os.ReadFile(baseline)
+	if err != nil {
+		t.Fatalf("failed to read baseline: %v", err)
+	}
+
+	var status int
+	stdout, _ := captureStdout(t, func() error {
+		status = Main(reportArgs("-update-baseline"))
+		return nil
+	})
+	if status != ExitCoverage {
+		t.Errorf("regression exited %d, want %d", status, ExitCoverage)
+	}
+	if !strings.Contains(stdout, regressed+" was covered by the baseline but is now missing") || !strings.Contains(stdout, "points)") {
+		t.Errorf("expected the delta and the regressed node type:\n%s", stdout)
+	}
+	if current, _ := os.ReadFile(baseline); !bytes.Equal(current, doctored) {
+		t.Errorf("a failing check replaced the baseline")
+	}
+
+	if status := Main(reportArgs("-allow-regression")); status != 0 {
+		t.Errorf("-allow-regression exited %d", status)
+	}
+	if status := Main(reportArgs("-baseline", "")); status != 0 {
+		t.Errorf("-baseline \"\" exited %d", status)
+	}
+}
+
+// TestMainBaselineIsJSON tests that when -json saves over the -baseline
+// report, it creates a missing baseline but a regression fails on every
+// run rather than only the first, since the failing report never
+// replaces the baseline
+func TestMainBaselineIsJSON(t *testing.T) {
+	dir := writeFixture(t)
+	out := t.TempDir()
+	baseline := filepath.Join(out, "report.json")
+	reportArgs := func(extra ...string) []string {
+		return append([]string{"report", "-nodes-dir", dir, "-report-out", filepath.Join(out, "report.txt"), "-json", "-baseline", baseline}, extra...)
+	}
+
+	if status := Main(reportArgs()); status != 0 {
+		t.Fatalf("report without a baseline exited %d", status)
+	}
+	rep, err := report.LoadReportJSON(baseline)
+	if err != nil {
+		t.Fatalf("-json did not create the baseline: %v", err)
+	}
+	rep.CoveredNodes = append(rep.CoveredNodes, rep.MissingNodes[0])
+	rep.MissingNodes = rep.MissingNodes[1:]
+	rep.CoveredNodeTypes++
+	rep.CoveragePercent = float64(rep.CoveredNodeTypes) / float64(rep.TotalNodeTypes) * 100
+	if err := report.SaveReportJSON(rep, baseline); err != nil {
+		t.Fatalf("failed to save the doctored baseline: %v", err)
+	}
+	doctored, err := os.ReadFile(baseline)
+	if err != nil {
+		t.Fatalf("failed to read baseline: %v", err)
+	}
+
+	for run := 1; run <= 2; run++ {
+		var status int
+		stderr, _ := captureStderr(t, func() error {
+			captureStdout(t, func() error {
+				status = Main(reportArgs())
+				return nil
+			})
+			return nil
+		})
+		if status != ExitCoverage {
+			t.Errorf("run %d: regression exited %d, want %d", run, status, ExitCoverage)
+		}
+		if !strings.Contains(stderr, "JSON report not saved") {
+			t.Errorf("run %d: expected a notice that the JSON report was not saved:\n%s", run, stderr)
+		}
+		if current, _ := os.ReadFile(baseline); !bytes.Equal(current, doctored) {
+			t.Fatalf("run %d: the regressed report replaced the baseline", run)
+		}
+	}
+}
+
+// TestMainQuiet tests that -quiet leaves stderr empty for a successful
+// run, while the report is still printed, and cannot be combined with
+// -verbose
+func TestMainQuiet(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping test - runs go run")
+	}
+	dir := writeFixture(t)
+	out := t.TempDir()
+	args := []string{"all", "-quiet", "-nodes-dir", dir,
+		"-report-out", filepath.Join(out, "report.txt"), "-baseline", ""}
+
+	var status int
+	var stdout string
+	stderr, _ := captureStderr(t, func() error {
+		stdout, _ = captureStdout(t, func() error {
+			status = Main(args)
+			return nil
+		})
+		return nil
+	})
+	if status != 0 {
+		t.Fatalf("-quiet run exited %d:\n%s", status, stderr)
+	}
+	if stderr != "" {
+		t.Errorf("expected nothing on stderr, got:\n%s", stderr)
+	}
+	if !strings.Contains(stdout, "Coverage:") {
+		t.Errorf("expected the report on stdout:\n%s", stdout)
+	}
+
+	stderr, _ = captureStderr(t, func() error {
+		status = Main([]string{"analyze", "-quiet", "-verbose", "-nodes-dir", dir})
+		return nil
+	})
+	if status == 0 || !strings.Contains(stderr, "cannot be combined") {
+		t.Errorf("-quiet -verbose exited %d:\n%s", status, stderr)
+	}
+}
@@ -0,0 +1,37 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"zylisp/go-ast-coverage/internal/config"
+)
+
+// applyConfig loads the -config file, or without -config the
+// astcoverage.json in the current directory if there is one, and applies
+// it to the flags in fs the command line did not set. With -no-config no
+// file is loaded.
+func (o *options) applyConfig(fs *flag.FlagSet) error {
+	if o.noConfig {
+		if o.configPath != "" {
+			return fmt.Errorf("-config and -no-config cannot be combined")
+		}
+		return nil
+	}
+
+	path := o.configPath
+	if path == "" {
+		if _, err := os.Stat(config.FileName); errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		path = config.FileName
+	}
+	c, err := config.Load(path)
+	if err != nil {
+		return err
+	}
+	o.debugf("Using config %s", path)
+	return c.Apply(fs)
+}
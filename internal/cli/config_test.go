@@ -0,0 +1,95 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"zylisp/go-ast-coverage/internal/config"
+)
+
+// parseCommand parses args for the named command as Main does, config
+// included
+func parseCommand(t *testing.T, name string, args ...string) (*options, error) {
+	t.Helper()
+	o := &options{}
+	fs := findCommand(name).flagSet(o)
+	if err := fs.Parse(args); err != nil {
+		t.Fatalf("failed to parse %v: %v", args, err)
+	}
+	return o, o.applyConfig(fs)
+}
+
+// TestApplyConfig tests that flags win over astcoverage.json, which wins
+// over the built-in defaults, for the file in the current directory and
+// one named by -config, and that -no-config ignores it
+func TestApplyConfig(t *testing.T) {
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get working directory: %v", err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("failed to change directory: %v", err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	contents := `{"jobs": 3, "timeout": "5s", "gate": "gate.json", "formats": ["html"], "exclude_files": ["*_wip.go"]}`
+	if err := os.WriteFile(config.FileName, []byte(contents), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	o, err := parseCommand(t, "all", "-jobs", "2")
+	if err != nil {
+		t.Fatalf("applyConfig failed: %v", err)
+	}
+	if o.runJobs != 2 {
+		t.Errorf("expected -jobs to win over the config, got %d", o.runJobs)
+	}
+	if o.runTimeout != 5*time.Second || o.gatePath != "gate.json" || !o.saveHTML || o.excludeFiles != "*_wip.go" {
+		t.Errorf("expected the config to win over the defaults, got timeout %s, gate %q, html %v, exclude %q", o.runTimeout, o.gatePath, o.saveHTML, o.excludeFiles)
+	}
+	if o.reportOut != "coverage-report.txt" {
+		t.Errorf("expected the default where the config is silent, got %q", o.reportOut)
+	}
+
+	// analyze has no -jobs or -gate; the rest still applies
+	if o, err = parseCommand(t, "analyze"); err != nil || o.excludeFiles != "*_wip.go" {
+		t.Errorf("expected the config to apply to analyze, got %q, %v", o.excludeFiles, err)
+	}
+
+	if o, err = parseCommand(t, "run", "-no-config"); err != nil || o.runTimeout != 30*time.Second {
+		t.Errorf("expected -no-config to keep the defaults, got %s, %v", o.runTimeout, err)
+	}
+
+	other := filepath.Join(dir, "other.json")
+	if err := os.WriteFile(other, []byte(`{"jobs": 7}`), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	if o, err = parseCommand(t, "run", "-config", other); err != nil || o.runJobs != 7 || o.runTimeout != 30*time.Second {
+		t.Errorf("expected only -config to be loaded, got jobs %d, timeout %s, %v", o.runJobs, o.runTimeout, err)
+	}
+
+	for _, args := range [][]string{
+		{"-config", filepath.Join(dir, "missing.json")},
+		{"-config", other, "-no-config"},
+	} {
+		if _, err := parseCommand(t, "run", args...); err == nil {
+			t.Errorf("%v: expected an error", args)
+		}
+	}
+
+	if err := os.WriteFile(config.FileName, []byte(`{"jobs": 3,}`), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	var code int
+	out, _ := captureOutput(t, func() error {
+		code = Main([]string{"run"})
+		return nil
+	})
+	if code != ExitError || !strings.Contains(out, "Error: failed to decode config astcoverage.json: line 1, column 12") {
+		t.Errorf("expected a malformed config to fail the command, got status %d:\n%s", code, out)
+	}
+}
@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"zylisp/go-ast-coverage/analyzer"
+)
+
+// parseCovering resolves the -covering node type names, reporting every
+// unknown one with its close matches.
+func parseCovering(list string) ([]string, error) {
+	var nodeTypes []string
+	var errs []error
+	for _, name := range strings.Split(list, ",") {
+		if strings.TrimSpace(name) == "" {
+			continue
+		}
+		nodeType, err := analyzer.LookupNodeType(name)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		nodeTypes = append(nodeTypes, nodeType)
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("invalid -covering: %w", errors.Join(errs...))
+	}
+	if len(nodeTypes) == 0 {
+		return nil, fmt.Errorf("invalid -covering: no node types given")
+	}
+	return nodeTypes, nil
+}
+
+// filesCovering analyzes files and returns those whose ASTs contain any of
+// nodeTypes, in order. It is an error for none to match.
+func filesCovering(files, nodeTypes []string) ([]string, error) {
+	var matched []string
+	for _, file := range files {
+		result, err := analyzer.AnalyzeFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to analyze %s: %w", file, err)
+		}
+		for _, nodeType := range nodeTypes {
+			if result.NodeCounts[nodeType] > 0 {
+				matched = append(matched, file)
+				break
+			}
+		}
+	}
+	if len(matched) == 0 {
+		return nil, fmt.Errorf("no sample files contain %s", strings.Join(nodeTypes, " or "))
+	}
+	return matched, nil
+}
@@ -0,0 +1,84 @@
+package cli
+
+import "errors"
+
+// Exit statuses, so that scripts can tell why a run failed. The README
+// lists them too; keep the two in step.
+const (
+	// ExitOK is the status of a run in which every phase succeeded.
+	ExitOK = 0
+
+	// ExitError is the status for command line errors and for internal
+	// errors, such as a file that cannot be read or written.
+	ExitError = 1
+
+	// ExitSamples is the status when sample files fail to execute, or to
+	// build and vet with -check, or time out.
+	ExitSamples = 2
+
+	// ExitAnalysis is the status when sample files cannot be analyzed.
+	ExitAnalysis = 3
+
+	// ExitCoverage is the status when the report fails -min-coverage,
+	// -require-full, -require-nodes, or the -gate file, or regresses from
+	// the -baseline report.
+	ExitCoverage = 4
+
+	// ExitMismatch is the status when output does not match what was
+	// expected: sample output against -golden-dir, golden AST dumps with
+	// -verify-golden, or an archive round trip with -verify-archives.
+	ExitMismatch = 5
+
+	// ExitInterrupted is the status when the run is stopped by SIGINT or
+	// SIGTERM, as shells report a process killed by SIGINT.
+	ExitInterrupted = 130
+)
+
+// severity orders the failure statuses from most to least severe, for a
+// -keep-going run that ends with several: a broken run first, then
+// samples that fail outright, then each way the results can fall short.
+var severity = []int{ExitInterrupted, ExitError, ExitSamples, ExitAnalysis, ExitMismatch, ExitCoverage}
+
+// moreSevere reports whether status a is more severe than b. ExitOK, and
+// any status not in severity, is the least severe.
+func moreSevere(a, b int) bool {
+	rank := func(status int) int {
+		for i, s := range severity {
+			if s == status {
+				return i
+			}
+		}
+		return len(severity)
+	}
+	return rank(a) < rank(b)
+}
+
+// statusError is a phase failure that ends the run with a particular exit
+// status.
+type statusError struct {
+	status int
+	err    error
+}
+
+func (e *statusError) Error() string {
+	return e.err.Error()
+}
+
+func (e *statusError) Unwrap() error {
+	return e.err
+}
+
+// withStatus marks err as ending the run with status.
+func withStatus(status int, err error) error {
+	return &statusError{status: status, err: err}
+}
+
+// exitStatus returns the exit status for a phase that failed with err:
+// the one it was marked with by withStatus, and otherwise ExitError.
+func exitStatus(err error) int {
+	var se *statusError
+	if errors.As(err, &se) {
+		return se.status
+	}
+	return ExitError
+}
@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"zylisp/go-ast-coverage/events"
+)
+
+// runError is one error the run met: the error a phase failed with, or
+// one of the sample files it failed on.
+type runError struct {
+	phase string
+
+	// file is the sample the error is about, or "" for the phase's own
+	// error.
+	file string
+
+	// status is the exit status the phase's own error calls for.
+	status int
+
+	err error
+}
+
+// fileError records that phase failed on a sample file with err.
+func (o *options) fileError(phase, file string, err error) {
+	o.runErrors = append(o.runErrors, runError{phase: phase, file: file, err: err})
+}
+
+// stopAfter records that phase failed with err, and reports whether the
+// run should end there: always unless -keep-going is set, and even then
+// when it was interrupted.
+func (o *options) stopAfter(phase string, err error) bool {
+	status := exitStatus(err)
+	o.runErrors = append(o.runErrors, runError{phase: phase, status: status, err: err})
+	return !o.keepGoing || status == ExitInterrupted
+}
+
+// worstStatus returns the most severe exit status among the phases that
+// failed, or ExitOK when none did.
+func (o *options) worstStatus() int {
+	worst := ExitOK
+	for _, e := range o.runErrors {
+		if e.file == "" && moreSevere(e.status, worst) {
+			worst = e.status
+		}
+	}
+	return worst
+}
+
+// phaseErrors groups the run's errors by phase, in the order the phases
+// failed; each group is the phase's own errors, then its files'.
+func (o *options) phaseErrors() [][]runError {
+	index := make(map[string]int)
+	var groups [][]runError
+	add := func(e runError) {
+		i, ok := index[e.phase]
+		if !ok {
+			i = len(groups)
+			index[e.phase] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], e)
+	}
+	for _, e := range o.runErrors {
+		if e.file == "" {
+			add(e)
+		}
+	}
+	for _, e := range o.runErrors {
+		if e.file != "" {
+			if _, ok := index[e.phase]; ok {
+				add(e)
+			}
+		}
+	}
+	return groups
+}
+
+// printErrors writes the Errors section that ends a -keep-going run that
+// failed: each failed phase with its errors, and the files it failed on.
+func (o *options) printErrors(w io.Writer) {
+	groups := o.phaseErrors()
+	fmt.Fprintf(w, "=== Errors (%d phase(s) failed) ===\n", len(groups))
+	for _, group := range groups {
+		fmt.Fprintf(w, "%s:\n", group[0].phase)
+		for _, e := range group {
+			if e.file == "" {
+				fmt.Fprintf(w, "  %v (exit status %d)\n", e.err, e.status)
+			} else {
+				fmt.Fprintf(w, "    %s: %v\n", filepath.Base(e.file), e.err)
+			}
+		}
+	}
+}
+
+// eventErrors returns the run's errors for the run_end event, grouped as
+// printErrors groups them.
+func (o *options) eventErrors() []events.RunError {
+	var errs []events.RunError
+	for _, group := range o.phaseErrors() {
+		for _, e := range group {
+			errs = append(errs, events.RunError{
+				Phase:      e.phase,
+				File:       e.file,
+				Message:    e.err.Error(),
+				ExitStatus: e.status,
+			})
+		}
+	}
+	return errs
+}
@@ -0,0 +1,95 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"zylisp/go-ast-coverage/events"
+)
+
+// TestExecuteKeepGoing tests that an analysis failure ends the run unless
+// -keep-going is set, and that then the report phase still runs and the
+// failure is listed under Errors and in the run_end event
+func TestExecuteKeepGoing(t *testing.T) {
+	dir := writeFixture(t)
+	if err := os.WriteFile(filepath.Join(dir, "broken.go"), []byte("package main\n\nfunc main() {\n"), 0644); err != nil {
+		t.Fatalf("failed to write sample: %v", err)
+	}
+
+	execute := func(keepGoing bool) (int, string, *bytes.Buffer) {
+		var stream bytes.Buffer
+		o := testOptions()
+		o.nodesDirs = dirList{dir}
+		o.analyze = true
+		o.generateReport = true
+		o.keepGoing = keepGoing
+		o.reportOut = filepath.Join(t.TempDir(), "coverage-report.txt")
+		o.baselinePath = ""
+		o.events = events.NewWriter(&stream)
+		var status int
+		out, _ := captureOutput(t, func() error {
+			status = o.execute()
+			return nil
+		})
+		return status, out, &stream
+	}
+
+	status, out, _ := execute(false)
+	if status != ExitAnalysis || strings.Contains(out, "Generating coverage report") {
+		t.Errorf("expected the analysis failure to end the run, got status %d:\n%s", status, out)
+	}
+
+	status, out, stream := execute(true)
+	if status != ExitAnalysis {
+		t.Errorf("expected status %d, got %d:\n%s", ExitAnalysis, status, out)
+	}
+	for _, want := range []string{
+		"Generating coverage report",
+		"=== Errors (1 phase(s) failed) ===\nanalyze:\n  1 file(s) failed to analyze (exit status 3)\n    broken.go: ",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "All tasks completed successfully") {
+		t.Errorf("expected the run not to succeed:\n%s", out)
+	}
+
+	evs, err := events.Read(stream)
+	if err != nil {
+		t.Fatalf("failed to read events: %v", err)
+	}
+	end, ok := evs[len(evs)-1].(*events.RunEnd)
+	if !ok || end.ExitStatus != ExitAnalysis || len(end.Errors) != 2 {
+		t.Fatalf("expected run_end with the analysis errors, got %+v", evs[len(evs)-1])
+	}
+	if e := end.Errors[0]; e.Phase != "analyze" || e.File != "" || e.ExitStatus != ExitAnalysis {
+		t.Errorf("expected the phase's error first, got %+v", e)
+	}
+	if e := end.Errors[1]; e.Phase != "analyze" || filepath.Base(e.File) != "broken.go" || e.Message == "" {
+		t.Errorf("expected broken.go's error, got %+v", e)
+	}
+}
+
+// TestMoreSevere tests the order -keep-going picks the exit status in
+func TestMoreSevere(t *testing.T) {
+	for _, tt := range []struct {
+		a, b int
+		want bool
+	}{
+		{ExitError, ExitSamples, true},
+		{ExitSamples, ExitAnalysis, true},
+		{ExitMismatch, ExitCoverage, true},
+		{ExitCoverage, ExitMismatch, false},
+		{ExitCoverage, ExitOK, true},
+		{ExitOK, ExitCoverage, false},
+		{ExitInterrupted, ExitError, true},
+	} {
+		if got := moreSevere(tt.a, tt.b); got != tt.want {
+			t.Errorf("moreSevere(%d, %d) = %v, expected %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
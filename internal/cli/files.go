@@ -0,0 +1,159 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// dirList collects the values of a repeatable directory flag.
+type dirList []string
+
+func (d *dirList) String() string {
+	return strings.Join(*d, ",")
+}
+
+func (d *dirList) Set(dir string) error {
+	*d = append(*d, dir)
+	return nil
+}
+
+// corpusFiles returns the sample files to work on across dirs, in order.
+// With -file the given files are used instead; otherwise every directory
+// must exist and contain Go files.
+func (o *options) corpusFiles(dirs []string) ([]string, error) {
+	if o.filePaths != "" {
+		return o.sampleFiles(dirs[0])
+	}
+
+	var files []string
+	for _, dir := range dirs {
+		info, err := os.Stat(dir)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("-nodes-dir %s does not exist", dir)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("-nodes-dir %s: %w", dir, err)
+		}
+		if !info.IsDir() {
+			return nil, fmt.Errorf("-nodes-dir %s is not a directory", dir)
+		}
+
+		dirFiles, err := o.sampleFiles(dir)
+		if err != nil {
+			return nil, err
+		}
+		if len(dirFiles) == 0 && o.excludeFiles != "" {
+			return nil, fmt.Errorf("-nodes-dir %s contains no .go files not matched by -exclude-files", dir)
+		}
+		if len(dirFiles) == 0 {
+			return nil, fmt.Errorf("-nodes-dir %s contains no .go files", dir)
+		}
+		files = append(files, dirFiles...)
+	}
+	return files, nil
+}
+
+// sampleFiles returns the sample files to work on: the -file paths if
+// given, each once in the order first named, and otherwise every Go file
+// in dir not matched by -exclude-files.
+func (o *options) sampleFiles(dir string) ([]string, error) {
+	if o.filePaths == "" {
+		files, err := goFiles(dir)
+		if err != nil {
+			return nil, err
+		}
+		return o.withoutExcluded(files)
+	}
+
+	var files []string
+	seen := make(map[string]bool)
+	for _, path := range strings.Split(o.filePaths, ",") {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+		key, err := filepath.Abs(path)
+		if err != nil {
+			key = filepath.Clean(path)
+		}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		info, err := os.Stat(path)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("-file %s does not exist", path)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("-file %s: %w", path, err)
+		}
+		if info.IsDir() || !strings.HasSuffix(path, ".go") {
+			return nil, fmt.Errorf("-file %s is not a .go file", path)
+		}
+		files = append(files, path)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("-file names no files")
+	}
+	return files, nil
+}
+
+// withoutExcluded returns files without those whose names match an
+// -exclude-files pattern.
+func (o *options) withoutExcluded(files []string) ([]string, error) {
+	if o.excludeFiles == "" {
+		return files, nil
+	}
+	var patterns []string
+	for _, pattern := range strings.Split(o.excludeFiles, ",") {
+		pattern = strings.TrimSpace(pattern)
+		if _, err := filepath.Match(pattern, ""); err != nil || pattern == "" {
+			return nil, fmt.Errorf("invalid -exclude-files pattern %q", pattern)
+		}
+		patterns = append(patterns, pattern)
+	}
+
+	var kept []string
+	for _, file := range files {
+		excluded := false
+		for _, pattern := range patterns {
+			if matched, _ := filepath.Match(pattern, filepath.Base(file)); matched {
+				excluded = true
+				break
+			}
+		}
+		if !excluded {
+			kept = append(kept, file)
+		}
+	}
+	return kept, nil
+}
+
+// goFiles returns every Go file in dir.
+func goFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+	var files []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".go") {
+			files = append(files, filepath.Join(dir, entry.Name()))
+		}
+	}
+	return files, nil
+}
+
+// shuffleFiles returns files in an order determined by seed, the same
+// order for the same seed.
+func shuffleFiles(files []string, seed int64) []string {
+	shuffled := append([]string{}, files...)
+	rand.New(rand.NewSource(seed)).Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+	return shuffled
+}
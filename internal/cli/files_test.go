@@ -0,0 +1,175 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"zylisp/go-ast-coverage/analyzer"
+	report "zylisp/go-ast-coverage/coverage-report"
+)
+
+// TestSampleFiles tests selecting sample files with -file, naming each
+// file once however often it is given
+func TestSampleFiles(t *testing.T) {
+	o := testOptions()
+	dir := writeFixture(t)
+
+	all, err := o.sampleFiles(dir)
+	if err != nil || len(all) == 0 {
+		t.Fatalf("expected the fixture's files, got %v, %v", all, err)
+	}
+
+	second := filepath.Join(dir, "second.go")
+	if err := os.WriteFile(second, []byte(fixtureSource), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", second, err)
+	}
+	o.filePaths = second + ", " + all[0] + ", " + filepath.Join(dir, ".", "second.go")
+	files, err := o.sampleFiles("unused")
+	if err != nil || len(files) != 2 || files[0] != second || files[1] != all[0] {
+		t.Errorf("expected each -file path once in order, got %v, %v", files, err)
+	}
+
+	notGo := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(notGo, []byte("notes"), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", notGo, err)
+	}
+	for path, want := range map[string]string{
+		filepath.Join(dir, "missing.go"): "does not exist",
+		notGo:                            "is not a .go file",
+		dir:                              "is not a .go file",
+	} {
+		o.filePaths = path
+		if _, err := o.sampleFiles("unused"); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("-file %s: expected %q, got %v", path, want, err)
+		}
+	}
+}
+
+// TestExcludeFiles tests leaving sample files out of the corpus by name
+// with -exclude-files
+func TestExcludeFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.go", "b_wip.go", "c.go"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(fixtureSource), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	o := testOptions()
+	o.excludeFiles = "*_wip.go, c.go"
+	files, err := o.corpusFiles([]string{dir})
+	if err != nil || len(files) != 1 || filepath.Base(files[0]) != "a.go" {
+		t.Errorf("expected only a.go, got %v, %v", files, err)
+	}
+
+	o.excludeFiles = "*.go"
+	if _, err := o.corpusFiles([]string{dir}); err == nil || !strings.Contains(err.Error(), "not matched by -exclude-files") {
+		t.Errorf("expected excluding every file to fail, got %v", err)
+	}
+
+	o.excludeFiles = "[a-"
+	if _, err := o.corpusFiles([]string{dir}); err == nil || !strings.Contains(err.Error(), "invalid -exclude-files pattern") {
+		t.Errorf("expected a bad pattern to fail, got %v", err)
+	}
+}
+
+// TestCorpusFiles tests collecting and validating samples from several
+// -nodes-dir directories
+func TestCorpusFiles(t *testing.T) {
+	o := testOptions()
+	first, second := writeFixture(t), writeFixture(t)
+	files, err := o.corpusFiles([]string{first, second})
+	if err != nil || len(files) != 2 || filepath.Dir(files[0]) != first || filepath.Dir(files[1]) != second {
+		t.Errorf("expected one file from each directory in order, got %v, %v", files, err)
+	}
+
+	empty := t.TempDir()
+	for dir, want := range map[string]string{
+		filepath.Join(empty, "missing"):    "does not exist",
+		empty:                              "contains no .go files",
+		filepath.Join(first, "fixture.go"): "is not a directory",
+	} {
+		if _, err := o.corpusFiles([]string{first, dir}); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("-nodes-dir %s: expected %q, got %v", dir, want, err)
+		}
+	}
+}
+
+// TestShuffleFiles tests that a seed always gives the same permutation and
+// that different seeds give different ones
+func TestShuffleFiles(t *testing.T) {
+	var files []string
+	for i := 0; i < 20; i++ {
+		files = append(files, fmt.Sprintf("sample%02d.go", i))
+	}
+
+	first := shuffleFiles(files, 42)
+	if again := shuffleFiles(files, 42); !slices.Equal(first, again) {
+		t.Errorf("seed 42 gave two orders:\n%v\n%v", first, again)
+	}
+	if other := shuffleFiles(files, 7); slices.Equal(first, other) {
+		t.Errorf("seeds 42 and 7 gave the same order %v", first)
+	}
+	if slices.Equal(first, files) {
+		t.Errorf("expected the order to change, got %v", first)
+	}
+
+	sorted := slices.Clone(first)
+	slices.Sort(sorted)
+	if !slices.Equal(sorted, files) {
+		t.Errorf("shuffle is not a permutation: %v", first)
+	}
+}
+
+// TestShuffleReportsMatch tests that the aggregated analysis and the
+// coverage report do not depend on the order the sample files are in
+func TestShuffleReportsMatch(t *testing.T) {
+	dir := filepath.Join("..", "..", "nodes", "go")
+	files, err := goFiles(dir)
+	if err != nil {
+		t.Fatalf("failed to list the corpus: %v", err)
+	}
+
+	var reports [][]byte
+	for _, seed := range []int64{1, 2} {
+		shuffled := shuffleFiles(files, seed)
+
+		var results []*analyzer.AnalysisResult
+		for _, file := range shuffled {
+			result, err := analyzer.AnalyzeFile(file)
+			if err != nil {
+				t.Fatalf("failed to analyze %s: %v", file, err)
+			}
+			results = append(results, result)
+		}
+		aggregated := analyzer.AggregateResults(results)
+
+		rep, err := report.GenerateReportWithOptions(dir, report.ReportOptions{Files: shuffled, IncludeRawAnalysis: true})
+		if err != nil {
+			t.Fatalf("failed to generate report: %v", err)
+		}
+		path := filepath.Join(t.TempDir(), "report.json")
+		if err := report.SaveReportJSONWithOptions(rep, path, report.JSONOptions{Deterministic: true}); err != nil {
+			t.Fatalf("failed to save report: %v", err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("failed to read report: %v", err)
+		}
+		aggregatedJSON, err := json.Marshal(aggregated)
+		if err != nil {
+			t.Fatalf("failed to encode aggregated results: %v", err)
+		}
+		reports = append(reports, append(aggregatedJSON, data...))
+	}
+
+	if !bytes.Equal(reports[0], reports[1]) {
+		t.Errorf("aggregated results differ between seeds 1 and 2")
+	}
+}
@@ -0,0 +1,181 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"zylisp/go-ast-coverage/archive"
+	"zylisp/go-ast-coverage/generator"
+	"zylisp/go-ast-coverage/internal/runner"
+)
+
+// generatorOptions builds generator options from the generate flags.
+func (o *options) generatorOptions() (generator.Options, error) {
+	format, err := generator.ParseFormat(o.genFormat)
+	if err != nil {
+		return generator.Options{}, fmt.Errorf("invalid -%sformat: %w", o.genPrefix, err)
+	}
+	positions, err := generator.ParsePositionMode(o.genPositions)
+	if err != nil {
+		return generator.Options{}, fmt.Errorf("invalid -%spositions: %w", o.genPrefix, err)
+	}
+	if o.genMaxDepth < 0 {
+		return generator.Options{}, fmt.Errorf("invalid -%smax-depth: must not be negative", o.genPrefix)
+	}
+
+	return generator.Options{
+		Format:    format,
+		Positions: positions,
+		MaxDepth:  o.genMaxDepth,
+	}, nil
+}
+
+// generateDirs returns where the generate phase writes text dumps and
+// archives for format; an empty directory means that output is skipped.
+// The archive format writes archives only.
+func (o *options) generateDirs(format generator.Format) (astDir, archiveDir string, err error) {
+	astDir, archiveDir = o.astOutDir, o.astOutDir
+	if o.archiveDir != "" {
+		archiveDir = o.archiveDir
+	}
+	if !o.genArchives {
+		archiveDir = ""
+	}
+	if format == generator.FormatArchive {
+		astDir = ""
+	}
+	if astDir == "" && archiveDir == "" {
+		return "", "", fmt.Errorf("-%sformat %s with -generate-archives=false writes nothing", o.genPrefix, format)
+	}
+	return astDir, archiveDir, nil
+}
+
+// generateASTFiles generates AST files from Go source files, parsing each
+// file once for both its text dump and its archive. The counts are kept
+// for the end-of-run summary. An interrupt stops it before the next file.
+func (o *options) generateASTFiles(files []string, opts generator.Options) error {
+	astDir, archiveDir, err := o.generateDirs(opts.Format)
+	if err != nil {
+		return err
+	}
+
+	opts.Output = o.logWriter(slog.LevelInfo)
+	w, err := generator.NewWriter(astDir, archiveDir, opts)
+	if err != nil {
+		return fmt.Errorf("failed to generate AST files: %w", err)
+	}
+
+	p := o.newProgress("generating", len(files))
+	g := &runner.Generation{Write: w.WriteFile, Begin: p.begin}
+	summary := g.Run(o.context(), files, func(path string, result *generator.WriteAllResult) {
+		for _, failure := range result.Failures {
+			o.warnf("Warning: %s", failure)
+		}
+	})
+	p.end()
+	o.processed = summary.Processed
+	o.generated = &summary.Totals
+
+	if summary.Interrupted {
+		printInterrupted(summary.Skipped)
+		return errInterrupted(summary.Skipped)
+	}
+	if !summary.Wrote() {
+		return fmt.Errorf("failed to generate AST files: no Go files processed")
+	}
+	o.infof("")
+	o.infof("Generated %d text dumps and %d archives", summary.Totals.Dumps, summary.Totals.Archives)
+	if astDir != "" {
+		o.infof("✓ Text dumps written to: %s", astDir)
+	}
+	if archiveDir != "" {
+		o.infof("✓ Archives written to: %s", archiveDir)
+	}
+	return nil
+}
+
+// verifyGoldenFiles checks that the golden AST dumps match the current sources.
+func (o *options) verifyGoldenFiles(inDir, goldenDir string) error {
+	mismatches, err := generator.VerifyGolden(inDir, goldenDir)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range mismatches {
+		o.warnf("  ✗ %s: %s", m.File, m.Reason)
+		o.fileError("verify-golden", m.File, errors.New(m.Reason))
+		if m.Diff != "" {
+			o.debugf("%s", m.Diff)
+		}
+	}
+	if len(mismatches) > 0 {
+		return withStatus(ExitMismatch, fmt.Errorf("%d golden file(s) out of date; rerun with -write-golden", len(mismatches)))
+	}
+
+	o.infof("✓ Golden AST dumps in %s are up to date", goldenDir)
+	return nil
+}
+
+// verifyArchiveFiles round-trips each sample file through an archive in a
+// temporary directory, printing whether it passed, with the details of
+// any mismatch, and then the aggregate statistics.
+func (o *options) verifyArchiveFiles(files []string) error {
+	dir, err := os.MkdirTemp("", "go-ast-coverage-archives-")
+	if err != nil {
+		return fmt.Errorf("failed to create archive directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	summary := &archive.RoundTripSummary{}
+	p := o.newProgress("verifying", len(files))
+	for _, file := range files {
+		p.begin(file)
+		result, err := archive.VerifyRoundTrip(file, dir)
+		if err != nil {
+			p.end()
+			return err
+		}
+		summary.Add(result)
+		if result.Err != nil {
+			o.warnf("  ✗ %s: %v", filepath.Base(file), result.Err)
+			o.fileError("verify-archives", file, result.Err)
+		} else {
+			o.infof("  ✓ %s", filepath.Base(file))
+		}
+	}
+	p.end()
+
+	fmt.Printf("\nArchive Summary: %d passed, %d failed, %d nodes in %d archive bytes\n",
+		summary.Passed, summary.Failed, summary.Nodes, summary.ArchiveBytes)
+	if summary.Failed > 0 {
+		return withStatus(ExitMismatch, fmt.Errorf("archive round trip failed: %d of %d file(s)", summary.Failed, len(files)))
+	}
+	return nil
+}
+
+// singleFileOutput returns the output path for single-file generation:
+// the -out (or legacy -gen-out) value when given, and stdout otherwise.
+func (o *options) singleFileOutput() string {
+	if o.genOut == "" {
+		return generator.StdioPath
+	}
+	return o.genOut
+}
+
+// generateSingleFile generates output for one Go file using the generate
+// flags. Either path may be "-" for stdin or stdout.
+func (o *options) generateSingleFile(inPath, outPath string) error {
+	opts, err := o.generatorOptions()
+	if err != nil {
+		return err
+	}
+	opts.StdinName = o.genName
+
+	if err := generator.GenerateFile(inPath, outPath, opts); err != nil {
+		return fmt.Errorf("failed to generate %s: %w", inPath, err)
+	}
+	return nil
+}
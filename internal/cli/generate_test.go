@@ -0,0 +1,160 @@
+package cli
+
+import (
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"zylisp/go-ast-coverage/archive"
+	"zylisp/go-ast-coverage/generator"
+)
+
+// TestGenerateFormats tests the -generate path for several formats
+func TestGenerateFormats(t *testing.T) {
+	o := testOptions()
+	files, err := o.sampleFiles(writeFixture(t))
+	if err != nil {
+		t.Fatalf("sampleFiles failed: %v", err)
+	}
+
+	tests := []struct {
+		format string
+		want   []string
+	}{
+		{"ast", []string{".ast", ".asta"}},
+		{"fprint", []string{".ast", ".asta"}},
+		{"asta", []string{".asta"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			o.genFormat = tt.format
+			defer func() { o.genFormat = "ast" }()
+
+			opts, err := o.generatorOptions()
+			if err != nil {
+				t.Fatalf("generatorOptions failed: %v", err)
+			}
+
+			outDir := t.TempDir()
+			o.astOutDir = outDir
+			if err := o.generateASTFiles(files, opts); err != nil {
+				t.Fatalf("generateASTFiles failed: %v", err)
+			}
+
+			exts := listExtensions(t, outDir)
+			for _, ext := range tt.want {
+				if !exts[ext] {
+					t.Errorf("expected a %s file in the output directory, got %v", ext, exts)
+				}
+			}
+			if len(exts) != len(tt.want) {
+				t.Errorf("expected only %v, got %v", tt.want, exts)
+			}
+		})
+	}
+}
+
+// TestGenerateArchives tests that generate writes text dumps and archives
+// to their own directories, and that the archives load back faithfully
+func TestGenerateArchives(t *testing.T) {
+	dir := writeFixture(t)
+	out := t.TempDir()
+	astDir, archiveDir := filepath.Join(out, "ast"), filepath.Join(out, "asta")
+
+	args := []string{"generate", "-nodes-dir", dir, "-ast-out-dir", astDir, "-archive-out-dir", archiveDir}
+	if status := Main(args); status != 0 {
+		t.Fatalf("%v exited %d", args, status)
+	}
+	if exts := listExtensions(t, astDir); len(exts) != 1 || !exts[".ast"] {
+		t.Errorf("expected only .ast dumps in %s, got %v", astDir, exts)
+	}
+	if exts := listExtensions(t, archiveDir); len(exts) != 1 || !exts[".asta"] {
+		t.Errorf("expected only .asta archives in %s, got %v", archiveDir, exts)
+	}
+
+	restored, restoredFset, source, err := archive.LoadASTWithSourceReconstruction(filepath.Join(archiveDir, "fixture.asta"))
+	if err != nil {
+		t.Fatalf("failed to load archive: %v", err)
+	}
+	if source != fixtureSource {
+		t.Errorf("archive source does not match the fixture")
+	}
+	fset := token.NewFileSet()
+	original, err := parser.ParseFile(fset, "fixture.go", fixtureSource, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("failed to parse fixture: %v", err)
+	}
+	if err := archive.VerifyPerfectFidelity(original, restored, fset, restoredFset); err != nil {
+		t.Errorf("archive failed verification: %v", err)
+	}
+
+	dumpsOnly := filepath.Join(out, "dumps-only")
+	if status := Main([]string{"generate", "-nodes-dir", dir, "-ast-out-dir", dumpsOnly, "-generate-archives=false"}); status != 0 {
+		t.Fatalf("-generate-archives=false exited %d", status)
+	}
+	if exts := listExtensions(t, dumpsOnly); exts[".asta"] {
+		t.Errorf("expected no archives with -generate-archives=false, got %v", exts)
+	}
+	if status := Main([]string{"generate", "-nodes-dir", dir, "-format", "asta", "-generate-archives=false"}); status != 1 {
+		t.Errorf("expected -format asta -generate-archives=false to fail, got %d", status)
+	}
+}
+
+// TestGeneratorOptionsValidation tests rejection of unknown flag values
+func TestGeneratorOptionsValidation(t *testing.T) {
+	o := testOptions()
+	o.genFormat = "yaml"
+
+	_, err := o.generatorOptions()
+	if err == nil || !strings.Contains(err.Error(), "supported:") {
+		t.Errorf("expected error listing supported formats, got %v", err)
+	}
+
+	o.genFormat = "ast"
+	o.genPositions = "everywhere"
+	if _, err := o.generatorOptions(); err == nil {
+		t.Error("expected error for unknown position mode")
+	}
+}
+
+// TestGeneratorOptionsPlumbing tests that flags reach the generator options
+func TestGeneratorOptionsPlumbing(t *testing.T) {
+	o := testOptions()
+	o.genPositions = "span"
+	o.genMaxDepth = 3
+
+	opts, err := o.generatorOptions()
+	if err != nil {
+		t.Fatalf("generatorOptions failed: %v", err)
+	}
+	if opts.Format != generator.FormatAST || opts.Positions != generator.PositionsSpan || opts.MaxDepth != 3 {
+		t.Errorf("unexpected options: %+v", opts)
+	}
+}
+
+// TestVerifyArchiveFiles tests the per-file results and summary of
+// -verify-archives, and that it does not generate anything
+func TestVerifyArchiveFiles(t *testing.T) {
+	dir := writeFixture(t)
+	o := testOptions()
+	out, err := captureOutput(t, func() error {
+		return o.verifyArchiveFiles([]string{filepath.Join(dir, "fixture.go")})
+	})
+	if err != nil {
+		t.Fatalf("verifyArchiveFiles failed: %v\n%s", err, out)
+	}
+	if !strings.Contains(out, "  ✓ fixture.go\n") || !strings.Contains(out, "Archive Summary: 1 passed, 0 failed, ") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+
+	outDir := t.TempDir()
+	if status := Main([]string{"generate", "-verify-archives", "-nodes-dir", dir, "-ast-out-dir", outDir}); status != 0 {
+		t.Errorf("generate -verify-archives exited %d", status)
+	}
+	if exts := listExtensions(t, outDir); len(exts) != 0 {
+		t.Errorf("expected nothing generated, got %v", exts)
+	}
+}
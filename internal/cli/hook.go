@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"zylisp/go-ast-coverage/internal/runner"
+)
+
+// splitCommand splits a -post-run command into words at unquoted spaces.
+// Single or double quotes group words with spaces in them, and are
+// removed; there is no other shell syntax.
+func splitCommand(command string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	var quote rune
+	for _, r := range command {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, inWord = r, true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	if len(words) == 0 {
+		return nil, fmt.Errorf("empty command")
+	}
+	return words, nil
+}
+
+// hookStatus is the {status} a -post-run command is given for status.
+func hookStatus(status runner.Status) string {
+	switch status {
+	case runner.Passed:
+		return "passed"
+	case runner.Cached:
+		return "cached"
+	case runner.TimedOut:
+		return "timeout"
+	}
+	return "failed"
+}
+
+// postRunHook returns the hook that runs -post-run after each sample, with
+// {file} replaced by the sample's path and {status} by how its execution
+// ended: passed, cached, failed, or timeout. The command runs in the
+// current directory with this process's environment, not in the sandbox.
+func (o *options) postRunHook() (runner.Hook, error) {
+	words, err := splitCommand(o.postRun)
+	if err != nil {
+		return nil, fmt.Errorf("invalid -post-run: %w", err)
+	}
+	return func(ctx context.Context, path string, status runner.Status) ([]byte, error) {
+		r := strings.NewReplacer("{file}", path, "{status}", hookStatus(status))
+		args := make([]string, len(words))
+		for i, word := range words {
+			args[i] = r.Replace(word)
+		}
+		return runCommand(ctx, "", nil, args[0], args[1:]...)
+	}, nil
+}
+
+// logPath returns the -log-dir file for a sample.
+func logPath(dir, filePath string) string {
+	return filepath.Join(dir, strings.TrimSuffix(filepath.Base(filePath), ".go")+".log")
+}
+
+// writeFileLog writes a sample's output, followed by its -post-run hook's,
+// to its file in -log-dir.
+func (o *options) writeFileLog(result *runner.FileResult) error {
+	var b strings.Builder
+	if result.Attempts == 0 {
+		b.WriteString("(cached: not executed)\n")
+	} else {
+		b.Write(result.Output)
+	}
+	if o.postRun != "" {
+		hookResult := "ok"
+		if result.HookErr != nil {
+			hookResult = result.HookErr.Error()
+		}
+		fmt.Fprintf(&b, "--- post-run: %s ---\n", hookResult)
+		b.Write(result.HookOutput)
+	}
+
+	if err := os.MkdirAll(o.logDir, 0755); err != nil {
+		return fmt.Errorf("failed to create log directory: %w", err)
+	}
+	if err := os.WriteFile(logPath(o.logDir, result.Path), []byte(b.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write log: %w", err)
+	}
+	return nil
+}
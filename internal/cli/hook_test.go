@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestSplitCommand tests splitting -post-run commands into words
+func TestSplitCommand(t *testing.T) {
+	for _, tt := range []struct {
+		command string
+		want    []string
+	}{
+		{"golint {file}", []string{"golint", "{file}"}},
+		{"  sh -c 'echo {status} >> log'  ", []string{"sh", "-c", "echo {status} >> log"}},
+		{`check "a b"c ''`, []string{"check", "a bc", ""}},
+	} {
+		got, err := splitCommand(tt.command)
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitCommand(%q) = %q, %v; expected %q", tt.command, got, err, tt.want)
+		}
+	}
+
+	for _, command := range []string{"", "   ", "sh -c 'echo"} {
+		if _, err := splitCommand(command); err == nil {
+			t.Errorf("expected splitCommand(%q) to fail", command)
+		}
+	}
+}
+
+// TestRunTestFilesPostRun tests that -post-run runs after each sample with
+// its file and status, that a failing command is reported as HOOK_FAILED
+// and fails the phase, and that -log-dir gets both outputs
+func TestRunTestFilesPostRun(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("Skipping test - needs sh")
+	}
+
+	dir := t.TempDir()
+	logDir := filepath.Join(dir, "logs")
+	o := testOptions()
+	o.goTool = func(ctx context.Context, dir string, env []string, args ...string) ([]byte, error) {
+		return []byte("sample ran\n"), nil
+	}
+	o.postRun = `sh -c 'echo checked {status}; case {file} in *bad*) exit 3;; esac'`
+	o.logDir = logDir
+
+	files := []string{filepath.Join(dir, "good.go"), filepath.Join(dir, "bad.go")}
+	out, err := captureRun(t, o, files)
+	if exitStatus(err) != ExitSamples || !strings.Contains(err.Error(), "1 file(s) failed their -post-run command") {
+		t.Errorf("expected the hook failure to fail the phase, got %v", err)
+	}
+	if !strings.Contains(out, "Running bad.go...\n  ✓ Success\n  ✗ HOOK_FAILED: exit status 3") {
+		t.Errorf("expected bad.go to be reported as HOOK_FAILED:\n%s", out)
+	}
+	if !strings.Contains(out, "1 executed, 0 cached, 0 failed, 0 timed out, 1 hook failures") {
+		t.Errorf("expected the hook failure to be counted:\n%s", out)
+	}
+
+	log, err := os.ReadFile(filepath.Join(logDir, "bad.log"))
+	if err != nil {
+		t.Fatalf("failed to read log: %v", err)
+	}
+	if got := string(log); got != "sample ran\n--- post-run: exit status 3 ---\nchecked passed\n" {
+		t.Errorf("unexpected log for bad.go:\n%s", got)
+	}
+	if log, _ := os.ReadFile(filepath.Join(logDir, "good.log")); !strings.Contains(string(log), "--- post-run: ok ---") {
+		t.Errorf("unexpected log for good.go:\n%s", log)
+	}
+}
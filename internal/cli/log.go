@@ -0,0 +1,134 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+)
+
+// logHandler is a slog.Handler that writes each record as a plain line:
+// its message followed by any attributes as key=value. Levels only decide
+// which records are written, so the console reads as it always has while
+// -quiet and -verbose choose how much of it there is.
+type logHandler struct {
+	out   io.Writer
+	level slog.Level
+	attrs []slog.Attr
+}
+
+func (h *logHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level
+}
+
+func (h *logHandler) Handle(_ context.Context, r slog.Record) error {
+	var b strings.Builder
+	b.WriteString(r.Message)
+	for _, a := range h.attrs {
+		fmt.Fprintf(&b, " %s", a)
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		fmt.Fprintf(&b, " %s", a)
+		return true
+	})
+	b.WriteByte('\n')
+	_, err := io.WriteString(h.out, b.String())
+	return err
+}
+
+func (h *logHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &logHandler{out: h.out, level: h.level, attrs: append(append([]slog.Attr{}, h.attrs...), attrs...)}
+}
+
+// WithGroup ignores groups; the command does not use them.
+func (h *logHandler) WithGroup(string) slog.Handler {
+	return h
+}
+
+// logOutput is where log lines are written: stderr, or while a phase shows
+// its progress, the progress, which keeps them off the progress line.
+type logOutput struct {
+	mu sync.Mutex
+	w  io.Writer // nil means os.Stderr as it is at the time of writing
+}
+
+func (l *logOutput) Write(b []byte) (int, error) {
+	l.mu.Lock()
+	w := l.w
+	l.mu.Unlock()
+	if w == nil {
+		w = os.Stderr
+	}
+	return w.Write(b)
+}
+
+// set makes w the destination; nil restores stderr.
+func (l *logOutput) set(w io.Writer) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.w = w
+}
+
+// logLevel is the lowest level logged: errors only with -quiet, debug
+// messages such as sample output with -verbose, and otherwise info.
+func (o *options) logLevel() slog.Level {
+	switch {
+	case o.quiet:
+		return slog.LevelError
+	case o.verbose:
+		return slog.LevelDebug
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// logger returns the logger diagnostics are written to, on stderr.
+// Results such as the report, analysis statistics, and summaries are
+// printed to stdout instead.
+func (o *options) logger() *slog.Logger {
+	if o.log == nil {
+		o.log = slog.New(&logHandler{out: &o.logOut, level: o.logLevel()})
+	}
+	return o.log
+}
+
+// debugf logs detail shown only with -verbose.
+func (o *options) debugf(format string, args ...any) {
+	o.logger().Debug(fmt.Sprintf(format, args...))
+}
+
+// infof logs progress, hidden by -quiet.
+func (o *options) infof(format string, args ...any) {
+	o.logger().Info(fmt.Sprintf(format, args...))
+}
+
+// warnf logs a problem that does not stop the run, hidden by -quiet.
+func (o *options) warnf(format string, args ...any) {
+	o.logger().Warn(fmt.Sprintf(format, args...))
+}
+
+// errorf logs a problem that fails the run; it is always shown.
+func (o *options) errorf(format string, args ...any) {
+	o.logger().Error(fmt.Sprintf(format, args...))
+}
+
+// levelWriter logs each write, which should be a whole line, as a message
+// at its level.
+type levelWriter struct {
+	o     *options
+	level slog.Level
+}
+
+func (w levelWriter) Write(b []byte) (int, error) {
+	w.o.logger().Log(context.Background(), w.level, strings.TrimSuffix(string(b), "\n"))
+	return len(b), nil
+}
+
+// logWriter returns a writer for packages that print their own messages,
+// such as the generator, that logs what they print at level.
+func (o *options) logWriter(level slog.Level) io.Writer {
+	return levelWriter{o: o, level: level}
+}
@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// launcher starts the program name with args and returns without waiting
+// for it to finish. startCommand is the real one; tests substitute their
+// own.
+type launcher func(name string, args ...string) error
+
+// startCommand starts name in the background, reaping it when it exits.
+func startCommand(name string, args ...string) error {
+	cmd := exec.Command(name, args...)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go cmd.Wait()
+	return nil
+}
+
+// browserCommand returns the command that opens u in the default browser
+// on goos, reading the environment with getenv. There is none, and ok is
+// false, on platforms without a known opener and on a Unix desktop-less
+// machine, which has neither DISPLAY nor WAYLAND_DISPLAY set.
+func browserCommand(goos string, getenv func(string) string, u string) (name string, args []string, ok bool) {
+	switch goos {
+	case "windows":
+		return "rundll32", []string{"url.dll,FileProtocolHandler", u}, true
+	case "darwin":
+		return "open", []string{u}, true
+	case "linux", "freebsd", "netbsd", "openbsd", "dragonfly", "solaris", "illumos":
+		if getenv("DISPLAY") == "" && getenv("WAYLAND_DISPLAY") == "" {
+			return "", nil, false
+		}
+		return "xdg-open", []string{u}, true
+	default:
+		return "", nil, false
+	}
+}
+
+// fileURL returns the file:// URL of path.
+func fileURL(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", path, err)
+	}
+	p := filepath.ToSlash(abs)
+	if !strings.HasPrefix(p, "/") {
+		p = "/" + p // a Windows drive letter
+	}
+	return (&url.URL{Scheme: "file", Path: p}).String(), nil
+}
+
+// openInBrowser prints the file URL of the report at path and opens it in
+// the browser, when there is one. Without a browser, or when it cannot be
+// started, the URL alone is printed.
+func (o *options) openInBrowser(path string) {
+	u, err := fileURL(path)
+	if err != nil {
+		o.warnf("Warning: %v", err)
+		return
+	}
+
+	name, args, ok := browserCommand(runtime.GOOS, os.Getenv, u)
+	if !ok {
+		o.infof("No browser available; open %s", u)
+		return
+	}
+	launch := o.launch
+	if launch == nil {
+		launch = startCommand
+	}
+	if err := launch(name, args...); err != nil {
+		o.warnf("Warning: failed to open a browser (%v); open %s", err, u)
+		return
+	}
+	o.infof("Opening %s", u)
+}
@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// TestBrowserCommand tests the opener chosen for each platform, and that
+// a Unix machine without a display has none
+func TestBrowserCommand(t *testing.T) {
+	const u = "file:///tmp/coverage-report.html"
+	env := func(vars map[string]string) func(string) string {
+		return func(key string) string { return vars[key] }
+	}
+	display := env(map[string]string{"DISPLAY": ":0"})
+	headless := env(nil)
+
+	tests := []struct {
+		goos   string
+		getenv func(string) string
+		want   string // "" for no opener
+	}{
+		{"linux", display, "xdg-open"},
+		{"linux", env(map[string]string{"WAYLAND_DISPLAY": "wayland-0"}), "xdg-open"},
+		{"linux", headless, ""},
+		{"freebsd", headless, ""},
+		{"darwin", headless, "open"},
+		{"windows", headless, "rundll32"},
+		{"plan9", display, ""},
+	}
+	for _, tt := range tests {
+		name, args, ok := browserCommand(tt.goos, tt.getenv, u)
+		if ok != (tt.want != "") || name != tt.want {
+			t.Errorf("%s: got %q, %v; want %q", tt.goos, name, ok, tt.want)
+			continue
+		}
+		if ok && args[len(args)-1] != u {
+			t.Errorf("%s: the URL is not the last argument: %q", tt.goos, args)
+		}
+	}
+}
+
+// TestOpenInBrowser tests that the report's file URL is launched without
+// waiting and printed, and is still printed when the launcher fails
+func TestOpenInBrowser(t *testing.T) {
+	t.Setenv("DISPLAY", ":0")
+	path := filepath.Join(t.TempDir(), "coverage-report.html")
+	u, err := fileURL(path)
+	if err != nil {
+		t.Fatalf("fileURL failed: %v", err)
+	}
+	if _, _, ok := browserCommand(runtime.GOOS, os.Getenv, u); !ok {
+		t.Skipf("no browser opener on %s", runtime.GOOS)
+	}
+
+	var launched []string
+	o := testOptions()
+	o.launch = func(name string, args ...string) error {
+		launched = append([]string{name}, args...)
+		return nil
+	}
+	out, _ := captureStderr(t, func() error {
+		o.openInBrowser(path)
+		return nil
+	})
+	if len(launched) == 0 || launched[len(launched)-1] != u {
+		t.Errorf("expected %s to be launched, got %q", u, launched)
+	}
+	if !strings.Contains(out, "Opening "+u) {
+		t.Errorf("expected the URL to be printed:\n%s", out)
+	}
+
+	o.launch = func(string, ...string) error { return errors.New("no such program") }
+	out, _ = captureStderr(t, func() error {
+		o.openInBrowser(path)
+		return nil
+	})
+	if !strings.Contains(out, "no such program") || !strings.Contains(out, u) {
+		t.Errorf("expected the failure and the URL:\n%s", out)
+	}
+}
@@ -0,0 +1,373 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	report "zylisp/go-ast-coverage/coverage-report"
+	"zylisp/go-ast-coverage/generator"
+)
+
+// outputStatus describes an output file relative to the sources it is
+// generated from.
+type outputStatus string
+
+const (
+	outputNew    outputStatus = "new"    // does not exist yet
+	outputStale  outputStatus = "stale"  // older than one of its sources
+	outputFresh  outputStatus = "fresh"  // newer than its sources; rewritten anyway
+	outputCached outputStatus = "cached" // a report cache entry that will be reused
+)
+
+// plannedOutput is a file a run would write, or with skip, reuse.
+type plannedOutput struct {
+	path   string
+	status outputStatus
+	skip   bool
+}
+
+// plan is what a run would do: the phases, sample files, and outputs,
+// worked out without executing or writing anything.
+type plan struct {
+	phases  []string
+	dirs    []string
+	files   []string
+	outputs []plannedOutput
+
+	// covering is the -covering node types, and corpusSize the number of
+	// sample files before they filtered them
+	covering   []string
+	corpusSize int
+
+	// seed is the -shuffle seed files were put in order with
+	seed int64
+}
+
+// selectPhases settles which phases run: the flag-only command line runs
+// all when no phase is selected, -targets implies -check, -check selects
+// run there, and a coverage threshold needs a report. -seed implies
+// -shuffle, and -open implies -html.
+func (o *options) selectPhases() {
+	if o.targetList != "" {
+		o.checkOnly = true
+	}
+	if o.seed != 0 {
+		o.shuffle = true
+	}
+	if o.openHTML {
+		o.saveHTML = true
+	}
+	if o.legacy && o.checkOnly {
+		o.runTests = true
+	}
+	if o.legacy && !o.runTests && !o.analyze && !o.generateReport && !o.writeGolden && !o.verifyGolden && !o.verifyArchives && !o.bench && !o.redundancy && o.heatmapPath == "" && o.mergePaths == "" && !o.browseTUI && o.serveAddr == "" && !o.all {
+		o.all = true
+	}
+
+	if o.all {
+		o.runTests = true
+		o.analyze = true
+		o.generateReport = true
+	}
+
+	if o.requireFull {
+		o.minCoverage = 100
+	}
+	if o.minCoverage > 0 || o.requireNodes != "" {
+		o.generateReport = true
+	}
+}
+
+// usesFiles reports whether a selected phase works on the sample files.
+// The others, such as -merge, read only their own inputs, so the corpus
+// need not exist for them.
+func (o *options) usesFiles() bool {
+	return o.runTests || o.analyze || o.generateAST || o.verifyArchives || o.bench || o.heatmapPath != "" || o.generateReport
+}
+
+// makePlan selects the phases, finds the sample files, and works out the
+// outputs the run would write. It only reads the filesystem.
+func (o *options) makePlan() (*plan, error) {
+	o.selectPhases()
+
+	if o.quiet && o.verbose {
+		return nil, fmt.Errorf("-quiet and -verbose cannot be combined")
+	}
+	switch o.execMode {
+	case "", execModeRun, execModeBuild:
+	default:
+		return nil, fmt.Errorf("invalid -exec-mode %q (want %s or %s)", o.execMode, execModeRun, execModeBuild)
+	}
+	if o.keepGoing && o.failFast {
+		return nil, fmt.Errorf("-keep-going and -fail-fast cannot be combined")
+	}
+	if o.retries < 0 {
+		return nil, fmt.Errorf("invalid -retries: must not be negative")
+	}
+	if o.postRun != "" {
+		if _, err := splitCommand(o.postRun); err != nil {
+			return nil, fmt.Errorf("invalid -post-run: %w", err)
+		}
+	}
+	if o.targetList != "" {
+		targets, err := parseTargets(o.targetList)
+		if err != nil {
+			return nil, err
+		}
+		o.targets = targets
+	}
+
+	p := &plan{dirs: o.nodesDirs}
+	if len(p.dirs) == 0 {
+		p.dirs = []string{"nodes/go"}
+	}
+	if o.usesFiles() {
+		files, err := o.corpusFiles(p.dirs)
+		if err != nil {
+			return nil, err
+		}
+		if o.covering != "" {
+			if p.covering, err = parseCovering(o.covering); err != nil {
+				return nil, err
+			}
+			p.corpusSize = len(files)
+			if files, err = filesCovering(files, p.covering); err != nil {
+				return nil, err
+			}
+		}
+		if o.shuffle {
+			p.seed = o.seed
+			if p.seed == 0 {
+				p.seed = o.clock().UnixNano()
+			}
+			files = shuffleFiles(files, p.seed)
+		}
+		p.files = files
+	}
+	files := p.files
+
+	for _, phase := range []struct {
+		name    string
+		enabled bool
+	}{
+		{"run", o.runTests && !o.checkOnly},
+		{"check", o.runTests && o.checkOnly},
+		{"analyze", o.analyze},
+		{"generate", o.generateAST},
+		{"write-golden", o.writeGolden},
+		{"verify-golden", o.verifyGolden},
+		{"verify-archives", o.verifyArchives},
+		{"bench", o.bench},
+		{"redundancy", o.redundancy},
+		{"serve", o.serveAddr != ""},
+		{"tui", o.browseTUI},
+		{"merge", o.mergePaths != ""},
+		{"heatmap", o.heatmapPath != ""},
+		{"report", o.generateReport},
+	} {
+		if phase.enabled {
+			p.phases = append(p.phases, phase.name)
+		}
+	}
+
+	if o.runTests && !o.checkOnly && o.cacheDir != "" && !o.noCache {
+		for _, file := range files {
+			entry, err := runCachePath(o.cacheDir, file)
+			if err != nil {
+				return nil, err
+			}
+			if o.cachedRun(file) != nil {
+				p.outputs = append(p.outputs, plannedOutput{path: entry, status: outputCached, skip: true})
+			} else {
+				p.addOutput(entry, file)
+			}
+		}
+	}
+
+	if o.runTests && !o.checkOnly && o.runGoldenDir != "" && o.updateGolden {
+		for _, file := range files {
+			p.addOutput(goldenOutputPath(o.runGoldenDir, file), file)
+		}
+	}
+
+	if o.runTests && !o.checkOnly && o.logDir != "" {
+		for _, file := range files {
+			p.addOutput(logPath(o.logDir, file), file)
+		}
+	}
+
+	if o.generateAST {
+		genOpts, err := o.generatorOptions()
+		if err != nil {
+			return nil, err
+		}
+		astDir, archiveDir, err := o.generateDirs(genOpts.Format)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			if archiveDir != "" {
+				p.addOutput(generator.OutputPath(file, archiveDir, generator.FormatArchive), file)
+			}
+			if astDir != "" {
+				p.addOutput(generator.OutputPath(file, astDir, genOpts.Format), file)
+			}
+		}
+	}
+
+	if o.writeGolden {
+		for _, dir := range p.dirs {
+			dirFiles, err := goFiles(dir)
+			if err != nil {
+				return nil, err
+			}
+			for _, file := range dirFiles {
+				p.addOutput(generator.OutputPath(file, goldenDir, generator.FormatFprint), file)
+			}
+		}
+	}
+
+	if o.bench && o.benchJSON != "" {
+		p.addOutput(o.benchJSON, files...)
+	}
+
+	if o.heatmapPath != "" {
+		p.addOutput(o.heatmapPath, files...)
+	}
+
+	if o.generateReport {
+		if err := o.planReport(p); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, profile := range []string{o.cpuProfile, o.memProfile} {
+		if profile != "" {
+			p.addOutput(profile)
+		}
+	}
+
+	return p, nil
+}
+
+// planReport adds the report cache entries and saved report files to p.
+func (o *options) planReport(p *plan) error {
+	if o.cacheDir != "" && !o.noCache {
+		dirs := p.dirs
+		if o.filePaths != "" {
+			dirs = dirs[:1]
+		}
+		for _, dir := range dirs {
+			opts, err := o.reportOptions(dir)
+			if err != nil {
+				return err
+			}
+			entry, exists, err := report.CacheEntry(dir, opts)
+			if err != nil {
+				return err
+			}
+			if exists {
+				p.outputs = append(p.outputs, plannedOutput{path: entry, status: outputCached, skip: true})
+			} else {
+				p.outputs = append(p.outputs, plannedOutput{path: entry, status: outputNew})
+			}
+		}
+	}
+
+	p.addOutput(o.reportOut, p.files...)
+	for _, format := range []struct {
+		enabled bool
+		exts    []string
+	}{
+		{o.saveJSON, []string{".json"}},
+		{o.saveJUnit, []string{".xml"}},
+		{o.saveHTML, []string{".html"}},
+		{o.saveSARIF, []string{".sarif"}},
+		{o.saveCSV, []string{"-nodes.csv", "-files.csv"}},
+	} {
+		if !format.enabled {
+			continue
+		}
+		for _, ext := range format.exts {
+			p.addOutput(o.reportPath(ext), p.files...)
+		}
+	}
+	if o.historyPath != "" {
+		p.addOutput(o.historyPath, p.files...)
+	}
+	if o.baselinePath != "" && o.updateBase && !o.jsonIsBaseline() {
+		p.addOutput(o.baselinePath, p.files...)
+	}
+	return nil
+}
+
+// filterSummary describes the -covering filter and how many files it
+// kept.
+func (p *plan) filterSummary() string {
+	return fmt.Sprintf("Filter: covering %s (%d of %d files match)", strings.Join(p.covering, ", "), len(p.files), p.corpusSize)
+}
+
+// shuffleSummary gives the -seed that reproduces the -shuffle order.
+func (p *plan) shuffleSummary() string {
+	return fmt.Sprintf("Shuffled file order (reproduce with -seed %d)", p.seed)
+}
+
+// addOutput records that the run would write path from sources.
+func (p *plan) addOutput(path string, sources ...string) {
+	p.outputs = append(p.outputs, plannedOutput{path: path, status: outputState(path, sources)})
+}
+
+// outputState compares an output's modification time with its sources'.
+func outputState(path string, sources []string) outputStatus {
+	info, err := os.Stat(path)
+	if err != nil {
+		return outputNew
+	}
+	for _, source := range sources {
+		if src, err := os.Stat(source); err == nil && src.ModTime().After(info.ModTime()) {
+			return outputStale
+		}
+	}
+	return outputFresh
+}
+
+// print writes the plan for -dry-run.
+func (p *plan) print(w io.Writer) {
+	fmt.Fprintln(w, "=== Dry Run ===")
+	fmt.Fprintln(w)
+	phases := "none"
+	if len(p.phases) > 0 {
+		phases = strings.Join(p.phases, ", ")
+	}
+	fmt.Fprintf(w, "Phases: %s\n\n", phases)
+	if p.covering != nil {
+		fmt.Fprintf(w, "%s\n\n", p.filterSummary())
+	}
+	if p.seed != 0 {
+		fmt.Fprintf(w, "%s\n\n", p.shuffleSummary())
+	}
+
+	fmt.Fprintf(w, "Files (%d):\n", len(p.files))
+	for _, file := range p.files {
+		fmt.Fprintf(w, "  %s\n", file)
+	}
+	fmt.Fprintln(w)
+
+	fmt.Fprintf(w, "Outputs (%d):\n", len(p.outputs))
+	width := 0
+	for _, out := range p.outputs {
+		width = max(width, len(out.path))
+	}
+	for _, out := range p.outputs {
+		action := "write"
+		if out.skip {
+			action = "reuse"
+		}
+		fmt.Fprintf(w, "  %-5s  %-*s  (%s)\n", action, width, filepath.ToSlash(out.path), out.status)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Nothing was executed or written.")
+}
@@ -0,0 +1,203 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestMakePlan tests the plan for a fixture directory with one stale and
+// one fresh output
+func TestMakePlan(t *testing.T) {
+	dir := writeFixture(t)
+	second := filepath.Join(dir, "second.go")
+	if err := os.WriteFile(second, []byte(fixtureSource), 0644); err != nil {
+		t.Fatalf("failed to write sample: %v", err)
+	}
+
+	// fixture.asta predates its source; second.asta was written after
+	out := t.TempDir()
+	past, now := time.Now().Add(-time.Hour), time.Now()
+	for name, mtime := range map[string]time.Time{"fixture.asta": past, "second.asta": now} {
+		path := filepath.Join(out, name)
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatalf("failed to set %s mtime: %v", name, err)
+		}
+	}
+	if err := os.Chtimes(second, past, past); err != nil {
+		t.Fatalf("failed to set source mtime: %v", err)
+	}
+
+	o := testOptions()
+	o.legacy = false
+	o.generateAST = true
+	o.nodesDirs = dirList{dir}
+	o.astOutDir = out
+	o.genFormat = "fprint"
+	p, err := o.makePlan()
+	if err != nil {
+		t.Fatalf("makePlan failed: %v", err)
+	}
+
+	if strings.Join(p.phases, ",") != "generate" {
+		t.Errorf("unexpected phases %v", p.phases)
+	}
+	if len(p.files) != 2 || filepath.Base(p.files[0]) != "fixture.go" || filepath.Base(p.files[1]) != "second.go" {
+		t.Errorf("unexpected files %v", p.files)
+	}
+
+	want := map[string]outputStatus{
+		"fixture.asta": outputStale,
+		"fixture.ast":  outputNew,
+		"second.asta":  outputFresh,
+		"second.ast":   outputNew,
+	}
+	if len(p.outputs) != len(want) {
+		t.Fatalf("expected %d outputs, got %+v", len(want), p.outputs)
+	}
+	for _, output := range p.outputs {
+		if status := want[filepath.Base(output.path)]; output.status != status || output.skip {
+			t.Errorf("%s: got %s (skip %v), want %s", output.path, output.status, output.skip, status)
+		}
+	}
+
+	var buf bytes.Buffer
+	p.print(&buf)
+	if !strings.Contains(buf.String(), "Phases: generate\n") || !strings.Contains(buf.String(), "fixture.asta  (stale)") {
+		t.Errorf("unexpected plan output:\n%s", buf.String())
+	}
+}
+
+// TestDryRun tests that -dry-run writes nothing
+func TestDryRun(t *testing.T) {
+	dir := writeFixture(t)
+	out := t.TempDir()
+	cache := filepath.Join(out, "cache")
+	reportOut := filepath.Join(out, "report", "coverage.txt")
+
+	args := []string{"all", "-dry-run", "-nodes-dir", dir, "-report-out", reportOut, "-json", "-cache", cache}
+	if status := Main(args); status != 0 {
+		t.Fatalf("%v exited %d", args, status)
+	}
+	entries, err := os.ReadDir(out)
+	if err != nil || len(entries) != 0 {
+		t.Errorf("dry run wrote %v, %v", entries, err)
+	}
+
+	// A cached report is listed as reused
+	o := testOptions()
+	o.nodesDirs = dirList{dir}
+	o.cacheDir = cache
+	o.generateReport = true
+	if status := Main([]string{"report", "-nodes-dir", dir, "-report-out", reportOut, "-cache", cache}); status != 0 {
+		t.Fatalf("report exited %d", status)
+	}
+	p, err := o.makePlan()
+	if err != nil {
+		t.Fatalf("makePlan failed: %v", err)
+	}
+	if len(p.outputs) == 0 || p.outputs[0].status != outputCached || !p.outputs[0].skip {
+		t.Errorf("expected a reused cache entry first, got %+v", p.outputs)
+	}
+}
+
+// TestMakePlanWithoutCorpus tests that phases that do not read the sample
+// files, such as -merge, plan without the corpus directory
+func TestMakePlanWithoutCorpus(t *testing.T) {
+	o := testOptions()
+	o.legacy = false
+	o.nodesDirs = dirList{filepath.Join(t.TempDir(), "missing")}
+	o.mergePaths = "a.json,b.json"
+	p, err := o.makePlan()
+	if err != nil {
+		t.Fatalf("makePlan failed: %v", err)
+	}
+	if strings.Join(p.phases, ",") != "merge" || len(p.files) != 0 {
+		t.Errorf("expected only the merge phase and no files, got %v and %v", p.phases, p.files)
+	}
+
+	o.generateReport = true
+	if _, err := o.makePlan(); err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Errorf("expected a missing corpus error for the report, got %v", err)
+	}
+}
+
+// TestMakePlanCovering tests that -covering keeps only the samples
+// containing the node types and rejects unknown names
+func TestMakePlanCovering(t *testing.T) {
+	dir := writeFixture(t)
+	src := "package main\n\nfunc main() {\n\tdone := make(chan bool)\n\tgo func() { done <- true }()\n\t<-done\n}\n"
+	if err := os.WriteFile(filepath.Join(dir, "goroutine.go"), []byte(src), 0644); err != nil {
+		t.Fatalf("failed to write sample: %v", err)
+	}
+
+	o := testOptions()
+	o.legacy = false
+	o.analyze = true
+	o.nodesDirs = dirList{dir}
+	o.covering = "*ast.SelectStmt,GoStmt"
+	p, err := o.makePlan()
+	if err != nil {
+		t.Fatalf("makePlan failed: %v", err)
+	}
+	if len(p.files) != 1 || filepath.Base(p.files[0]) != "goroutine.go" {
+		t.Errorf("expected only goroutine.go, got %v", p.files)
+	}
+	if got := p.filterSummary(); got != "Filter: covering *ast.SelectStmt, *ast.GoStmt (1 of 2 files match)" {
+		t.Errorf("unexpected summary %q", got)
+	}
+
+	o.covering = "SelectStmnt"
+	if _, err := o.makePlan(); err == nil || !strings.Contains(err.Error(), "did you mean *ast.SelectStmt") {
+		t.Errorf("expected close matches for an unknown node type, got %v", err)
+	}
+	o.covering = "DeferStmt"
+	if _, err := o.makePlan(); err == nil || !strings.Contains(err.Error(), "no sample files contain *ast.DeferStmt") {
+		t.Errorf("expected an error when nothing matches, got %v", err)
+	}
+}
+
+// TestMakePlanShuffle tests that -seed implies -shuffle and reproduces the
+// same file order
+func TestMakePlanShuffle(t *testing.T) {
+	dir := t.TempDir()
+	for i := 0; i < 8; i++ {
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("sample%d.go", i)), []byte(fixtureSource), 0644); err != nil {
+			t.Fatalf("failed to write sample: %v", err)
+		}
+	}
+
+	o := testOptions()
+	o.nodesDirs = dirList{dir}
+	o.seed = 12345
+	p, err := o.makePlan()
+	if err != nil {
+		t.Fatalf("makePlan failed: %v", err)
+	}
+	files, err := goFiles(dir)
+	if err != nil {
+		t.Fatalf("goFiles failed: %v", err)
+	}
+	if !o.shuffle || !slices.Equal(p.files, shuffleFiles(files, 12345)) {
+		t.Errorf("expected the seed 12345 order, got %v", p.files)
+	}
+	if got := p.shuffleSummary(); got != "Shuffled file order (reproduce with -seed 12345)" {
+		t.Errorf("unexpected summary %q", got)
+	}
+
+	o = testOptions()
+	o.nodesDirs = dirList{dir}
+	o.shuffle = true
+	o.now = func() time.Time { return time.Unix(0, 777) }
+	if p, err = o.makePlan(); err != nil || p.seed != 777 {
+		t.Errorf("expected a seed from the clock, got %d, %v", p.seed, err)
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !unix
+
+package cli
+
+import "os/exec"
+
+// setProcessGroup does nothing where process groups are unavailable.
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// killProcessGroup kills cmd's process; programs it started may survive.
+func killProcessGroup(cmd *exec.Cmd) error {
+	return cmd.Process.Kill()
+}
@@ -0,0 +1,146 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// plainProgressInterval is how often progress is printed when it cannot
+// be updated in place.
+const plainProgressInterval = 10 * time.Second
+
+// progress reports how far a phase has got through the sample files, such
+// as "[ 37/142 ] running map_channel_types.go  (12.3s elapsed)". On a
+// terminal the line is redrawn in place as each file begins; otherwise a
+// plain line is printed at most every interval. While it is shown, log
+// lines are written through it, so they are never mixed into the line. It
+// is safe for concurrent use.
+type progress struct {
+	mu       sync.Mutex
+	w        io.Writer
+	now      func() time.Time
+	inPlace  bool
+	interval time.Duration
+
+	verb    string
+	total   int
+	started int
+	current string
+
+	begun    time.Time
+	lastLine time.Time
+	drawn    bool
+	ended    bool
+
+	// onEnd is called by the first end
+	onEnd func()
+}
+
+// newProgress returns the progress of a phase working through total files,
+// described by verb (e.g. "running"), written to stderr, and routes log
+// lines through it until it ends. It is drawn in place only when stderr is
+// a terminal and -verbose is not set, since verbose output would break up
+// the line. With -quiet nothing is shown.
+func (o *options) newProgress(verb string, total int) *progress {
+	if o.quiet {
+		return newProgress(io.Discard, verb, total, false, time.Now)
+	}
+	p := newProgress(os.Stderr, verb, total, isTerminal(os.Stderr) && !o.verbose, time.Now)
+	o.logOut.set(p)
+	p.onEnd = func() { o.logOut.set(nil) }
+	return p
+}
+
+// newProgress returns a progress writing to w, reading the time from now.
+func newProgress(w io.Writer, verb string, total int, inPlace bool, now func() time.Time) *progress {
+	start := now()
+	return &progress{
+		w:        w,
+		now:      now,
+		inPlace:  inPlace,
+		interval: plainProgressInterval,
+		verb:     verb,
+		total:    total,
+		begun:    start,
+		lastLine: start,
+	}
+}
+
+// begin records that work on the file at path has started.
+func (p *progress) begin(path string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.started++
+	p.current = path
+	if p.ended {
+		return
+	}
+
+	if p.inPlace {
+		p.draw()
+		return
+	}
+	if now := p.now(); now.Sub(p.lastLine) >= p.interval {
+		fmt.Fprintln(p.w, p.line())
+		p.lastLine = now
+	}
+}
+
+// Write writes b to the underlying writer, clearing the in-place line
+// first and redrawing it once b ends a line.
+func (p *progress) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.drawn {
+		p.clear()
+	}
+	n, err := p.w.Write(b)
+	if p.inPlace && !p.ended && p.started > 0 && len(b) > 0 && b[len(b)-1] == '\n' {
+		p.draw()
+	}
+	return n, err
+}
+
+// end clears the in-place line; nothing more is drawn after it.
+func (p *progress) end() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.drawn {
+		p.clear()
+	}
+	if !p.ended && p.onEnd != nil {
+		p.onEnd()
+	}
+	p.ended = true
+}
+
+// line renders the progress line.
+func (p *progress) line() string {
+	elapsed := p.now().Sub(p.begun).Seconds()
+	return fmt.Sprintf("[ %d/%d ] %s %s  (%.1fs elapsed)", p.started, p.total, p.verb, filepath.Base(p.current), elapsed)
+}
+
+// draw replaces the current terminal line with the progress line.
+func (p *progress) draw() {
+	fmt.Fprint(p.w, "\r\x1b[K"+p.line())
+	p.drawn = true
+}
+
+// clear erases the in-place line.
+func (p *progress) clear() {
+	fmt.Fprint(p.w, "\r\x1b[K")
+	p.drawn = false
+}
+
+// isTerminal reports whether f is a character device such as a terminal.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock is a clock tests advance by hand.
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) now() time.Time          { return c.t }
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+func newFakeClock() *fakeClock               { return &fakeClock{t: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)} }
+
+// TestProgressInPlace tests that the line is redrawn in place and cleared
+// around the phase's own output
+func TestProgressInPlace(t *testing.T) {
+	clock := newFakeClock()
+	var buf bytes.Buffer
+	p := newProgress(&buf, "running", 142, true, clock.now)
+
+	clock.advance(12300 * time.Millisecond)
+	p.begin("nodes/go/map_channel_types.go")
+	if want := "\r\x1b[K[ 1/142 ] running map_channel_types.go  (12.3s elapsed)"; buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	fmt.Fprintf(p, "Running %s...\n", "map_channel_types.go")
+	want := "\r\x1b[KRunning map_channel_types.go...\n\r\x1b[K[ 1/142 ] running map_channel_types.go  (12.3s elapsed)"
+	if buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	p.end()
+	fmt.Fprintln(p, "summary")
+	p.begin("later.go")
+	if want := "\r\x1b[Ksummary\n"; buf.String() != want {
+		t.Errorf("expected nothing drawn after end, got %q", buf.String())
+	}
+}
+
+// TestProgressPlain tests that without a terminal, lines are printed at
+// most once per interval and output passes through untouched
+func TestProgressPlain(t *testing.T) {
+	clock := newFakeClock()
+	var buf bytes.Buffer
+	p := newProgress(&buf, "analyzing", 3, false, clock.now)
+
+	p.begin("a.go")
+	fmt.Fprintln(p, "Warning: a.go")
+	clock.advance(plainProgressInterval)
+	p.begin("b.go")
+	clock.advance(time.Second)
+	p.begin("c.go")
+	p.end()
+
+	want := "Warning: a.go\n[ 2/3 ] analyzing b.go  (10.0s elapsed)\n"
+	if buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
+
+// TestProgressConcurrent tests that parallel workers each count once
+func TestProgressConcurrent(t *testing.T) {
+	var buf bytes.Buffer
+	p := newProgress(&buf, "running", 100, true, time.Now)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			p.begin(fmt.Sprintf("sample%d.go", i))
+			fmt.Fprintf(p, "Running sample%d.go...\n", i)
+		}(i)
+	}
+	wg.Wait()
+	p.end()
+
+	if p.started != 100 {
+		t.Errorf("expected 100 files started, got %d", p.started)
+	}
+}
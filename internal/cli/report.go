@@ -0,0 +1,309 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	report "zylisp/go-ast-coverage/coverage-report"
+	"zylisp/go-ast-coverage/internal/runner"
+)
+
+// checkCoverage checks the report against -min-coverage and
+// -require-nodes, logging every missing node type as an error when coverage
+// is short.
+func (o *options) checkCoverage(rep *report.CoverageReport) error {
+	var required []string
+	if o.requireNodes != "" {
+		required = strings.Split(o.requireNodes, ",")
+	}
+	err := report.CheckThreshold(rep, o.minCoverage, required)
+	if errors.Is(err, report.ErrBelowThreshold) {
+		o.errorf("Missing node types (%d):", len(rep.MissingNodes))
+		for _, nodeType := range rep.MissingNodes {
+			o.errorf("  %s", nodeType)
+		}
+	}
+	return err
+}
+
+// reportPath returns where to save the report in the format with
+// extension ext: -report-out with its extension replaced.
+func (o *options) reportPath(ext string) string {
+	return strings.TrimSuffix(o.reportOut, filepath.Ext(o.reportOut)) + ext
+}
+
+// jsonIsBaseline reports whether the -json report would be saved over the
+// -baseline report.
+func (o *options) jsonIsBaseline() bool {
+	if !o.saveJSON || o.baselinePath == "" {
+		return false
+	}
+	return samePath(o.reportPath(".json"), o.baselinePath)
+}
+
+// jsonHeldBack reports whether the -json report is not saved because it
+// is the -baseline and the check did not update it.
+func (o *options) jsonHeldBack() bool {
+	return o.jsonIsBaseline() && o.regression != nil && !o.regression.BaselineUpdated
+}
+
+// samePath reports whether a and b name the same file.
+func samePath(a, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	if errA != nil || errB != nil {
+		return filepath.Clean(a) == filepath.Clean(b)
+	}
+	return absA == absB
+}
+
+// saveSARIFReport saves the report as SARIF, comparing against the
+// -sarif-baseline report when one is given.
+func (o *options) saveSARIFReport(rep *report.CoverageReport) error {
+	var opts report.SARIFOptions
+	if o.sarifBaseline != "" {
+		baseline, err := report.LoadReportJSON(o.sarifBaseline)
+		if err != nil {
+			return fmt.Errorf("failed to load baseline: %w", err)
+		}
+		opts.Baseline = baseline
+	}
+	return report.SaveReportSARIFWithOptions(rep, o.reportPath(".sarif"), opts)
+}
+
+// checkBaseline compares rep with the -baseline report, printing the
+// coverage delta and any node types that are no longer covered. With
+// -update-baseline a passing report replaces the baseline, or becomes it
+// when there is none. Without a baseline there is nothing to check, and
+// the result is nil.
+func (o *options) checkBaseline(rep *report.CoverageReport) (*report.RegressionResult, error) {
+	if o.baselinePath == "" {
+		return nil, nil
+	}
+	result, err := report.RegressionCheck(rep, o.baselinePath, report.RegressionOptions{UpdateBaseline: o.updateBase})
+	if errors.Is(err, report.ErrMissingBaseline) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Println()
+	if diff := result.Diff; diff != nil {
+		fmt.Printf("Coverage vs %s: %.2f%% → %.2f%% (%+.2f points)\n", o.baselinePath, diff.OldPercent, diff.NewPercent, diff.PercentDelta)
+	}
+	report.PrintRegressionResult(os.Stdout, result)
+	if !result.Pass && o.allowRegress {
+		fmt.Println("  Allowed by -allow-regression")
+	}
+	return result, nil
+}
+
+// mergeReportFiles loads the JSON reports at paths and prints their merged
+// report.
+func (o *options) mergeReportFiles(paths []string) error {
+	var reports []*report.CoverageReport
+	for _, path := range paths {
+		rep, err := report.LoadReportJSON(path)
+		if err != nil {
+			return fmt.Errorf("failed to load %s: %w", path, err)
+		}
+		if rep.Source == "" {
+			rep.Source = path
+		}
+		reports = append(reports, rep)
+	}
+
+	merged, err := report.MergeReports(reports...)
+	if err != nil {
+		return err
+	}
+	return o.printReport(merged)
+}
+
+// printReport prints the built-in text report to stdout in the -color mode,
+// in ASCII if -ascii is set or the locale is not UTF-8.
+func (o *options) printReport(rep *report.CoverageReport) error {
+	color, err := report.ParseColorMode(o.colorMode)
+	if err != nil {
+		return fmt.Errorf("invalid -color: %w", err)
+	}
+	opts := report.PrintOptions{Color: color, ASCIIOnly: o.asciiOnly || !report.LocaleSupportsUTF8()}
+	return report.PrintReportWithOptions(os.Stdout, rep, opts)
+}
+
+// buildCoverageReport generates the report for dirs. Several directories
+// are reported on separately and merged, so the report lists what each
+// contributed; -file reports on just the given files.
+func (o *options) buildCoverageReport(dirs []string) (*report.CoverageReport, error) {
+	if o.filePaths != "" {
+		dirs = dirs[:1]
+	}
+	return runner.BuildReport(dirs, o.buildDirReport)
+}
+
+// buildDirReport generates a report for dir with the -go-version profile
+// and -exclude exclusions, recording the git revision dir is at.
+func (o *options) buildDirReport(dir string) (*report.CoverageReport, error) {
+	opts, err := o.reportOptions(dir)
+	if err != nil {
+		return nil, err
+	}
+	return report.GenerateReportWithOptions(dir, opts)
+}
+
+// reportOptions returns the report options the flags select for dir.
+func (o *options) reportOptions(dir string) (report.ReportOptions, error) {
+	opts := report.ReportOptions{
+		GoVersion:          o.goVersion,
+		Revision:           report.GitRevision(dir),
+		IncludeRawAnalysis: o.rawAnalysis,
+		Warnings:           o.logWriter(slog.LevelWarn),
+	}
+	if !o.noCache {
+		opts.CacheDir = o.cacheDir
+	}
+	if o.filePaths != "" || o.excludeFiles != "" {
+		files, err := o.sampleFiles(dir)
+		if err != nil {
+			return opts, err
+		}
+		opts.Files = files
+	}
+	if o.excludePath != "" {
+		exclusions, err := report.LoadExclusions(o.excludePath)
+		if err != nil {
+			return opts, err
+		}
+		opts.Exclusions = exclusions
+	}
+	return opts, nil
+}
+
+// generateCoverageReport generates, displays, and saves the coverage report.
+func (o *options) generateCoverageReport(dirs []string) (*report.CoverageReport, error) {
+	if o.filePaths != "" {
+		o.warnf("Warning: -file limits the report to the given files; coverage percentages describe only them, not the corpus")
+	}
+	rep, err := o.buildCoverageReport(dirs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate report: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(o.reportOut), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create report directory: %w", err)
+	}
+
+	// Print report to stdout
+	if o.reportTmpl != "" {
+		tmpl, err := report.LoadTemplateFile(o.reportTmpl)
+		if err != nil {
+			return nil, err
+		}
+		if err := report.RenderTemplate(rep, tmpl, os.Stdout); err != nil {
+			return nil, err
+		}
+	} else if err := o.printReport(rep); err != nil {
+		return nil, err
+	}
+
+	// Compare with the baseline before a -json report can replace it
+	if o.regression, err = o.checkBaseline(rep); err != nil {
+		return nil, err
+	}
+
+	// Save the report in each format requested. When the JSON report is
+	// the baseline, it is only written to create a missing baseline or
+	// when the check updated it, so a regression cannot replace the report
+	// it regressed from.
+	o.infof("")
+	if o.saveJSON && o.jsonHeldBack() {
+		o.infof("JSON report not saved: %s is the -baseline and the check did not update it", o.baselinePath)
+	}
+	runner.SaveReport(rep, o.reportOutputs(), func(out *runner.Output, err error) {
+		if err != nil {
+			o.warnf("Warning: failed to save %s report: %v", out.Format, err)
+			return
+		}
+		o.infof("✓ %s report saved to: %s", out.Format, out.Path)
+		if out.Format == "HTML" && o.openHTML {
+			o.openInBrowser(out.Path)
+		}
+	})
+
+	// Record and show coverage history if requested
+	if o.historyPath != "" {
+		if err := report.AppendHistory(rep, o.historyPath); err != nil {
+			o.warnf("Warning: failed to record history: %v", err)
+		} else if history, err := report.LoadHistoryTo(o.historyPath, o.logWriter(slog.LevelWarn)); err != nil {
+			o.warnf("Warning: failed to load history: %v", err)
+		} else {
+			fmt.Println()
+			report.RenderTrend(os.Stdout, history)
+		}
+	}
+
+	return rep, nil
+}
+
+// reportOutputs returns the outputs the report is saved to: each format
+// the flags select, and then the text report at -report-out.
+func (o *options) reportOutputs() []runner.Output {
+	var outputs []runner.Output
+	if o.saveJSON && !o.jsonHeldBack() {
+		jsonPath := o.reportPath(".json")
+		opts := report.JSONOptions{Deterministic: o.deterministic}
+		outputs = append(outputs, runner.Output{Format: "JSON", Path: jsonPath, Save: func(rep *report.CoverageReport) error {
+			return report.SaveReportJSONWithOptions(rep, jsonPath, opts)
+		}})
+	}
+	if o.saveJUnit {
+		outputs = append(outputs, savedTo("JUnit", o.reportPath(".xml"), report.SaveReportJUnit))
+	}
+	if o.saveHTML {
+		outputs = append(outputs, savedTo("HTML", o.reportPath(".html"), report.SaveReportHTML))
+	}
+	if o.saveSARIF {
+		outputs = append(outputs, runner.Output{Format: "SARIF", Path: o.reportPath(".sarif"), Save: o.saveSARIFReport})
+	}
+	if o.saveCSV {
+		csvPath := o.reportPath(".csv")
+		outputs = append(outputs, runner.Output{
+			Format: "CSV",
+			Path:   o.reportPath("-nodes.csv") + ", " + o.reportPath("-files.csv"),
+			Save:   func(rep *report.CoverageReport) error { return report.SaveReportCSV(rep, csvPath) },
+		})
+	}
+	return append(outputs, savedTo("Text", o.reportOut, report.SaveReportText))
+}
+
+// savedTo returns the output that saves a report to path with save.
+func savedTo(format, path string, save func(*report.CoverageReport, string) error) runner.Output {
+	return runner.Output{Format: format, Path: path, Save: func(rep *report.CoverageReport) error {
+		return save(rep, path)
+	}}
+}
+
+// checkGate evaluates the report against the gate file at path and prints
+// the outcome, returning an error if the gate fails.
+func checkGate(rep *report.CoverageReport, path string) error {
+	gate, err := report.LoadGate(path)
+	if err != nil {
+		return err
+	}
+	result, err := report.EvaluateGate(rep, gate)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println()
+	report.PrintGateResult(os.Stdout, result)
+	if !result.Passed {
+		return withStatus(ExitCoverage, fmt.Errorf("coverage gate %s failed", path))
+	}
+	return nil
+}
@@ -0,0 +1,403 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"zylisp/go-ast-coverage/events"
+	"zylisp/go-ast-coverage/generator"
+	"zylisp/go-ast-coverage/internal/runner"
+)
+
+// Sample execution modes, selected with -exec-mode.
+const (
+	execModeRun   = "run"   // go run each sample
+	execModeBuild = "build" // go build each sample, then run the binary
+)
+
+// retryBackoff is how long -retries waits before the second attempt at a
+// sample; each later attempt waits that much longer again.
+const retryBackoff = 250 * time.Millisecond
+
+// runTestFiles executes the sample files with go run, -jobs at a time,
+// killing any that runs longer than -timeout. Results are printed in file
+// order as soon as a file and all those before it have finished, so each
+// file's output stays together. With -cache, a sample whose last run
+// succeeded and whose contents have not changed since is not executed.
+// With -retries, a failed run is attempted again, and a sample that then
+// succeeds is counted as flaky. With -fail-fast, nothing more is started
+// after the first failure, and samples still running are killed. With
+// -exec-mode build, samples are built and their binaries run instead of
+// using go run. With -post-run, a command is run after each sample, and
+// a sample whose command fails is reported as HOOK_FAILED; with -log-dir,
+// each sample's output and its command's are also written to a file. An
+// interrupt kills the samples running, and stops the
+// phase after reporting those that finished.
+func (o *options) runTestFiles(files []string) error {
+	execute, cleanup, err := o.sampleExecutor()
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	p := o.newProgress("running", len(files))
+	r := &runner.Runner{
+		Execute:  execute,
+		Jobs:     o.runJobs,
+		Timeout:  o.runTimeout,
+		Retries:  o.retries,
+		Backoff:  retryBackoff,
+		FailFast: o.failFast,
+		Begin:    p.begin,
+		Now:      o.clock,
+	}
+	if o.cacheDir != "" && !o.noCache {
+		r.Cache = runCache{o: o}
+	}
+	if o.runGoldenDir != "" {
+		r.Check = o.checkGoldenOutput
+	}
+	if o.postRun != "" {
+		if r.Hook, err = o.postRunHook(); err != nil {
+			return err
+		}
+	}
+	summary := r.Run(o.context(), files, o.printRunResult)
+	p.end()
+
+	fmt.Printf("\nExecution Summary: %d executed, %d cached, %d failed, %d timed out", summary.Executed, summary.Cached, summary.Failed, summary.TimedOut)
+	if o.runGoldenDir != "" {
+		fmt.Printf(", %d output mismatches", summary.Mismatched)
+	}
+	if o.retries > 0 {
+		fmt.Printf(", %d flaky", summary.Flaky)
+	}
+	if o.postRun != "" {
+		fmt.Printf(", %d hook failures", summary.HookFailed)
+	}
+	fmt.Println()
+	o.processed = summary.Processed
+	if summary.Interrupted {
+		printInterrupted(summary.Skipped)
+		return errInterrupted(summary.Skipped)
+	}
+	printSkipped(summary.Skipped)
+
+	if summary.Failed > 0 || summary.TimedOut > 0 {
+		return withStatus(ExitSamples, fmt.Errorf("%d file(s) failed to execute, %d timed out", summary.Failed, summary.TimedOut))
+	}
+	if summary.HookFailed > 0 {
+		return withStatus(ExitSamples, fmt.Errorf("%d file(s) failed their -post-run command", summary.HookFailed))
+	}
+	if summary.Mismatched > 0 {
+		return withStatus(ExitMismatch, fmt.Errorf("%d file(s) did not match their golden output; rerun with -update-golden if the change is intended", summary.Mismatched))
+	}
+
+	return nil
+}
+
+// printRunResult prints one sample's result, and that of its -post-run
+// command, emits it as an event, and writes it to -log-dir.
+func (o *options) printRunResult(result *runner.FileResult) {
+	o.printSampleResult(result)
+	if o.postRun != "" {
+		if result.HookErr != nil {
+			o.warnf("  ✗ HOOK_FAILED: %v", result.HookErr)
+			o.sampleOutput("Hook output", result.HookOutput)
+		} else if o.verbose {
+			o.debugf("Hook output:\n%s", result.HookOutput)
+		}
+	}
+	if err := runFileError(result, o.runTimeout); err != nil {
+		o.fileError("run", result.Path, err)
+	}
+	if o.logDir != "" {
+		if err := o.writeFileLog(result); err != nil {
+			o.warnf("Warning: %v", err)
+		}
+	}
+}
+
+// runFileError returns why result fails the run phase, or nil.
+func runFileError(result *runner.FileResult, timeout time.Duration) error {
+	switch result.Status {
+	case runner.Failed:
+		return result.Err
+	case runner.TimedOut:
+		return fmt.Errorf("timed out after %s", timeout)
+	case runner.HookFailed:
+		return fmt.Errorf("-post-run command failed: %w", result.HookErr)
+	case runner.Mismatched:
+		if result.CheckErr != nil {
+			return result.CheckErr
+		}
+		return fmt.Errorf("output does not match its golden file")
+	}
+	return nil
+}
+
+// printSampleResult prints how one sample's execution ended and emits it
+// as an event.
+func (o *options) printSampleResult(result *runner.FileResult) {
+	name := filepath.Base(result.Path)
+	o.infof("Running %s...", name)
+	hookFailed := result.Status == runner.HookFailed
+	// Only cached files were not executed
+	if result.Attempts == 0 {
+		o.emit(&events.FileRunResult{
+			Name:        name,
+			OK:          !hookFailed,
+			Cached:      true,
+			OutputBytes: result.CachedBytes,
+			HookFailed:  hookFailed,
+		})
+		o.infof("  cached ✓")
+		return
+	}
+	o.timeFile(result.Path, result.Duration)
+	o.emit(&events.FileRunResult{
+		Name:        name,
+		OK:          result.Err == nil && !hookFailed,
+		TimedOut:    result.Status == runner.TimedOut,
+		DurationMS:  float64(result.Duration) / float64(time.Millisecond),
+		OutputBytes: len(result.Output),
+		Attempts:    result.Attempts,
+		HookFailed:  hookFailed,
+	})
+
+	attempts := ""
+	if result.Attempts > 1 {
+		attempts = fmt.Sprintf(" (after %d attempts)", result.Attempts)
+	}
+	switch result.Status {
+	case runner.TimedOut:
+		o.warnf("  ⏱ TIMEOUT after %s%s", o.runTimeout, attempts)
+		o.sampleOutput("Partial output", result.Output)
+		return
+	case runner.Failed:
+		o.warnf("  ✗ FAILED: %v%s", result.Err, attempts)
+		o.sampleOutput("Output", result.Output)
+		return
+	}
+
+	if result.Flaky() {
+		o.warnf("  flaky ✓ (attempt %d/%d)", result.Attempts, o.retries+1)
+	}
+	if o.verbose {
+		o.debugf("Output:\n%s", result.Output)
+	} else if result.Attempts <= 1 {
+		o.infof("  ✓ Success")
+	}
+	if result.CheckErr != nil {
+		o.warnf("  ✗ GOLDEN: %v", result.CheckErr)
+	} else if result.Diff != "" {
+		o.warnf("  ✗ OUTPUT MISMATCH\n%s", strings.TrimSuffix(result.Diff, "\n"))
+	}
+	if result.CacheErr != nil {
+		o.warnf("Warning: %v", result.CacheErr)
+	}
+}
+
+// sampleOutput logs the output of a sample that failed, with -fail-fast
+// always and otherwise only with -verbose.
+func (o *options) sampleOutput(label string, output []byte) {
+	if o.failFast {
+		o.warnf("%s:\n%s", label, output)
+	} else {
+		o.debugf("%s:\n%s", label, output)
+	}
+}
+
+// printSkipped notes how many files -fail-fast left unprocessed.
+func printSkipped(skipped int) {
+	if skipped > 0 {
+		fmt.Printf("Stopped at the first failure (-fail-fast): %d file(s) skipped\n", skipped)
+	}
+}
+
+// printInterrupted notes how many files an interrupt left unprocessed.
+func printInterrupted(skipped int) {
+	fmt.Printf("Interrupted: %d file(s) not processed\n", skipped)
+}
+
+// checkGoldenOutput compares a sample's output with <name>.golden in
+// -golden-dir, returning a unified diff when they differ. With
+// -update-golden it rewrites the golden file instead. A missing golden file
+// is an error unless it is being written.
+func (o *options) checkGoldenOutput(filePath string, output []byte) (string, error) {
+	goldenPath := goldenOutputPath(o.runGoldenDir, filePath)
+	actual := normalizeOutput(string(output))
+
+	if o.updateGolden {
+		if err := os.MkdirAll(o.runGoldenDir, 0755); err != nil {
+			return "", fmt.Errorf("failed to create golden directory: %w", err)
+		}
+		if err := os.WriteFile(goldenPath, []byte(actual), 0644); err != nil {
+			return "", fmt.Errorf("failed to write golden file: %w", err)
+		}
+		return "", nil
+	}
+
+	golden, err := os.ReadFile(goldenPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read golden file: %w", err)
+	}
+
+	diff, _ := generator.DiffText(goldenPath, filepath.Base(filePath)+" output", normalizeOutput(string(golden)), actual)
+	return diff, nil
+}
+
+// goldenOutputPath returns the golden output file for a sample in dir.
+func goldenOutputPath(dir, filePath string) string {
+	return filepath.Join(dir, strings.TrimSuffix(filepath.Base(filePath), ".go")+".golden")
+}
+
+// normalizeOutput converts line endings to LF and trims trailing
+// whitespace from every line and trailing blank lines from the end, so
+// golden comparisons ignore differences editors and platforms introduce.
+func normalizeOutput(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = strings.ReplaceAll(s, "\r", "\n")
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	s = strings.TrimRight(strings.Join(lines, "\n"), "\n")
+	if s == "" {
+		return ""
+	}
+	return s + "\n"
+}
+
+// sampleExecutor returns the executor for -exec-mode, running samples in
+// a sandbox unless -inherit-env is set, and a function that removes
+// anything it leaves behind.
+func (o *options) sampleExecutor() (runner.Executor, func(), error) {
+	tool := o.goToolRunner()
+	var sb *sandbox
+	if !o.inheritEnv {
+		var err error
+		if sb, err = newSandbox(o.extraEnv); err != nil {
+			return nil, nil, err
+		}
+		if o.goTool == nil {
+			tool = goCommandEnv
+		}
+	}
+	if o.execMode != execModeBuild {
+		return goRunExecutor(tool, sb), func() {
+			if sb != nil {
+				sb.remove()
+			}
+		}, nil
+	}
+
+	binDir, err := os.MkdirTemp("", "go-ast-coverage-bin-")
+	if err != nil {
+		if sb != nil {
+			sb.remove()
+		}
+		return nil, nil, fmt.Errorf("failed to create binary directory: %w", err)
+	}
+	return buildExecutor(tool, binDir, sb), func() {
+		os.RemoveAll(binDir)
+		if sb != nil {
+			sb.remove()
+		}
+	}, nil
+}
+
+// goRunExecutor executes samples with go run, in sb unless it is nil. With
+// a sandbox, run must take env as the whole environment.
+func goRunExecutor(run goRunner, sb *sandbox) runner.Executor {
+	return func(ctx context.Context, filePath string) ([]byte, error) {
+		dir, env, path, err := sb.prepare(filePath)
+		if err != nil {
+			return nil, err
+		}
+		return run(ctx, dir, env, "run", path)
+	}
+}
+
+// buildExecutor executes samples by building each with go build into a
+// directory of its own under binDir, which is removed afterwards, and then
+// running the binary. Builds share the go build cache, so rebuilding an
+// unchanged sample is cheap. The output is what go run gives: the build's
+// diagnostics when it fails, and otherwise the program's output, followed
+// by go run's "exit status" line when the program fails. Both the build
+// and the binary run in sb unless it is nil, as with goRunExecutor.
+func buildExecutor(run goRunner, binDir string, sb *sandbox) runner.Executor {
+	return func(ctx context.Context, filePath string) ([]byte, error) {
+		workDir, env, path, err := sb.prepare(filePath)
+		if err != nil {
+			return nil, err
+		}
+
+		name := strings.TrimSuffix(filepath.Base(filePath), ".go")
+		dir, err := os.MkdirTemp(binDir, name+"-")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create build directory: %w", err)
+		}
+		defer os.RemoveAll(dir)
+
+		bin := filepath.Join(dir, name)
+		if runtime.GOOS == "windows" {
+			bin += ".exe"
+		}
+		if output, err := run(ctx, workDir, env, "build", "-o", bin, path); err != nil {
+			return output, err
+		}
+
+		output, err := runCommand(ctx, workDir, env, bin)
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && ctx.Err() == nil {
+			output = append(output, exitErr.Error()+"\n"...)
+		}
+		return output, err
+	}
+}
+
+// goToolRunner returns the runner the go tool is invoked with.
+func (o *options) goToolRunner() goRunner {
+	if o.goTool != nil {
+		return o.goTool
+	}
+	return goCommand
+}
+
+// goCommand runs the go tool in dir (the current directory when empty),
+// with env added to the environment, and returns its combined output. When
+// ctx is done, the tool is killed along with everything it started.
+func goCommand(ctx context.Context, dir string, env []string, args ...string) ([]byte, error) {
+	if len(env) > 0 {
+		env = append(os.Environ(), env...)
+	}
+	return runCommand(ctx, dir, env, "go", args...)
+}
+
+// goCommandEnv runs the go tool like goCommand, but with env as its whole
+// environment rather than added to this process's.
+func goCommandEnv(ctx context.Context, dir string, env []string, args ...string) ([]byte, error) {
+	return runCommand(ctx, dir, env, "go", args...)
+}
+
+// runCommand runs the program name in dir (the current directory when
+// empty) with env as its environment (this process's when nil), and
+// returns its combined output. When ctx is done, the program is killed
+// along with everything it started.
+func runCommand(ctx context.Context, dir string, env []string, name string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = dir
+	cmd.Env = env
+	setProcessGroup(cmd)
+	cmd.Cancel = func() error { return killProcessGroup(cmd) }
+	cmd.WaitDelay = time.Second
+	return cmd.CombinedOutput()
+}
@@ -0,0 +1,247 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"zylisp/go-ast-coverage/events"
+	"zylisp/go-ast-coverage/internal/runner"
+)
+
+// captureRun runs the samples with o, returning what runTestFiles printed
+// and its error.
+func captureRun(t *testing.T, o *options, files []string) (string, error) {
+	t.Helper()
+	return captureOutput(t, func() error { return o.runTestFiles(files) })
+}
+
+// TestRunSampleTimeout tests that a sample that blocks is killed and
+// reported as timed out
+func TestRunSampleTimeout(t *testing.T) {
+	o := testOptions()
+	if testing.Short() {
+		t.Skip("Skipping test - runs go run")
+	}
+
+	sample := filepath.Join(t.TempDir(), "blocks.go")
+	src := "package main\n\nimport (\n\t\"fmt\"\n\t\"time\"\n)\n\nfunc main() {\n\tfmt.Println(\"started\")\n\ttime.Sleep(time.Hour)\n}\n"
+	if err := os.WriteFile(sample, []byte(src), 0644); err != nil {
+		t.Fatalf("failed to write sample: %v", err)
+	}
+
+	start := time.Now()
+	r := &runner.Runner{Execute: goRunExecutor(goCommand, nil), Timeout: 2 * time.Second}
+	r.Run(context.Background(), []string{sample}, func(result *runner.FileResult) {
+		if result.Status != runner.TimedOut || result.Err == nil {
+			t.Errorf("expected a timeout, got %s, %v", result.Status, result.Err)
+		}
+	})
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("run took %s, the timeout did not stop it", elapsed)
+	}
+
+	o.runTimeout = 2 * time.Second
+	if err := o.runTestFiles([]string{sample}); err == nil || !strings.Contains(err.Error(), "1 timed out") {
+		t.Errorf("expected runTestFiles to count the timeout, got %v", err)
+	}
+}
+
+// TestRunTestFilesOrdering tests that parallel runs print each file's
+// output contiguously and in filename order
+func TestRunTestFilesOrdering(t *testing.T) {
+	o := testOptions()
+	if testing.Short() {
+		t.Skip("Skipping test - runs go run")
+	}
+
+	// Later samples sleep less, so they finish first
+	dir := t.TempDir()
+	var files []string
+	for i := 0; i < 4; i++ {
+		sample := filepath.Join(dir, fmt.Sprintf("sample%d.go", i))
+		src := fmt.Sprintf("package main\n\nimport (\n\t\"fmt\"\n\t\"time\"\n)\n\nfunc main() {\n\ttime.Sleep(%d * time.Millisecond)\n\tfmt.Println(\"line one of %d\")\n\tfmt.Println(\"line two of %d\")\n}\n", (3-i)*300, i, i)
+		if err := os.WriteFile(sample, []byte(src), 0644); err != nil {
+			t.Fatalf("failed to write sample: %v", err)
+		}
+		files = append(files, sample)
+	}
+
+	o.runJobs, o.verbose = 4, true
+
+	out, runErr := captureRun(t, o, files)
+
+	if runErr != nil {
+		t.Fatalf("runTestFiles failed: %v\n%s", runErr, out)
+	}
+
+	var want strings.Builder
+	for i := range files {
+		fmt.Fprintf(&want, "Running sample%d.go...\nOutput:\nline one of %d\nline two of %d\n\n", i, i, i)
+	}
+	if !strings.HasPrefix(out, want.String()) {
+		t.Errorf("output not in file order:\n%s", out)
+	}
+	if !strings.Contains(out, "4 executed, 0 cached, 0 failed, 0 timed out") {
+		t.Errorf("unexpected summary:\n%s", out)
+	}
+}
+
+// TestRunTestFilesFailFast tests that -fail-fast stops at the first failing
+// sample, prints its output, and counts the files skipped
+func TestRunTestFilesFailFast(t *testing.T) {
+	o := testOptions()
+	if testing.Short() {
+		t.Skip("Skipping test - runs go run")
+	}
+
+	dir := t.TempDir()
+	var files []string
+	for i, body := range []string{"fmt.Println(\"ok\")", "fmt.Println(\"boom\")\n\tos.Exit(1)", "fmt.Println(\"ok\")", "fmt.Println(\"ok\")"} {
+		sample := filepath.Join(dir, fmt.Sprintf("sample%d.go", i))
+		src := fmt.Sprintf("package main\n\nimport (\n\t\"fmt\"\n\t\"os\"\n)\n\nvar _ = os.Exit\n\nfunc main() {\n\t%s\n}\n", body)
+		if err := os.WriteFile(sample, []byte(src), 0644); err != nil {
+			t.Fatalf("failed to write sample: %v", err)
+		}
+		files = append(files, sample)
+	}
+
+	o.runJobs, o.failFast = 1, true
+
+	out, runErr := captureRun(t, o, files)
+
+	if runErr == nil || !strings.Contains(runErr.Error(), "1 file(s) failed") {
+		t.Errorf("expected the failure to be returned, got %v", runErr)
+	}
+	if !strings.Contains(out, "Output:\nboom\n") {
+		t.Errorf("expected the failing output without -verbose:\n%s", out)
+	}
+	if strings.Contains(out, "sample2.go") || strings.Contains(out, "sample3.go") {
+		t.Errorf("expected the files after the failure to be skipped:\n%s", out)
+	}
+	if !strings.Contains(out, "1 executed, 0 cached, 1 failed, 0 timed out") || !strings.Contains(out, "2 file(s) skipped") {
+		t.Errorf("unexpected summary:\n%s", out)
+	}
+}
+
+// flakyRunner returns a go tool runner whose go run fails the first
+// failures times for each file, and the number of times each file was run.
+func flakyRunner(failures int) (goRunner, map[string]int) {
+	var mu sync.Mutex
+	calls := make(map[string]int)
+	return func(ctx context.Context, dir string, env []string, args ...string) ([]byte, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls[args[1]]++
+		if calls[args[1]] <= failures {
+			return []byte("deadline exceeded\n"), errors.New("exit status 1")
+		}
+		return []byte("ok\n"), nil
+	}, calls
+}
+
+// TestRunTestFilesRetries tests that a sample passing on a retry is
+// reported and counted as flaky, with its attempts in the event stream
+func TestRunTestFilesRetries(t *testing.T) {
+	run, _ := flakyRunner(1)
+	var stream bytes.Buffer
+	o := testOptions()
+	o.goTool = run
+	o.retries = 2
+	o.events = events.NewWriter(&stream)
+
+	out, err := captureRun(t, o, []string{"flaky.go"})
+	if err != nil {
+		t.Fatalf("runTestFiles failed: %v\n%s", err, out)
+	}
+	if !strings.Contains(out, "  flaky ✓ (attempt 2/3)\n") || !strings.Contains(out, "1 executed, 0 cached, 0 failed, 0 timed out, 1 flaky") {
+		t.Errorf("expected a flaky pass:\n%s", out)
+	}
+
+	evs, err := events.Read(&stream)
+	if err != nil {
+		t.Fatalf("failed to read events: %v", err)
+	}
+	if result, ok := evs[0].(*events.FileRunResult); !ok || !result.OK || result.Attempts != 2 {
+		t.Errorf("expected a result with 2 attempts, got %+v", evs[0])
+	}
+}
+
+// TestCheckGoldenOutput tests writing, normalizing, and diffing golden
+// sample output
+func TestCheckGoldenOutput(t *testing.T) {
+	o := testOptions()
+	o.runGoldenDir = filepath.Join(t.TempDir(), "golden")
+
+	if _, err := o.checkGoldenOutput("iota.go", []byte("A = 0\n")); err == nil {
+		t.Errorf("expected an error for a missing golden file")
+	}
+
+	o.updateGolden = true
+	if diff, err := o.checkGoldenOutput("iota.go", []byte("A = 0\r\nB = 1  \n\n")); err != nil || diff != "" {
+		t.Fatalf("update failed: %q, %v", diff, err)
+	}
+	golden, err := os.ReadFile(filepath.Join(o.runGoldenDir, "iota.golden"))
+	if err != nil || string(golden) != "A = 0\nB = 1\n" {
+		t.Errorf("golden file not normalized: %q, %v", golden, err)
+	}
+
+	o.updateGolden = false
+	if diff, err := o.checkGoldenOutput("iota.go", []byte("A = 0\nB = 1\t\n")); err != nil || diff != "" {
+		t.Errorf("expected normalized output to match, got %q, %v", diff, err)
+	}
+	diff, err := o.checkGoldenOutput("iota.go", []byte("A = 0\nB = 2\n"))
+	if err != nil {
+		t.Fatalf("checkGoldenOutput failed: %v", err)
+	}
+	if !strings.Contains(diff, "-B = 1\n+B = 2\n") {
+		t.Errorf("expected a unified diff, got:\n%s", diff)
+	}
+}
+
+// TestExecModesMatch tests that -exec-mode build gives the same output and
+// outcome as go run, for a passing sample, one that fails when run, and
+// one that does not compile, and leaves no binaries behind
+func TestExecModesMatch(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping test - runs go run and go build")
+	}
+
+	dir := t.TempDir()
+	samples := map[string]string{
+		"passes.go":    fixtureSource,
+		"exits.go":     "package main\n\nimport (\n\t\"fmt\"\n\t\"os\"\n)\n\nfunc main() {\n\tfmt.Println(\"failing\")\n\tos.Exit(3)\n}\n",
+		"undefined.go": "package main\n\nfunc main() {\n\tundefined()\n}\n",
+	}
+	binDir := t.TempDir()
+	goRun, build := goRunExecutor(goCommand, nil), buildExecutor(goCommand, binDir, nil)
+	for name, source := range samples {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(source), 0644); err != nil {
+			t.Fatalf("failed to write sample: %v", err)
+		}
+
+		wantOutput, wantErr := goRun(context.Background(), path)
+		output, err := build(context.Background(), path)
+		if string(output) != string(wantOutput) || (err == nil) != (wantErr == nil) {
+			t.Errorf("%s: build mode gave %v:\n%s\ngo run gave %v:\n%s", name, err, output, wantErr, wantOutput)
+		}
+	}
+
+	if entries, err := os.ReadDir(binDir); err != nil || len(entries) != 0 {
+		t.Errorf("expected the binaries to be removed, found %d entries (%v)", len(entries), err)
+	}
+
+	o := testOptions()
+	o.execMode = execModeBuild
+	if _, err := captureRun(t, o, []string{filepath.Join(dir, "passes.go")}); err != nil {
+		t.Errorf("runTestFiles in build mode failed: %v", err)
+	}
+}
@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// runCacheEntry records a sample's last successful run. Failed runs are
+// never cached, so ExitStatus is always 0; it is kept so the entry says
+// what it records.
+type runCacheEntry struct {
+	ExitStatus   int    `json:"exit_status"`
+	OutputSHA256 string `json:"output_sha256"`
+	OutputBytes  int    `json:"output_bytes"`
+}
+
+// runCachePath returns the file in <cacheDir>/run that caches the run of a
+// sample with filePath's current contents.
+func runCachePath(cacheDir, filePath string) (string, error) {
+	source, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read sample: %w", err)
+	}
+	sum := sha256.Sum256(source)
+	return filepath.Join(cacheDir, "run", hex.EncodeToString(sum[:])+".json"), nil
+}
+
+// loadRunCache returns the cached run of filePath, or nil when there is
+// none or it cannot be read.
+func loadRunCache(cacheDir, filePath string) *runCacheEntry {
+	path, err := runCachePath(cacheDir, filePath)
+	if err != nil {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var entry runCacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.ExitStatus != 0 {
+		return nil
+	}
+	return &entry
+}
+
+// saveRunCache records a successful run of filePath and its output.
+func saveRunCache(cacheDir, filePath string, output []byte) error {
+	path, err := runCachePath(cacheDir, filePath)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(&runCacheEntry{
+		OutputSHA256: outputDigest(output),
+		OutputBytes:  len(output),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode run cache entry: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create run cache directory: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write run cache entry: %w", err)
+	}
+	return nil
+}
+
+// outputDigest returns the hex SHA-256 of a sample's normalized output, so
+// it can be compared with a golden file's.
+func outputDigest(output []byte) string {
+	sum := sha256.Sum256([]byte(normalizeOutput(string(output))))
+	return hex.EncodeToString(sum[:])
+}
+
+// cachedRun returns the cached run of filePath when -cache is set, -no-cache
+// is not, and the cached output still matches its golden file when
+// -golden-dir is set; otherwise the sample has to be executed.
+func (o *options) cachedRun(filePath string) *runCacheEntry {
+	if o.cacheDir == "" || o.noCache || o.updateGolden {
+		return nil
+	}
+	entry := loadRunCache(o.cacheDir, filePath)
+	if entry == nil || o.runGoldenDir == "" {
+		return entry
+	}
+
+	golden, err := os.ReadFile(goldenOutputPath(o.runGoldenDir, filePath))
+	if err != nil || outputDigest(golden) != entry.OutputSHA256 {
+		return nil
+	}
+	return entry
+}
+
+// runCache is the run cache in -cache, as the runner uses it.
+type runCache struct {
+	o *options
+}
+
+func (c runCache) Lookup(filePath string) (int, bool) {
+	entry := c.o.cachedRun(filePath)
+	if entry == nil {
+		return 0, false
+	}
+	return entry.OutputBytes, true
+}
+
+func (c runCache) Store(filePath string, output []byte) error {
+	return saveRunCache(c.o.cacheDir, filePath, output)
+}
@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestRunCache tests cache hits, misses after an edit, and that failed
+// runs are never cached
+func TestRunCache(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping test - runs go run")
+	}
+
+	dir := writeFixture(t)
+	sample := filepath.Join(dir, "fixture.go")
+	failing := filepath.Join(dir, "failing.go")
+	if err := os.WriteFile(failing, []byte("package main\n\nimport \"os\"\n\nfunc main() {\n\tos.Exit(1)\n}\n"), 0644); err != nil {
+		t.Fatalf("failed to write sample: %v", err)
+	}
+
+	o := testOptions()
+	o.cacheDir = filepath.Join(t.TempDir(), ".astcache")
+	files := []string{sample, failing}
+
+	out, err := captureRun(t, o, files)
+	if err == nil || !strings.Contains(out, "1 executed, 0 cached, 1 failed") {
+		t.Fatalf("unexpected first run (%v):\n%s", err, out)
+	}
+	entries, _ := filepath.Glob(filepath.Join(o.cacheDir, "run", "*.json"))
+	if len(entries) != 1 {
+		t.Errorf("expected only the successful run cached, got %v", entries)
+	}
+
+	// The unchanged sample is skipped; the failure runs again
+	out, _ = captureRun(t, o, files)
+	if !strings.Contains(out, "Running fixture.go...\n  cached ✓\n") || !strings.Contains(out, "0 executed, 1 cached, 1 failed") {
+		t.Errorf("expected a cache hit:\n%s", out)
+	}
+
+	o.noCache = true
+	if out, _ = captureRun(t, o, files); !strings.Contains(out, "1 executed, 0 cached, 1 failed") {
+		t.Errorf("expected -no-cache to execute every sample:\n%s", out)
+	}
+	o.noCache = false
+
+	edited := strings.Replace(fixtureSource, `"fixture"`, `"edited"`, 1)
+	if err := os.WriteFile(sample, []byte(edited), 0644); err != nil {
+		t.Fatalf("failed to edit sample: %v", err)
+	}
+	if out, _ = captureRun(t, o, files); !strings.Contains(out, "1 executed, 0 cached, 1 failed") {
+		t.Errorf("expected a miss after the edit:\n%s", out)
+	}
+	if out, _ = captureRun(t, o, files[:1]); !strings.Contains(out, "0 executed, 1 cached, 0 failed") {
+		t.Errorf("expected the edited sample to be cached:\n%s", out)
+	}
+}
@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"fmt"
+	"go/build"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// sandboxEnvPassed lists the variables samples inherit from this process
+// in the sandbox. SYSTEMROOT is needed by almost any Windows program.
+var sandboxEnvPassed = []string{"PATH", "SYSTEMROOT"}
+
+// envList is a repeatable KEY=VALUE flag.
+type envList []string
+
+func (e *envList) String() string {
+	return strings.Join(*e, ",")
+}
+
+func (e *envList) Set(entry string) error {
+	if key, _, ok := strings.Cut(entry, "="); !ok || key == "" {
+		return fmt.Errorf("want KEY=VALUE, got %q", entry)
+	}
+	*e = append(*e, entry)
+	return nil
+}
+
+// sandbox is the controlled environment samples run in unless -inherit-env
+// is set. Each sample runs in a scratch working directory of its own, with
+// only PATH from this process's environment, the go tool's GOCACHE and
+// GOPATH so builds still share their caches, HOME and TMPDIR in the
+// sandbox's scratch directory, and the -env entries.
+type sandbox struct {
+	root string // removed by remove
+	work string // holds the per-file working directories
+	env  []string
+}
+
+// newSandbox creates the sandbox's scratch directories under a new
+// temporary directory, and its environment, ending with extra.
+func newSandbox(extra []string) (*sandbox, error) {
+	root, err := os.MkdirTemp("", "go-ast-coverage-sandbox-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sandbox: %w", err)
+	}
+	s := &sandbox{root: root, work: filepath.Join(root, "work")}
+	home, tmp := filepath.Join(root, "home"), filepath.Join(root, "tmp")
+	for _, dir := range []string{s.work, home, tmp} {
+		if err := os.Mkdir(dir, 0755); err != nil {
+			s.remove()
+			return nil, fmt.Errorf("failed to create sandbox: %w", err)
+		}
+	}
+
+	for _, key := range sandboxEnvPassed {
+		if value, ok := os.LookupEnv(key); ok {
+			s.env = append(s.env, key+"="+value)
+		}
+	}
+	goCache, err := goCacheDir()
+	if err != nil {
+		s.remove()
+		return nil, err
+	}
+	s.env = append(s.env, "GOCACHE="+goCache, "GOPATH="+build.Default.GOPATH, "HOME="+home, "TMPDIR="+tmp)
+	if runtime.GOOS == "windows" {
+		s.env = append(s.env, "USERPROFILE="+home, "TMP="+tmp, "TEMP="+tmp)
+	}
+	s.env = append(s.env, extra...)
+	return s, nil
+}
+
+// goCacheDir returns the build cache the go tool uses from this process's
+// environment, which it would no longer find from the sandbox's HOME.
+func goCacheDir() (string, error) {
+	if dir := os.Getenv("GOCACHE"); dir != "" {
+		return dir, nil
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find the go build cache: %w", err)
+	}
+	return filepath.Join(dir, "go-build"), nil
+}
+
+// prepare returns the working directory, environment, and path to run the
+// sample at filePath with: a new scratch directory, the sandbox's
+// environment, and the absolute path. A nil sandbox runs samples where
+// and as this process runs, returning "", nil, and filePath.
+func (s *sandbox) prepare(filePath string) (dir string, env []string, path string, err error) {
+	if s == nil {
+		return "", nil, filePath, nil
+	}
+	if path, err = filepath.Abs(filePath); err != nil {
+		return "", nil, "", fmt.Errorf("failed to resolve %s: %w", filePath, err)
+	}
+	dir, err = os.MkdirTemp(s.work, strings.TrimSuffix(filepath.Base(filePath), ".go")+"-")
+	if err != nil {
+		return "", nil, "", fmt.Errorf("failed to create working directory: %w", err)
+	}
+	return dir, s.env, path, nil
+}
+
+// remove deletes the sandbox's scratch directories.
+func (s *sandbox) remove() {
+	os.RemoveAll(s.root)
+}
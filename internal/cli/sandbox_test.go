@@ -0,0 +1,109 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// envSampleSource prints the environment and working directory it runs in.
+const envSampleSource = `package main
+
+import (
+	"fmt"
+	"os"
+)
+
+func main() {
+	wd, _ := os.Getwd()
+	fmt.Println("HOME=" + os.Getenv("HOME"))
+	fmt.Println("TMPDIR=" + os.Getenv("TMPDIR"))
+	fmt.Println("PARENT=" + os.Getenv("ASTCOV_PARENT"))
+	fmt.Println("EXTRA=" + os.Getenv("ASTCOV_EXTRA"))
+	fmt.Println("WD=" + wd)
+}
+`
+
+// TestEnvList tests that -env takes repeated KEY=VALUE entries and
+// rejects anything else
+func TestEnvList(t *testing.T) {
+	var env envList
+	for _, entry := range []string{"A=1", "B=", "C=x=y"} {
+		if err := env.Set(entry); err != nil {
+			t.Errorf("Set(%q) failed: %v", entry, err)
+		}
+	}
+	if got := env.String(); got != "A=1,B=,C=x=y" {
+		t.Errorf("expected all entries, got %q", got)
+	}
+	for _, entry := range []string{"A", "=1", ""} {
+		if err := env.Set(entry); err == nil {
+			t.Errorf("expected Set(%q) to fail", entry)
+		}
+	}
+}
+
+// TestSandbox tests that samples run in a scratch working directory with
+// only the sandbox's environment and the -env entries, in both execution
+// modes, that -inherit-env restores this process's environment and working
+// directory, and that the sandbox is removed afterwards
+func TestSandbox(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping test - runs go run and go build")
+	}
+
+	t.Setenv("ASTCOV_PARENT", "leaked")
+	sample := filepath.Join(t.TempDir(), "env.go")
+	if err := os.WriteFile(sample, []byte(envSampleSource), 0644); err != nil {
+		t.Fatalf("failed to write sample: %v", err)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get working directory: %v", err)
+	}
+
+	sampleEnv := func(o *options) map[string]string {
+		t.Helper()
+		execute, cleanup, err := o.sampleExecutor()
+		if err != nil {
+			t.Fatalf("sampleExecutor failed: %v", err)
+		}
+		defer cleanup()
+		output, err := execute(context.Background(), sample)
+		if err != nil {
+			t.Fatalf("sample failed: %v\n%s", err, output)
+		}
+		env := map[string]string{}
+		for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+			key, value, _ := strings.Cut(line, "=")
+			env[key] = value
+		}
+		return env
+	}
+
+	for _, mode := range []string{execModeRun, execModeBuild} {
+		o := testOptions()
+		o.execMode = mode
+		o.extraEnv = envList{"ASTCOV_EXTRA=added"}
+		env := sampleEnv(o)
+		root := strings.TrimSuffix(env["HOME"], string(filepath.Separator)+"home")
+		if root == env["HOME"] || env["TMPDIR"] != filepath.Join(root, "tmp") || !strings.HasPrefix(env["WD"], filepath.Join(root, "work")+string(filepath.Separator)) {
+			t.Errorf("%s: expected HOME, TMPDIR, and the working directory in the sandbox, got %v", mode, env)
+		}
+		if env["PARENT"] != "" || env["EXTRA"] != "added" {
+			t.Errorf("%s: expected only the -env entry from outside the sandbox, got %v", mode, env)
+		}
+		if _, err := os.Stat(root); !os.IsNotExist(err) {
+			t.Errorf("%s: expected the sandbox to be removed, got %v", mode, err)
+		}
+	}
+
+	o := testOptions()
+	o.inheritEnv = true
+	env := sampleEnv(o)
+	if env["PARENT"] != "leaked" || env["HOME"] != os.Getenv("HOME") || env["WD"] != wd {
+		t.Errorf("expected -inherit-env to run the sample as this process, got %v", env)
+	}
+}
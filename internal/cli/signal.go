@@ -0,0 +1,149 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	report "zylisp/go-ast-coverage/coverage-report"
+)
+
+// interruptSignals stop a run: the first gracefully, a second at once.
+var interruptSignals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+
+// handleInterrupts sets o.ctx to a context that the first interrupt
+// signal cancels, which kills the samples being executed and stops the
+// phase at the next file; see finishInterrupted. A second signal exits at
+// once. The returned function stops handling the signals.
+func (o *options) handleInterrupts() func() {
+	sigs := o.interrupts
+	stopNotify := func() {}
+	if sigs == nil {
+		ch := make(chan os.Signal, 2)
+		signal.Notify(ch, interruptSignals...)
+		sigs, stopNotify = ch, func() { signal.Stop(ch) }
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	o.ctx = ctx
+	o.logger() // created here, before the goroutine below can log
+	done := make(chan struct{})
+	go func() {
+		select {
+		case sig := <-sigs:
+			o.warnf("Interrupted (%v): stopping; interrupt again to exit at once", sig)
+			cancel()
+		case <-done:
+			return
+		}
+		select {
+		case <-sigs:
+			o.errorf("Interrupted again: exiting")
+			o.exitNow(ExitInterrupted)
+		case <-done:
+		}
+	}()
+
+	return func() {
+		stopNotify()
+		close(done)
+		cancel()
+	}
+}
+
+// context returns the run's context, canceled by an interrupt.
+func (o *options) context() context.Context {
+	if o.ctx != nil {
+		return o.ctx
+	}
+	return context.Background()
+}
+
+// interrupted reports whether the run has been interrupted.
+func (o *options) interrupted() bool {
+	return o.context().Err() != nil
+}
+
+// exitNow ends the process with status, skipping any cleanup.
+func (o *options) exitNow(status int) {
+	if o.exitProcess != nil {
+		o.exitProcess(status)
+		return
+	}
+	os.Exit(status)
+}
+
+// errInterrupted returns the error a phase stopped by an interrupt fails
+// with, having left skipped of its files unprocessed.
+func errInterrupted(skipped int) error {
+	return withStatus(ExitInterrupted, fmt.Errorf("interrupted: %d file(s) not processed", skipped))
+}
+
+// finishInterrupted ends a run stopped by an interrupt. When the run would
+// have reported on coverage, it prints a report on just the files the
+// interrupted phase finished with, labeled as incomplete. It is not saved,
+// nor compared with the baseline or gates, so nothing on disk is replaced
+// by a partial result.
+func (o *options) finishInterrupted(dirs []string) int {
+	if o.generateReport && len(o.processed) > 0 {
+		rep, err := o.partialReport(dirs, o.processed)
+		if err != nil {
+			o.errorf("Error generating partial report: %v", err)
+		} else if rep != nil {
+			fmt.Printf("\n=== INCOMPLETE: partial report on the %d file(s) processed before the interrupt ===\n\n", len(o.processed))
+			if err := o.printReport(rep); err != nil {
+				o.errorf("Error printing partial report: %v", err)
+			}
+			fmt.Println("\n=== INCOMPLETE: not saved; rerun to completion for the full report ===")
+		}
+	}
+
+	if len(o.phaseTimings) > 0 {
+		fmt.Println()
+		o.printTimings(os.Stdout)
+	}
+	return o.exit(ExitInterrupted)
+}
+
+// partialReport builds a report on files, which are among the sample files
+// in dirs or the -file files, bypassing the report cache. It is nil when
+// there are none.
+func (o *options) partialReport(dirs, files []string) (*report.CoverageReport, error) {
+	if o.filePaths != "" {
+		dirs = dirs[:1]
+	}
+
+	var reports []*report.CoverageReport
+	for _, dir := range dirs {
+		opts, err := o.reportOptions(dir)
+		if err != nil {
+			return nil, err
+		}
+		opts.CacheDir = ""
+		opts.Files = nil
+		for _, file := range files {
+			if o.filePaths != "" || filepath.Dir(file) == filepath.Clean(dir) {
+				opts.Files = append(opts.Files, file)
+			}
+		}
+		if len(opts.Files) == 0 {
+			continue
+		}
+		rep, err := report.GenerateReportWithOptions(dir, opts)
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, rep)
+	}
+
+	switch len(reports) {
+	case 0:
+		return nil, nil
+	case 1:
+		return reports[0], nil
+	}
+	return report.MergeReports(reports...)
+}
@@ -0,0 +1,141 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// blockingGoTool returns a go tool stub that runs pass.go at once and
+// blocks on every other sample until it is canceled, sending the sample's
+// name on started as it begins
+func blockingGoTool(started chan<- string) goRunner {
+	return func(ctx context.Context, dir string, env []string, args ...string) ([]byte, error) {
+		name := filepath.Base(args[len(args)-1])
+		if name == "pass.go" {
+			return []byte("ok\n"), nil
+		}
+		started <- name
+		<-ctx.Done()
+		return []byte("killed\n"), ctx.Err()
+	}
+}
+
+// interruptWhenStarted sends an interrupt on sigs once a blocking sample
+// has started
+func interruptWhenStarted(started <-chan string, sigs chan<- os.Signal) {
+	go func() {
+		<-started
+		sigs <- os.Interrupt
+	}()
+}
+
+// TestRunTestFilesInterrupt tests that an interrupt kills the samples in
+// flight and stops the run, reporting the samples that finished first
+func TestRunTestFilesInterrupt(t *testing.T) {
+	dir := t.TempDir()
+	files := []string{filepath.Join(dir, "pass.go")}
+	for i := 0; i < 3; i++ {
+		files = append(files, filepath.Join(dir, fmt.Sprintf("block%d.go", i)))
+	}
+
+	started := make(chan string, len(files))
+	sigs := make(chan os.Signal, 1)
+	o := testOptions()
+	o.runJobs = 2
+	o.goTool = blockingGoTool(started)
+	o.interrupts = sigs
+	defer o.handleInterrupts()()
+	interruptWhenStarted(started, sigs)
+
+	begun := time.Now()
+	out, err := captureRun(t, o, files)
+	if exitStatus(err) != ExitInterrupted || time.Since(begun) > 10*time.Second {
+		t.Fatalf("expected the run to stop promptly with status %d, got %v after %s:\n%s", ExitInterrupted, err, time.Since(begun), out)
+	}
+	for _, want := range []string{"Interrupted (interrupt)", "Execution Summary: 1 executed", "Interrupted: 3 file(s) not processed"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "FAILED") {
+		t.Errorf("expected killed samples not to be reported as failures:\n%s", out)
+	}
+	if len(o.processed) != 1 || o.processed[0] != files[0] {
+		t.Errorf("expected only pass.go to be processed, got %v", o.processed)
+	}
+}
+
+// TestSecondInterrupt tests that a second interrupt exits at once
+func TestSecondInterrupt(t *testing.T) {
+	sigs := make(chan os.Signal, 2)
+	exited := make(chan int, 1)
+	o := testOptions()
+	o.interrupts = sigs
+	o.exitProcess = func(status int) { exited <- status }
+	defer o.handleInterrupts()()
+
+	_, _ = captureOutput(t, func() error {
+		sigs <- os.Interrupt
+		sigs <- os.Interrupt
+		select {
+		case status := <-exited:
+			if status != ExitInterrupted {
+				t.Errorf("expected exit status %d, got %d", ExitInterrupted, status)
+			}
+		case <-time.After(10 * time.Second):
+			t.Errorf("expected the second interrupt to exit")
+		}
+		return nil
+	})
+	if !o.interrupted() {
+		t.Errorf("expected the first interrupt to cancel the run")
+	}
+}
+
+// TestExecuteInterrupt tests that an interrupted run prints a partial
+// report on the files it finished, labeled incomplete and not saved, and
+// exits with the interrupted status
+func TestExecuteInterrupt(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"pass.go", "wait.go"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(fixtureSource), 0644); err != nil {
+			t.Fatalf("failed to write sample: %v", err)
+		}
+	}
+
+	started := make(chan string, 2)
+	sigs := make(chan os.Signal, 1)
+	o := testOptions()
+	o.nodesDirs = dirList{dir}
+	o.runJobs = 1
+	o.reportOut = filepath.Join(t.TempDir(), "coverage-report.txt")
+	o.baselinePath = ""
+	o.goTool = blockingGoTool(started)
+	o.interrupts = sigs
+	interruptWhenStarted(started, sigs)
+
+	var status int
+	out, _ := captureOutput(t, func() error {
+		status = o.execute()
+		return nil
+	})
+	if status != ExitInterrupted {
+		t.Fatalf("expected status %d, got %d:\n%s", ExitInterrupted, status, out)
+	}
+	for _, want := range []string{"INCOMPLETE: partial report on the 1 file(s) processed", "INCOMPLETE: not saved", "Phase"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "Aggregated Statistics") {
+		t.Errorf("expected the phases after the interrupt not to run:\n%s", out)
+	}
+	if _, err := os.Stat(o.reportOut); !os.IsNotExist(err) {
+		t.Errorf("expected no report to be saved, got %v", err)
+	}
+}
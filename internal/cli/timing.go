@@ -0,0 +1,117 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"zylisp/go-ast-coverage/events"
+)
+
+// slowestFileCount is how many of the slowest sample runs the timing
+// summary lists.
+const slowestFileCount = 5
+
+// phaseTiming is how long a phase took and how many items it handled.
+type phaseTiming struct {
+	name    string
+	elapsed time.Duration
+	items   int
+}
+
+// fileTiming is how long one sample took to run or check.
+type fileTiming struct {
+	name    string
+	elapsed time.Duration
+}
+
+// clock returns the current time from the injected clock, if any.
+func (o *options) clock() time.Time {
+	if o.now != nil {
+		return o.now()
+	}
+	return time.Now()
+}
+
+// timePhase records that the named phase, begun at start, has finished
+// with items handled.
+func (o *options) timePhase(name string, start time.Time, items int) {
+	o.phaseTimings = append(o.phaseTimings, phaseTiming{name: name, elapsed: o.clock().Sub(start), items: items})
+}
+
+// timeFile records how long the sample at path took.
+func (o *options) timeFile(path string, elapsed time.Duration) {
+	o.fileTimings = append(o.fileTimings, fileTiming{name: filepath.Base(path), elapsed: elapsed})
+}
+
+// slowestFiles returns the slowest sample runs, slowest first, keeping
+// file order among equals.
+func (o *options) slowestFiles() []fileTiming {
+	files := append([]fileTiming{}, o.fileTimings...)
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].elapsed > files[j].elapsed
+	})
+	if len(files) > slowestFileCount {
+		files = files[:slowestFileCount]
+	}
+	return files
+}
+
+// printTimings writes the phase timing table and the slowest sample
+// files.
+func (o *options) printTimings(w io.Writer) {
+	total := o.clock().Sub(o.started)
+
+	nameWidth := len("Phase")
+	for _, phase := range o.phaseTimings {
+		nameWidth = max(nameWidth, len(phase.name))
+	}
+
+	fmt.Fprintln(w, "=== Timing Summary ===")
+	fmt.Fprintf(w, "%-*s  %10s  %6s\n", nameWidth, "Phase", "Elapsed", "Items")
+	for _, phase := range o.phaseTimings {
+		fmt.Fprintf(w, "%-*s  %10s  %6d\n", nameWidth, phase.name, formatElapsed(phase.elapsed), phase.items)
+	}
+	fmt.Fprintf(w, "%-*s  %10s\n", nameWidth, "total", formatElapsed(total))
+
+	slowest := o.slowestFiles()
+	if len(slowest) == 0 {
+		return
+	}
+	fileWidth := 0
+	for _, file := range slowest {
+		fileWidth = max(fileWidth, len(file.name))
+	}
+	fmt.Fprintf(w, "\nSlowest files:\n")
+	for i, file := range slowest {
+		fmt.Fprintf(w, "  %d. %-*s  %10s\n", i+1, fileWidth, file.name, formatElapsed(file.elapsed))
+	}
+}
+
+// formatElapsed renders a duration in seconds with millisecond precision.
+func formatElapsed(d time.Duration) string {
+	return fmt.Sprintf("%.3fs", d.Seconds())
+}
+
+// runEnd returns the run_end event for status, with the timings so far.
+func (o *options) runEnd(status int) *events.RunEnd {
+	end := &events.RunEnd{ExitStatus: status}
+	if !o.started.IsZero() {
+		end.DurationMS = milliseconds(o.clock().Sub(o.started))
+	}
+	for _, phase := range o.phaseTimings {
+		end.Phases = append(end.Phases, events.PhaseTiming{Name: phase.name, DurationMS: milliseconds(phase.elapsed), Items: phase.items})
+	}
+	for _, file := range o.slowestFiles() {
+		end.SlowestFiles = append(end.SlowestFiles, events.FileTiming{Name: file.name, DurationMS: milliseconds(file.elapsed)})
+	}
+	end.Errors = o.eventErrors()
+	return end
+}
+
+// milliseconds converts d to fractional milliseconds for the event stream.
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
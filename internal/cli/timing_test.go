@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+
+	"zylisp/go-ast-coverage/events"
+)
+
+// steppingClock returns a clock that advances by step on every reading.
+func steppingClock(step time.Duration) func() time.Time {
+	t := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	return func() time.Time {
+		t = t.Add(step)
+		return t
+	}
+}
+
+// TestPrintTimings tests the timing table and the slowest files
+func TestPrintTimings(t *testing.T) {
+	o := testOptions()
+	o.now = steppingClock(time.Second)
+	o.started = o.clock()
+
+	o.timePhase("run", o.clock(), 7)
+	o.timePhase("analyze", o.clock(), 7)
+	for i, ms := range []int{120, 2400, 80, 950, 2400, 15, 300} {
+		o.timeFile(fmt.Sprintf("nodes/go/sample%d.go", i), time.Duration(ms)*time.Millisecond)
+	}
+
+	var buf bytes.Buffer
+	o.printTimings(&buf)
+	want := `=== Timing Summary ===
+Phase       Elapsed   Items
+run          1.000s       7
+analyze      1.000s       7
+total        5.000s
+
+Slowest files:
+  1. sample1.go      2.400s
+  2. sample4.go      2.400s
+  3. sample3.go      0.950s
+  4. sample6.go      0.300s
+  5. sample0.go      0.120s
+`
+	if buf.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+// TestRunEndTimings tests that run_end carries the phase timings
+func TestRunEndTimings(t *testing.T) {
+	var stream bytes.Buffer
+	o := testOptions()
+	o.legacy = false
+	o.analyze = true
+	o.nodesDirs = dirList{writeFixture(t)}
+	o.now = steppingClock(time.Second)
+	o.events = events.NewWriter(&stream)
+
+	out, _ := captureStdout(t, func() error {
+		if status := o.execute(); status != 0 {
+			return fmt.Errorf("execute exited %d", status)
+		}
+		return nil
+	})
+	if !bytes.Contains([]byte(out), []byte("analyze      1.000s       1\ntotal        3.000s\n")) {
+		t.Errorf("missing timing table:\n%s", out)
+	}
+
+	evs, err := events.Read(&stream)
+	if err != nil {
+		t.Fatalf("failed to read events: %v", err)
+	}
+	end, ok := evs[len(evs)-1].(*events.RunEnd)
+	if !ok {
+		t.Fatalf("expected run_end last, got %+v", evs[len(evs)-1])
+	}
+	want := []events.PhaseTiming{{Name: "analyze", DurationMS: 1000, Items: 1}}
+	if len(end.Phases) != 1 || end.Phases[0] != want[0] || end.DurationMS != 4000 {
+		t.Errorf("unexpected run_end %+v", end)
+	}
+}
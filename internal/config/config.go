@@ -0,0 +1,255 @@
+// Package config loads astcoverage.json, a project's checked-in defaults
+// for the orchestrator's flags, and applies it beneath the command line:
+// a flag given on the command line wins over the config, and the config
+// wins over the flag's built-in default.
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FileName is the config file loaded from the current directory when no
+// other is named.
+const FileName = "astcoverage.json"
+
+// Formats are the report formats the formats key accepts, each saved by
+// the flag of the same name.
+var Formats = []string{"json", "junit", "html", "sarif", "csv"}
+
+// Config is the contents of a config file. Every key is optional; a
+// missing one leaves its flag's default alone.
+type Config struct {
+	// NodesDirs are the sample directories, as for repeated -nodes-dir.
+	NodesDirs []string `json:"nodes_dirs"`
+
+	// ExcludeFiles are glob patterns of sample file names to leave out of
+	// the corpus, as for -exclude-files.
+	ExcludeFiles []string `json:"exclude_files"`
+
+	// ASTOutDir and ArchiveOutDir are the generate output directories, as
+	// for -ast-out-dir and -archive-out-dir.
+	ASTOutDir     string `json:"ast_out_dir"`
+	ArchiveOutDir string `json:"archive_out_dir"`
+
+	// ReportOut is the path of the saved text report, as for -report-out.
+	ReportOut string `json:"report_out"`
+
+	// Jobs is the number of samples executed at once, as for -jobs.
+	Jobs *int `json:"jobs"`
+
+	// Timeout is the per-file time limit, such as "30s", as for -timeout;
+	// "0s" means none.
+	Timeout *Duration `json:"timeout"`
+
+	// Gate is the gate file to check, as for -gate.
+	Gate string `json:"gate"`
+
+	// Formats lists the report formats to save besides text, each one of
+	// Formats, as for -json, -junit, -html, -sarif, and -csv.
+	Formats []string `json:"formats"`
+}
+
+// Duration is a time.Duration written in a config file as a string such
+// as "1m30s".
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("invalid duration %s (want a string such as \"30s\")", data)
+	}
+	duration, err := time.ParseDuration(s)
+	if err != nil {
+		return fmt.Errorf("invalid duration %q (want a string such as \"30s\")", s)
+	}
+	*d = Duration(duration)
+	return nil
+}
+
+// Setting is one flag value a config sets.
+type Setting struct {
+	Flag  string
+	Value string
+}
+
+// Load reads and validates the config file at path. Keys it does not know
+// are rejected rather than ignored, so a misspelled key is not silently
+// lost. Relative paths in the file are resolved against its directory.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	var c Config
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&c); err != nil {
+		return nil, fmt.Errorf("failed to decode config %s: %w", path, decodeError(data, err))
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("failed to decode config %s: unexpected data after the top-level object", path)
+	}
+	if err := c.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+
+	c.resolve(filepath.Dir(path))
+	return &c, nil
+}
+
+// decodeError rewrites a JSON decoding error to say where in data it is,
+// and for an unknown key, which keys there are.
+func decodeError(data []byte, err error) error {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		return fmt.Errorf("%s: %v", position(data, syntaxErr.Offset-1), err)
+	case errors.As(err, &typeErr):
+		return fmt.Errorf("%s: key %q: want %s, got %s", position(data, typeErr.Offset-1), typeErr.Field, typeErr.Type, typeErr.Value)
+	}
+	if key, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		return fmt.Errorf("unknown key %s (want one of %s)", key, strings.Join(Keys(), ", "))
+	}
+	return err
+}
+
+// position renders the position of the byte at offset in data as "line
+// L, column C".
+func position(data []byte, offset int64) string {
+	before := data[:max(0, min(int(offset), len(data)))]
+	line := bytes.Count(before, []byte("\n")) + 1
+	column := len(before) - bytes.LastIndexByte(before, '\n')
+	return fmt.Sprintf("line %d, column %d", line, column)
+}
+
+// Keys returns the keys a config file may contain, sorted.
+func Keys() []string {
+	var keys []string
+	t := reflect.TypeOf(Config{})
+	for i := 0; i < t.NumField(); i++ {
+		keys = append(keys, t.Field(i).Tag.Get("json"))
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Validate checks the values a config sets.
+func (c *Config) Validate() error {
+	for _, dir := range c.NodesDirs {
+		if dir == "" {
+			return fmt.Errorf("nodes_dirs: empty directory")
+		}
+	}
+	for _, pattern := range c.ExcludeFiles {
+		if _, err := filepath.Match(pattern, ""); err != nil || pattern == "" {
+			return fmt.Errorf("exclude_files: invalid glob pattern %q", pattern)
+		}
+	}
+	if c.Jobs != nil && *c.Jobs < 1 {
+		return fmt.Errorf("jobs: must be at least 1, got %d", *c.Jobs)
+	}
+	if c.Timeout != nil && *c.Timeout < 0 {
+		return fmt.Errorf("timeout: must not be negative, got %s", time.Duration(*c.Timeout))
+	}
+	for _, format := range c.Formats {
+		if !isFormat(format) {
+			return fmt.Errorf("formats: unknown format %q (want one of %s)", format, strings.Join(Formats, ", "))
+		}
+	}
+	return nil
+}
+
+// isFormat reports whether format is one of Formats.
+func isFormat(format string) bool {
+	for _, f := range Formats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// resolve makes the config's relative paths relative to dir instead.
+func (c *Config) resolve(dir string) {
+	join := func(path string) string {
+		if path == "" || filepath.IsAbs(path) {
+			return path
+		}
+		return filepath.Join(dir, path)
+	}
+	for i, d := range c.NodesDirs {
+		c.NodesDirs[i] = join(d)
+	}
+	c.ASTOutDir = join(c.ASTOutDir)
+	c.ArchiveOutDir = join(c.ArchiveOutDir)
+	c.ReportOut = join(c.ReportOut)
+	c.Gate = join(c.Gate)
+}
+
+// Settings returns the flag values the config sets, in the order the
+// keys are declared. A repeatable flag appears once per value.
+func (c *Config) Settings() []Setting {
+	var settings []Setting
+	add := func(name, value string) {
+		settings = append(settings, Setting{Flag: name, Value: value})
+	}
+	for _, dir := range c.NodesDirs {
+		add("nodes-dir", dir)
+	}
+	if len(c.ExcludeFiles) > 0 {
+		add("exclude-files", strings.Join(c.ExcludeFiles, ","))
+	}
+	if c.ASTOutDir != "" {
+		add("ast-out-dir", c.ASTOutDir)
+	}
+	if c.ArchiveOutDir != "" {
+		add("archive-out-dir", c.ArchiveOutDir)
+	}
+	if c.ReportOut != "" {
+		add("report-out", c.ReportOut)
+	}
+	if c.Jobs != nil {
+		add("jobs", strconv.Itoa(*c.Jobs))
+	}
+	if c.Timeout != nil {
+		add("timeout", time.Duration(*c.Timeout).String())
+	}
+	if c.Gate != "" {
+		add("gate", c.Gate)
+	}
+	for _, format := range c.Formats {
+		add(format, "true")
+	}
+	return settings
+}
+
+// Apply sets the config's values on the flags in fs that the command line
+// did not set. fs must already have been parsed. Settings for flags fs
+// does not define are skipped, since one config serves every command.
+func (c *Config) Apply(fs *flag.FlagSet) error {
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	for _, s := range c.Settings() {
+		if fs.Lookup(s.Flag) == nil || set[s.Flag] {
+			continue
+		}
+		if err := fs.Set(s.Flag, s.Value); err != nil {
+			return fmt.Errorf("failed to apply config to -%s: %w", s.Flag, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeConfig writes a config file with contents to a temporary directory
+// and returns its path
+func writeConfig(t *testing.T, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), FileName)
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	return path
+}
+
+// TestLoad tests that every key is read, and relative paths are resolved
+// against the config file's directory
+func TestLoad(t *testing.T) {
+	path := writeConfig(t, `{
+  "nodes_dirs": ["nodes/go", "/abs/extra"],
+  "exclude_files": ["*_wip.go"],
+  "ast_out_dir": "out/ast",
+  "archive_out_dir": "out/asta",
+  "report_out": "out/report.txt",
+  "jobs": 4,
+  "timeout": "1m30s",
+  "gate": "gate.json",
+  "formats": ["json", "html"]
+}`)
+	c, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	dir := filepath.Dir(path)
+	if len(c.NodesDirs) != 2 || c.NodesDirs[0] != filepath.Join(dir, "nodes/go") || c.NodesDirs[1] != "/abs/extra" {
+		t.Errorf("expected nodes_dirs resolved against %s, got %v", dir, c.NodesDirs)
+	}
+	if c.ASTOutDir != filepath.Join(dir, "out/ast") || c.Gate != filepath.Join(dir, "gate.json") {
+		t.Errorf("expected paths resolved against %s, got %+v", dir, c)
+	}
+	if c.Jobs == nil || *c.Jobs != 4 || c.Timeout == nil || time.Duration(*c.Timeout) != 90*time.Second {
+		t.Errorf("expected jobs 4 and timeout 1m30s, got %+v", c)
+	}
+	if len(c.ExcludeFiles) != 1 || len(c.Formats) != 2 {
+		t.Errorf("expected the exclusions and formats, got %+v", c)
+	}
+}
+
+// TestLoadErrors tests that malformed and invalid config files are
+// rejected with an error saying what is wrong and where
+func TestLoadErrors(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		contents string
+		want     string
+	}{
+		{"syntax", "{\n  \"jobs\": 4,\n}", "line 3, column 1"},
+		{"unknown key", `{"jbos": 4}`, `unknown key "jbos" (want one of archive_out_dir, ast_out_dir,`},
+		{"wrong type", "{\n  \"jobs\": \"four\"\n}", `line 2, column 16: key "jobs": want int, got string`},
+		{"bad duration", `{"timeout": "soon"}`, `invalid duration "soon"`},
+		{"not a string duration", `{"timeout": 30}`, "invalid duration 30"},
+		{"trailing data", `{"jobs": 4} {}`, "unexpected data after the top-level object"},
+		{"zero jobs", `{"jobs": 0}`, "jobs: must be at least 1"},
+		{"negative timeout", `{"timeout": "-1s"}`, "timeout: must not be negative"},
+		{"unknown format", `{"formats": ["pdf"]}`, `formats: unknown format "pdf" (want one of json, junit, html, sarif, csv)`},
+		{"bad glob", `{"exclude_files": ["[a-"]}`, `exclude_files: invalid glob pattern "[a-"`},
+		{"empty directory", `{"nodes_dirs": [""]}`, "nodes_dirs: empty directory"},
+	} {
+		_, err := Load(writeConfig(t, tt.contents))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: expected an error containing %q, got %v", tt.name, tt.want, err)
+		}
+	}
+
+	if _, err := Load(filepath.Join(t.TempDir(), FileName)); err == nil || !strings.Contains(err.Error(), "failed to read config") {
+		t.Errorf("expected a missing file to fail, got %v", err)
+	}
+}
+
+// TestApply tests the precedence of flags over the config over built-in
+// defaults, and that keys for flags a command lacks are skipped
+func TestApply(t *testing.T) {
+	c, err := Load(writeConfig(t, `{"nodes_dirs": ["a", "b"], "jobs": 4, "timeout": "5s", "gate": "gate.json", "formats": ["json"]}`))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	var dirs []string
+	fs.Func("nodes-dir", "", func(dir string) error {
+		dirs = append(dirs, dir)
+		return nil
+	})
+	jobs := fs.Int("jobs", 1, "")
+	timeout := fs.Duration("timeout", 30*time.Second, "")
+	reportOut := fs.String("report-out", "coverage-report.txt", "")
+	saveJSON := fs.Bool("json", false, "")
+	if err := fs.Parse([]string{"-jobs", "2"}); err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if err := c.Apply(fs); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if *jobs != 2 {
+		t.Errorf("expected the -jobs flag to win over the config, got %d", *jobs)
+	}
+	if *timeout != 5*time.Second || !*saveJSON || len(dirs) != 2 {
+		t.Errorf("expected the config to win over the defaults, got timeout %s, json %v, dirs %v", *timeout, *saveJSON, dirs)
+	}
+	if *reportOut != "coverage-report.txt" {
+		t.Errorf("expected the default where the config is silent, got %q", *reportOut)
+	}
+}
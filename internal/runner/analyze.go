@@ -0,0 +1,115 @@
+package runner
+
+import (
+	"context"
+
+	"zylisp/go-ast-coverage/analyzer"
+)
+
+// Analyzer analyzes the sample file at path.
+type Analyzer func(path string) (*analyzer.AnalysisResult, error)
+
+// Analysis analyzes sample files for the orchestrator's analyze phase.
+// Only Analyze is required.
+type Analysis struct {
+	Analyze Analyzer
+
+	// Package parses a directory as a package, for RunPackages.
+	Package func(dir string) error
+
+	// FailFast stops the analysis at the first file that fails.
+	FailFast bool
+
+	// Begin, if set, is called as work on each file starts.
+	Begin func(path string)
+}
+
+// AnalyzedFile is the outcome of analyzing one sample file: its Result,
+// or Err when it could not be analyzed.
+type AnalyzedFile struct {
+	Path   string
+	Result *analyzer.AnalysisResult
+	Err    error
+}
+
+// PackageResult is the outcome of parsing one directory as a package.
+type PackageResult struct {
+	Dir string
+	Err error
+}
+
+// AnalysisSummary counts the outcomes of an analysis.
+type AnalysisSummary struct {
+	// Results holds the files analyzed successfully, in file order.
+	Results []*analyzer.AnalysisResult
+
+	// Failed counts the files that could not be analyzed.
+	Failed int
+
+	// Processed is the files whose results were handed over, in order.
+	Processed []string
+
+	// Skipped counts the files not analyzed because the run stopped
+	// early: with FailFast, or because ctx was done.
+	Skipped int
+
+	// Interrupted is set when ctx was done before every file was
+	// analyzed.
+	Interrupted bool
+}
+
+// Aggregated returns the statistics of every file analyzed successfully
+// together, or nil when there are none.
+func (s *AnalysisSummary) Aggregated() *analyzer.AnalysisResult {
+	if len(s.Results) == 0 {
+		return nil
+	}
+	return analyzer.AggregateResults(s.Results)
+}
+
+// Run analyzes files in order, calling report with each file's result as
+// it finishes. A file that fails is counted and the rest are still
+// analyzed; with FailFast the run stops after reporting it. When ctx is
+// done the run stops before the next file.
+func (a *Analysis) Run(ctx context.Context, files []string, report func(*AnalyzedFile)) *AnalysisSummary {
+	summary := &AnalysisSummary{}
+	processed := len(files)
+	for i, path := range files {
+		if ctx.Err() != nil {
+			processed = i
+			summary.Interrupted = true
+			break
+		}
+		if a.Begin != nil {
+			a.Begin(path)
+		}
+		result, err := a.Analyze(path)
+		report(&AnalyzedFile{Path: path, Result: result, Err: err})
+		if err != nil {
+			summary.Failed++
+			if a.FailFast {
+				processed = i + 1
+				break
+			}
+			continue
+		}
+		summary.Results = append(summary.Results, result)
+	}
+	summary.Processed = files[:processed]
+	summary.Skipped = len(files) - processed
+	return summary
+}
+
+// RunPackages parses each of dirs as a package with Package, calling
+// report with each result in turn, and returns how many failed.
+func (a *Analysis) RunPackages(dirs []string, report func(*PackageResult)) int {
+	failed := 0
+	for _, dir := range dirs {
+		result := &PackageResult{Dir: dir, Err: a.Package(dir)}
+		if result.Err != nil {
+			failed++
+		}
+		report(result)
+	}
+	return failed
+}
@@ -0,0 +1,121 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"zylisp/go-ast-coverage/analyzer"
+)
+
+// fakeAnalyze analyzes samples by name: bad*.go fail, and the rest have
+// as many nodes as their name has characters
+func fakeAnalyze(path string) (*analyzer.AnalysisResult, error) {
+	name := filepath.Base(path)
+	if strings.HasPrefix(name, "bad") {
+		return nil, errors.New("syntax error")
+	}
+	return &analyzer.AnalysisResult{
+		FileName:   name,
+		NodeCounts: map[string]int{"*ast.Ident": len(name)},
+		TotalNodes: len(name),
+	}, nil
+}
+
+// collectAnalysis runs an analysis of files, returning the paths reported
+// in order and the summary
+func collectAnalysis(a *Analysis, ctx context.Context, files []string) ([]string, *AnalysisSummary) {
+	var reported []string
+	summary := a.Run(ctx, files, func(file *AnalyzedFile) {
+		reported = append(reported, file.Path)
+	})
+	return reported, summary
+}
+
+// TestAnalysis tests that a failing file is counted without stopping the
+// rest, and that the successful results are aggregated
+func TestAnalysis(t *testing.T) {
+	files := []string{"a.go", "bad.go", "bb.go"}
+	var begun []string
+	a := &Analysis{Analyze: fakeAnalyze, Begin: func(path string) { begun = append(begun, path) }}
+
+	reported, summary := collectAnalysis(a, context.Background(), files)
+	if !slices.Equal(reported, files) || !slices.Equal(begun, files) {
+		t.Errorf("expected every file begun and reported in order, got %v and %v", begun, reported)
+	}
+	if summary.Failed != 1 || len(summary.Results) != 2 || summary.Skipped != 0 || summary.Interrupted {
+		t.Errorf("expected 1 failure and 2 results, got %+v", summary)
+	}
+	if !slices.Equal(summary.Processed, files) {
+		t.Errorf("expected every file processed, got %v", summary.Processed)
+	}
+	if got := summary.Aggregated(); got == nil || got.TotalNodes != len("a.go")+len("bb.go") {
+		t.Errorf("expected the two results aggregated, got %+v", got)
+	}
+
+	if got := (&AnalysisSummary{}).Aggregated(); got != nil {
+		t.Errorf("expected no aggregate without results, got %+v", got)
+	}
+}
+
+// TestAnalysisFailFast tests that FailFast stops after reporting the first
+// failure and counts the rest as skipped
+func TestAnalysisFailFast(t *testing.T) {
+	files := []string{"a.go", "bad.go", "b.go", "c.go"}
+	a := &Analysis{Analyze: fakeAnalyze, FailFast: true}
+
+	reported, summary := collectAnalysis(a, context.Background(), files)
+	if !slices.Equal(reported, files[:2]) || !slices.Equal(summary.Processed, files[:2]) {
+		t.Errorf("expected the run to stop at bad.go, got %v, processed %v", reported, summary.Processed)
+	}
+	if summary.Failed != 1 || summary.Skipped != 2 || summary.Interrupted {
+		t.Errorf("expected 1 failure and 2 skipped, got %+v", summary)
+	}
+}
+
+// TestAnalysisInterrupted tests that a done context stops the analysis
+// before the next file
+func TestAnalysisInterrupted(t *testing.T) {
+	files := []string{"a.go", "b.go", "c.go"}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	a := &Analysis{Analyze: func(path string) (*analyzer.AnalysisResult, error) {
+		if filepath.Base(path) == "b.go" {
+			cancel()
+		}
+		return fakeAnalyze(path)
+	}}
+
+	reported, summary := collectAnalysis(a, ctx, files)
+	if !slices.Equal(reported, files[:2]) || !summary.Interrupted || summary.Skipped != 1 {
+		t.Errorf("expected the run to stop after b.go, got %v and %+v", reported, summary)
+	}
+	if len(summary.Results) != 2 {
+		t.Errorf("expected the results so far to be kept, got %d", len(summary.Results))
+	}
+}
+
+// TestRunPackages tests that every directory is parsed as a package and
+// the failures counted
+func TestRunPackages(t *testing.T) {
+	a := &Analysis{Package: func(dir string) error {
+		if dir == "broken" {
+			return errors.New("no Go files")
+		}
+		return nil
+	}}
+
+	var reported []PackageResult
+	failed := a.RunPackages([]string{"nodes", "broken", "more"}, func(pkg *PackageResult) {
+		reported = append(reported, *pkg)
+	})
+	if failed != 1 || len(reported) != 3 {
+		t.Fatalf("expected 1 of 3 packages to fail, got %d of %v", failed, reported)
+	}
+	if reported[1].Dir != "broken" || reported[1].Err == nil || reported[0].Err != nil {
+		t.Errorf("expected only broken to fail, got %v", reported)
+	}
+}
@@ -0,0 +1,65 @@
+package runner
+
+import (
+	"context"
+
+	"zylisp/go-ast-coverage/generator"
+)
+
+// Writer writes the outputs generated from the sample file at path,
+// returning how many it wrote and a description of each that failed.
+type Writer func(path string) *generator.WriteAllResult
+
+// Generation writes the outputs of sample files for the orchestrator's
+// generate phase. Only Write is required.
+type Generation struct {
+	Write Writer
+
+	// Begin, if set, is called as work on each file starts.
+	Begin func(path string)
+}
+
+// GenerationSummary counts what a generation wrote.
+type GenerationSummary struct {
+	// Totals adds up every file's outputs and failures.
+	Totals generator.WriteAllResult
+
+	// Processed is the files written, in order.
+	Processed []string
+
+	// Skipped counts the files not written because ctx was done, and
+	// Interrupted is set when there were any.
+	Skipped     int
+	Interrupted bool
+}
+
+// Wrote reports whether any output was written.
+func (s *GenerationSummary) Wrote() bool {
+	return s.Totals.Dumps > 0 || s.Totals.Archives > 0
+}
+
+// Run writes the outputs of files in order, calling report with each
+// file's result. A failure in one file does not stop the rest. When ctx
+// is done the run stops before the next file.
+func (g *Generation) Run(ctx context.Context, files []string, report func(path string, result *generator.WriteAllResult)) *GenerationSummary {
+	summary := &GenerationSummary{}
+	processed := len(files)
+	for i, path := range files {
+		if ctx.Err() != nil {
+			processed = i
+			summary.Interrupted = true
+			break
+		}
+		if g.Begin != nil {
+			g.Begin(path)
+		}
+		result := g.Write(path)
+		summary.Totals.Dumps += result.Dumps
+		summary.Totals.Archives += result.Archives
+		summary.Totals.Failures = append(summary.Totals.Failures, result.Failures...)
+		report(path, result)
+	}
+	summary.Processed = files[:processed]
+	summary.Skipped = len(files) - processed
+	return summary
+}
@@ -0,0 +1,66 @@
+package runner
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"zylisp/go-ast-coverage/generator"
+)
+
+// fakeWrite writes samples by name: bad*.go fail to parse, dump*.go get a
+// text dump only, and the rest a text dump and an archive
+func fakeWrite(path string) *generator.WriteAllResult {
+	name := filepath.Base(path)
+	switch {
+	case strings.HasPrefix(name, "bad"):
+		return &generator.WriteAllResult{Failures: []string{name + ": failed to parse file"}}
+	case strings.HasPrefix(name, "dump"):
+		return &generator.WriteAllResult{Dumps: 1}
+	}
+	return &generator.WriteAllResult{Dumps: 1, Archives: 1}
+}
+
+// TestGeneration tests that every file's outputs and failures are added
+// up, and that a failure does not stop the rest
+func TestGeneration(t *testing.T) {
+	files := []string{"a.go", "bad.go", "dump.go"}
+	var reported []string
+	g := &Generation{Write: fakeWrite}
+	summary := g.Run(context.Background(), files, func(path string, result *generator.WriteAllResult) {
+		reported = append(reported, path)
+	})
+
+	if !slices.Equal(reported, files) || !slices.Equal(summary.Processed, files) {
+		t.Errorf("expected every file reported in order, got %v", reported)
+	}
+	if got := summary.Totals; got.Dumps != 2 || got.Archives != 1 || len(got.Failures) != 1 {
+		t.Errorf("expected 2 dumps, 1 archive, and 1 failure, got %+v", got)
+	}
+	if !summary.Wrote() || summary.Interrupted || summary.Skipped != 0 {
+		t.Errorf("unexpected summary %+v", summary)
+	}
+
+	summary = g.Run(context.Background(), []string{"bad.go"}, func(string, *generator.WriteAllResult) {})
+	if summary.Wrote() {
+		t.Errorf("expected nothing written, got %+v", summary.Totals)
+	}
+}
+
+// TestGenerationInterrupted tests that a done context stops the
+// generation before the next file
+func TestGenerationInterrupted(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	g := &Generation{Write: func(path string) *generator.WriteAllResult {
+		cancel()
+		return fakeWrite(path)
+	}}
+
+	summary := g.Run(ctx, []string{"a.go", "b.go", "c.go"}, func(string, *generator.WriteAllResult) {})
+	if !summary.Interrupted || summary.Skipped != 2 || !slices.Equal(summary.Processed, []string{"a.go"}) {
+		t.Errorf("expected the run to stop after a.go, got %+v", summary)
+	}
+}
@@ -0,0 +1,56 @@
+package runner
+
+import (
+	"fmt"
+
+	report "zylisp/go-ast-coverage/coverage-report"
+)
+
+// Reporter generates the coverage report for the sample files in dir.
+type Reporter func(dir string) (*report.CoverageReport, error)
+
+// BuildReport generates the coverage report for dirs with build. Several
+// directories are reported on separately and merged, so the report lists
+// what each contributed.
+func BuildReport(dirs []string, build Reporter) (*report.CoverageReport, error) {
+	if len(dirs) == 1 {
+		return build(dirs[0])
+	}
+
+	var reports []*report.CoverageReport
+	for _, dir := range dirs {
+		rep, err := build(dir)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", dir, err)
+		}
+		reports = append(reports, rep)
+	}
+	return report.MergeReports(reports...)
+}
+
+// Output is one file, or set of files, a report is saved to.
+type Output struct {
+	// Format names the output, such as "JSON", and Path says where it is
+	// saved.
+	Format string
+	Path   string
+
+	// Save writes the report.
+	Save func(rep *report.CoverageReport) error
+}
+
+// SaveReport saves rep to each of outputs in order, calling done with each
+// output and why it could not be saved, or nil, as soon as it is. A
+// failure does not stop the outputs after it. It returns how many
+// failed.
+func SaveReport(rep *report.CoverageReport, outputs []Output, done func(out *Output, err error)) int {
+	failed := 0
+	for i := range outputs {
+		err := outputs[i].Save(rep)
+		if err != nil {
+			failed++
+		}
+		done(&outputs[i], err)
+	}
+	return failed
+}
@@ -0,0 +1,83 @@
+package runner
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	report "zylisp/go-ast-coverage/coverage-report"
+)
+
+// fakeReport builds a report for dir covering one node type named after
+// it, failing for broken
+func fakeReport(dir string) (*report.CoverageReport, error) {
+	if dir == "broken" {
+		return nil, errors.New("no Go files")
+	}
+	return &report.CoverageReport{
+		SchemaVersion:    report.CurrentSchemaVersion,
+		TotalNodeTypes:   2,
+		CoveredNodeTypes: 1,
+		CoveragePercent:  50,
+		CoveredNodes:     []string{"*ast." + dir},
+		MissingNodes:     []string{"*ast.Other"},
+		Source:           dir,
+	}, nil
+}
+
+// TestBuildReport tests that one directory's report is used as it is and
+// several are merged, naming the directory that fails
+func TestBuildReport(t *testing.T) {
+	var built []string
+	build := func(dir string) (*report.CoverageReport, error) {
+		built = append(built, dir)
+		return fakeReport(dir)
+	}
+
+	rep, err := BuildReport([]string{"Ident"}, build)
+	if err != nil || rep.Source != "Ident" {
+		t.Fatalf("expected the directory's own report, got %+v, %v", rep, err)
+	}
+
+	built = nil
+	rep, err = BuildReport([]string{"Ident", "BasicLit"}, build)
+	if err != nil {
+		t.Fatalf("BuildReport failed: %v", err)
+	}
+	if !slices.Equal(built, []string{"Ident", "BasicLit"}) || len(rep.Sources) != 2 {
+		t.Errorf("expected both directories reported on and merged, got %v and %+v", built, rep.Sources)
+	}
+
+	if _, err := BuildReport([]string{"Ident", "broken"}, build); err == nil || !strings.HasPrefix(err.Error(), "broken: ") {
+		t.Errorf("expected the failing directory named, got %v", err)
+	}
+}
+
+// TestSaveReport tests that every output is saved in order, and that a
+// failure is reported and counted without stopping the rest
+func TestSaveReport(t *testing.T) {
+	var saved []string
+	output := func(format string, err error) Output {
+		return Output{Format: format, Path: "coverage." + strings.ToLower(format), Save: func(*report.CoverageReport) error {
+			saved = append(saved, format)
+			return err
+		}}
+	}
+	outputs := []Output{output("JSON", nil), output("HTML", errors.New("disk full")), output("Text", nil)}
+
+	var done []string
+	failed := SaveReport(&report.CoverageReport{}, outputs, func(out *Output, err error) {
+		done = append(done, out.Format)
+		if (err != nil) != (out.Format == "HTML") {
+			t.Errorf("%s: unexpected error %v", out.Format, err)
+		}
+	})
+	if failed != 1 {
+		t.Errorf("expected 1 failure, got %d", failed)
+	}
+	want := []string{"JSON", "HTML", "Text"}
+	if !slices.Equal(saved, want) || !slices.Equal(done, want) {
+		t.Errorf("expected every output saved and reported in order, got %v and %v", saved, done)
+	}
+}
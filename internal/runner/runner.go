@@ -0,0 +1,411 @@
+// Package runner carries out the orchestrator's file-by-file phases. A
+// Runner executes sample files for the run phase: several at a time, each
+// with a time limit and retries, handing each file's result over in file
+// order as soon as it and every file before it have finished, and counting
+// the outcomes. An Analysis and a Generation do the same for the analyze
+// and generate phases one file at a time, and BuildReport and SaveReport
+// build and save the coverage report. None does I/O of its own: samples
+// are executed, analyzed, written, cached, and checked through what they
+// are given, and results are returned rather than printed, so the caller
+// decides how to show them.
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Executor runs the sample file at path and returns its combined output.
+// When ctx is done it should stop the sample and return.
+type Executor func(ctx context.Context, path string) ([]byte, error)
+
+// Hook runs after a sample with how its execution ended, and returns its
+// combined output. When ctx is done it should stop and return.
+type Hook func(ctx context.Context, path string, status Status) ([]byte, error)
+
+// Checker compares a sample's output with what was expected, returning a
+// description of the difference, or "" when they match.
+type Checker func(path string, output []byte) (diff string, err error)
+
+// Cache holds the outcome of samples that passed, so that they need not
+// be executed again while they are unchanged.
+type Cache interface {
+	// Lookup returns the size of the output cached for path, and whether
+	// there is a usable entry.
+	Lookup(path string) (outputBytes int, ok bool)
+
+	// Store records that path passed with output.
+	Store(path string, output []byte) error
+}
+
+// Status is the outcome of one sample file.
+type Status int
+
+const (
+	// Passed means the sample ran successfully.
+	Passed Status = iota
+
+	// Cached means the sample passed before and was not executed.
+	Cached
+
+	// Failed means the sample failed on every attempt.
+	Failed
+
+	// TimedOut means the last attempt ran longer than the time limit.
+	TimedOut
+
+	// Mismatched means the sample ran successfully, but the Checker found
+	// its output differs from what was expected, or could not check it.
+	Mismatched
+
+	// HookFailed means the sample passed or was cached, but the Hook run
+	// after it failed or timed out.
+	HookFailed
+)
+
+func (s Status) String() string {
+	switch s {
+	case Passed:
+		return "passed"
+	case Cached:
+		return "cached"
+	case Failed:
+		return "failed"
+	case TimedOut:
+		return "timed out"
+	case Mismatched:
+		return "mismatched"
+	case HookFailed:
+		return "hook failed"
+	}
+	return "unknown"
+}
+
+// FileResult is the outcome of one sample file.
+type FileResult struct {
+	Path   string
+	Status Status
+
+	// Output is what the last attempt printed; for a Cached file it is
+	// nil, and CachedBytes is the size of the output when it passed.
+	Output      []byte
+	CachedBytes int
+
+	// Err is why the last attempt failed, for Failed and TimedOut files.
+	Err error
+
+	// Attempts is how many times the sample was executed, and Duration
+	// how long that took in all. Both are zero for a Cached file.
+	Attempts int
+	Duration time.Duration
+
+	// Diff and CheckErr are the Checker's result, for a Mismatched file.
+	Diff     string
+	CheckErr error
+
+	// CacheErr is why a passing run could not be stored in the Cache.
+	CacheErr error
+
+	// HookOutput and HookErr are what the Hook printed and why it failed,
+	// when there is a Hook.
+	HookOutput []byte
+	HookErr    error
+}
+
+// Flaky reports whether the sample ran successfully, but only after
+// failing at least once.
+func (r *FileResult) Flaky() bool {
+	return r.Attempts > 1 && (r.Status == Passed || r.Status == Mismatched)
+}
+
+// Summary counts the outcomes of a run.
+type Summary struct {
+	// Executed counts the samples that ran successfully, whether or not
+	// their output then matched.
+	Executed   int
+	Cached     int
+	Failed     int
+	TimedOut   int
+	Mismatched int
+	HookFailed int
+	Flaky      int
+
+	// Processed is the files whose results were handed over, in order.
+	Processed []string
+
+	// Skipped counts the files that were not processed because the run
+	// stopped early: with FailFast, or because ctx was done.
+	Skipped int
+
+	// Interrupted is set when ctx was done by the end of the run.
+	Interrupted bool
+}
+
+// Runner executes sample files. Only Execute is required.
+type Runner struct {
+	Execute Executor
+
+	// Jobs is how many samples run at once; less than 1 means 1.
+	Jobs int
+
+	// Timeout limits each attempt at a sample; 0 means no limit.
+	Timeout time.Duration
+
+	// Retries is how many more times a sample that fails or times out is
+	// attempted, waiting Backoff before the second attempt and that much
+	// longer again before each later one.
+	Retries int
+	Backoff time.Duration
+
+	// FailFast stops the run at the first file that does not pass or
+	// match, killing the samples still running.
+	FailFast bool
+
+	// Cache, if set, skips samples with a usable entry and stores those
+	// that pass.
+	Cache Cache
+
+	// Check, if set, checks the output of each sample that ran
+	// successfully.
+	Check Checker
+
+	// Hook, if set, runs after each sample, cached or not, as part of the
+	// same job and with its own Timeout.
+	Hook Hook
+
+	// Begin, if set, is called as work on each file starts, from the
+	// goroutine doing it.
+	Begin func(path string)
+
+	// Now is the clock samples are timed with; nil means time.Now.
+	Now func() time.Time
+}
+
+// Run executes files and calls report with each file's result in file
+// order, on the calling goroutine, as soon as that file and every one
+// before it have finished. With FailFast it stops after reporting the
+// first file that does not pass. When ctx is done, the samples running
+// are killed and the run stops at the first of them, without reporting
+// it, since it did not get to finish.
+func (r *Runner) Run(ctx context.Context, files []string, report func(*FileResult)) *Summary {
+	parent := ctx
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+
+	results := make([]FileResult, len(files))
+	summary := &Summary{}
+	interruptedAt := -1
+
+	reported := ForEachInOrder(len(files), r.Jobs, func(i int) {
+		if r.Begin != nil {
+			r.Begin(files[i])
+		}
+		results[i] = r.runFile(ctx, files[i])
+		if r.Hook != nil && ctx.Err() == nil {
+			r.runHook(ctx, &results[i])
+		}
+	}, func(next int) bool {
+		result := &results[next]
+		if (result.Status == Failed || result.Status == TimedOut || result.Status == HookFailed) && parent.Err() != nil {
+			interruptedAt = next
+			return false
+		}
+		r.finish(result)
+		summary.add(result)
+		report(result)
+
+		if r.FailFast && result.Status != Passed && result.Status != Cached {
+			cancel()
+			return false
+		}
+		return true
+	})
+
+	if interruptedAt >= 0 {
+		reported = interruptedAt
+	}
+	summary.Processed = files[:reported]
+	summary.Skipped = len(files) - reported
+	summary.Interrupted = parent.Err() != nil
+	return summary
+}
+
+// runFile executes one file, or finds it in the cache.
+func (r *Runner) runFile(ctx context.Context, path string) FileResult {
+	if r.Cache != nil {
+		if outputBytes, ok := r.Cache.Lookup(path); ok {
+			return FileResult{Path: path, Status: Cached, CachedBytes: outputBytes}
+		}
+	}
+
+	start := r.now()
+	result := runWithRetries(ctx, r.Execute, path, r.Timeout, r.Retries, r.Backoff)
+	result.Duration = r.now().Sub(start)
+	return result
+}
+
+// runHook runs the Hook after result's sample, recording a failure.
+func (r *Runner) runHook(ctx context.Context, result *FileResult) {
+	status := result.Status
+	hook := func(ctx context.Context, path string) ([]byte, error) {
+		return r.Hook(ctx, path, status)
+	}
+	output, timedOut, err := runSample(ctx, hook, result.Path, r.Timeout)
+	if timedOut {
+		err = fmt.Errorf("timed out after %s: %w", r.Timeout, err)
+	}
+	result.HookOutput, result.HookErr = output, err
+	if err != nil && (status == Passed || status == Cached) {
+		result.Status = HookFailed
+	}
+}
+
+// finish checks and caches a successful run, on the goroutine reporting
+// results, so that checks see files in order.
+func (r *Runner) finish(result *FileResult) {
+	if result.Status != Passed {
+		return
+	}
+	if r.Check != nil {
+		result.Diff, result.CheckErr = r.Check(result.Path, result.Output)
+		if result.Diff != "" || result.CheckErr != nil {
+			result.Status = Mismatched
+		}
+	}
+	// Only successful runs are cached
+	if r.Cache != nil {
+		result.CacheErr = r.Cache.Store(result.Path, result.Output)
+	}
+}
+
+// add counts result.
+func (s *Summary) add(result *FileResult) {
+	switch result.Status {
+	case Passed:
+		s.Executed++
+	case Cached:
+		s.Cached++
+	case Failed:
+		s.Failed++
+	case TimedOut:
+		s.TimedOut++
+	case Mismatched:
+		s.Executed++
+		s.Mismatched++
+	case HookFailed:
+		s.HookFailed++
+	}
+	if result.Flaky() {
+		s.Flaky++
+	}
+}
+
+func (r *Runner) now() time.Time {
+	if r.Now != nil {
+		return r.Now()
+	}
+	return time.Now()
+}
+
+// runWithRetries runs a sample, attempting it again up to retries more
+// times while it fails or times out, waiting attempt*backoff between
+// attempts. A cancelled run is not retried.
+func runWithRetries(ctx context.Context, execute Executor, path string, timeout time.Duration, retries int, backoff time.Duration) FileResult {
+	var result FileResult
+	for attempt := 1; ; attempt++ {
+		output, timedOut, err := runSample(ctx, execute, path, timeout)
+		result = FileResult{Path: path, Status: Passed, Output: output, Err: err, Attempts: attempt}
+		switch {
+		case timedOut:
+			result.Status = TimedOut
+		case err != nil:
+			result.Status = Failed
+		}
+		if err == nil || attempt > retries || ctx.Err() != nil {
+			return result
+		}
+
+		select {
+		case <-time.After(time.Duration(attempt) * backoff):
+		case <-ctx.Done():
+			return result
+		}
+	}
+}
+
+// runSample runs a sample file with execute and returns its combined
+// output. With a non-zero timeout, a run that exceeds it is stopped, and
+// timedOut is set.
+func runSample(ctx context.Context, execute Executor, path string, timeout time.Duration) (output []byte, timedOut bool, err error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	output, err = execute(ctx, path)
+	return output, errors.Is(ctx.Err(), context.DeadlineExceeded), err
+}
+
+// ForEachInOrder calls work for each index in 0..n-1, jobs at a time, and
+// done for each index in order on the calling goroutine as soon as its work
+// and that of every index before it has finished. done may read what work
+// wrote for its index without further synchronization. Once done returns
+// false no more work is started and done is not called again; work already
+// started is waited for, so the caller should cancel it. It returns how
+// many indexes done was called for.
+func ForEachInOrder(n, jobs int, work func(i int), done func(i int) bool) int {
+	if jobs < 1 {
+		jobs = 1
+	}
+	if jobs > n {
+		jobs = n
+	}
+
+	ready := make([]bool, n)
+	pending := make(chan int)
+	finished := make(chan int)
+	stop := make(chan struct{})
+	go func() {
+		defer close(pending)
+		for i := 0; i < n; i++ {
+			select {
+			case pending <- i:
+			case <-stop:
+				return
+			}
+		}
+	}()
+	var wg sync.WaitGroup
+	for w := 0; w < jobs; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range pending {
+				work(i)
+				finished <- i
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(finished)
+	}()
+
+	// Only this goroutine reads ready and calls done
+	next := 0
+	stopped := false
+	for i := range finished {
+		ready[i] = true
+		for ; !stopped && next < n && ready[next]; next++ {
+			if !done(next) {
+				stopped = true
+				close(stop)
+			}
+		}
+	}
+	return next
+}
@@ -0,0 +1,328 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeExecutor executes samples by name: pass*.go succeed, fail*.go fail,
+// flaky*.go fail on their first attempt only, and block*.go run until they
+// are stopped, sending their name on blocked if it is not nil
+type fakeExecutor struct {
+	mu      sync.Mutex
+	calls   map[string]int
+	blocked chan string
+}
+
+func newFakeExecutor() *fakeExecutor {
+	return &fakeExecutor{calls: make(map[string]int)}
+}
+
+func (f *fakeExecutor) execute(ctx context.Context, path string) ([]byte, error) {
+	name := filepath.Base(path)
+	f.mu.Lock()
+	f.calls[name]++
+	calls := f.calls[name]
+	f.mu.Unlock()
+
+	switch {
+	case strings.HasPrefix(name, "fail"):
+		return []byte("boom\n"), errors.New("exit status 1")
+	case strings.HasPrefix(name, "flaky") && calls == 1:
+		return []byte("flake\n"), errors.New("exit status 1")
+	case strings.HasPrefix(name, "block"):
+		if f.blocked != nil {
+			f.blocked <- name
+		}
+		<-ctx.Done()
+		return []byte("partial\n"), ctx.Err()
+	}
+	return []byte(name + " ok\n"), nil
+}
+
+func (f *fakeExecutor) callsTo(name string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls[name]
+}
+
+// fakeCache holds cached.go, and records what is stored, failing to store
+// readonly.go
+type fakeCache struct {
+	stored []string
+}
+
+func (c *fakeCache) Lookup(path string) (int, bool) {
+	return 42, filepath.Base(path) == "cached.go"
+}
+
+func (c *fakeCache) Store(path string, output []byte) error {
+	if filepath.Base(path) == "pass-readonly.go" {
+		return errors.New("read-only cache")
+	}
+	c.stored = append(c.stored, filepath.Base(path))
+	return nil
+}
+
+// collect runs files with r, returning the results in the order they were
+// reported and the summary
+func collect(r *Runner, ctx context.Context, files []string) ([]*FileResult, *Summary) {
+	var results []*FileResult
+	summary := r.Run(ctx, files, func(result *FileResult) {
+		results = append(results, result)
+	})
+	return results, summary
+}
+
+// TestRun tests each outcome, the order results are reported in, how they
+// are counted, and what is checked and cached
+func TestRun(t *testing.T) {
+	files := []string{"pass.go", "fail.go", "cached.go", "block.go", "flaky.go", "mismatch-pass.go", "pass-readonly.go"}
+	exec := newFakeExecutor()
+	cache := &fakeCache{}
+	var clock time.Time
+	var clockMu sync.Mutex
+	r := &Runner{
+		Execute: exec.execute,
+		Jobs:    3,
+		Timeout: 50 * time.Millisecond,
+		Retries: 1,
+		Cache:   cache,
+		Check: func(path string, output []byte) (string, error) {
+			if strings.HasPrefix(filepath.Base(path), "mismatch") {
+				return "-want\n+got\n", nil
+			}
+			return "", nil
+		},
+		Now: func() time.Time {
+			clockMu.Lock()
+			defer clockMu.Unlock()
+			clock = clock.Add(time.Second)
+			return clock
+		},
+	}
+
+	results, summary := collect(r, context.Background(), files)
+	if len(results) != len(files) {
+		t.Fatalf("expected %d results, got %d", len(files), len(results))
+	}
+	want := []Status{Passed, Failed, Cached, TimedOut, Passed, Mismatched, Passed}
+	for i, result := range results {
+		if result.Path != files[i] || result.Status != want[i] {
+			t.Errorf("result %d: expected %s %s, got %s %s", i, files[i], want[i], result.Path, result.Status)
+		}
+	}
+
+	if got := results[1]; got.Attempts != 2 || got.Err == nil || string(got.Output) != "boom\n" {
+		t.Errorf("expected fail.go to fail twice with its output, got %+v", got)
+	}
+	if got := results[2]; got.CachedBytes != 42 || got.Attempts != 0 || exec.callsTo("cached.go") != 0 {
+		t.Errorf("expected cached.go to come from the cache, got %+v", got)
+	}
+	if got := results[4]; !got.Flaky() || got.Attempts != 2 {
+		t.Errorf("expected flaky.go to pass on its second attempt, got %+v", got)
+	}
+	if got := results[5]; got.Diff == "" || got.Flaky() {
+		t.Errorf("expected mismatch-pass.go to carry its diff, got %+v", got)
+	}
+	if got := results[6]; got.CacheErr == nil {
+		t.Errorf("expected the cache error for pass-readonly.go, got %+v", got)
+	}
+	if got := results[0]; got.Duration != time.Second {
+		t.Errorf("expected pass.go to take one tick of the clock, got %s", got.Duration)
+	}
+
+	wantSummary := Summary{Executed: 4, Cached: 1, Failed: 1, TimedOut: 1, Mismatched: 1, Flaky: 1}
+	if summary.Executed != wantSummary.Executed || summary.Cached != wantSummary.Cached || summary.Failed != wantSummary.Failed ||
+		summary.TimedOut != wantSummary.TimedOut || summary.Mismatched != wantSummary.Mismatched || summary.Flaky != wantSummary.Flaky {
+		t.Errorf("expected %+v, got %+v", wantSummary, *summary)
+	}
+	if len(summary.Processed) != len(files) || summary.Skipped != 0 || summary.Interrupted {
+		t.Errorf("expected every file processed, got %+v", *summary)
+	}
+	if fmt.Sprint(cache.stored) != "[pass.go flaky.go mismatch-pass.go]" {
+		t.Errorf("expected only successful runs to be cached, got %v", cache.stored)
+	}
+}
+
+// TestRunFailFast tests that FailFast stops at the first file that does
+// not pass, stopping the samples still running and counting the rest as
+// skipped
+func TestRunFailFast(t *testing.T) {
+	exec := newFakeExecutor()
+	r := &Runner{Execute: exec.execute, Jobs: 2, FailFast: true}
+	files := []string{"pass.go", "fail.go", "block.go", "pass2.go", "pass3.go"}
+
+	results, summary := collect(r, context.Background(), files)
+	if len(results) != 2 || results[1].Status != Failed {
+		t.Fatalf("expected to stop after fail.go, got %d results", len(results))
+	}
+	if summary.Failed != 1 || summary.Executed != 1 || summary.Skipped != 3 || summary.Interrupted {
+		t.Errorf("unexpected summary %+v", *summary)
+	}
+	if exec.callsTo("pass3.go") != 0 {
+		t.Errorf("expected no more files to be started after the failure")
+	}
+}
+
+// TestRunInterrupted tests that when ctx is done the samples running are
+// stopped and the run ends at the first of them, without reporting it
+func TestRunInterrupted(t *testing.T) {
+	exec := newFakeExecutor()
+	exec.blocked = make(chan string, 4)
+	r := &Runner{Execute: exec.execute, Jobs: 2, Retries: 3, Backoff: time.Hour}
+	files := []string{"pass.go", "block1.go", "block2.go", "pass2.go"}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-exec.blocked
+		cancel()
+	}()
+	results, summary := collect(r, ctx, files)
+	if len(results) != 1 || results[0].Path != "pass.go" {
+		t.Errorf("expected only pass.go to be reported, got %d results", len(results))
+	}
+	if !summary.Interrupted || summary.Skipped != 3 || len(summary.Processed) != 1 {
+		t.Errorf("unexpected summary %+v", *summary)
+	}
+	if exec.callsTo("block1.go") != 1 {
+		t.Errorf("expected a stopped sample not to be retried, got %d attempts", exec.callsTo("block1.go"))
+	}
+}
+
+// TestRunHook tests that the Hook runs after every sample with its status,
+// that a failing or slow one marks a passing or cached file HookFailed,
+// and that it counts toward FailFast
+func TestRunHook(t *testing.T) {
+	exec := newFakeExecutor()
+	var mu sync.Mutex
+	seen := make(map[string]Status)
+	r := &Runner{
+		Execute: exec.execute,
+		Jobs:    2,
+		Timeout: 50 * time.Millisecond,
+		Cache:   &fakeCache{},
+		Hook: func(ctx context.Context, path string, status Status) ([]byte, error) {
+			mu.Lock()
+			seen[path] = status
+			mu.Unlock()
+			switch {
+			case strings.Contains(path, "lint"):
+				return []byte("lint error\n"), errors.New("exit status 2")
+			case strings.Contains(path, "slow"):
+				<-ctx.Done()
+				return nil, ctx.Err()
+			}
+			return []byte("hook ok\n"), nil
+		},
+	}
+	files := []string{"pass.go", "fail.go", "pass-lint.go", "cached.go", "pass-slow.go", "fail-lint.go"}
+
+	results, summary := collect(r, context.Background(), files)
+	want := []Status{Passed, Failed, HookFailed, Cached, HookFailed, Failed}
+	for i, result := range results {
+		if result.Status != want[i] {
+			t.Errorf("%s: expected %s, got %s", files[i], want[i], result.Status)
+		}
+	}
+	if seen["fail.go"] != Failed || seen["cached.go"] != Cached || seen["pass.go"] != Passed {
+		t.Errorf("expected the hook to be given each status, got %v", seen)
+	}
+	if got := results[2]; string(got.HookOutput) != "lint error\n" || got.HookErr == nil {
+		t.Errorf("expected pass-lint.go to carry the hook's failure, got %+v", got)
+	}
+	if got := results[4]; got.HookErr == nil || !strings.Contains(got.HookErr.Error(), "timed out") {
+		t.Errorf("expected pass-slow.go's hook to time out, got %v", got.HookErr)
+	}
+	if got := results[5]; got.HookErr == nil {
+		t.Errorf("expected fail-lint.go to record its hook's failure too")
+	}
+	if summary.HookFailed != 2 || summary.Executed != 1 || summary.Failed != 2 || summary.Cached != 1 {
+		t.Errorf("unexpected summary %+v", *summary)
+	}
+
+	r.FailFast = true
+	results, _ = collect(r, context.Background(), []string{"pass.go", "pass-lint.go", "pass2.go"})
+	if len(results) != 2 {
+		t.Errorf("expected -fail-fast to stop at the hook failure, got %d results", len(results))
+	}
+}
+
+// TestForEachInOrderStop tests that no more work is started once done
+// returns false
+func TestForEachInOrderStop(t *testing.T) {
+	var worked [10]bool
+	var reported []int
+	n := ForEachInOrder(len(worked), 1, func(i int) {
+		worked[i] = true
+	}, func(i int) bool {
+		reported = append(reported, i)
+		return i < 2
+	})
+
+	if n != 3 || len(reported) != 3 {
+		t.Errorf("expected 3 files reported, got %d: %v", n, reported)
+	}
+	// With one job, at most one more file can have been handed out
+	started := 0
+	for _, w := range worked {
+		if w {
+			started++
+		}
+	}
+	if started > 4 {
+		t.Errorf("expected work to stop after the failure, %d files started", started)
+	}
+}
+
+// failingExecutor returns an executor that fails the first failures times
+// for each file, and the number of times each file was run
+func failingExecutor(failures int) (Executor, map[string]int) {
+	var mu sync.Mutex
+	calls := make(map[string]int)
+	return func(ctx context.Context, path string) ([]byte, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls[path]++
+		if calls[path] <= failures {
+			return []byte("deadline exceeded\n"), errors.New("exit status 1")
+		}
+		return []byte("ok\n"), nil
+	}, calls
+}
+
+// TestRunWithRetries tests that a failing run is attempted again up to the
+// retry limit, stopping at the first success
+func TestRunWithRetries(t *testing.T) {
+	for _, tt := range []struct {
+		failures, retries int
+		wantAttempts      int
+		wantOK            bool
+	}{
+		{failures: 0, retries: 2, wantAttempts: 1, wantOK: true},
+		{failures: 1, retries: 2, wantAttempts: 2, wantOK: true},
+		{failures: 2, retries: 2, wantAttempts: 3, wantOK: true},
+		{failures: 3, retries: 2, wantAttempts: 3, wantOK: false},
+		{failures: 1, retries: 0, wantAttempts: 1, wantOK: false},
+	} {
+		execute, calls := failingExecutor(tt.failures)
+		result := runWithRetries(context.Background(), execute, "sample.go", 0, tt.retries, 0)
+		if result.Attempts != tt.wantAttempts || calls["sample.go"] != tt.wantAttempts || (result.Err == nil) != tt.wantOK {
+			t.Errorf("%d failures, %d retries: got %d attempts (%d runs), err %v", tt.failures, tt.retries, result.Attempts, calls["sample.go"], result.Err)
+		}
+	}
+
+	// A cancelled run is not retried
+	execute, calls := failingExecutor(5)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if result := runWithRetries(ctx, execute, "sample.go", 0, 3, time.Hour); result.Attempts != 1 || calls["sample.go"] != 1 {
+		t.Errorf("expected one attempt once cancelled, got %d", result.Attempts)
+	}
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"zylisp/go-ast-coverage/events"
+	"zylisp/go-ast-coverage/internal/cli"
+)
+
+const fixtureSource = `package main
+
+import "fmt"
+
+func main() {
+	fmt.Println("fixture")
+}
+`
+
+// writeFixture creates a directory holding a single runnable sample file.
+func writeFixture(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "fixture.go"), []byte(fixtureSource), 0644); err != nil {
+		t.Fatalf("failed to write fixture: %v", err)
+	}
+	return dir
+}
+
+// TestGenerateStdinPipeline tests piping source through the built binary
+func TestGenerateStdinPipeline(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping test - builds the binary")
+	}
+
+	bin := filepath.Join(t.TempDir(), "astcov")
+	if out, err := exec.Command("go", "build", "-o", bin, ".").CombinedOutput(); err != nil {
+		t.Fatalf("failed to build binary: %v\n%s", err, out)
+	}
+
+	cmd := exec.Command(bin, "-gen-in", "-", "-gen-name", "piped.go", "-gen-format", "ast")
+	cmd.Stdin = strings.NewReader(fixtureSource)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("pipeline failed: %v\n%s", err, stderr.String())
+	}
+
+	out := stdout.String()
+	if !strings.HasPrefix(out, "// file: piped.go\n// package: main\n// imports: fmt\n") {
+		t.Errorf("stdout should start with the dump header:\n%s", out)
+	}
+	if strings.Contains(out, "===") || !strings.Contains(out, "CallExpr") {
+		t.Errorf("stdout should hold only the dump:\n%s", out)
+	}
+
+	// Errors go to stderr and leave stdout empty
+	cmd = exec.Command(bin, "-gen-in", "-")
+	cmd.Stdin = strings.NewReader("package broken\n\nfunc {")
+	stdout.Reset()
+	stderr.Reset()
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err == nil {
+		t.Fatal("expected invalid source to fail")
+	}
+	if stdout.Len() != 0 || !strings.Contains(stderr.String(), "Error:") {
+		t.Errorf("expected error only on stderr: stdout=%q stderr=%q", stdout.String(), stderr.String())
+	}
+}
+
+// TestCoverageThresholdExit tests that the binary passes a met threshold
+// and exits with cli.ExitCoverage, listing missing node types, otherwise
+func TestCoverageThresholdExit(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping test - builds the binary")
+	}
+
+	tmp := t.TempDir()
+	bin := filepath.Join(tmp, "astcov")
+	if out, err := exec.Command("go", "build", "-o", bin, ".").CombinedOutput(); err != nil {
+		t.Fatalf("failed to build binary: %v\n%s", err, out)
+	}
+	dir := writeFixture(t)
+	reportOut := filepath.Join(tmp, "out", "coverage.txt")
+
+	// Run outside the repository so the default -baseline is not found
+	cmd := exec.Command(bin, "-analyze", "-nodes-dir", dir, "-report-out", reportOut, "-min-coverage", "1")
+	cmd.Dir = tmp
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("expected a met threshold to pass: %v\n%s", err, out)
+	}
+	if _, err := os.Stat(reportOut); err != nil {
+		t.Errorf("threshold did not imply -report: %v", err)
+	}
+
+	cmd = exec.Command(bin, "-analyze", "-nodes-dir", dir, "-report-out", reportOut, "-require-full")
+	cmd.Dir = tmp
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != cli.ExitCoverage {
+		t.Fatalf("expected exit status %d, got %v\n%s", cli.ExitCoverage, err, stderr.String())
+	}
+	if !strings.Contains(stderr.String(), "Missing node types") || !strings.Contains(stderr.String(), "  *ast.SelectStmt\n") {
+		t.Errorf("expected the missing node types on stderr:\n%s", stderr.String())
+	}
+}
+
+// TestExitStatuses tests the exit status of each kind of failure, driving
+// the binary against fixtures
+func TestExitStatuses(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping test - builds the binary")
+	}
+
+	tmp := t.TempDir()
+	bin := filepath.Join(tmp, "astcov")
+	if out, err := exec.Command("go", "build", "-o", bin, ".").CombinedOutput(); err != nil {
+		t.Fatalf("failed to build binary: %v\n%s", err, out)
+	}
+	dir := writeFixture(t)
+	sample := func(name, source string) string {
+		sampleDir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(sampleDir, name), []byte(source), 0644); err != nil {
+			t.Fatalf("failed to write sample: %v", err)
+		}
+		return sampleDir
+	}
+	failing := sample("fails.go", "package main\n\nfunc main() {\n\tpanic(\"boom\")\n}\n")
+	broken := sample("broken.go", "package main\n\nfunc main() {\n")
+	golden := sample("fixture.golden", "something else\n")
+
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{"success", []string{"analyze", "-nodes-dir", dir}, cli.ExitOK},
+		{"unknown command", []string{"bogus"}, cli.ExitError},
+		{"unknown flag", []string{"run", "-bogus"}, cli.ExitError},
+		{"missing corpus", []string{"analyze", "-nodes-dir", filepath.Join(tmp, "missing")}, cli.ExitError},
+		{"sample fails", []string{"run", "-nodes-dir", failing}, cli.ExitSamples},
+		{"analysis fails", []string{"analyze", "-nodes-dir", broken}, cli.ExitAnalysis},
+		{"coverage short", []string{"report", "-nodes-dir", dir, "-require-full"}, cli.ExitCoverage},
+		{"output mismatch", []string{"run", "-nodes-dir", dir, "-golden-dir", golden}, cli.ExitMismatch},
+	}
+	for _, tt := range tests {
+		cmd := exec.Command(bin, tt.args...)
+		cmd.Dir = tmp // keep the default report outputs out of the tree
+		out, err := cmd.CombinedOutput()
+		status := 0
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			status = exitErr.ExitCode()
+		} else if err != nil {
+			t.Fatalf("%s: failed to run: %v", tt.name, err)
+		}
+		if status != tt.want {
+			t.Errorf("%s: %v exited %d, want %d\n%s", tt.name, tt.args, status, tt.want, out)
+		}
+	}
+}
+
+// TestNDJSONOutput tests that -all -output ndjson writes only events to
+// stdout, in a consistent order
+func TestNDJSONOutput(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping test - builds the binary")
+	}
+
+	tmp := t.TempDir()
+	bin := filepath.Join(tmp, "astcov")
+	if out, err := exec.Command("go", "build", "-o", bin, ".").CombinedOutput(); err != nil {
+		t.Fatalf("failed to build binary: %v\n%s", err, out)
+	}
+	dir := writeFixture(t)
+	if err := os.WriteFile(filepath.Join(dir, "second.go"), []byte(fixtureSource), 0644); err != nil {
+		t.Fatalf("failed to write sample: %v", err)
+	}
+
+	cmd := exec.Command(bin, "-all", "-output", "ndjson", "-nodes-dir", dir, "-report-out", filepath.Join(tmp, "coverage.txt"))
+	cmd.Dir = tmp
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("run failed: %v\n%s", err, stderr.String())
+	}
+	if !strings.Contains(stderr.String(), "COVERAGE") {
+		t.Errorf("expected the text report on stderr")
+	}
+
+	stream, err := events.Read(&stdout)
+	if err != nil {
+		t.Fatalf("stdout is not an event stream: %v", err)
+	}
+	var types []string
+	for _, ev := range stream {
+		types = append(types, ev.EventType())
+	}
+	want := []string{
+		events.TypeRunStart,
+		events.TypeFileRunResult, events.TypeFileRunResult,
+		events.TypeAnalysisResult, events.TypeAnalysisResult,
+		events.TypeReportSummary,
+		events.TypeRunEnd,
+	}
+	if strings.Join(types, ",") != strings.Join(want, ",") {
+		t.Fatalf("unexpected event sequence %v", types)
+	}
+
+	start := stream[0].(*events.RunStart)
+	if len(start.Files) != 2 {
+		t.Errorf("run_start lists %d files, want 2", len(start.Files))
+	}
+	for i, ev := range stream[1:3] {
+		run := ev.(*events.FileRunResult)
+		if run.Name != filepath.Base(start.Files[i]) || !run.OK || run.OutputBytes != len("fixture\n") || run.DurationMS <= 0 {
+			t.Errorf("unexpected file_run_result %+v", run)
+		}
+	}
+	for i, ev := range stream[3:5] {
+		if res := ev.(*events.AnalysisResult); res.File != start.Files[i] || res.Nodes == 0 || res.UniqueTypes == 0 {
+			t.Errorf("unexpected analysis_result %+v", res)
+		}
+	}
+	summary := stream[5].(*events.ReportSummary)
+	if summary.Total != summary.Covered+len(summary.Missing) || summary.Percent <= 0 {
+		t.Errorf("inconsistent report_summary %+v", summary)
+	}
+	if end := stream[6].(*events.RunEnd); end.ExitStatus != 0 {
+		t.Errorf("run_end exit status %d, want 0", end.ExitStatus)
+	}
+}
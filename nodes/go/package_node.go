@@ -9,11 +9,20 @@ import (
 	"go/parser"
 	"go/token"
 	"os"
+	"path/filepath"
+	"sort"
 )
 
 // AST Nodes Covered:
 // - *ast.Package - Created by parser.ParseDir() when parsing a directory
 
+// packageFiles is the package parsed into an ast.Package: two files
+// sharing one package scope.
+var packageFiles = map[string]string{
+	"shapes.go": "package shapes\n\nimport \"math\"\n\n// Circle is a shape.\ntype Circle struct{ R float64 }\n\n// Area returns the circle's area.\nfunc (c Circle) Area() float64 { return math.Pi * c.R * c.R }\n",
+	"square.go": "package shapes\n\n// Square is a shape.\ntype Square struct{ Side float64 }\n\n// Area returns the square's area.\nfunc (s Square) Area() float64 { return s.Side * s.Side }\n",
+}
+
 func main() {
 	fmt.Println("=== package_node.go AST Node Coverage ===")
 	fmt.Println("Exercising AST Nodes:")
@@ -25,15 +34,23 @@ func main() {
 	fmt.Println("  that collectively form a Go package.")
 	fmt.Println()
 
-	// Demonstrate creating an ast.Package by parsing the current directory
+	// Demonstrate creating an ast.Package by parsing a directory. The
+	// package is written to a temporary directory of its own, so the
+	// result does not depend on where the sample is run from.
 	fmt.Println("  ✓ Creating ast.Package by parsing directory:")
 
-	// Get current directory
-	dir, err := os.Getwd()
+	dir, err := os.MkdirTemp("", "package-node-")
 	if err != nil {
-		fmt.Printf("    Error getting working directory: %v\n", err)
+		fmt.Printf("    Error creating directory: %v\n", err)
 		return
 	}
+	defer os.RemoveAll(dir)
+	for name, source := range packageFiles {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(source), 0644); err != nil {
+			fmt.Printf("    Error writing %s: %v\n", name, err)
+			return
+		}
+	}
 
 	// Parse the directory as a package
 	fset := token.NewFileSet()
@@ -58,7 +75,12 @@ func main() {
 
 		// Show files in the package
 		fmt.Println("    Files in package:")
+		var fileNames []string
 		for fileName := range pkg.Files {
+			fileNames = append(fileNames, filepath.Base(fileName))
+		}
+		sort.Strings(fileNames)
+		for _, fileName := range fileNames {
 			fmt.Printf("      - %s\n", fileName)
 		}
 		fmt.Println()
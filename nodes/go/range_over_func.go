@@ -0,0 +1,143 @@
+// Package main demonstrates range-over-func AST nodes (Go 1.23+).
+// This file exercises ast.RangeStmt ranging over iterator functions.
+package main
+
+import (
+	"fmt"
+	"iter"
+	"maps"
+	"slices"
+)
+
+// AST Nodes Covered:
+// - ast.RangeStmt (X of function type: no value, one value, two values)
+// - ast.FuncType (yield parameters: func() bool, func(V) bool, func(K, V) bool)
+// - ast.FuncLit (iterator returned by a function, ranged over directly)
+// - ast.BranchStmt (break and continue inside an iterator loop)
+
+// countTo returns a single-value iterator over 1..n.
+func countTo(n int) func(yield func(int) bool) {
+	return func(yield func(int) bool) {
+		for i := 1; i <= n; i++ {
+			if !yield(i) {
+				return
+			}
+		}
+	}
+}
+
+// enumerate returns a two-value iterator over the index and word pairs.
+func enumerate(words []string) iter.Seq2[int, string] {
+	return func(yield func(int, string) bool) {
+		for i, w := range words {
+			if !yield(i, w) {
+				return
+			}
+		}
+	}
+}
+
+// Evens is a named single-value iterator type.
+type Evens iter.Seq[int]
+
+// evensBelow returns the even numbers below n as a named iterator type.
+func evensBelow(n int) Evens {
+	return func(yield func(int) bool) {
+		for i := 0; i < n; i += 2 {
+			if !yield(i) {
+				return
+			}
+		}
+	}
+}
+
+// threeTimes is a zero-value iterator: it yields nothing but calls.
+func threeTimes(yield func() bool) {
+	for i := 0; i < 3; i++ {
+		if !yield() {
+			return
+		}
+	}
+}
+
+func funcMain() {
+	fmt.Println("=== range_over_func.go AST Node Coverage ===")
+	fmt.Println("Exercising AST Nodes:")
+
+	// Single-value iterator returned by a call
+	fmt.Print("  ✓ ast.RangeStmt (one value, func(yield func(int) bool)):")
+	for n := range countTo(4) {
+		fmt.Printf(" %d", n)
+	}
+	fmt.Println()
+
+	// Two-value iterator
+	fmt.Print("  ✓ ast.RangeStmt (two values, iter.Seq2):")
+	for i, w := range enumerate([]string{"alpha", "beta", "gamma"}) {
+		fmt.Printf(" %d=%s", i, w)
+	}
+	fmt.Println()
+
+	// Early break: yield returns false and the iterator stops
+	fmt.Print("  ✓ ast.RangeStmt (early break):")
+	for n := range countTo(100) {
+		if n > 3 {
+			break
+		}
+		fmt.Printf(" %d", n)
+	}
+	fmt.Println()
+
+	// Continue skips to the next yielded value
+	fmt.Print("  ✓ ast.RangeStmt (continue):")
+	for i, w := range enumerate([]string{"keep", "skip", "keep"}) {
+		if w == "skip" {
+			continue
+		}
+		fmt.Printf(" %d=%s", i, w)
+	}
+	fmt.Println()
+
+	// Named iterator type held in a variable
+	evens := evensBelow(7)
+	fmt.Print("  ✓ ast.RangeStmt (named iterator type in a variable):")
+	for n := range evens {
+		fmt.Printf(" %d", n)
+	}
+	fmt.Println()
+
+	// Zero-value iterator, ranged over by name with no variables
+	calls := 0
+	for range threeTimes {
+		calls++
+	}
+	fmt.Printf("  ✓ ast.RangeStmt (no values, function by name): %d calls\n", calls)
+
+	// Function literal ranged over directly
+	fmt.Print("  ✓ ast.RangeStmt (func literal):")
+	for s := range func(yield func(string) bool) {
+		_ = yield("x") && yield("y")
+	} {
+		fmt.Printf(" %s", s)
+	}
+	fmt.Println()
+
+	// Standard library iterators, sorted for deterministic output
+	ages := map[string]int{"bob": 31, "alice": 29}
+	keys := slices.Sorted(maps.Keys(ages))
+	fmt.Printf("  ✓ maps.Keys collected with slices.Sorted: %v\n", keys)
+	fmt.Print("  ✓ ast.RangeStmt (slices.Backward):")
+	for i, k := range slices.Backward(keys) {
+		fmt.Printf(" %d=%s", i, k)
+	}
+	fmt.Println()
+
+	fmt.Println("Summary: range-over-func AST node coverage (Go 1.23+)")
+	fmt.Println("Primary AST Nodes: ast.RangeStmt over iterator functions")
+	fmt.Println("Features: single- and two-value iterators, iter.Seq/Seq2, early break, func literals")
+	fmt.Println("========================================")
+}
+
+func main() {
+	funcMain()
+}
@@ -0,0 +1,77 @@
+// Package main demonstrates range-over-int AST nodes (Go 1.22+).
+// This file exercises ast.RangeStmt ranging over integers.
+package main
+
+import "fmt"
+
+// AST Nodes Covered:
+// - ast.RangeStmt (X a BasicLit: for i := range 10)
+// - ast.RangeStmt (no Key, Tok ILLEGAL: for range n)
+// - ast.RangeStmt (Tok = with a declared variable: for i = range n)
+// - ast.RangeStmt (X a constant, len call, or arithmetic expression)
+
+// rows is an untyped constant bound for range-over-int.
+const rows = 3
+
+func funcMain() {
+	fmt.Println("=== range_over_int.go AST Node Coverage ===")
+	fmt.Println("Exercising AST Nodes:")
+
+	// Single variable with :=, ranging over an integer literal
+	fmt.Print("  ✓ ast.RangeStmt (for i := range 5):")
+	for i := range 5 {
+		fmt.Printf(" %d", i)
+	}
+	fmt.Println()
+
+	// Bare form: no iteration variable at all
+	n := 4
+	ticks := 0
+	for range n {
+		ticks++
+	}
+	fmt.Printf("  ✓ ast.RangeStmt (for range n): %d ticks\n", ticks)
+
+	// Single variable with =, assigning to a variable declared earlier
+	var last int
+	for last = range 3 {
+	}
+	fmt.Printf("  ✓ ast.RangeStmt (for i = range 3): last = %d\n", last)
+
+	// Constant bound
+	fmt.Print("  ✓ ast.RangeStmt (range over a constant):")
+	for r := range rows {
+		fmt.Printf(" row%d", r)
+	}
+	fmt.Println()
+
+	// len call and arithmetic bounds
+	words := []string{"a", "b", "c", "d"}
+	fmt.Print("  ✓ ast.RangeStmt (range len(words)-1):")
+	for i := range len(words) - 1 {
+		fmt.Printf(" %s%s", words[i], words[i+1])
+	}
+	fmt.Println()
+
+	// Typed integer bound: the variable takes the bound's type
+	var small int8 = 2
+	for i := range small {
+		fmt.Printf("  ✓ ast.RangeStmt (int8 bound): i=%d has type %T\n", i, i)
+	}
+
+	// A zero or negative bound runs no iterations
+	count := 0
+	for range -1 {
+		count++
+	}
+	fmt.Printf("  ✓ ast.RangeStmt (negative bound): %d iterations\n", count)
+
+	fmt.Println("Summary: range-over-int AST node coverage (Go 1.22+)")
+	fmt.Println("Primary AST Nodes: ast.RangeStmt with an integer X")
+	fmt.Println("Features: for range n, for i := range n, for i = range n")
+	fmt.Println("========================================")
+}
+
+func main() {
+	funcMain()
+}